@@ -2,13 +2,18 @@ module github.com/sw5005-sus/ceramicraft-order-mservice/client
 
 go 1.25.7
 
-require google.golang.org/grpc v1.75.1
+require (
+	github.com/sw5005-sus/ceramicraft-order-mservice/common v0.0.1
+	google.golang.org/grpc v1.75.1
+)
 
 require (
-	github.com/sw5005-sus/ceramicraft-order-mservice/common v0.0.1 // indirect
 	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+// common 与客户端在同一仓库中开发，直接引用本地代码
+replace github.com/sw5005-sus/ceramicraft-order-mservice/common => ../common
@@ -4,30 +4,34 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/sw5005-sus/ceramicraft-order-mservice/common/demopb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/orderpb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
 	conn           *grpc.ClientConn
-	client         demopb.DemoServiceClient
+	client         orderpb.OrderServiceClient
 	clientSyncOnce sync.Once
 )
 
-func GetUserServiceClient(config *GRpcClientConfig) (demopb.DemoServiceClient, error) {
+func GetOrderServiceClient(config *GRpcClientConfig) (orderpb.OrderServiceClient, error) {
+	var err error
 	clientSyncOnce.Do(func() {
 		opts := []grpc.DialOption{
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
 			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(1024 * 1024)),
 			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(1024 * 1024)),
 		}
-		conn, err := grpc.NewClient(fmt.Sprintf("%s:%d", config.Host, config.Port), opts...)
+		conn, err = grpc.NewClient(fmt.Sprintf("%s:%d", config.Host, config.Port), opts...)
 		if err != nil {
-			panic(err)
+			return
 		}
-		client = demopb.NewDemoServiceClient(conn)
+		client = orderpb.NewOrderServiceClient(conn)
 	})
+	if err != nil {
+		return nil, err
+	}
 	return client, nil
 }
 
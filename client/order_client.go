@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/orderpb"
+)
+
+// Error 订单服务返回的非 SUCCESS 响应
+type Error struct {
+	Code orderpb.RespCode
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("order service error %d: %s", e.Code, e.Msg)
+}
+
+// IsNotFound 判断错误是否为订单不存在
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == orderpb.RespCode_NOT_FOUND
+}
+
+// OrderClient 对 orderpb.OrderServiceClient 的封装，将响应码转换为 error
+type OrderClient struct {
+	rpc orderpb.OrderServiceClient
+}
+
+func NewOrderClient(config *GRpcClientConfig) (*OrderClient, error) {
+	rpc, err := GetOrderServiceClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderClient{rpc: rpc}, nil
+}
+
+func (c *OrderClient) GetOrder(ctx context.Context, orderNo string) (*orderpb.Order, error) {
+	resp, err := c.rpc.GetOrder(ctx, &orderpb.GetOrderRequest{OrderNo: orderNo})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCode(resp.Code, resp.ErrorMsg); err != nil {
+		return nil, err
+	}
+	return resp.Order, nil
+}
+
+func (c *OrderClient) ListOrders(ctx context.Context, req *orderpb.ListOrdersRequest) ([]*orderpb.OrderSummary, int32, error) {
+	resp, err := c.rpc.ListOrders(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkCode(resp.Code, resp.ErrorMsg); err != nil {
+		return nil, 0, err
+	}
+	return resp.Orders, resp.Total, nil
+}
+
+// BatchGetOrders 返回找到的订单以及不存在的订单号
+func (c *OrderClient) BatchGetOrders(ctx context.Context, orderNos []string) ([]*orderpb.Order, []string, error) {
+	resp, err := c.rpc.BatchGetOrders(ctx, &orderpb.BatchGetOrdersRequest{OrderNos: orderNos})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCode(resp.Code, resp.ErrorMsg); err != nil {
+		return nil, nil, err
+	}
+	return resp.Orders, resp.NotFoundOrderNos, nil
+}
+
+func (c *OrderClient) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) error {
+	resp, err := c.rpc.UpdateOrderStatus(ctx, req)
+	if err != nil {
+		return err
+	}
+	return checkCode(resp.Code, resp.ErrorMsg)
+}
+
+func (c *OrderClient) GetOrderStats(ctx context.Context) (*orderpb.GetOrderStatsResponse, error) {
+	resp, err := c.rpc.GetOrderStats(ctx, &orderpb.GetOrderStatsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCode(resp.Code, resp.ErrorMsg); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func checkCode(code int32, msg string) error {
+	if code == int32(orderpb.RespCode_SUCCESS) {
+		return nil
+	}
+	return &Error{Code: orderpb.RespCode(code), Msg: msg}
+}
@@ -0,0 +1,152 @@
+// Package events 定义订单服务发布到 kafka 的事件契约：CloudEvents 格式的信封，
+// 以及各 topic 的事件类型、数据格式版本和 eventspb 中的数据定义。
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/eventspb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	SPEC_VERSION      = "1.0"
+	SOURCE            = "ceramicraft-order-mservice"
+	DATA_CONTENT_TYPE = "application/json"
+)
+
+const (
+	ORDER_CREATED_TOPIC        = "order_created"
+	ORDER_CANCELED_TOPIC       = "order_canceled"
+	ORDER_STATUS_CHANGED_TOPIC = "order_status_changed"
+)
+
+var (
+	ErrInvalidEnvelope = errors.New("invalid event envelope")
+	ErrInvalidData     = errors.New("event data does not match schema")
+)
+
+// Envelope CloudEvents 1.0 JSON 格式的事件信封，schemaversion 为扩展属性
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`      // 事件ID，重复投递时不变，消费者据此去重
+	Source          string          `json:"source"`  // 产生事件的服务
+	Type            string          `json:"type"`    // 事件类型，不兼容的修改使用新的类型
+	Time            time.Time       `json:"time"`    // 事件发生时间
+	Subject         string          `json:"subject"` // 事件关联的订单号
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema"`    // data 的 protobuf 定义
+	SchemaVersion   string          `json:"schemaversion"` // data 的格式版本，兼容的修改只升级次版本号
+	Data            json.RawMessage `json:"data"`
+}
+
+// Contract 一个 topic 的事件契约
+type Contract struct {
+	Topic         string
+	Type          string
+	SchemaVersion string
+	// Required data 中必须有非零值的字段
+	Required   []string
+	newMessage func() proto.Message
+}
+
+var contracts = map[string]*Contract{
+	ORDER_CREATED_TOPIC: {
+		Topic:         ORDER_CREATED_TOPIC,
+		Type:          "com.ceramicraft.order.created.v1",
+		SchemaVersion: "1.0",
+		Required:      []string{"user_id", "order_id", "order_item_list"},
+		newMessage:    func() proto.Message { return &eventspb.OrderMessage{} },
+	},
+	ORDER_CANCELED_TOPIC: {
+		Topic:         ORDER_CANCELED_TOPIC,
+		Type:          "com.ceramicraft.order.canceled.v1",
+		SchemaVersion: "1.0",
+		Required:      []string{"user_id", "order_id", "order_item_list"},
+		newMessage:    func() proto.Message { return &eventspb.OrderMessage{} },
+	},
+	ORDER_STATUS_CHANGED_TOPIC: {
+		Topic:         ORDER_STATUS_CHANGED_TOPIC,
+		Type:          "com.ceramicraft.order.status_changed.v1",
+		SchemaVersion: "1.0",
+		Required:      []string{"order_no", "user_id", "current_status"},
+		newMessage:    func() proto.Message { return &eventspb.OrderStatusChangedMessage{} },
+	},
+}
+
+// Lookup 返回 topic 的事件契约，没有契约的 topic 返回 false
+func Lookup(topic string) (*Contract, bool) {
+	contract, ok := contracts[topic]
+	return contract, ok
+}
+
+// Contracts 返回所有事件契约
+func Contracts() []*Contract {
+	return []*Contract{contracts[ORDER_CREATED_TOPIC], contracts[ORDER_CANCELED_TOPIC], contracts[ORDER_STATUS_CHANGED_TOPIC]}
+}
+
+// Descriptor 返回 data 的 protobuf 定义
+func (c *Contract) Descriptor() protoreflect.MessageDescriptor {
+	return c.newMessage().ProtoReflect().Descriptor()
+}
+
+// DataSchema 返回信封中 dataschema 属性的值
+func (c *Contract) DataSchema() string {
+	return "proto:" + string(c.Descriptor().FullName())
+}
+
+// Validate 按契约校验 data：不允许未定义的字段和类型不符的值，必填字段不能为空
+func (c *Contract) Validate(data []byte) error {
+	msg := c.newMessage()
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidData, c.Type, err.Error())
+	}
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	for _, name := range c.Required {
+		if !msg.ProtoReflect().Has(fields.ByName(protoreflect.Name(name))) {
+			return fmt.Errorf("%w: %s: missing required field %s", ErrInvalidData, c.Type, name)
+		}
+	}
+	return nil
+}
+
+// New 校验 data 并生成 topic 对应类型的事件信封
+func New(topic, id string, occurredAt time.Time, subject string, data []byte) (*Envelope, error) {
+	contract, ok := Lookup(topic)
+	if !ok {
+		return nil, fmt.Errorf("%w: no contract for topic %s", ErrInvalidEnvelope, topic)
+	}
+	if err := contract.Validate(data); err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		SpecVersion:     SPEC_VERSION,
+		ID:              id,
+		Source:          SOURCE,
+		Type:            contract.Type,
+		Time:            occurredAt,
+		Subject:         subject,
+		DataContentType: DATA_CONTENT_TYPE,
+		DataSchema:      contract.DataSchema(),
+		SchemaVersion:   contract.SchemaVersion,
+		Data:            data,
+	}, nil
+}
+
+// Parse 解析事件信封；value 不是 CloudEvents 信封时返回 ErrInvalidEnvelope，
+// 消费者可以据此兼容信封上线前生产的消息
+func Parse(value []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEnvelope, err.Error())
+	}
+	if envelope.SpecVersion == "" || envelope.ID == "" || envelope.Type == "" || len(envelope.Data) == 0 {
+		return nil, fmt.Errorf("%w: missing required attributes", ErrInvalidEnvelope)
+	}
+	return &envelope, nil
+}
@@ -0,0 +1,233 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// 修改契约后确认兼容，用 go test ./events -update 更新快照
+var update = flag.Bool("update", false, "update the contract snapshot")
+
+const snapshotFile = "testdata/contracts.json"
+
+type fieldSnapshot struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Cardinality string `json:"cardinality"`
+	Message     string `json:"message,omitempty"`
+}
+
+type contractSnapshot struct {
+	Type          string                              `json:"type"`
+	SchemaVersion string                              `json:"schema_version"`
+	Required      []string                            `json:"required"`
+	Message       string                              `json:"message"`
+	Messages      map[string]map[int32]*fieldSnapshot `json:"messages"`
+}
+
+func snapshotMessage(md protoreflect.MessageDescriptor, messages map[string]map[int32]*fieldSnapshot) {
+	if _, ok := messages[string(md.FullName())]; ok {
+		return
+	}
+	fields := map[int32]*fieldSnapshot{}
+	messages[string(md.FullName())] = fields
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		field := &fieldSnapshot{Name: string(fd.Name()), Kind: fd.Kind().String(), Cardinality: fd.Cardinality().String()}
+		if fd.Message() != nil {
+			field.Message = string(fd.Message().FullName())
+			snapshotMessage(fd.Message(), messages)
+		}
+		fields[int32(fd.Number())] = field
+	}
+}
+
+func snapshotContracts() map[string]*contractSnapshot {
+	snapshots := map[string]*contractSnapshot{}
+	for _, contract := range Contracts() {
+		snapshot := &contractSnapshot{
+			Type:          contract.Type,
+			SchemaVersion: contract.SchemaVersion,
+			Required:      contract.Required,
+			Message:       string(contract.Descriptor().FullName()),
+			Messages:      map[string]map[int32]*fieldSnapshot{},
+		}
+		snapshotMessage(contract.Descriptor(), snapshot.Messages)
+		snapshots[contract.Topic] = snapshot
+	}
+	return snapshots
+}
+
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// checkCompatible 返回 current 相对 released 的不兼容修改：
+// 删除 topic、修改事件类型或主版本、删除/改名/改类型字段、取消必填
+func checkCompatible(released, current map[string]*contractSnapshot) (breaking []string) {
+	for topic, old := range released {
+		cur, ok := current[topic]
+		if !ok {
+			breaking = append(breaking, topic+": contract removed")
+			continue
+		}
+		if cur.Type != old.Type || majorVersion(cur.SchemaVersion) != majorVersion(old.SchemaVersion) {
+			breaking = append(breaking, topic+": event type or major schema version changed, publish a new event type instead")
+		}
+		for _, name := range old.Required {
+			if !contains(cur.Required, name) {
+				breaking = append(breaking, topic+": field "+name+" is no longer required")
+			}
+		}
+		for msgName, oldFields := range old.Messages {
+			curFields, ok := cur.Messages[msgName]
+			if !ok {
+				breaking = append(breaking, topic+": message "+msgName+" removed")
+				continue
+			}
+			for number, oldField := range oldFields {
+				curField, ok := curFields[number]
+				if !ok {
+					breaking = append(breaking, topic+": field "+msgName+"."+oldField.Name+" removed")
+					continue
+				}
+				if *curField != *oldField {
+					breaking = append(breaking, topic+": field "+msgName+"."+oldField.Name+" changed")
+				}
+			}
+		}
+	}
+	return breaking
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TestContracts_Compatible fails the build on breaking changes to the published event contracts
+func TestContracts_Compatible(t *testing.T) {
+	current := snapshotContracts()
+	if *update {
+		raw, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			t.Fatalf("Marshal snapshot failed: %v", err)
+		}
+		if err = os.WriteFile(snapshotFile, append(raw, '\n'), 0o644); err != nil {
+			t.Fatalf("Write snapshot failed: %v", err)
+		}
+		return
+	}
+
+	raw, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		t.Fatalf("Read snapshot failed: %v", err)
+	}
+	var released map[string]*contractSnapshot
+	if err = json.Unmarshal(raw, &released); err != nil {
+		t.Fatalf("Parse snapshot failed: %v", err)
+	}
+
+	for _, change := range checkCompatible(released, current) {
+		t.Errorf("Breaking change: %s", change)
+	}
+	if t.Failed() {
+		return
+	}
+	for topic, cur := range current {
+		old, ok := released[topic]
+		if ok && !reflect.DeepEqual(old.Messages, cur.Messages) && old.SchemaVersion == cur.SchemaVersion {
+			t.Errorf("%s: fields added without bumping the schema version", topic)
+		}
+	}
+	if !reflect.DeepEqual(released, current) {
+		t.Errorf("Contracts changed compatibly, run go test ./events -update to record them")
+	}
+}
+
+// TestCheckCompatible tests removed, renumbered and retyped fields are reported while added fields are not
+func TestCheckCompatible(t *testing.T) {
+	released := snapshotContracts()
+	fields := func(s map[string]*contractSnapshot) map[int32]*fieldSnapshot {
+		return s[ORDER_STATUS_CHANGED_TOPIC].Messages["eventspb.OrderStatusChangedMessage"]
+	}
+
+	added := snapshotContracts()
+	fields(added)[99] = &fieldSnapshot{Name: "new_field", Kind: "string", Cardinality: "optional"}
+	if breaking := checkCompatible(released, added); len(breaking) != 0 {
+		t.Errorf("Expected added field to be compatible, got %v", breaking)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(map[string]*contractSnapshot)
+	}{
+		{name: "removed", modify: func(s map[string]*contractSnapshot) { delete(fields(s), 1) }},
+		{name: "renumbered", modify: func(s map[string]*contractSnapshot) { fields(s)[9] = fields(s)[1]; delete(fields(s), 1) }},
+		{name: "retyped", modify: func(s map[string]*contractSnapshot) { fields(s)[3].Kind = "string" }},
+		{name: "renamed type", modify: func(s map[string]*contractSnapshot) { s[ORDER_STATUS_CHANGED_TOPIC].Type += "2" }},
+		{name: "no longer required", modify: func(s map[string]*contractSnapshot) { s[ORDER_STATUS_CHANGED_TOPIC].Required = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := snapshotContracts()
+			tt.modify(current)
+			if breaking := checkCompatible(released, current); len(breaking) == 0 {
+				t.Error("Expected breaking change to be reported")
+			}
+		})
+	}
+}
+
+// TestNew tests data is validated against the topic's schema before it is wrapped
+func TestNew(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	envelope, err := New(ORDER_STATUS_CHANGED_TOPIC, "evt-1", at, "ORDER001", []byte(`{"order_no":"ORDER001","user_id":1,"current_status":2,"remark":""}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if envelope.Type != "com.ceramicraft.order.status_changed.v1" || envelope.DataSchema != "proto:eventspb.OrderStatusChangedMessage" || envelope.SpecVersion != SPEC_VERSION {
+		t.Errorf("Unexpected envelope: %+v", envelope)
+	}
+
+	invalid := []string{
+		`{"order_no":"ORDER001","user_id":1,"current_status":2,"unknown":1}`,
+		`{"order_no":1,"user_id":1,"current_status":2}`,
+		`{"order_no":"","user_id":1,"current_status":2}`,
+	}
+	for _, data := range invalid {
+		if _, err = New(ORDER_STATUS_CHANGED_TOPIC, "evt-1", at, "ORDER001", []byte(data)); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("Expected ErrInvalidData for %s, got: %v", data, err)
+		}
+	}
+	if _, err = New("order_refund", "evt-1", at, "ORDER001", []byte(`{}`)); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("Expected ErrInvalidEnvelope for topic without contract, got: %v", err)
+	}
+}
+
+// TestParse tests envelopes round-trip and bare payloads are rejected
+func TestParse(t *testing.T) {
+	envelope, _ := New(ORDER_CREATED_TOPIC, "evt-1", time.Now(), "ORDER001", []byte(`{"user_id":1,"order_id":"ORDER001","order_item_list":[{"product_id":2,"quantity":1,"price":10}]}`))
+	raw, _ := json.Marshal(envelope)
+	parsed, err := Parse(raw)
+	if err != nil || parsed.ID != "evt-1" || string(parsed.Data) != string(envelope.Data) {
+		t.Errorf("Expected envelope round-trip, got %+v, err: %v", parsed, err)
+	}
+
+	if _, err = Parse([]byte(`{"order_no":"ORDER001","current_status":2}`)); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("Expected ErrInvalidEnvelope for bare payload, got: %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v4.25.3
+// source: proto/events.proto
+
+package eventspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// order_created、order_canceled 的数据
+type OrderMessage struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UserId            int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                   // 下单用户
+	OrderId           string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`                                 // 订单号
+	ReceiverFirstName string                 `protobuf:"bytes,3,opt,name=receiver_first_name,json=receiverFirstName,proto3" json:"receiver_first_name,omitempty"` // 收货人姓名
+	ReceiverLastName  string                 `protobuf:"bytes,4,opt,name=receiver_last_name,json=receiverLastName,proto3" json:"receiver_last_name,omitempty"`    // 收货人姓名
+	ReceiverPhone     string                 `protobuf:"bytes,5,opt,name=receiver_phone,json=receiverPhone,proto3" json:"receiver_phone,omitempty"`               // 收货人电话
+	ReceiverAddress   string                 `protobuf:"bytes,6,opt,name=receiver_address,json=receiverAddress,proto3" json:"receiver_address,omitempty"`         // 收货地址
+	ReceiverCountry   string                 `protobuf:"bytes,7,opt,name=receiver_country,json=receiverCountry,proto3" json:"receiver_country,omitempty"`         // 收货人国家
+	ReceiverZipCode   int64                  `protobuf:"varint,8,opt,name=receiver_zip_code,json=receiverZipCode,proto3" json:"receiver_zip_code,omitempty"`      // 收货人邮政编码
+	Remark            string                 `protobuf:"bytes,9,opt,name=remark,proto3" json:"remark,omitempty"`                                                  // 备注
+	OrderItemList     []*OrderItemInfo       `protobuf:"bytes,10,rep,name=order_item_list,json=orderItemList,proto3" json:"order_item_list,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *OrderMessage) Reset() {
+	*x = OrderMessage{}
+	mi := &file_proto_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderMessage) ProtoMessage() {}
+
+func (x *OrderMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderMessage.ProtoReflect.Descriptor instead.
+func (*OrderMessage) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OrderMessage) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderMessage) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetReceiverFirstName() string {
+	if x != nil {
+		return x.ReceiverFirstName
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetReceiverLastName() string {
+	if x != nil {
+		return x.ReceiverLastName
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetReceiverPhone() string {
+	if x != nil {
+		return x.ReceiverPhone
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetReceiverAddress() string {
+	if x != nil {
+		return x.ReceiverAddress
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetReceiverCountry() string {
+	if x != nil {
+		return x.ReceiverCountry
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetReceiverZipCode() int64 {
+	if x != nil {
+		return x.ReceiverZipCode
+	}
+	return 0
+}
+
+func (x *OrderMessage) GetRemark() string {
+	if x != nil {
+		return x.Remark
+	}
+	return ""
+}
+
+func (x *OrderMessage) GetOrderItemList() []*OrderItemInfo {
+	if x != nil {
+		return x.OrderItemList
+	}
+	return nil
+}
+
+type OrderItemInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductName   string                 `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderItemInfo) Reset() {
+	*x = OrderItemInfo{}
+	mi := &file_proto_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItemInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItemInfo) ProtoMessage() {}
+
+func (x *OrderItemInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItemInfo.ProtoReflect.Descriptor instead.
+func (*OrderItemInfo) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderItemInfo) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *OrderItemInfo) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+// order_status_changed 的数据，事件ID和发生时间在信封中
+type OrderStatusChangedMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CurrentStatus int32                  `protobuf:"varint,3,opt,name=current_status,json=currentStatus,proto3" json:"current_status,omitempty"` // 变更后的订单状态
+	Remark        string                 `protobuf:"bytes,4,opt,name=remark,proto3" json:"remark,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderStatusChangedMessage) Reset() {
+	*x = OrderStatusChangedMessage{}
+	mi := &file_proto_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStatusChangedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStatusChangedMessage) ProtoMessage() {}
+
+func (x *OrderStatusChangedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStatusChangedMessage.ProtoReflect.Descriptor instead.
+func (*OrderStatusChangedMessage) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderStatusChangedMessage) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *OrderStatusChangedMessage) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderStatusChangedMessage) GetCurrentStatus() int32 {
+	if x != nil {
+		return x.CurrentStatus
+	}
+	return 0
+}
+
+func (x *OrderStatusChangedMessage) GetRemark() string {
+	if x != nil {
+		return x.Remark
+	}
+	return ""
+}
+
+var File_proto_events_proto protoreflect.FileDescriptor
+
+const file_proto_events_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/events.proto\x12\beventspb\"\xa2\x03\n" +
+	"\fOrderMessage\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\x12.\n" +
+	"\x13receiver_first_name\x18\x03 \x01(\tR\x11receiverFirstName\x12,\n" +
+	"\x12receiver_last_name\x18\x04 \x01(\tR\x10receiverLastName\x12%\n" +
+	"\x0ereceiver_phone\x18\x05 \x01(\tR\rreceiverPhone\x12)\n" +
+	"\x10receiver_address\x18\x06 \x01(\tR\x0freceiverAddress\x12)\n" +
+	"\x10receiver_country\x18\a \x01(\tR\x0freceiverCountry\x12*\n" +
+	"\x11receiver_zip_code\x18\b \x01(\x03R\x0freceiverZipCode\x12\x16\n" +
+	"\x06remark\x18\t \x01(\tR\x06remark\x12?\n" +
+	"\x0forder_item_list\x18\n" +
+	" \x03(\v2\x17.eventspb.OrderItemInfoR\rorderItemList\"\x83\x01\n" +
+	"\rOrderItemInfo\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\"\x8e\x01\n" +
+	"\x19OrderStatusChangedMessage\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12%\n" +
+	"\x0ecurrent_status\x18\x03 \x01(\x05R\rcurrentStatus\x12\x16\n" +
+	"\x06remark\x18\x04 \x01(\tR\x06remarkB\x14Z\x12/eventspb;eventspbb\x06proto3"
+
+var (
+	file_proto_events_proto_rawDescOnce sync.Once
+	file_proto_events_proto_rawDescData []byte
+)
+
+func file_proto_events_proto_rawDescGZIP() []byte {
+	file_proto_events_proto_rawDescOnce.Do(func() {
+		file_proto_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_events_proto_rawDesc), len(file_proto_events_proto_rawDesc)))
+	})
+	return file_proto_events_proto_rawDescData
+}
+
+var file_proto_events_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_events_proto_goTypes = []any{
+	(*OrderMessage)(nil),              // 0: eventspb.OrderMessage
+	(*OrderItemInfo)(nil),             // 1: eventspb.OrderItemInfo
+	(*OrderStatusChangedMessage)(nil), // 2: eventspb.OrderStatusChangedMessage
+}
+var file_proto_events_proto_depIdxs = []int32{
+	1, // 0: eventspb.OrderMessage.order_item_list:type_name -> eventspb.OrderItemInfo
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_events_proto_init() }
+func file_proto_events_proto_init() {
+	if File_proto_events_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_events_proto_rawDesc), len(file_proto_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_events_proto_goTypes,
+		DependencyIndexes: file_proto_events_proto_depIdxs,
+		MessageInfos:      file_proto_events_proto_msgTypes,
+	}.Build()
+	File_proto_events_proto = out.File
+	file_proto_events_proto_goTypes = nil
+	file_proto_events_proto_depIdxs = nil
+}
@@ -0,0 +1,1615 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v4.25.3
+// source: proto/order.proto
+
+package orderpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RespCode int32
+
+const (
+	RespCode_SUCCESS            RespCode = 0
+	RespCode_BAD_REQUEST        RespCode = 4000
+	RespCode_NOT_FOUND          RespCode = 4004
+	RespCode_INVALID_TRANSITION RespCode = 4009 // 订单状态不允许该变更，或调用方无权变更
+	RespCode_UNKNOWN_ERROR      RespCode = 5000
+)
+
+// Enum value maps for RespCode.
+var (
+	RespCode_name = map[int32]string{
+		0:    "SUCCESS",
+		4000: "BAD_REQUEST",
+		4004: "NOT_FOUND",
+		4009: "INVALID_TRANSITION",
+		5000: "UNKNOWN_ERROR",
+	}
+	RespCode_value = map[string]int32{
+		"SUCCESS":            0,
+		"BAD_REQUEST":        4000,
+		"NOT_FOUND":          4004,
+		"INVALID_TRANSITION": 4009,
+		"UNKNOWN_ERROR":      5000,
+	}
+)
+
+func (x RespCode) Enum() *RespCode {
+	p := new(RespCode)
+	*p = x
+	return p
+}
+
+func (x RespCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RespCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_order_proto_enumTypes[0].Descriptor()
+}
+
+func (RespCode) Type() protoreflect.EnumType {
+	return &file_proto_order_proto_enumTypes[0]
+}
+
+func (x RespCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RespCode.Descriptor instead.
+func (RespCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{0}
+}
+
+// 触发状态变更的角色，与订单状态机中的角色一致
+type Actor int32
+
+const (
+	Actor_ACTOR_UNSPECIFIED Actor = 0
+	Actor_CUSTOMER          Actor = 1
+	Actor_MERCHANT          Actor = 2
+	Actor_SYSTEM            Actor = 4
+)
+
+// Enum value maps for Actor.
+var (
+	Actor_name = map[int32]string{
+		0: "ACTOR_UNSPECIFIED",
+		1: "CUSTOMER",
+		2: "MERCHANT",
+		4: "SYSTEM",
+	}
+	Actor_value = map[string]int32{
+		"ACTOR_UNSPECIFIED": 0,
+		"CUSTOMER":          1,
+		"MERCHANT":          2,
+		"SYSTEM":            4,
+	}
+)
+
+func (x Actor) Enum() *Actor {
+	p := new(Actor)
+	*p = x
+	return p
+}
+
+func (x Actor) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Actor) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_order_proto_enumTypes[1].Descriptor()
+}
+
+func (Actor) Type() protoreflect.EnumType {
+	return &file_proto_order_proto_enumTypes[1]
+}
+
+func (x Actor) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Actor.Descriptor instead.
+func (Actor) EnumDescriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{1}
+}
+
+type OrderItem struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // 订单商品ID
+	ProductId      int32                  `protobuf:"varint,2,opt,name=productId,proto3" json:"productId,omitempty"`
+	ProductName    string                 `protobuf:"bytes,3,opt,name=productName,proto3" json:"productName,omitempty"`
+	Price          int32                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity       int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	TotalPrice     int32                  `protobuf:"varint,6,opt,name=totalPrice,proto3" json:"totalPrice,omitempty"`
+	DiscountAmount int32                  `protobuf:"varint,7,opt,name=discountAmount,proto3" json:"discountAmount,omitempty"` // 分摊到该商品的优惠金额
+	TaxRate        int32                  `protobuf:"varint,8,opt,name=taxRate,proto3" json:"taxRate,omitempty"`               // 适用税率，万分之一
+	TaxAmount      int32                  `protobuf:"varint,9,opt,name=taxAmount,proto3" json:"taxAmount,omitempty"`           // 分摊到该商品的税额
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OrderItem) Reset() {
+	*x = OrderItem{}
+	mi := &file_proto_order_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItem) ProtoMessage() {}
+
+func (x *OrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
+func (*OrderItem) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OrderItem) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *OrderItem) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *OrderItem) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *OrderItem) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderItem) GetTotalPrice() int32 {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return 0
+}
+
+func (x *OrderItem) GetDiscountAmount() int32 {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return 0
+}
+
+func (x *OrderItem) GetTaxRate() int32 {
+	if x != nil {
+		return x.TaxRate
+	}
+	return 0
+}
+
+func (x *OrderItem) GetTaxAmount() int32 {
+	if x != nil {
+		return x.TaxAmount
+	}
+	return 0
+}
+
+type Order struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo           string                 `protobuf:"bytes,1,opt,name=orderNo,proto3" json:"orderNo,omitempty"`
+	UserId            int32                  `protobuf:"varint,2,opt,name=userId,proto3" json:"userId,omitempty"`
+	Status            int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	StatusName        string                 `protobuf:"bytes,4,opt,name=statusName,proto3" json:"statusName,omitempty"`
+	TotalAmount       int32                  `protobuf:"varint,5,opt,name=totalAmount,proto3" json:"totalAmount,omitempty"`
+	PayAmount         int32                  `protobuf:"varint,6,opt,name=payAmount,proto3" json:"payAmount,omitempty"`
+	ShippingFee       int32                  `protobuf:"varint,7,opt,name=shippingFee,proto3" json:"shippingFee,omitempty"`
+	Tax               int32                  `protobuf:"varint,8,opt,name=tax,proto3" json:"tax,omitempty"`
+	RefundedAmount    int32                  `protobuf:"varint,9,opt,name=refundedAmount,proto3" json:"refundedAmount,omitempty"` // 已退款金额
+	ReceiverFirstName string                 `protobuf:"bytes,10,opt,name=receiverFirstName,proto3" json:"receiverFirstName,omitempty"`
+	ReceiverLastName  string                 `protobuf:"bytes,11,opt,name=receiverLastName,proto3" json:"receiverLastName,omitempty"`
+	ReceiverPhone     string                 `protobuf:"bytes,12,opt,name=receiverPhone,proto3" json:"receiverPhone,omitempty"`
+	ReceiverAddress   string                 `protobuf:"bytes,13,opt,name=receiverAddress,proto3" json:"receiverAddress,omitempty"`
+	ReceiverCountry   string                 `protobuf:"bytes,14,opt,name=receiverCountry,proto3" json:"receiverCountry,omitempty"`
+	ReceiverZipCode   int32                  `protobuf:"varint,15,opt,name=receiverZipCode,proto3" json:"receiverZipCode,omitempty"`
+	Remark            string                 `protobuf:"bytes,16,opt,name=remark,proto3" json:"remark,omitempty"`
+	LogisticsNo       string                 `protobuf:"bytes,17,opt,name=logisticsNo,proto3" json:"logisticsNo,omitempty"`
+	CancelReason      string                 `protobuf:"bytes,18,opt,name=cancelReason,proto3" json:"cancelReason,omitempty"`
+	// 以下时间均为 unix 秒，0 表示未发生
+	CreatedTime      int64        `protobuf:"varint,19,opt,name=createdTime,proto3" json:"createdTime,omitempty"`
+	PayTime          int64        `protobuf:"varint,20,opt,name=payTime,proto3" json:"payTime,omitempty"`
+	DeliveryTime     int64        `protobuf:"varint,21,opt,name=deliveryTime,proto3" json:"deliveryTime,omitempty"`
+	ConfirmTime      int64        `protobuf:"varint,22,opt,name=confirmTime,proto3" json:"confirmTime,omitempty"`
+	CancelTime       int64        `protobuf:"varint,23,opt,name=cancelTime,proto3" json:"cancelTime,omitempty"`
+	Items            []*OrderItem `protobuf:"bytes,24,rep,name=items,proto3" json:"items,omitempty"`
+	PayTransactionId string       `protobuf:"bytes,25,opt,name=payTransactionId,proto3" json:"payTransactionId,omitempty"` // 支付服务的支付单号
+	PayMethod        string       `protobuf:"bytes,26,opt,name=payMethod,proto3" json:"payMethod,omitempty"`               // 支付方式
+	DiscountAmount   int32        `protobuf:"varint,27,opt,name=discountAmount,proto3" json:"discountAmount,omitempty"`    // 优惠减免金额，包括商品和运费
+	CouponCode       string       `protobuf:"bytes,28,opt,name=couponCode,proto3" json:"couponCode,omitempty"`             // 使用的优惠码
+	TaxRegion        string       `protobuf:"bytes,29,opt,name=taxRegion,proto3" json:"taxRegion,omitempty"`               // 下单时匹配的税率规则
+	TaxRate          int32        `protobuf:"varint,30,opt,name=taxRate,proto3" json:"taxRate,omitempty"`                  // 下单时的标准税率，万分之一
+	TaxInclusive     bool         `protobuf:"varint,31,opt,name=taxInclusive,proto3" json:"taxInclusive,omitempty"`        // 商品价格是否已含税
+	// 金额字段均为基础货币，按 exchangeRate 换算为订单货币
+	Currency      string `protobuf:"bytes,32,opt,name=currency,proto3" json:"currency,omitempty"`          // 订单货币
+	BaseCurrency  string `protobuf:"bytes,33,opt,name=baseCurrency,proto3" json:"baseCurrency,omitempty"`  // 基础货币
+	ExchangeRate  int64  `protobuf:"varint,34,opt,name=exchangeRate,proto3" json:"exchangeRate,omitempty"` // 下单时的汇率快照，1e6 个基础货币最小单位可兑换的订单货币最小单位
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_proto_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Order) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *Order) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Order) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Order) GetStatusName() string {
+	if x != nil {
+		return x.StatusName
+	}
+	return ""
+}
+
+func (x *Order) GetTotalAmount() int32 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *Order) GetPayAmount() int32 {
+	if x != nil {
+		return x.PayAmount
+	}
+	return 0
+}
+
+func (x *Order) GetShippingFee() int32 {
+	if x != nil {
+		return x.ShippingFee
+	}
+	return 0
+}
+
+func (x *Order) GetTax() int32 {
+	if x != nil {
+		return x.Tax
+	}
+	return 0
+}
+
+func (x *Order) GetRefundedAmount() int32 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+func (x *Order) GetReceiverFirstName() string {
+	if x != nil {
+		return x.ReceiverFirstName
+	}
+	return ""
+}
+
+func (x *Order) GetReceiverLastName() string {
+	if x != nil {
+		return x.ReceiverLastName
+	}
+	return ""
+}
+
+func (x *Order) GetReceiverPhone() string {
+	if x != nil {
+		return x.ReceiverPhone
+	}
+	return ""
+}
+
+func (x *Order) GetReceiverAddress() string {
+	if x != nil {
+		return x.ReceiverAddress
+	}
+	return ""
+}
+
+func (x *Order) GetReceiverCountry() string {
+	if x != nil {
+		return x.ReceiverCountry
+	}
+	return ""
+}
+
+func (x *Order) GetReceiverZipCode() int32 {
+	if x != nil {
+		return x.ReceiverZipCode
+	}
+	return 0
+}
+
+func (x *Order) GetRemark() string {
+	if x != nil {
+		return x.Remark
+	}
+	return ""
+}
+
+func (x *Order) GetLogisticsNo() string {
+	if x != nil {
+		return x.LogisticsNo
+	}
+	return ""
+}
+
+func (x *Order) GetCancelReason() string {
+	if x != nil {
+		return x.CancelReason
+	}
+	return ""
+}
+
+func (x *Order) GetCreatedTime() int64 {
+	if x != nil {
+		return x.CreatedTime
+	}
+	return 0
+}
+
+func (x *Order) GetPayTime() int64 {
+	if x != nil {
+		return x.PayTime
+	}
+	return 0
+}
+
+func (x *Order) GetDeliveryTime() int64 {
+	if x != nil {
+		return x.DeliveryTime
+	}
+	return 0
+}
+
+func (x *Order) GetConfirmTime() int64 {
+	if x != nil {
+		return x.ConfirmTime
+	}
+	return 0
+}
+
+func (x *Order) GetCancelTime() int64 {
+	if x != nil {
+		return x.CancelTime
+	}
+	return 0
+}
+
+func (x *Order) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Order) GetPayTransactionId() string {
+	if x != nil {
+		return x.PayTransactionId
+	}
+	return ""
+}
+
+func (x *Order) GetPayMethod() string {
+	if x != nil {
+		return x.PayMethod
+	}
+	return ""
+}
+
+func (x *Order) GetDiscountAmount() int32 {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return 0
+}
+
+func (x *Order) GetCouponCode() string {
+	if x != nil {
+		return x.CouponCode
+	}
+	return ""
+}
+
+func (x *Order) GetTaxRegion() string {
+	if x != nil {
+		return x.TaxRegion
+	}
+	return ""
+}
+
+func (x *Order) GetTaxRate() int32 {
+	if x != nil {
+		return x.TaxRate
+	}
+	return 0
+}
+
+func (x *Order) GetTaxInclusive() bool {
+	if x != nil {
+		return x.TaxInclusive
+	}
+	return false
+}
+
+func (x *Order) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Order) GetBaseCurrency() string {
+	if x != nil {
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *Order) GetExchangeRate() int64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+// 订单列表中的订单摘要
+type OrderSummary struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo           string                 `protobuf:"bytes,1,opt,name=orderNo,proto3" json:"orderNo,omitempty"`
+	ReceiverFirstName string                 `protobuf:"bytes,2,opt,name=receiverFirstName,proto3" json:"receiverFirstName,omitempty"`
+	ReceiverLastName  string                 `protobuf:"bytes,3,opt,name=receiverLastName,proto3" json:"receiverLastName,omitempty"`
+	ReceiverPhone     string                 `protobuf:"bytes,4,opt,name=receiverPhone,proto3" json:"receiverPhone,omitempty"`
+	CreatedTime       int64                  `protobuf:"varint,5,opt,name=createdTime,proto3" json:"createdTime,omitempty"`
+	TotalAmount       int32                  `protobuf:"varint,6,opt,name=totalAmount,proto3" json:"totalAmount,omitempty"`
+	StatusName        string                 `protobuf:"bytes,7,opt,name=statusName,proto3" json:"statusName,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *OrderSummary) Reset() {
+	*x = OrderSummary{}
+	mi := &file_proto_order_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderSummary) ProtoMessage() {}
+
+func (x *OrderSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderSummary.ProtoReflect.Descriptor instead.
+func (*OrderSummary) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderSummary) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *OrderSummary) GetReceiverFirstName() string {
+	if x != nil {
+		return x.ReceiverFirstName
+	}
+	return ""
+}
+
+func (x *OrderSummary) GetReceiverLastName() string {
+	if x != nil {
+		return x.ReceiverLastName
+	}
+	return ""
+}
+
+func (x *OrderSummary) GetReceiverPhone() string {
+	if x != nil {
+		return x.ReceiverPhone
+	}
+	return ""
+}
+
+func (x *OrderSummary) GetCreatedTime() int64 {
+	if x != nil {
+		return x.CreatedTime
+	}
+	return 0
+}
+
+func (x *OrderSummary) GetTotalAmount() int32 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *OrderSummary) GetStatusName() string {
+	if x != nil {
+		return x.StatusName
+	}
+	return ""
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=orderNo,proto3" json:"orderNo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_proto_order_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetOrderRequest) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+type GetOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	ErrorMsg      string                 `protobuf:"bytes,2,opt,name=errorMsg,proto3" json:"errorMsg,omitempty"`
+	Order         *Order                 `protobuf:"bytes,3,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderResponse) Reset() {
+	*x = GetOrderResponse{}
+	mi := &file_proto_order_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderResponse) ProtoMessage() {}
+
+func (x *GetOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetOrderResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetOrderResponse) GetErrorMsg() string {
+	if x != nil {
+		return x.ErrorMsg
+	}
+	return ""
+}
+
+func (x *GetOrderResponse) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type ListOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=userId,proto3" json:"userId,omitempty"`           // 0 表示不按用户筛选
+	OrderStatus   int32                  `protobuf:"varint,2,opt,name=orderStatus,proto3" json:"orderStatus,omitempty"` // 0 表示不按状态筛选
+	StartTime     int64                  `protobuf:"varint,3,opt,name=startTime,proto3" json:"startTime,omitempty"`     // unix 秒，0 表示不限
+	EndTime       int64                  `protobuf:"varint,4,opt,name=endTime,proto3" json:"endTime,omitempty"`         // unix 秒，0 表示不限
+	OrderNo       string                 `protobuf:"bytes,5,opt,name=orderNo,proto3" json:"orderNo,omitempty"`
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"` // 默认 20，最大 100
+	Offset        int32                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersRequest) Reset() {
+	*x = ListOrdersRequest{}
+	mi := &file_proto_order_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersRequest) ProtoMessage() {}
+
+func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListOrdersRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetOrderStatus() int32 {
+	if x != nil {
+		return x.OrderStatus
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	ErrorMsg      string                 `protobuf:"bytes,2,opt,name=errorMsg,proto3" json:"errorMsg,omitempty"`
+	Orders        []*OrderSummary        `protobuf:"bytes,3,rep,name=orders,proto3" json:"orders,omitempty"`
+	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResponse) Reset() {
+	*x = ListOrdersResponse{}
+	mi := &file_proto_order_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResponse) ProtoMessage() {}
+
+func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListOrdersResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListOrdersResponse) GetErrorMsg() string {
+	if x != nil {
+		return x.ErrorMsg
+	}
+	return ""
+}
+
+func (x *ListOrdersResponse) GetOrders() []*OrderSummary {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListOrdersResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type BatchGetOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNos      []string               `protobuf:"bytes,1,rep,name=orderNos,proto3" json:"orderNos,omitempty"` // 最多 100 个
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetOrdersRequest) Reset() {
+	*x = BatchGetOrdersRequest{}
+	mi := &file_proto_order_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOrdersRequest) ProtoMessage() {}
+
+func (x *BatchGetOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOrdersRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchGetOrdersRequest) GetOrderNos() []string {
+	if x != nil {
+		return x.OrderNos
+	}
+	return nil
+}
+
+type BatchGetOrdersResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Code             int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	ErrorMsg         string                 `protobuf:"bytes,2,opt,name=errorMsg,proto3" json:"errorMsg,omitempty"`
+	Orders           []*Order               `protobuf:"bytes,3,rep,name=orders,proto3" json:"orders,omitempty"`
+	NotFoundOrderNos []string               `protobuf:"bytes,4,rep,name=notFoundOrderNos,proto3" json:"notFoundOrderNos,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BatchGetOrdersResponse) Reset() {
+	*x = BatchGetOrdersResponse{}
+	mi := &file_proto_order_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOrdersResponse) ProtoMessage() {}
+
+func (x *BatchGetOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOrdersResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchGetOrdersResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchGetOrdersResponse) GetErrorMsg() string {
+	if x != nil {
+		return x.ErrorMsg
+	}
+	return ""
+}
+
+func (x *BatchGetOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *BatchGetOrdersResponse) GetNotFoundOrderNos() []string {
+	if x != nil {
+		return x.NotFoundOrderNos
+	}
+	return nil
+}
+
+type UpdateOrderStatusRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo    string                 `protobuf:"bytes,1,opt,name=orderNo,proto3" json:"orderNo,omitempty"`
+	NewStatus  int32                  `protobuf:"varint,2,opt,name=newStatus,proto3" json:"newStatus,omitempty"`
+	Actor      Actor                  `protobuf:"varint,3,opt,name=actor,proto3,enum=orderpb.Actor" json:"actor,omitempty"`
+	UserId     int32                  `protobuf:"varint,4,opt,name=userId,proto3" json:"userId,omitempty"`        // actor 为 CUSTOMER 时必填
+	TrackingNo string                 `protobuf:"bytes,5,opt,name=trackingNo,proto3" json:"trackingNo,omitempty"` // 发货时必填
+	Reason     string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	// 以下为置为已付款时的支付信息
+	PayAmount        int32  `protobuf:"varint,7,opt,name=payAmount,proto3" json:"payAmount,omitempty"`              // 实际支付金额
+	PayTransactionId string `protobuf:"bytes,8,opt,name=payTransactionId,proto3" json:"payTransactionId,omitempty"` // 支付服务的支付单号
+	PayMethod        string `protobuf:"bytes,9,opt,name=payMethod,proto3" json:"payMethod,omitempty"`               // 支付方式，为空时为 BALANCE
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UpdateOrderStatusRequest) Reset() {
+	*x = UpdateOrderStatusRequest{}
+	mi := &file_proto_order_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrderStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrderStatusRequest) ProtoMessage() {}
+
+func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrderStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOrderStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateOrderStatusRequest) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetNewStatus() int32 {
+	if x != nil {
+		return x.NewStatus
+	}
+	return 0
+}
+
+func (x *UpdateOrderStatusRequest) GetActor() Actor {
+	if x != nil {
+		return x.Actor
+	}
+	return Actor_ACTOR_UNSPECIFIED
+}
+
+func (x *UpdateOrderStatusRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UpdateOrderStatusRequest) GetTrackingNo() string {
+	if x != nil {
+		return x.TrackingNo
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetPayAmount() int32 {
+	if x != nil {
+		return x.PayAmount
+	}
+	return 0
+}
+
+func (x *UpdateOrderStatusRequest) GetPayTransactionId() string {
+	if x != nil {
+		return x.PayTransactionId
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetPayMethod() string {
+	if x != nil {
+		return x.PayMethod
+	}
+	return ""
+}
+
+type UpdateOrderStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	ErrorMsg      string                 `protobuf:"bytes,2,opt,name=errorMsg,proto3" json:"errorMsg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOrderStatusResponse) Reset() {
+	*x = UpdateOrderStatusResponse{}
+	mi := &file_proto_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrderStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrderStatusResponse) ProtoMessage() {}
+
+func (x *UpdateOrderStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrderStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOrderStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateOrderStatusResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *UpdateOrderStatusResponse) GetErrorMsg() string {
+	if x != nil {
+		return x.ErrorMsg
+	}
+	return ""
+}
+
+type GetOrderStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderStatsRequest) Reset() {
+	*x = GetOrderStatsRequest{}
+	mi := &file_proto_order_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderStatsRequest) ProtoMessage() {}
+
+func (x *GetOrderStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{11}
+}
+
+type GetOrderStatsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Code             int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	ErrorMsg         string                 `protobuf:"bytes,2,opt,name=errorMsg,proto3" json:"errorMsg,omitempty"`
+	TotalOrders      int32                  `protobuf:"varint,3,opt,name=totalOrders,proto3" json:"totalOrders,omitempty"`
+	TotalSales       int32                  `protobuf:"varint,4,opt,name=totalSales,proto3" json:"totalSales,omitempty"`
+	TotalCustomers   int32                  `protobuf:"varint,5,opt,name=totalCustomers,proto3" json:"totalCustomers,omitempty"`
+	AvgSalesPerOrder int32                  `protobuf:"varint,6,opt,name=avgSalesPerOrder,proto3" json:"avgSalesPerOrder,omitempty"`
+	BaseCurrency     string                 `protobuf:"bytes,7,opt,name=baseCurrency,proto3" json:"baseCurrency,omitempty"` // 销售额的货币
+	SalesByCurrency  []*CurrencySales       `protobuf:"bytes,8,rep,name=salesByCurrency,proto3" json:"salesByCurrency,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetOrderStatsResponse) Reset() {
+	*x = GetOrderStatsResponse{}
+	mi := &file_proto_order_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderStatsResponse) ProtoMessage() {}
+
+func (x *GetOrderStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetOrderStatsResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetOrderStatsResponse) GetErrorMsg() string {
+	if x != nil {
+		return x.ErrorMsg
+	}
+	return ""
+}
+
+func (x *GetOrderStatsResponse) GetTotalOrders() int32 {
+	if x != nil {
+		return x.TotalOrders
+	}
+	return 0
+}
+
+func (x *GetOrderStatsResponse) GetTotalSales() int32 {
+	if x != nil {
+		return x.TotalSales
+	}
+	return 0
+}
+
+func (x *GetOrderStatsResponse) GetTotalCustomers() int32 {
+	if x != nil {
+		return x.TotalCustomers
+	}
+	return 0
+}
+
+func (x *GetOrderStatsResponse) GetAvgSalesPerOrder() int32 {
+	if x != nil {
+		return x.AvgSalesPerOrder
+	}
+	return 0
+}
+
+func (x *GetOrderStatsResponse) GetBaseCurrency() string {
+	if x != nil {
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *GetOrderStatsResponse) GetSalesByCurrency() []*CurrencySales {
+	if x != nil {
+		return x.SalesByCurrency
+	}
+	return nil
+}
+
+// 一种订单货币的销售额
+type CurrencySales struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	TotalOrders   int32                  `protobuf:"varint,2,opt,name=totalOrders,proto3" json:"totalOrders,omitempty"`
+	TotalSales    int32                  `protobuf:"varint,3,opt,name=totalSales,proto3" json:"totalSales,omitempty"` // 基础货币
+	LocalSales    int32                  `protobuf:"varint,4,opt,name=localSales,proto3" json:"localSales,omitempty"` // 按下单时的汇率换算为该货币
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CurrencySales) Reset() {
+	*x = CurrencySales{}
+	mi := &file_proto_order_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CurrencySales) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrencySales) ProtoMessage() {}
+
+func (x *CurrencySales) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrencySales.ProtoReflect.Descriptor instead.
+func (*CurrencySales) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CurrencySales) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CurrencySales) GetTotalOrders() int32 {
+	if x != nil {
+		return x.TotalOrders
+	}
+	return 0
+}
+
+func (x *CurrencySales) GetTotalSales() int32 {
+	if x != nil {
+		return x.TotalSales
+	}
+	return 0
+}
+
+func (x *CurrencySales) GetLocalSales() int32 {
+	if x != nil {
+		return x.LocalSales
+	}
+	return 0
+}
+
+var File_proto_order_proto protoreflect.FileDescriptor
+
+const file_proto_order_proto_rawDesc = "" +
+	"\n" +
+	"\x11proto/order.proto\x12\aorderpb\"\x8d\x02\n" +
+	"\tOrderItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1c\n" +
+	"\tproductId\x18\x02 \x01(\x05R\tproductId\x12 \n" +
+	"\vproductName\x18\x03 \x01(\tR\vproductName\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x05R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x1e\n" +
+	"\n" +
+	"totalPrice\x18\x06 \x01(\x05R\n" +
+	"totalPrice\x12&\n" +
+	"\x0ediscountAmount\x18\a \x01(\x05R\x0ediscountAmount\x12\x18\n" +
+	"\ataxRate\x18\b \x01(\x05R\ataxRate\x12\x1c\n" +
+	"\ttaxAmount\x18\t \x01(\x05R\ttaxAmount\"\x87\t\n" +
+	"\x05Order\x12\x18\n" +
+	"\aorderNo\x18\x01 \x01(\tR\aorderNo\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\x05R\x06userId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12\x1e\n" +
+	"\n" +
+	"statusName\x18\x04 \x01(\tR\n" +
+	"statusName\x12 \n" +
+	"\vtotalAmount\x18\x05 \x01(\x05R\vtotalAmount\x12\x1c\n" +
+	"\tpayAmount\x18\x06 \x01(\x05R\tpayAmount\x12 \n" +
+	"\vshippingFee\x18\a \x01(\x05R\vshippingFee\x12\x10\n" +
+	"\x03tax\x18\b \x01(\x05R\x03tax\x12&\n" +
+	"\x0erefundedAmount\x18\t \x01(\x05R\x0erefundedAmount\x12,\n" +
+	"\x11receiverFirstName\x18\n" +
+	" \x01(\tR\x11receiverFirstName\x12*\n" +
+	"\x10receiverLastName\x18\v \x01(\tR\x10receiverLastName\x12$\n" +
+	"\rreceiverPhone\x18\f \x01(\tR\rreceiverPhone\x12(\n" +
+	"\x0freceiverAddress\x18\r \x01(\tR\x0freceiverAddress\x12(\n" +
+	"\x0freceiverCountry\x18\x0e \x01(\tR\x0freceiverCountry\x12(\n" +
+	"\x0freceiverZipCode\x18\x0f \x01(\x05R\x0freceiverZipCode\x12\x16\n" +
+	"\x06remark\x18\x10 \x01(\tR\x06remark\x12 \n" +
+	"\vlogisticsNo\x18\x11 \x01(\tR\vlogisticsNo\x12\"\n" +
+	"\fcancelReason\x18\x12 \x01(\tR\fcancelReason\x12 \n" +
+	"\vcreatedTime\x18\x13 \x01(\x03R\vcreatedTime\x12\x18\n" +
+	"\apayTime\x18\x14 \x01(\x03R\apayTime\x12\"\n" +
+	"\fdeliveryTime\x18\x15 \x01(\x03R\fdeliveryTime\x12 \n" +
+	"\vconfirmTime\x18\x16 \x01(\x03R\vconfirmTime\x12\x1e\n" +
+	"\n" +
+	"cancelTime\x18\x17 \x01(\x03R\n" +
+	"cancelTime\x12(\n" +
+	"\x05items\x18\x18 \x03(\v2\x12.orderpb.OrderItemR\x05items\x12*\n" +
+	"\x10payTransactionId\x18\x19 \x01(\tR\x10payTransactionId\x12\x1c\n" +
+	"\tpayMethod\x18\x1a \x01(\tR\tpayMethod\x12&\n" +
+	"\x0ediscountAmount\x18\x1b \x01(\x05R\x0ediscountAmount\x12\x1e\n" +
+	"\n" +
+	"couponCode\x18\x1c \x01(\tR\n" +
+	"couponCode\x12\x1c\n" +
+	"\ttaxRegion\x18\x1d \x01(\tR\ttaxRegion\x12\x18\n" +
+	"\ataxRate\x18\x1e \x01(\x05R\ataxRate\x12\"\n" +
+	"\ftaxInclusive\x18\x1f \x01(\bR\ftaxInclusive\x12\x1a\n" +
+	"\bcurrency\x18  \x01(\tR\bcurrency\x12\"\n" +
+	"\fbaseCurrency\x18! \x01(\tR\fbaseCurrency\x12\"\n" +
+	"\fexchangeRate\x18\" \x01(\x03R\fexchangeRate\"\x8c\x02\n" +
+	"\fOrderSummary\x12\x18\n" +
+	"\aorderNo\x18\x01 \x01(\tR\aorderNo\x12,\n" +
+	"\x11receiverFirstName\x18\x02 \x01(\tR\x11receiverFirstName\x12*\n" +
+	"\x10receiverLastName\x18\x03 \x01(\tR\x10receiverLastName\x12$\n" +
+	"\rreceiverPhone\x18\x04 \x01(\tR\rreceiverPhone\x12 \n" +
+	"\vcreatedTime\x18\x05 \x01(\x03R\vcreatedTime\x12 \n" +
+	"\vtotalAmount\x18\x06 \x01(\x05R\vtotalAmount\x12\x1e\n" +
+	"\n" +
+	"statusName\x18\a \x01(\tR\n" +
+	"statusName\"+\n" +
+	"\x0fGetOrderRequest\x12\x18\n" +
+	"\aorderNo\x18\x01 \x01(\tR\aorderNo\"h\n" +
+	"\x10GetOrderResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x1a\n" +
+	"\berrorMsg\x18\x02 \x01(\tR\berrorMsg\x12$\n" +
+	"\x05order\x18\x03 \x01(\v2\x0e.orderpb.OrderR\x05order\"\xcd\x01\n" +
+	"\x11ListOrdersRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x05R\x06userId\x12 \n" +
+	"\vorderStatus\x18\x02 \x01(\x05R\vorderStatus\x12\x1c\n" +
+	"\tstartTime\x18\x03 \x01(\x03R\tstartTime\x12\x18\n" +
+	"\aendTime\x18\x04 \x01(\x03R\aendTime\x12\x18\n" +
+	"\aorderNo\x18\x05 \x01(\tR\aorderNo\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x05R\x06offset\"\x89\x01\n" +
+	"\x12ListOrdersResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x1a\n" +
+	"\berrorMsg\x18\x02 \x01(\tR\berrorMsg\x12-\n" +
+	"\x06orders\x18\x03 \x03(\v2\x15.orderpb.OrderSummaryR\x06orders\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\x05R\x05total\"3\n" +
+	"\x15BatchGetOrdersRequest\x12\x1a\n" +
+	"\borderNos\x18\x01 \x03(\tR\borderNos\"\x9c\x01\n" +
+	"\x16BatchGetOrdersResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x1a\n" +
+	"\berrorMsg\x18\x02 \x01(\tR\berrorMsg\x12&\n" +
+	"\x06orders\x18\x03 \x03(\v2\x0e.orderpb.OrderR\x06orders\x12*\n" +
+	"\x10notFoundOrderNos\x18\x04 \x03(\tR\x10notFoundOrderNos\"\xb0\x02\n" +
+	"\x18UpdateOrderStatusRequest\x12\x18\n" +
+	"\aorderNo\x18\x01 \x01(\tR\aorderNo\x12\x1c\n" +
+	"\tnewStatus\x18\x02 \x01(\x05R\tnewStatus\x12$\n" +
+	"\x05actor\x18\x03 \x01(\x0e2\x0e.orderpb.ActorR\x05actor\x12\x16\n" +
+	"\x06userId\x18\x04 \x01(\x05R\x06userId\x12\x1e\n" +
+	"\n" +
+	"trackingNo\x18\x05 \x01(\tR\n" +
+	"trackingNo\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12\x1c\n" +
+	"\tpayAmount\x18\a \x01(\x05R\tpayAmount\x12*\n" +
+	"\x10payTransactionId\x18\b \x01(\tR\x10payTransactionId\x12\x1c\n" +
+	"\tpayMethod\x18\t \x01(\tR\tpayMethod\"K\n" +
+	"\x19UpdateOrderStatusResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x1a\n" +
+	"\berrorMsg\x18\x02 \x01(\tR\berrorMsg\"\x16\n" +
+	"\x14GetOrderStatsRequest\"\xc3\x02\n" +
+	"\x15GetOrderStatsResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x1a\n" +
+	"\berrorMsg\x18\x02 \x01(\tR\berrorMsg\x12 \n" +
+	"\vtotalOrders\x18\x03 \x01(\x05R\vtotalOrders\x12\x1e\n" +
+	"\n" +
+	"totalSales\x18\x04 \x01(\x05R\n" +
+	"totalSales\x12&\n" +
+	"\x0etotalCustomers\x18\x05 \x01(\x05R\x0etotalCustomers\x12*\n" +
+	"\x10avgSalesPerOrder\x18\x06 \x01(\x05R\x10avgSalesPerOrder\x12\"\n" +
+	"\fbaseCurrency\x18\a \x01(\tR\fbaseCurrency\x12@\n" +
+	"\x0fsalesByCurrency\x18\b \x03(\v2\x16.orderpb.CurrencySalesR\x0fsalesByCurrency\"\x8d\x01\n" +
+	"\rCurrencySales\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12 \n" +
+	"\vtotalOrders\x18\x02 \x01(\x05R\vtotalOrders\x12\x1e\n" +
+	"\n" +
+	"totalSales\x18\x03 \x01(\x05R\n" +
+	"totalSales\x12\x1e\n" +
+	"\n" +
+	"localSales\x18\x04 \x01(\x05R\n" +
+	"localSales*f\n" +
+	"\bRespCode\x12\v\n" +
+	"\aSUCCESS\x10\x00\x12\x10\n" +
+	"\vBAD_REQUEST\x10\xa0\x1f\x12\x0e\n" +
+	"\tNOT_FOUND\x10\xa4\x1f\x12\x17\n" +
+	"\x12INVALID_TRANSITION\x10\xa9\x1f\x12\x12\n" +
+	"\rUNKNOWN_ERROR\x10\x88'*F\n" +
+	"\x05Actor\x12\x15\n" +
+	"\x11ACTOR_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bCUSTOMER\x10\x01\x12\f\n" +
+	"\bMERCHANT\x10\x02\x12\n" +
+	"\n" +
+	"\x06SYSTEM\x10\x042\x95\x03\n" +
+	"\fOrderService\x12?\n" +
+	"\bGetOrder\x12\x18.orderpb.GetOrderRequest\x1a\x19.orderpb.GetOrderResponse\x12E\n" +
+	"\n" +
+	"ListOrders\x12\x1a.orderpb.ListOrdersRequest\x1a\x1b.orderpb.ListOrdersResponse\x12Q\n" +
+	"\x0eBatchGetOrders\x12\x1e.orderpb.BatchGetOrdersRequest\x1a\x1f.orderpb.BatchGetOrdersResponse\x12Z\n" +
+	"\x11UpdateOrderStatus\x12!.orderpb.UpdateOrderStatusRequest\x1a\".orderpb.UpdateOrderStatusResponse\x12N\n" +
+	"\rGetOrderStats\x12\x1d.orderpb.GetOrderStatsRequest\x1a\x1e.orderpb.GetOrderStatsResponseB\x12Z\x10/orderpb;orderpbb\x06proto3"
+
+var (
+	file_proto_order_proto_rawDescOnce sync.Once
+	file_proto_order_proto_rawDescData []byte
+)
+
+func file_proto_order_proto_rawDescGZIP() []byte {
+	file_proto_order_proto_rawDescOnce.Do(func() {
+		file_proto_order_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_order_proto_rawDesc), len(file_proto_order_proto_rawDesc)))
+	})
+	return file_proto_order_proto_rawDescData
+}
+
+var file_proto_order_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_order_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_order_proto_goTypes = []any{
+	(RespCode)(0),                     // 0: orderpb.RespCode
+	(Actor)(0),                        // 1: orderpb.Actor
+	(*OrderItem)(nil),                 // 2: orderpb.OrderItem
+	(*Order)(nil),                     // 3: orderpb.Order
+	(*OrderSummary)(nil),              // 4: orderpb.OrderSummary
+	(*GetOrderRequest)(nil),           // 5: orderpb.GetOrderRequest
+	(*GetOrderResponse)(nil),          // 6: orderpb.GetOrderResponse
+	(*ListOrdersRequest)(nil),         // 7: orderpb.ListOrdersRequest
+	(*ListOrdersResponse)(nil),        // 8: orderpb.ListOrdersResponse
+	(*BatchGetOrdersRequest)(nil),     // 9: orderpb.BatchGetOrdersRequest
+	(*BatchGetOrdersResponse)(nil),    // 10: orderpb.BatchGetOrdersResponse
+	(*UpdateOrderStatusRequest)(nil),  // 11: orderpb.UpdateOrderStatusRequest
+	(*UpdateOrderStatusResponse)(nil), // 12: orderpb.UpdateOrderStatusResponse
+	(*GetOrderStatsRequest)(nil),      // 13: orderpb.GetOrderStatsRequest
+	(*GetOrderStatsResponse)(nil),     // 14: orderpb.GetOrderStatsResponse
+	(*CurrencySales)(nil),             // 15: orderpb.CurrencySales
+}
+var file_proto_order_proto_depIdxs = []int32{
+	2,  // 0: orderpb.Order.items:type_name -> orderpb.OrderItem
+	3,  // 1: orderpb.GetOrderResponse.order:type_name -> orderpb.Order
+	4,  // 2: orderpb.ListOrdersResponse.orders:type_name -> orderpb.OrderSummary
+	3,  // 3: orderpb.BatchGetOrdersResponse.orders:type_name -> orderpb.Order
+	1,  // 4: orderpb.UpdateOrderStatusRequest.actor:type_name -> orderpb.Actor
+	15, // 5: orderpb.GetOrderStatsResponse.salesByCurrency:type_name -> orderpb.CurrencySales
+	5,  // 6: orderpb.OrderService.GetOrder:input_type -> orderpb.GetOrderRequest
+	7,  // 7: orderpb.OrderService.ListOrders:input_type -> orderpb.ListOrdersRequest
+	9,  // 8: orderpb.OrderService.BatchGetOrders:input_type -> orderpb.BatchGetOrdersRequest
+	11, // 9: orderpb.OrderService.UpdateOrderStatus:input_type -> orderpb.UpdateOrderStatusRequest
+	13, // 10: orderpb.OrderService.GetOrderStats:input_type -> orderpb.GetOrderStatsRequest
+	6,  // 11: orderpb.OrderService.GetOrder:output_type -> orderpb.GetOrderResponse
+	8,  // 12: orderpb.OrderService.ListOrders:output_type -> orderpb.ListOrdersResponse
+	10, // 13: orderpb.OrderService.BatchGetOrders:output_type -> orderpb.BatchGetOrdersResponse
+	12, // 14: orderpb.OrderService.UpdateOrderStatus:output_type -> orderpb.UpdateOrderStatusResponse
+	14, // 15: orderpb.OrderService.GetOrderStats:output_type -> orderpb.GetOrderStatsResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_proto_order_proto_init() }
+func file_proto_order_proto_init() {
+	if File_proto_order_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_order_proto_rawDesc), len(file_proto_order_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_order_proto_goTypes,
+		DependencyIndexes: file_proto_order_proto_depIdxs,
+		EnumInfos:         file_proto_order_proto_enumTypes,
+		MessageInfos:      file_proto_order_proto_msgTypes,
+	}.Build()
+	File_proto_order_proto = out.File
+	file_proto_order_proto_goTypes = nil
+	file_proto_order_proto_depIdxs = nil
+}
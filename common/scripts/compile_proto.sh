@@ -1,3 +1,3 @@
 #!/bin/bash
-protoc --go_out=. --go-grpc_out=. proto/demo.proto
-
+protoc --go_out=. --go-grpc_out=. proto/order.proto
+protoc --go_out=. proto/events.proto
@@ -17,6 +17,82 @@ type Conf struct {
 	PaymentClient   *PaymentClient   `mapstruct:"paymentClient"`
 	KafkaConfig     *KafkaConfig     `mapstructure:"kafka"`
 	RedisConfig     *RedisConfig     `mapstructure:"redis"`
+	OrderConfig     *OrderConfig     `mapstructure:"order"`
+	TaxConfig       *TaxConfig       `mapstructure:"tax"`
+	ShippingConfig  *ShippingConfig  `mapstructure:"shipping"`
+	CurrencyConfig  *CurrencyConfig  `mapstructure:"currency"`
+	InvoiceConfig   *InvoiceConfig   `mapstructure:"invoice"`
+}
+
+type OrderConfig struct {
+	UnpaidExpireMinutes int `mapstructure:"unpaid_expire_minutes"` // 未付款订单超过该时间自动取消
+}
+
+// TaxConfig 按收货国家及邮编配置税率，税率单位为万分之一 (900 = 9%)
+type TaxConfig struct {
+	DefaultCountry    string           `mapstructure:"default_country"`    // 收货国家未配置时使用该国家的税率
+	Regions           []*TaxRegion     `mapstructure:"regions"`            // 税率规则
+	ProductCategories map[string][]int `mapstructure:"product_categories"` // 税务类别 -> 商品ID，未列出的商品为标准税率
+}
+
+type TaxRegion struct {
+	Country       string         `mapstructure:"country"`        // 国家，不区分大小写
+	ZipPrefix     string         `mapstructure:"zip_prefix"`     // 邮编前缀，为空时适用整个国家
+	Rate          int            `mapstructure:"rate"`           // 标准税率
+	Inclusive     bool           `mapstructure:"inclusive"`      // 商品价格是否已含税
+	CategoryRates map[string]int `mapstructure:"category_rates"` // 税务类别的税率，覆盖标准税率
+}
+
+// ShippingConfig 按收货国家划分运费区域，重量单位为克，尺寸单位为厘米。
+// 商品服务暂不提供重量和尺寸，由 Products 配置
+type ShippingConfig struct {
+	DefaultZone   string             `mapstructure:"default_zone"`   // 收货国家不在任何区域时使用的区域
+	DefaultWeight int                `mapstructure:"default_weight"` // 未配置的商品按该重量计算
+	Zones         []*ShippingZone    `mapstructure:"zones"`
+	Products      []*ProductShipping `mapstructure:"products"`
+}
+
+type ShippingZone struct {
+	Name              string   `mapstructure:"name"`
+	Countries         []string `mapstructure:"countries"`          // 国家，不区分大小写
+	BaseFee           int      `mapstructure:"base_fee"`           // 首重运费
+	BaseWeight        int      `mapstructure:"base_weight"`        // 首重
+	PerKgFee          int      `mapstructure:"per_kg_fee"`         // 超出首重部分每公斤运费，不足一公斤按一公斤计
+	VolumetricDivisor int      `mapstructure:"volumetric_divisor"` // 体积(立方厘米) / 系数 = 体积重(公斤)，为 0 时不计体积重
+	FragileSurcharge  int      `mapstructure:"fragile_surcharge"`  // 每件易碎商品的附加费
+	FreeThreshold     int      `mapstructure:"free_threshold"`     // 商品金额达到该值时免首重及续重运费，为 0 时不免运费
+}
+
+type ProductShipping struct {
+	ProductID int  `mapstructure:"product_id"`
+	Weight    int  `mapstructure:"weight"`
+	Length    int  `mapstructure:"length"`
+	Width     int  `mapstructure:"width"`
+	Height    int  `mapstructure:"height"`
+	Fragile   bool `mapstructure:"fragile"`
+}
+
+// CurrencyConfig 商品价格、支付和统计使用基础货币，订单另外记录展示给客户的货币及下单时的汇率
+type CurrencyConfig struct {
+	BaseCurrency string          `mapstructure:"base_currency"`
+	Currencies   []*CurrencyRate `mapstructure:"currencies"`
+}
+
+type CurrencyRate struct {
+	Code      string   `mapstructure:"code"`       // ISO 4217 货币代码
+	Symbol    string   `mapstructure:"symbol"`     // 格式化金额时的前缀
+	MinorUnit int      `mapstructure:"minor_unit"` // 1 单位货币包含的最小单位数，SGD 为 100，JPY 为 1，为 0 时按 100 处理
+	Rate      float64  `mapstructure:"rate"`       // 1 基础货币可兑换的该货币
+	Countries []string `mapstructure:"countries"`  // 默认使用该货币的收货国家
+}
+
+// InvoiceConfig 发票及贷项通知单上的商家信息和编号前缀，编号格式为 前缀-年份-6位序号
+type InvoiceConfig struct {
+	SellerName       string `mapstructure:"seller_name"`
+	SellerAddress    string `mapstructure:"seller_address"`
+	TaxID            string `mapstructure:"tax_id"`             // 商家税务登记号
+	InvoicePrefix    string `mapstructure:"invoice_prefix"`     // 发票编号前缀，默认 INV
+	CreditNotePrefix string `mapstructure:"credit_note_prefix"` // 贷项通知单编号前缀，默认 CN
 }
 
 type RedisConfig struct {
@@ -60,8 +136,30 @@ type PaymentClient struct {
 }
 
 type KafkaConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host      string                 `mapstructure:"host"`
+	Port      int                    `mapstructure:"port"`
+	Consumers []*KafkaConsumerConfig `mapstructure:"consumers"` // 消费组，未配置时使用默认消费组
+	Producer  *KafkaProducerConfig   `mapstructure:"producer"`
+}
+
+// KafkaProducerConfig 发送消息的批量参数，为 0 时使用默认值
+type KafkaProducerConfig struct {
+	BufferSize   int `mapstructure:"buffer_size"`   // 异步发送缓冲区大小，缓冲区满时 SendMsg 返回错误，默认 1000
+	BatchSize    int `mapstructure:"batch_size"`    // 每批最多发送的消息数，默认 100
+	BatchTimeout int `mapstructure:"batch_timeout"` // 毫秒，未凑满一批时最多等待的时间，默认 10
+	FlushTimeout int `mapstructure:"flush_timeout"` // 秒，退出时发送异步缓冲区中剩余消息的时间，默认 10
+}
+
+// KafkaConsumerConfig 一个消费组；所有副本使用相同的 group_id，由 kafka 在副本之间分配分区，
+// 分区数应不少于 副本数 × concurrency 才能充分并行
+type KafkaConsumerConfig struct {
+	GroupID           string   `mapstructure:"group_id"`
+	Topics            []string `mapstructure:"topics"`             // 订阅的 topic，每个 topic 需要注册 handler
+	Concurrency       int      `mapstructure:"concurrency"`        // 每个副本同时处理的分区数，同一分区的消息按顺序处理，默认 1
+	SessionTimeout    int      `mapstructure:"session_timeout"`    // 秒，超过该时间没有心跳的副本被移出消费组，为 0 时使用 kafka-go 默认值
+	RebalanceTimeout  int      `mapstructure:"rebalance_timeout"`  // 秒，重新分配分区时等待副本重新加入的时间
+	HeartbeatInterval int      `mapstructure:"heartbeat_interval"` // 秒
+	DrainTimeout      int      `mapstructure:"drain_timeout"`      // 秒，退出时等待正在处理的消息完成的时间，默认 10
 }
 
 var UseLocalConfig = false
@@ -15,9 +15,71 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/customer/gift-cards/{code}": {
+            "get": {
+                "description": "下单前查询礼品卡的可用余额，不返回流水",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GiftCard"
+                ],
+                "summary": "用户侧查询礼品卡余额",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "卡号",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.GiftCardInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/customer/orders": {
             "post": {
-                "description": "创建一个新订单",
+                "description": "创建一个新订单，coupon_code 不为空时使用优惠券",
                 "consumes": [
                     "application/json"
                 ],
@@ -29,6 +91,12 @@ const docTemplate = `{
                 ],
                 "summary": "创建订单",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，相同的键重放返回原订单号，用于不同的请求返回 409",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "订单信息",
                         "name": "order",
@@ -46,6 +114,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.Response"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "商品价格已变化，返回重新报价；或幂等键已被其他请求使用",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.PriceChangedInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -113,6 +205,64 @@ const docTemplate = `{
                 }
             }
         },
+        "/customer/orders/quote": {
+            "post": {
+                "description": "按与创建订单相同的计算过程返回商品价格、库存、运费、优惠、税费和总金额，不创建订单也不扣款",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "下单前报价",
+                "parameters": [
+                    {
+                        "description": "订单信息",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.OrderInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.OrderQuote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/customer/orders/{order_no}": {
             "get": {
                 "description": "根据订单号查询订单详情，包括订单基本信息、商品列表和状态日志",
@@ -175,9 +325,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/customer/orders/{order_no}/confirm": {
+        "/customer/orders/{order_no}/cancel": {
             "patch": {
-                "description": "用户确认收到商品，订单状态变更为已收货",
+                "description": "用户取消未发货的订单，回补库存，已付款的订单会发起退款",
                 "consumes": [
                     "application/json"
                 ],
@@ -187,15 +337,22 @@ const docTemplate = `{
                 "tags": [
                     "Order"
                 ],
-                "summary": "用户确认收货",
+                "summary": "用户取消订单",
                 "parameters": [
                     {
-                        "description": "确认收货信息",
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "取消原因",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.ConfirmOrderRequest"
+                            "$ref": "#/definitions/types.CancelOrderRequest"
                         }
                     }
                 ],
@@ -221,9 +378,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/merchant/order-stats": {
-            "get": {
-                "description": "get Order Stats",
+        "/customer/orders/{order_no}/confirm": {
+            "patch": {
+                "description": "用户确认收到商品，订单状态变更为已收货",
                 "consumes": [
                     "application/json"
                 ],
@@ -233,7 +390,18 @@ const docTemplate = `{
                 "tags": [
                     "Order"
                 ],
-                "summary": "get Order Stats",
+                "summary": "用户确认收货",
+                "parameters": [
+                    {
+                        "description": "确认收货信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ConfirmOrderRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -241,6 +409,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.Response"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -250,9 +424,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/merchant/orders/list": {
-            "post": {
-                "description": "根据条件查询订单列表，支持分页",
+        "/customer/orders/{order_no}/credit-notes": {
+            "get": {
+                "description": "按开具时间返回订单退款产生的贷项通知单",
                 "consumes": [
                     "application/json"
                 ],
@@ -260,18 +434,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Invoice"
                 ],
-                "summary": "查询订单列表",
+                "summary": "用户侧查询订单的贷项通知单",
                 "parameters": [
                     {
-                        "description": "查询条件",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.ListOrderRequest"
-                        }
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -286,7 +458,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/types.ListOrderResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/types.Invoice"
+                                            }
                                         }
                                     }
                                 }
@@ -308,19 +483,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/merchant/orders/{order_no}": {
+        "/customer/orders/{order_no}/credit-notes/{credit_note_no}": {
             "get": {
-                "description": "根据订单号查询订单详情，包括订单基本信息、商品列表和状态日志",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "退款时开具的贷项通知单，format 为 pdf（默认）、html 或 json",
                 "produces": [
+                    "application/pdf",
+                    "text/html",
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Invoice"
                 ],
-                "summary": "查询订单详情",
+                "summary": "用户侧下载贷项通知单",
                 "parameters": [
                     {
                         "type": "string",
@@ -328,11 +502,24 @@ const docTemplate = `{
                         "name": "order_no",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "贷项通知单号",
+                        "name": "credit_note_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件格式 (pdf, html, json)",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "format 为 json 时返回贷项通知单内容，否则返回文件",
                         "schema": {
                             "allOf": [
                                 {
@@ -342,7 +529,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/types.OrderDetail"
+                                            "$ref": "#/definitions/types.Invoice"
                                         }
                                     }
                                 }
@@ -370,35 +557,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/merchant/orders/{order_no}/ship": {
-            "patch": {
-                "description": "商家标记订单为已发货状态，并添加物流单号",
-                "consumes": [
-                    "application/json"
-                ],
+        "/customer/orders/{order_no}/invoice": {
+            "get": {
+                "description": "订单付款后开具的发票，format 为 pdf（默认）、html 或 json",
                 "produces": [
+                    "application/pdf",
+                    "text/html",
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Invoice"
                 ],
-                "summary": "商家发货",
+                "summary": "用户侧下载订单发票",
                 "parameters": [
                     {
-                        "description": "发货信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.ShipOrderRequest"
-                        }
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件格式 (pdf, html, json)",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "format 为 json 时返回发票内容，否则返回文件",
                         "schema": {
-                            "$ref": "#/definitions/api.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -407,6 +609,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -415,356 +623,3167 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.Response": {
-            "type": "object",
-            "properties": {
+        },
+        "/customer/orders/{order_no}/refunds": {
+            "post": {
+                "description": "用户对已付款的订单申请全部或部分商品退款，items 为空时退还剩余全部商品，订单进入退款中等待商家审核",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "用户申请退款",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "退款信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.RefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "退款单号",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/customer/orders/{order_no}/returns": {
+            "post": {
+                "description": "用户对已收货的订单申请退货，需提供原因和商品照片，items 为空时退回剩余全部商品",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "用户申请退货",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "退货信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateReturnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "退货单号",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/customer/shipping/quote": {
+            "post": {
+                "description": "购物车页面下单前按收货国家、商品重量和尺寸查询运费，不创建订单",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "查询运费",
+                "parameters": [
+                    {
+                        "description": "收货地址和商品列表",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ShippingQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.ShippingQuote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/customer/store-credit": {
+            "get": {
+                "description": "查询退款发放的店铺余额及最近的流水",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GiftCard"
+                ],
+                "summary": "查询店铺余额",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.GiftCardInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/coupons": {
+            "get": {
+                "description": "分页查询优惠券，按创建时间倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupon"
+                ],
+                "summary": "查询优惠券列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "状态 (1-可用； 2-已停用)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "类型 (1-比例折扣； 2-固定减免； 3-免运费； 4-买X送Y)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页限制，默认 20，最大 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页偏移",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.ListCouponResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建比例折扣、固定减免、免运费或买X送Y 优惠券，可设置最低消费及总使用次数和每个用户的使用次数上限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupon"
+                ],
+                "summary": "创建优惠券",
+                "parameters": [
+                    {
+                        "description": "优惠券",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateCouponRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.CouponInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/coupons/{code}/status": {
+            "patch": {
+                "description": "停用后优惠码不能再用于下单，已下单的订单不受影响",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupon"
+                ],
+                "summary": "启用或停用优惠券",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "优惠码",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateCouponStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/dead-letters/{topic}/replay": {
+            "post": {
+                "description": "将 \u003ctopic\u003e.dlq 中的消息重新投递到原 topic，由原消费者重新处理；读不到新的死信或达到 limit 时结束，死信队列为空时需要等待加入消费组的超时",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "DeadLetter"
+                ],
+                "summary": "重放死信队列",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "原 topic，目前支持 order_status_changed 和 payment_result",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最多重放的条数，默认 100，最大 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.ReplayDeadLetterResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "重放中途失败，data 为已重放的结果",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.ReplayDeadLetterResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/gift-cards": {
+            "post": {
+                "description": "发行指定面额的礼品卡，卡号为空时随机生成，SC- 开头的卡号保留给店铺余额",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GiftCard"
+                ],
+                "summary": "发行礼品卡",
+                "parameters": [
+                    {
+                        "description": "礼品卡",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateGiftCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.GiftCardInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/gift-cards/{code}": {
+            "get": {
+                "description": "查询礼品卡或店铺余额（卡号 SC-用户ID）的余额及最近的流水",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GiftCard"
+                ],
+                "summary": "查询礼品卡",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "卡号",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.GiftCardInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/gift-cards/{code}/status": {
+            "patch": {
+                "description": "停用后不能再用于下单，已下单冻结的金额不受影响",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GiftCard"
+                ],
+                "summary": "启用或停用礼品卡",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "卡号",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateGiftCardStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/order-stats": {
+            "get": {
+                "description": "get Order Stats",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "get Order Stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/list": {
+            "post": {
+                "description": "根据条件查询订单列表，支持分页",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "查询订单列表",
+                "parameters": [
+                    {
+                        "description": "查询条件",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ListOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.ListOrderResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/{order_no}": {
+            "get": {
+                "description": "根据订单号查询订单详情，包括订单基本信息、商品列表和状态日志",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "查询订单详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.OrderDetail"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/{order_no}/cancel": {
+            "patch": {
+                "description": "商家取消未发货的订单，回补库存，已付款的订单会发起退款",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家取消订单",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "取消原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CancelOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/{order_no}/credit-notes": {
+            "get": {
+                "description": "按开具时间返回订单退款产生的贷项通知单",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoice"
+                ],
+                "summary": "查询订单的贷项通知单",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/types.Invoice"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/{order_no}/credit-notes/{credit_note_no}": {
+            "get": {
+                "description": "退款时开具的贷项通知单，format 为 pdf（默认）、html 或 json",
+                "produces": [
+                    "application/pdf",
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoice"
+                ],
+                "summary": "下载贷项通知单",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "贷项通知单号",
+                        "name": "credit_note_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件格式 (pdf, html, json)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "format 为 json 时返回贷项通知单内容，否则返回文件",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/{order_no}/invoice": {
+            "get": {
+                "description": "订单付款后开具的发票，format 为 pdf（默认）、html 或 json",
+                "produces": [
+                    "application/pdf",
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoice"
+                ],
+                "summary": "下载订单发票",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件格式 (pdf, html, json)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "format 为 json 时返回发票内容，否则返回文件",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/orders/{order_no}/ship": {
+            "patch": {
+                "description": "商家标记订单为已发货状态，并添加物流单号",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家发货",
+                "parameters": [
+                    {
+                        "description": "发货信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ShipOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/reconciliation": {
+            "get": {
+                "description": "分页查询对账任务发现的订单与支付记录之间的差异，按发现时间倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "查询对账差异",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "差异类型 (1-缺少支付单； 2-支付单无对应已付款订单； 3-金额不一致)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "order_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发现时间开始范围 (RFC3339)",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发现时间结束范围 (RFC3339)",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页限制，默认 20，最大 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分页偏移",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/types.ListDiscrepancyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/refunds/{refund_no}/approve": {
+            "patch": {
+                "description": "商家同意退款申请，通知支付服务退款，订单变为已退款或部分退款",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家同意退款",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "退款单号",
+                        "name": "refund_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/refunds/{refund_no}/reject": {
+            "patch": {
+                "description": "商家拒绝退款申请，订单回到申请退款前的状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家拒绝退款",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "退款单号",
+                        "name": "refund_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "拒绝原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.RejectRefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/returns/{return_no}/approve": {
+            "patch": {
+                "description": "商家同意退货申请，提供退货面单和退货物流单号",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家同意退货",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "退货单号",
+                        "name": "return_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "退货物流信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ApproveReturnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/returns/{return_no}/receive": {
+            "patch": {
+                "description": "商家收到退回的商品后退款，可选择将商品加回库存",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家确认收到退货",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "退货单号",
+                        "name": "return_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "是否回补库存",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ReceiveReturnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/merchant/returns/{return_no}/reject": {
+            "patch": {
+                "description": "商家拒绝退货申请，订单回到已收货",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "商家拒绝退货",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "退货单号",
+                        "name": "return_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "拒绝原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.RejectReturnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.Response": {
+            "type": "object",
+            "properties": {
                 "data": {},
                 "error": {
                     "type": "string"
                 },
-                "msg": {
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ApproveReturnRequest": {
+            "type": "object",
+            "properties": {
+                "return_label": {
+                    "description": "退货面单",
+                    "type": "string"
+                },
+                "tracking_no": {
+                    "description": "退货物流单号",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CancelOrderRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "取消原因",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ConfirmOrderRequest": {
+            "type": "object",
+            "properties": {
+                "order_no": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.CouponInfo": {
+            "type": "object",
+            "properties": {
+                "buy_quantity": {
+                    "description": "买X送Y 中的 X",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "优惠码",
+                    "type": "string"
+                },
+                "create_time": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "end_time": {
+                    "description": "失效时间",
+                    "type": "string"
+                },
+                "get_quantity": {
+                    "description": "买X送Y 中的 Y",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_discount": {
+                    "description": "比例折扣的最高减免金额",
+                    "type": "integer"
+                },
+                "min_spend": {
+                    "description": "最低消费",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string"
+                },
+                "per_user_limit": {
+                    "description": "每个用户的使用次数上限",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "买X送Y 限定的商品",
+                    "type": "integer"
+                },
+                "start_time": {
+                    "description": "生效时间",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "状态",
+                    "type": "integer"
+                },
+                "status_name": {
+                    "description": "状态名称",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "类型",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "类型名称",
+                    "type": "string"
+                },
+                "usage_limit": {
+                    "description": "总使用次数上限",
+                    "type": "integer"
+                },
+                "used_count": {
+                    "description": "已使用次数",
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "折扣百分比或减免金额",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateCouponRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "type"
+            ],
+            "properties": {
+                "buy_quantity": {
+                    "description": "买X送Y 中的 X",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "优惠码",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "end_time": {
+                    "description": "失效时间，为空表示长期有效",
+                    "type": "string"
+                },
+                "get_quantity": {
+                    "description": "买X送Y 中的 Y",
+                    "type": "integer"
+                },
+                "max_discount": {
+                    "description": "比例折扣的最高减免金额，0 表示不限",
+                    "type": "integer"
+                },
+                "min_spend": {
+                    "description": "最低消费",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "per_user_limit": {
+                    "description": "每个用户的使用次数上限，0 表示不限",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "买X送Y 限定的商品，0 表示所有商品",
+                    "type": "integer"
+                },
+                "start_time": {
+                    "description": "生效时间，为空表示立即生效",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "类型 (1-比例折扣； 2-固定减免； 3-免运费； 4-买X送Y)",
+                    "type": "integer"
+                },
+                "usage_limit": {
+                    "description": "总使用次数上限，0 表示不限",
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "比例折扣为折扣百分比 (1-100)，固定减免为减免金额",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.CreateGiftCardRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "面额",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "卡号，为空时随机生成",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "expire_time": {
+                    "description": "过期时间，为空表示长期有效",
+                    "type": "string"
+                }
+            }
+        },
+        "types.CreateReturnRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "退货商品",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.RefundItemRequest"
+                    }
+                },
+                "photo_urls": {
+                    "description": "商品照片",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "description": "退货原因",
+                    "type": "string"
+                },
+                "to_store_credit": {
+                    "description": "收货后是否退为店铺余额，否则退回原支付方式",
+                    "type": "boolean"
+                }
+            }
+        },
+        "types.CustomerListOrderRequest": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "description": "创建时间结束范围",
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "分页限制",
+                    "type": "integer"
+                },
+                "offset": {
+                    "description": "分页偏移",
+                    "type": "integer"
+                },
+                "start_time": {
+                    "description": "创建时间开始范围",
+                    "type": "string"
+                }
+            }
+        },
+        "types.DiscrepancyInfo": {
+            "type": "object",
+            "properties": {
+                "create_time": {
+                    "description": "发现时间",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_amount": {
+                    "description": "订单金额",
+                    "type": "integer"
+                },
+                "order_no": {
+                    "description": "订单编号",
+                    "type": "string"
+                },
+                "order_status": {
+                    "description": "对账时的订单状态",
+                    "type": "integer"
+                },
+                "order_status_name": {
+                    "description": "对账时的订单状态名称",
+                    "type": "string"
+                },
+                "order_time": {
+                    "description": "下单时间",
+                    "type": "string"
+                },
+                "paid_amount": {
+                    "description": "支付服务中的支付金额合计",
+                    "type": "integer"
+                },
+                "pay_order_ids": {
+                    "description": "支付单号",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "差异类型",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "差异类型名称",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "下单用户",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.GiftCardInfo": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "可用余额",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "卡号",
+                    "type": "string"
+                },
+                "create_time": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "expire_time": {
+                    "description": "过期时间",
+                    "type": "string"
+                },
+                "initial_balance": {
+                    "description": "面额，店铺余额为 0",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "状态",
+                    "type": "integer"
+                },
+                "status_name": {
+                    "description": "状态名称",
+                    "type": "string"
+                },
+                "transactions": {
+                    "description": "最近的余额流水",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GiftCardTransactionInfo"
+                    }
+                },
+                "type": {
+                    "description": "类型",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "类型名称",
+                    "type": "string"
+                }
+            }
+        },
+        "types.GiftCardTransactionInfo": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "余额变动，扣减为负数",
+                    "type": "integer"
+                },
+                "balance_after": {
+                    "description": "变动后的余额",
+                    "type": "integer"
+                },
+                "create_time": {
+                    "description": "时间",
+                    "type": "string"
+                },
+                "order_no": {
+                    "description": "订单编号",
+                    "type": "string"
+                },
+                "refund_no": {
+                    "description": "退款单号",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "流水类型",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "流水类型名称",
+                    "type": "string"
+                }
+            }
+        },
+        "types.Invoice": {
+            "type": "object",
+            "properties": {
+                "bill_to": {
+                    "description": "收货方",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceParty"
+                        }
+                    ]
+                },
+                "currency": {
+                    "description": "金额使用的基础货币",
+                    "type": "string"
+                },
+                "discount_amount": {
+                    "description": "优惠减免合计",
+                    "type": "integer"
+                },
+                "exchange_rate": {
+                    "description": "下单时的汇率快照",
+                    "type": "integer"
+                },
+                "invoice_no": {
+                    "description": "发票编号",
+                    "type": "string"
+                },
+                "issue_time": {
+                    "description": "开具时间",
+                    "type": "string"
+                },
+                "lines": {
+                    "description": "明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.InvoiceLine"
+                    }
+                },
+                "order_currency": {
+                    "description": "订单货币",
+                    "type": "string"
+                },
+                "order_no": {
+                    "description": "订单编号",
+                    "type": "string"
+                },
+                "pay_method": {
+                    "description": "支付方式",
+                    "type": "string"
+                },
+                "pay_transaction_id": {
+                    "description": "支付单号",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "退款原因",
+                    "type": "string"
+                },
+                "refund_no": {
+                    "description": "贷项通知单对应的退款单号",
+                    "type": "string"
+                },
+                "related_invoice_no": {
+                    "description": "贷项通知单引用的原发票编号",
+                    "type": "string"
+                },
+                "seller": {
+                    "description": "商家",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceParty"
+                        }
+                    ]
+                },
+                "tax": {
+                    "description": "税额合计",
+                    "type": "integer"
+                },
+                "tax_breakdown": {
+                    "description": "按税率汇总",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.InvoiceTaxLine"
+                    }
+                },
+                "tax_inclusive": {
+                    "description": "商品价格是否已含税",
+                    "type": "boolean"
+                },
+                "tax_region": {
+                    "description": "税率规则",
+                    "type": "string"
+                },
+                "total_amount": {
+                    "description": "含税总金额，贷项通知单为退款金额",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "类型 (1-发票； 2-贷项通知单)",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "类型名称",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "下单用户",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.InvoiceLine": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "discount": {
+                    "description": "优惠减免",
+                    "type": "integer"
+                },
+                "net_amount": {
+                    "description": "优惠后金额，含税价格时包含税额",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "运费及调整行为 0",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "tax_amount": {
+                    "description": "税额",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "税率，万分之一",
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.InvoiceParty": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "tax_id": {
+                    "description": "税务登记号",
+                    "type": "string"
+                },
+                "zip_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.InvoiceTaxLine": {
+            "type": "object",
+            "properties": {
+                "tax_amount": {
+                    "description": "税额",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "税率，万分之一",
+                    "type": "integer"
+                },
+                "taxable_amount": {
+                    "description": "不含税金额",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ListCouponResponse": {
+            "type": "object",
+            "properties": {
+                "coupons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.CouponInfo"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ListDiscrepancyResponse": {
+            "type": "object",
+            "properties": {
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.DiscrepancyInfo"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ListOrderRequest": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "description": "创建时间结束范围",
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "分页限制",
+                    "type": "integer"
+                },
+                "offset": {
+                    "description": "分页偏移",
+                    "type": "integer"
+                },
+                "order_no": {
+                    "description": "订单号筛选",
+                    "type": "string"
+                },
+                "order_status": {
+                    "description": "订单状态筛选",
+                    "type": "integer"
+                },
+                "start_time": {
+                    "description": "创建时间开始范围",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "用户ID筛选",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ListOrderResponse": {
+            "type": "object",
+            "properties": {
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderInfoInList"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "formatted": {
+                    "description": "如 S$12.50",
+                    "type": "string"
+                }
+            }
+        },
+        "types.OrderAmounts": {
+            "type": "object",
+            "properties": {
+                "discount_amount": {
+                    "description": "优惠减免金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                },
+                "item_total_amount": {
+                    "description": "商品金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                },
+                "pay_amount": {
+                    "description": "实际支付金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                },
+                "refunded_amount": {
+                    "description": "已退款金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                },
+                "shipping_fee": {
+                    "description": "运费",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                },
+                "tax": {
+                    "description": "税费",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                },
+                "total_amount": {
+                    "description": "总金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Money"
+                        }
+                    ]
+                }
+            }
+        },
+        "types.OrderDetail": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "description": "订单货币的格式化金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.OrderAmounts"
+                        }
+                    ]
+                },
+                "base_amounts": {
+                    "description": "基础货币的格式化金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.OrderAmounts"
+                        }
+                    ]
+                },
+                "base_currency": {
+                    "description": "基础货币",
+                    "type": "string"
+                },
+                "cancel_reason": {
+                    "description": "取消原因",
+                    "type": "string"
+                },
+                "cancel_time": {
+                    "description": "取消时间",
+                    "type": "string"
+                },
+                "confirm_time": {
+                    "description": "收货确认时间",
+                    "type": "string"
+                },
+                "coupon_code": {
+                    "description": "使用的优惠码",
+                    "type": "string"
+                },
+                "create_time": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "货币信息，金额字段均为基础货币，Amounts 按下单时的汇率换算为订单货币",
+                    "type": "string"
+                },
+                "delivery_time": {
+                    "description": "发货时间",
+                    "type": "string"
+                },
+                "discount_amount": {
+                    "description": "优惠信息",
+                    "type": "integer"
+                },
+                "discounts": {
+                    "description": "优惠明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderDiscountDetail"
+                    }
+                },
+                "exchange_rate": {
+                    "description": "下单时的汇率快照，1e6 个基础货币最小单位可兑换的订单货币最小单位",
+                    "type": "integer"
+                },
+                "logistics_no": {
+                    "description": "物流单号",
+                    "type": "string"
+                },
+                "order_items": {
+                    "description": "订单商品列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderItemDetail"
+                    }
+                },
+                "order_no": {
+                    "description": "基本订单信息",
+                    "type": "string"
+                },
+                "pay_amount": {
+                    "description": "实际支付金额",
+                    "type": "integer"
+                },
+                "pay_method": {
+                    "description": "支付方式",
+                    "type": "string"
+                },
+                "pay_time": {
+                    "description": "支付时间",
+                    "type": "string"
+                },
+                "pay_transaction_id": {
+                    "description": "支付信息，PayAmount 为支付服务扣款的金额，不包括礼品卡和店铺余额抵扣的部分",
+                    "type": "string"
+                },
+                "receiver_address": {
+                    "description": "收货地址",
+                    "type": "string"
+                },
+                "receiver_country": {
+                    "description": "收货人国家",
+                    "type": "string"
+                },
+                "receiver_first_name": {
+                    "description": "收货信息",
+                    "type": "string"
+                },
+                "receiver_last_name": {
+                    "description": "收货人姓名",
+                    "type": "string"
+                },
+                "receiver_phone": {
+                    "description": "收货人电话",
+                    "type": "string"
+                },
+                "receiver_zip_code": {
+                    "description": "收货人邮政编码",
+                    "type": "integer"
+                },
+                "refunded_amount": {
+                    "description": "退款记录",
+                    "type": "integer"
+                },
+                "refunds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.RefundDetail"
+                    }
+                },
+                "remark": {
+                    "description": "其他信息",
+                    "type": "string"
+                },
+                "returns": {
+                    "description": "退货记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ReturnDetail"
+                    }
+                },
+                "shipping_fee": {
+                    "description": "运费",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "订单状态",
+                    "type": "integer"
+                },
+                "status_logs": {
+                    "description": "订单状态变更日志",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderStatusLogDetail"
+                    }
+                },
+                "status_name": {
+                    "description": "订单状态名称",
+                    "type": "string"
+                },
+                "tax": {
+                    "description": "税费",
+                    "type": "integer"
+                },
+                "tax_inclusive": {
+                    "description": "商品价格是否已含税",
+                    "type": "boolean"
+                },
+                "tax_rate": {
+                    "description": "下单时的标准税率，万分之一",
+                    "type": "integer"
+                },
+                "tax_region": {
+                    "description": "税费信息",
+                    "type": "string"
+                },
+                "tender_amount": {
+                    "description": "礼品卡和店铺余额抵扣的金额",
+                    "type": "integer"
+                },
+                "tenders": {
+                    "description": "礼品卡和店铺余额抵扣明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderTenderDetail"
+                    }
+                },
+                "total_amount": {
+                    "description": "总金额",
+                    "type": "integer"
+                },
+                "update_time": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "下单用户",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.OrderDiscountDetail": {
+            "type": "object",
+            "properties": {
+                "coupon_code": {
+                    "description": "优惠码",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "优惠说明",
+                    "type": "string"
+                },
+                "item_discount": {
+                    "description": "商品金额减免",
+                    "type": "integer"
+                },
+                "shipping_discount": {
+                    "description": "运费减免",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "优惠券类型",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "优惠券类型名称",
+                    "type": "string"
+                }
+            }
+        },
+        "types.OrderInfo": {
+            "type": "object",
+            "properties": {
+                "coupon_code": {
+                    "description": "优惠码，可为空",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "订单货币，为空时按收货国家选择",
+                    "type": "string"
+                },
+                "expected_total_amount": {
+                    "description": "客户端展示的订单总金额，非 0 时需与服务端一致",
+                    "type": "integer"
+                },
+                "gift_card_codes": {
+                    "description": "使用的礼品卡号，按顺序抵扣订单金额",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "order_item_list": {
+                    "description": "订单商品列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderItemInfo"
+                    }
+                },
+                "receiver_address": {
+                    "description": "收货地址",
+                    "type": "string"
+                },
+                "receiver_country": {
+                    "description": "收货人国家",
                     "type": "string"
                 },
-                "status": {
+                "receiver_first_name": {
+                    "description": "收货人姓名",
+                    "type": "string"
+                },
+                "receiver_last_name": {
+                    "description": "收货人姓名",
+                    "type": "string"
+                },
+                "receiver_phone": {
+                    "description": "收货人电话",
+                    "type": "string"
+                },
+                "receiver_zip_code": {
+                    "description": "收货人邮政编码",
                     "type": "integer"
+                },
+                "remark": {
+                    "description": "备注",
+                    "type": "string"
+                },
+                "use_store_credit": {
+                    "description": "是否使用店铺余额，在礼品卡之后抵扣",
+                    "type": "boolean"
                 }
             }
         },
-        "types.ConfirmOrderRequest": {
+        "types.OrderInfoInList": {
             "type": "object",
             "properties": {
+                "create_time": {
+                    "type": "string"
+                },
                 "order_no": {
                     "type": "string"
+                },
+                "receiver_first_name": {
+                    "description": "收货人姓名",
+                    "type": "string"
+                },
+                "receiver_last_name": {
+                    "description": "收货人姓名",
+                    "type": "string"
+                },
+                "receiver_phone": {
+                    "description": "收货人电话",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "integer"
                 }
             }
         },
-        "types.CustomerListOrderRequest": {
+        "types.OrderItemDetail": {
             "type": "object",
             "properties": {
-                "end_time": {
-                    "description": "创建时间结束范围",
+                "create_time": {
+                    "description": "创建时间",
                     "type": "string"
                 },
-                "limit": {
-                    "description": "分页限制",
+                "discount_amount": {
+                    "description": "分摊到该商品的优惠金额",
                     "type": "integer"
                 },
-                "offset": {
-                    "description": "分页偏移",
+                "id": {
+                    "description": "订单商品ID",
                     "type": "integer"
                 },
-                "start_time": {
-                    "description": "创建时间开始范围",
+                "price": {
+                    "description": "商品单价",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "description": "商品ID",
+                    "type": "integer"
+                },
+                "product_name": {
+                    "description": "商品名称",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "商品数量",
+                    "type": "integer"
+                },
+                "tax_amount": {
+                    "description": "分摊到该商品的税额",
+                    "type": "integer"
+                },
+                "tax_category": {
+                    "description": "税务类别，标准税率时为空",
+                    "type": "string"
+                },
+                "tax_rate": {
+                    "description": "适用税率，万分之一",
+                    "type": "integer"
+                },
+                "total_price": {
+                    "description": "商品总价",
+                    "type": "integer"
+                },
+                "update_time": {
+                    "description": "更新时间",
                     "type": "string"
                 }
             }
         },
-        "types.ListOrderRequest": {
+        "types.OrderItemInfo": {
             "type": "object",
             "properties": {
-                "end_time": {
-                    "description": "创建时间结束范围",
+                "price": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
                     "type": "string"
                 },
-                "limit": {
-                    "description": "分页限制",
+                "quantity": {
                     "type": "integer"
+                }
+            }
+        },
+        "types.OrderQuote": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "description": "订单货币的格式化金额",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.OrderAmounts"
+                        }
+                    ]
                 },
-                "offset": {
-                    "description": "分页偏移",
+                "available": {
+                    "description": "所有商品库存是否足够",
+                    "type": "boolean"
+                },
+                "base_currency": {
+                    "description": "金额字段使用的基础货币",
+                    "type": "string"
+                },
+                "coupon_code": {
+                    "description": "使用的优惠码",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "订单货币",
+                    "type": "string"
+                },
+                "discount_amount": {
+                    "description": "优惠减免金额，包括商品和运费",
                     "type": "integer"
                 },
-                "order_no": {
-                    "description": "订单号筛选",
+                "discounts": {
+                    "description": "优惠明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderDiscountDetail"
+                    }
+                },
+                "exchange_rate": {
+                    "description": "当前汇率，下单时保存为快照",
+                    "type": "integer"
+                },
+                "item_total_amount": {
+                    "description": "商品金额",
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderQuoteItem"
+                    }
+                },
+                "shipping": {
+                    "description": "运费明细",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ShippingQuote"
+                        }
+                    ]
+                },
+                "shipping_fee": {
+                    "description": "运费",
+                    "type": "integer"
+                },
+                "tax": {
+                    "description": "税费",
+                    "type": "integer"
+                },
+                "tax_inclusive": {
+                    "description": "商品价格是否已含税",
+                    "type": "boolean"
+                },
+                "tax_rate": {
+                    "description": "标准税率，万分之一",
+                    "type": "integer"
+                },
+                "tax_region": {
+                    "description": "匹配的税率规则",
                     "type": "string"
                 },
-                "order_status": {
-                    "description": "订单状态筛选",
+                "total_amount": {
+                    "description": "应付总金额，可作为 expected_total_amount 提交",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.OrderQuoteItem": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "库存是否足够",
+                    "type": "boolean"
+                },
+                "discount_amount": {
+                    "description": "分摊到该商品的优惠金额",
+                    "type": "integer"
+                },
+                "expected_price": {
+                    "description": "客户端提交的单价",
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "当前单价",
+                    "type": "integer"
+                },
+                "price_changed": {
+                    "description": "客户端提交的单价与当前单价不一致",
+                    "type": "boolean"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "商品数量",
+                    "type": "integer"
+                },
+                "stock": {
+                    "description": "当前库存",
+                    "type": "integer"
+                },
+                "tax_amount": {
+                    "description": "分摊到该商品的税额",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "适用税率，万分之一",
+                    "type": "integer"
+                },
+                "total_price": {
+                    "description": "商品总价",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.OrderStatusLogDetail": {
+            "type": "object",
+            "properties": {
+                "create_time": {
+                    "description": "日志写入时间",
+                    "type": "string"
+                },
+                "current_status": {
+                    "description": "当前状态",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "日志ID",
+                    "type": "integer"
+                },
+                "occurred_at": {
+                    "description": "变更发生时间",
+                    "type": "string"
+                },
+                "remark": {
+                    "description": "备注",
+                    "type": "string"
+                },
+                "status_name": {
+                    "description": "状态名称",
+                    "type": "string"
+                }
+            }
+        },
+        "types.OrderTenderDetail": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "抵扣金额",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "卡号，只显示最后 4 位",
+                    "type": "string"
+                },
+                "refunded_amount": {
+                    "description": "已退回原卡的金额",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "状态",
+                    "type": "integer"
+                },
+                "status_name": {
+                    "description": "状态名称",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "储值卡类型",
+                    "type": "integer"
+                },
+                "type_name": {
+                    "description": "储值卡类型名称",
+                    "type": "string"
+                }
+            }
+        },
+        "types.PriceChangedInfo": {
+            "type": "object",
+            "properties": {
+                "expected_total_amount": {
+                    "description": "客户端预期总金额",
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.PriceChangedItem"
+                    }
+                },
+                "order_item_list": {
+                    "description": "按当前价格生成的商品列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderItemInfo"
+                    }
+                },
+                "total_amount": {
+                    "description": "按当前价格计算的总金额",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.PriceChangedItem": {
+            "type": "object",
+            "properties": {
+                "current_price": {
+                    "description": "当前单价",
                     "type": "integer"
                 },
-                "start_time": {
-                    "description": "创建时间开始范围",
-                    "type": "string"
+                "expected_price": {
+                    "description": "客户端提交的单价",
+                    "type": "integer"
                 },
-                "user_id": {
-                    "description": "用户ID筛选",
+                "product_id": {
                     "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
                 }
             }
         },
-        "types.ListOrderResponse": {
+        "types.ReceiveReturnRequest": {
             "type": "object",
             "properties": {
-                "orders": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/types.OrderInfoInList"
-                    }
-                },
-                "total": {
-                    "type": "integer"
+                "restock": {
+                    "description": "是否将退回的商品加回库存",
+                    "type": "boolean"
                 }
             }
         },
-        "types.OrderDetail": {
+        "types.RefundDetail": {
             "type": "object",
             "properties": {
-                "confirm_time": {
-                    "description": "收货确认时间",
-                    "type": "string"
+                "amount": {
+                    "description": "退款金额",
+                    "type": "integer"
                 },
                 "create_time": {
-                    "description": "创建时间",
-                    "type": "string"
-                },
-                "delivery_time": {
-                    "description": "发货时间",
-                    "type": "string"
-                },
-                "logistics_no": {
-                    "description": "物流单号",
+                    "description": "申请时间",
                     "type": "string"
                 },
-                "order_items": {
-                    "description": "订单商品列表",
+                "items": {
+                    "description": "退款商品",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.OrderItemDetail"
+                        "$ref": "#/definitions/types.RefundItemDetail"
                     }
                 },
-                "order_no": {
-                    "description": "基本订单信息",
-                    "type": "string"
-                },
-                "pay_amount": {
-                    "description": "实际支付金额",
-                    "type": "integer"
-                },
-                "pay_time": {
-                    "description": "支付时间",
+                "reason": {
+                    "description": "退款原因",
                     "type": "string"
                 },
-                "receiver_address": {
-                    "description": "收货地址",
+                "refund_no": {
+                    "description": "退款单号",
                     "type": "string"
                 },
-                "receiver_country": {
-                    "description": "收货人国家",
+                "reject_reason": {
+                    "description": "拒绝原因",
                     "type": "string"
                 },
-                "receiver_first_name": {
-                    "description": "收货信息",
+                "review_time": {
+                    "description": "审核时间",
                     "type": "string"
                 },
-                "receiver_last_name": {
-                    "description": "收货人姓名",
-                    "type": "string"
+                "status": {
+                    "description": "退款状态",
+                    "type": "integer"
                 },
-                "receiver_phone": {
-                    "description": "收货人电话",
+                "status_name": {
+                    "description": "退款状态名称",
                     "type": "string"
                 },
-                "receiver_zip_code": {
-                    "description": "收货人邮政编码",
+                "to_store_credit": {
+                    "description": "是否退为店铺余额",
+                    "type": "boolean"
+                }
+            }
+        },
+        "types.RefundItemDetail": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "退款金额",
                     "type": "integer"
                 },
-                "remark": {
-                    "description": "其他信息",
-                    "type": "string"
+                "order_product_id": {
+                    "description": "订单商品ID",
+                    "type": "integer"
                 },
-                "shipping_fee": {
-                    "description": "运费",
+                "product_id": {
+                    "description": "商品ID",
                     "type": "integer"
                 },
-                "status": {
-                    "description": "订单状态",
+                "quantity": {
+                    "description": "退款数量",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.RefundItemRequest": {
+            "type": "object",
+            "properties": {
+                "order_product_id": {
+                    "description": "订单商品ID，即订单详情中 order_items 的 id",
                     "type": "integer"
                 },
-                "status_logs": {
-                    "description": "订单状态变更日志",
+                "quantity": {
+                    "description": "退款数量",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.RefundRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "退款商品",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.OrderStatusLogDetail"
+                        "$ref": "#/definitions/types.RefundItemRequest"
                     }
                 },
-                "status_name": {
-                    "description": "订单状态名称",
+                "reason": {
+                    "description": "退款原因",
                     "type": "string"
                 },
-                "tax": {
-                    "description": "税费",
+                "to_store_credit": {
+                    "description": "是否退为店铺余额，否则退回原支付方式",
+                    "type": "boolean"
+                }
+            }
+        },
+        "types.RejectRefundRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "拒绝原因",
+                    "type": "string"
+                }
+            }
+        },
+        "types.RejectReturnRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "拒绝原因",
+                    "type": "string"
+                }
+            }
+        },
+        "types.ReplayDeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "replayed": {
+                    "description": "重新投递到原 topic 的消息数",
                     "type": "integer"
                 },
-                "total_amount": {
-                    "description": "总金额",
+                "skipped": {
+                    "description": "无法解析而丢弃的死信数",
                     "type": "integer"
                 },
-                "update_time": {
-                    "description": "更新时间",
+                "topic": {
+                    "description": "原 topic",
                     "type": "string"
-                },
-                "user_id": {
-                    "description": "下单用户",
-                    "type": "integer"
                 }
             }
         },
-        "types.OrderInfo": {
+        "types.ReturnDetail": {
             "type": "object",
             "properties": {
-                "order_item_list": {
-                    "description": "订单商品列表",
+                "create_time": {
+                    "description": "申请时间",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "退货商品",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.OrderItemInfo"
+                        "$ref": "#/definitions/types.ReturnItemDetail"
                     }
                 },
-                "receiver_address": {
-                    "description": "收货地址",
-                    "type": "string"
-                },
-                "receiver_country": {
-                    "description": "收货人国家",
-                    "type": "string"
-                },
-                "receiver_first_name": {
-                    "description": "收货人姓名",
-                    "type": "string"
+                "photo_urls": {
+                    "description": "商品照片",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "receiver_last_name": {
-                    "description": "收货人姓名",
+                "reason": {
+                    "description": "退货原因",
                     "type": "string"
                 },
-                "receiver_phone": {
-                    "description": "收货人电话",
+                "receive_time": {
+                    "description": "收货时间",
                     "type": "string"
                 },
-                "receiver_zip_code": {
-                    "description": "收货人邮政编码",
+                "refund_amount": {
+                    "description": "收货后的退款金额",
                     "type": "integer"
                 },
-                "remark": {
-                    "description": "备注",
-                    "type": "string"
-                }
-            }
-        },
-        "types.OrderInfoInList": {
-            "type": "object",
-            "properties": {
-                "create_time": {
+                "refund_no": {
+                    "description": "退款单号",
                     "type": "string"
                 },
-                "order_no": {
+                "reject_reason": {
+                    "description": "拒绝原因",
                     "type": "string"
                 },
-                "receiver_first_name": {
-                    "description": "收货人姓名",
+                "restock": {
+                    "description": "是否回补库存",
+                    "type": "boolean"
+                },
+                "return_label": {
+                    "description": "退货面单",
                     "type": "string"
                 },
-                "receiver_last_name": {
-                    "description": "收货人姓名",
+                "return_no": {
+                    "description": "退货单号",
                     "type": "string"
                 },
-                "receiver_phone": {
-                    "description": "收货人电话",
+                "return_tracking_no": {
+                    "description": "退货物流单号",
                     "type": "string"
                 },
-                "status": {
+                "review_time": {
+                    "description": "审核时间",
                     "type": "string"
                 },
-                "total_amount": {
+                "status": {
+                    "description": "退货状态",
                     "type": "integer"
+                },
+                "status_name": {
+                    "description": "退货状态名称",
+                    "type": "string"
                 }
             }
         },
-        "types.OrderItemDetail": {
+        "types.ReturnItemDetail": {
             "type": "object",
             "properties": {
-                "create_time": {
-                    "description": "创建时间",
-                    "type": "string"
-                },
-                "id": {
+                "order_product_id": {
                     "description": "订单商品ID",
                     "type": "integer"
                 },
-                "price": {
-                    "description": "商品单价",
-                    "type": "integer"
-                },
                 "product_id": {
                     "description": "商品ID",
                     "type": "integer"
                 },
-                "product_name": {
-                    "description": "商品名称",
-                    "type": "string"
-                },
                 "quantity": {
-                    "description": "商品数量",
-                    "type": "integer"
-                },
-                "total_price": {
-                    "description": "商品总价",
+                    "description": "退货数量",
                     "type": "integer"
-                },
-                "update_time": {
-                    "description": "更新时间",
+                }
+            }
+        },
+        "types.ShipOrderRequest": {
+            "type": "object",
+            "properties": {
+                "tracking_no": {
                     "type": "string"
                 }
             }
         },
-        "types.OrderItemInfo": {
+        "types.ShippingQuote": {
             "type": "object",
             "properties": {
-                "price": {
+                "base_fee": {
+                    "description": "首重运费",
                     "type": "integer"
                 },
-                "product_id": {
+                "chargeable_weight": {
+                    "description": "计费重量，取实际重量和体积重中较大者",
                     "type": "integer"
                 },
-                "product_name": {
-                    "type": "string"
+                "fragile_surcharge": {
+                    "description": "易碎商品附加费",
+                    "type": "integer"
                 },
-                "quantity": {
+                "free_shipping": {
+                    "description": "是否达到免运费门槛，免首重及续重运费",
+                    "type": "boolean"
+                },
+                "shipping_fee": {
+                    "description": "运费合计",
+                    "type": "integer"
+                },
+                "weight": {
+                    "description": "实际重量",
                     "type": "integer"
+                },
+                "weight_fee": {
+                    "description": "续重运费",
+                    "type": "integer"
+                },
+                "zone": {
+                    "description": "运费区域",
+                    "type": "string"
                 }
             }
         },
-        "types.OrderStatusLogDetail": {
+        "types.ShippingQuoteRequest": {
             "type": "object",
             "properties": {
-                "create_time": {
-                    "description": "变更时间",
+                "order_item_list": {
+                    "description": "商品列表，只使用商品ID和数量",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.OrderItemInfo"
+                    }
+                },
+                "receiver_country": {
+                    "description": "收货人国家",
                     "type": "string"
                 },
-                "current_status": {
-                    "description": "当前状态",
+                "receiver_zip_code": {
+                    "description": "收货人邮政编码",
                     "type": "integer"
-                },
-                "id": {
-                    "description": "日志ID",
+                }
+            }
+        },
+        "types.UpdateCouponStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "description": "1-可用； 2-已停用",
                     "type": "integer"
-                },
-                "remark": {
-                    "description": "备注",
-                    "type": "string"
-                },
-                "status_name": {
-                    "description": "状态名称",
-                    "type": "string"
                 }
             }
         },
-        "types.ShipOrderRequest": {
+        "types.UpdateGiftCardStatusRequest": {
             "type": "object",
+            "required": [
+                "status"
+            ],
             "properties": {
-                "tracking_no": {
-                    "type": "string"
+                "status": {
+                    "description": "1-可用； 2-已停用",
+                    "type": "integer"
                 }
             }
         }
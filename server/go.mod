@@ -94,4 +94,7 @@ require (
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
 
-replace gopkg.in/yaml.v3 => gopkg.in/yaml.v3 v3.0.1
\ No newline at end of file
+replace gopkg.in/yaml.v3 => gopkg.in/yaml.v3 v3.0.1
+
+// common 与服务在同一仓库中开发，直接引用本地代码
+replace github.com/sw5005-sus/ceramicraft-order-mservice/common => ../common
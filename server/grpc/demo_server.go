@@ -1,17 +0,0 @@
-package grpc
-
-import (
-	"context"
-
-	"github.com/sw5005-sus/ceramicraft-order-mservice/common/demopb"
-	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
-)
-
-type DemoService struct {
-	demopb.UnimplementedDemoServiceServer
-}
-
-func (s *DemoService) SayHello(ctx context.Context, in *demopb.HelloRequest) (*demopb.HelloResponse, error) {
-	log.Logger.Infof("Received: %v", in.GetName())
-	return &demopb.HelloResponse{Message: "Hello " + in.GetName()}, nil
-}
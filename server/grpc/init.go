@@ -6,9 +6,10 @@ import (
 	"os"
 	"time"
 
-	"github.com/sw5005-sus/ceramicraft-order-mservice/common/demopb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/orderpb"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
 	"google.golang.org/grpc"
 )
 
@@ -28,7 +29,7 @@ func Init(exitSig chan os.Signal) {
 		grpc.MaxSendMsgSize(1024 * 1024), // Set maximum send message size (1MB here)
 	}
 	grpcServer := grpc.NewServer(opts...)
-	demopb.RegisterDemoServiceServer(grpcServer, &DemoService{})
+	orderpb.RegisterOrderServiceServer(grpcServer, NewOrderServer(service.GetOrderServiceInstance()))
 
 	log.Logger.Infof("Server is running on %s", ipPort)
 	if err := grpcServer.Serve(listener); err != nil {
@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/orderpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
+	"gorm.io/gorm"
+)
+
+const (
+	DEFAULT_LIST_LIMIT = 20
+	MAX_LIST_LIMIT     = 100
+	MAX_BATCH_GET_SIZE = 100
+)
+
+// OrderServer 供其他服务调用的订单 rpc，业务逻辑由 service.OrderService 实现
+type OrderServer struct {
+	orderpb.UnimplementedOrderServiceServer
+	orderService service.OrderService
+}
+
+func NewOrderServer(orderService service.OrderService) *OrderServer {
+	return &OrderServer{orderService: orderService}
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, in *orderpb.GetOrderRequest) (*orderpb.GetOrderResponse, error) {
+	if in.GetOrderNo() == "" {
+		return &orderpb.GetOrderResponse{Code: int32(orderpb.RespCode_BAD_REQUEST), ErrorMsg: "orderNo is required"}, nil
+	}
+	detail, err := s.orderService.GetOrderDetail(ctx, in.GetOrderNo())
+	if err != nil {
+		log.Logger.Errorf("GetOrder: orderNo: %s, err: %s", in.GetOrderNo(), err.Error())
+		return &orderpb.GetOrderResponse{Code: respCode(err), ErrorMsg: err.Error()}, nil
+	}
+	return &orderpb.GetOrderResponse{Code: int32(orderpb.RespCode_SUCCESS), Order: toPbOrder(detail)}, nil
+}
+
+func (s *OrderServer) ListOrders(ctx context.Context, in *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	limit := int(in.GetLimit())
+	if limit <= 0 {
+		limit = DEFAULT_LIST_LIMIT
+	}
+	if limit > MAX_LIST_LIMIT {
+		limit = MAX_LIST_LIMIT
+	}
+	resp, err := s.orderService.ListOrders(ctx, types.ListOrderRequest{
+		UserID:      int(in.GetUserId()),
+		OrderStatus: int(in.GetOrderStatus()),
+		StartTime:   fromUnix(in.GetStartTime()),
+		EndTime:     fromUnix(in.GetEndTime()),
+		OrderNo:     in.GetOrderNo(),
+		Limit:       limit,
+		Offset:      int(in.GetOffset()),
+	})
+	if err != nil {
+		log.Logger.Errorf("ListOrders: err: %s", err.Error())
+		return &orderpb.ListOrdersResponse{Code: respCode(err), ErrorMsg: err.Error()}, nil
+	}
+
+	orders := make([]*orderpb.OrderSummary, 0, len(resp.Orders))
+	for _, order := range resp.Orders {
+		orders = append(orders, &orderpb.OrderSummary{
+			OrderNo:           order.OrderNo,
+			ReceiverFirstName: order.ReceiverFirstName,
+			ReceiverLastName:  order.ReceiverLastName,
+			ReceiverPhone:     order.ReceiverPhone,
+			CreatedTime:       toUnix(order.CreateTime),
+			TotalAmount:       int32(order.TotalAmount),
+			StatusName:        order.Status,
+		})
+	}
+	return &orderpb.ListOrdersResponse{Code: int32(orderpb.RespCode_SUCCESS), Orders: orders, Total: int32(resp.Total)}, nil
+}
+
+func (s *OrderServer) BatchGetOrders(ctx context.Context, in *orderpb.BatchGetOrdersRequest) (*orderpb.BatchGetOrdersResponse, error) {
+	if len(in.GetOrderNos()) > MAX_BATCH_GET_SIZE {
+		return &orderpb.BatchGetOrdersResponse{
+			Code:     int32(orderpb.RespCode_BAD_REQUEST),
+			ErrorMsg: fmt.Sprintf("at most %d orderNos per request", MAX_BATCH_GET_SIZE),
+		}, nil
+	}
+	details, err := s.orderService.BatchGetOrders(ctx, in.GetOrderNos())
+	if err != nil {
+		log.Logger.Errorf("BatchGetOrders: err: %s", err.Error())
+		return &orderpb.BatchGetOrdersResponse{Code: respCode(err), ErrorMsg: err.Error()}, nil
+	}
+
+	found := make(map[string]bool, len(details))
+	orders := make([]*orderpb.Order, 0, len(details))
+	for _, detail := range details {
+		found[detail.OrderNo] = true
+		orders = append(orders, toPbOrder(detail))
+	}
+	var notFound []string
+	for _, orderNo := range in.GetOrderNos() {
+		if !found[orderNo] {
+			notFound = append(notFound, orderNo)
+		}
+	}
+	return &orderpb.BatchGetOrdersResponse{Code: int32(orderpb.RespCode_SUCCESS), Orders: orders, NotFoundOrderNos: notFound}, nil
+}
+
+func (s *OrderServer) UpdateOrderStatus(ctx context.Context, in *orderpb.UpdateOrderStatusRequest) (*orderpb.UpdateOrderStatusResponse, error) {
+	if in.GetOrderNo() == "" {
+		return &orderpb.UpdateOrderStatusResponse{Code: int32(orderpb.RespCode_BAD_REQUEST), ErrorMsg: "orderNo is required"}, nil
+	}
+	actor := consts.Actor(in.GetActor())
+	if actor != consts.ActorCustomer && actor != consts.ActorMerchant && actor != consts.ActorSystem {
+		return &orderpb.UpdateOrderStatusResponse{Code: int32(orderpb.RespCode_BAD_REQUEST), ErrorMsg: "invalid actor"}, nil
+	}
+	if actor == consts.ActorCustomer && in.GetUserId() <= 0 {
+		return &orderpb.UpdateOrderStatusResponse{Code: int32(orderpb.RespCode_BAD_REQUEST), ErrorMsg: "userId is required for customer"}, nil
+	}
+	// 退款、退货状态只能由退款、退货流程变更，不对其他服务开放
+	switch int(in.GetNewStatus()) {
+	case consts.REFUNDING, consts.REFUNDED, consts.PARTIALLY_REFUNDED, consts.RETURN_REQUESTED, consts.RETURNING:
+		return &orderpb.UpdateOrderStatusResponse{Code: int32(orderpb.RespCode_INVALID_TRANSITION), ErrorMsg: consts.ErrInternalTransition.Error()}, nil
+	}
+
+	err := s.orderService.UpdateOrderStatus(ctx, in.GetOrderNo(), int(in.GetNewStatus()), consts.TransitionInput{
+		Actor:      actor,
+		UserID:     int(in.GetUserId()),
+		TrackingNo: in.GetTrackingNo(),
+		Reason:     in.GetReason(),
+		// 置为已付款时记录支付信息
+		PayAmount:        int(in.GetPayAmount()),
+		PayTransactionID: in.GetPayTransactionId(),
+		PayMethod:        in.GetPayMethod(),
+	})
+	if err != nil {
+		log.Logger.Errorf("UpdateOrderStatus: orderNo: %s, err: %s", in.GetOrderNo(), err.Error())
+		return &orderpb.UpdateOrderStatusResponse{Code: respCode(err), ErrorMsg: err.Error()}, nil
+	}
+	return &orderpb.UpdateOrderStatusResponse{Code: int32(orderpb.RespCode_SUCCESS)}, nil
+}
+
+func (s *OrderServer) GetOrderStats(ctx context.Context, in *orderpb.GetOrderStatsRequest) (*orderpb.GetOrderStatsResponse, error) {
+	stats, err := s.orderService.GetOrderStats(ctx)
+	if err != nil {
+		log.Logger.Errorf("GetOrderStats: err: %s", err.Error())
+		return &orderpb.GetOrderStatsResponse{Code: respCode(err), ErrorMsg: err.Error()}, nil
+	}
+	resp := &orderpb.GetOrderStatsResponse{
+		Code:             int32(orderpb.RespCode_SUCCESS),
+		TotalOrders:      int32(stats.TotalOrders),
+		TotalSales:       int32(stats.TotalSales),
+		TotalCustomers:   int32(stats.TotalCustomers),
+		AvgSalesPerOrder: int32(stats.AvgSalesPerOrder),
+		BaseCurrency:     stats.BaseCurrency,
+	}
+	for _, sales := range stats.SalesByCurrency {
+		resp.SalesByCurrency = append(resp.SalesByCurrency, &orderpb.CurrencySales{
+			Currency:    sales.Currency,
+			TotalOrders: int32(sales.TotalOrders),
+			TotalSales:  int32(sales.TotalSales),
+			LocalSales:  int32(sales.LocalSales),
+		})
+	}
+	return resp, nil
+}
+
+// respCode 将业务错误转换为响应码
+func respCode(err error) int32 {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return int32(orderpb.RespCode_NOT_FOUND)
+	case errors.Is(err, consts.ErrInvalidTransition), errors.Is(err, consts.ErrActorNotAllowed), errors.Is(err, consts.ErrInternalTransition):
+		return int32(orderpb.RespCode_INVALID_TRANSITION)
+	default:
+		return int32(orderpb.RespCode_UNKNOWN_ERROR)
+	}
+}
+
+func toPbOrder(detail *types.OrderDetail) *orderpb.Order {
+	items := make([]*orderpb.OrderItem, 0, len(detail.OrderItems))
+	for _, item := range detail.OrderItems {
+		items = append(items, &orderpb.OrderItem{
+			Id:             int32(item.ID),
+			ProductId:      int32(item.ProductID),
+			ProductName:    item.ProductName,
+			Price:          int32(item.Price),
+			Quantity:       int32(item.Quantity),
+			TotalPrice:     int32(item.TotalPrice),
+			DiscountAmount: int32(item.DiscountAmount),
+			TaxRate:        int32(item.TaxRate),
+			TaxAmount:      int32(item.TaxAmount),
+		})
+	}
+	return &orderpb.Order{
+		OrderNo:           detail.OrderNo,
+		UserId:            int32(detail.UserID),
+		Status:            int32(detail.Status),
+		StatusName:        detail.StatusName,
+		TotalAmount:       int32(detail.TotalAmount),
+		PayAmount:         int32(detail.PayAmount),
+		ShippingFee:       int32(detail.ShippingFee),
+		Tax:               int32(detail.Tax),
+		RefundedAmount:    int32(detail.RefundedAmount),
+		ReceiverFirstName: detail.ReceiverFirstName,
+		ReceiverLastName:  detail.ReceiverLastName,
+		ReceiverPhone:     detail.ReceiverPhone,
+		ReceiverAddress:   detail.ReceiverAddress,
+		ReceiverCountry:   detail.ReceiverCountry,
+		ReceiverZipCode:   int32(detail.ReceiverZipCode),
+		Remark:            detail.Remark,
+		LogisticsNo:       detail.LogisticsNo,
+		CancelReason:      detail.CancelReason,
+		PayTransactionId:  detail.PayTransactionID,
+		PayMethod:         detail.PayMethod,
+		DiscountAmount:    int32(detail.DiscountAmount),
+		CouponCode:        detail.CouponCode,
+		TaxRegion:         detail.TaxRegion,
+		TaxRate:           int32(detail.TaxRate),
+		TaxInclusive:      detail.TaxInclusive,
+		Currency:          detail.Currency,
+		BaseCurrency:      detail.BaseCurrency,
+		ExchangeRate:      detail.ExchangeRate,
+		CreatedTime:       toUnix(detail.CreateTime),
+		PayTime:           toUnix(detail.PayTime),
+		DeliveryTime:      toUnix(detail.DeliveryTime),
+		ConfirmTime:       toUnix(detail.ConfirmTime),
+		CancelTime:        toUnix(detail.CancelTime),
+		Items:             items,
+	}
+}
+
+// toUnix 零值时间转换为 0
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func fromUnix(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
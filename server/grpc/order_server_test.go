@@ -0,0 +1,216 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/orderpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service/mocks"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func init() {
+	// 初始化测试用logger
+	logger, _ := zap.NewDevelopment()
+	log.Logger = logger.Sugar()
+}
+
+func TestOrderServer_GetOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	server := NewOrderServer(orderService)
+	payTime := time.Unix(1700000000, 0)
+
+	t.Run("success", func(t *testing.T) {
+		orderService.EXPECT().GetOrderDetail(gomock.Any(), "ORDER001").Return(&types.OrderDetail{
+			OrderNo:     "ORDER001",
+			UserID:      123,
+			Status:      consts.PAYED,
+			TotalAmount: 3525,
+			PayTime:     payTime,
+			OrderItems:  []*types.OrderItemDetail{{ID: 11, ProductID: 1, Price: 1000, Quantity: 2, TotalPrice: 2000}},
+		}, nil)
+
+		resp, err := server.GetOrder(context.Background(), &orderpb.GetOrderRequest{OrderNo: "ORDER001"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Code != int32(orderpb.RespCode_SUCCESS) {
+			t.Fatalf("expected SUCCESS, got %d", resp.Code)
+		}
+		if resp.Order.OrderNo != "ORDER001" || resp.Order.TotalAmount != 3525 || len(resp.Order.Items) != 1 {
+			t.Errorf("unexpected order: %+v", resp.Order)
+		}
+		if resp.Order.PayTime != payTime.Unix() || resp.Order.DeliveryTime != 0 {
+			t.Errorf("unexpected times: pay %d, delivery %d", resp.Order.PayTime, resp.Order.DeliveryTime)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		orderService.EXPECT().GetOrderDetail(gomock.Any(), "MISSING").Return(nil, gorm.ErrRecordNotFound)
+
+		resp, _ := server.GetOrder(context.Background(), &orderpb.GetOrderRequest{OrderNo: "MISSING"})
+		if resp.Code != int32(orderpb.RespCode_NOT_FOUND) {
+			t.Errorf("expected NOT_FOUND, got %d", resp.Code)
+		}
+	})
+
+	t.Run("empty order no", func(t *testing.T) {
+		resp, _ := server.GetOrder(context.Background(), &orderpb.GetOrderRequest{})
+		if resp.Code != int32(orderpb.RespCode_BAD_REQUEST) {
+			t.Errorf("expected BAD_REQUEST, got %d", resp.Code)
+		}
+	})
+}
+
+func TestOrderServer_ListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	server := NewOrderServer(orderService)
+
+	orderService.EXPECT().ListOrders(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req types.ListOrderRequest) (*types.ListOrderResponse, error) {
+			if req.Limit != MAX_LIST_LIMIT || req.UserID != 123 || !req.StartTime.IsZero() {
+				t.Errorf("unexpected request: %+v", req)
+			}
+			return &types.ListOrderResponse{
+				Orders: []*types.OrderInfoInList{{OrderNo: "ORDER001", TotalAmount: 3525, Status: "PAYED"}},
+				Total:  1,
+			}, nil
+		})
+
+	resp, err := server.ListOrders(context.Background(), &orderpb.ListOrdersRequest{UserId: 123, Limit: 500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Code != int32(orderpb.RespCode_SUCCESS) || resp.Total != 1 || len(resp.Orders) != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestOrderServer_BatchGetOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	server := NewOrderServer(orderService)
+
+	t.Run("partially found", func(t *testing.T) {
+		orderService.EXPECT().BatchGetOrders(gomock.Any(), []string{"ORDER001", "ORDER002"}).
+			Return([]*types.OrderDetail{{OrderNo: "ORDER002"}}, nil)
+
+		resp, _ := server.BatchGetOrders(context.Background(), &orderpb.BatchGetOrdersRequest{OrderNos: []string{"ORDER001", "ORDER002"}})
+		if resp.Code != int32(orderpb.RespCode_SUCCESS) || len(resp.Orders) != 1 {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+		if len(resp.NotFoundOrderNos) != 1 || resp.NotFoundOrderNos[0] != "ORDER001" {
+			t.Errorf("expected ORDER001 not found, got %v", resp.NotFoundOrderNos)
+		}
+	})
+
+	t.Run("too many order nos", func(t *testing.T) {
+		orderNos := make([]string, MAX_BATCH_GET_SIZE+1)
+		resp, _ := server.BatchGetOrders(context.Background(), &orderpb.BatchGetOrdersRequest{OrderNos: orderNos})
+		if resp.Code != int32(orderpb.RespCode_BAD_REQUEST) {
+			t.Errorf("expected BAD_REQUEST, got %d", resp.Code)
+		}
+	})
+}
+
+func TestOrderServer_UpdateOrderStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	server := NewOrderServer(orderService)
+
+	t.Run("success", func(t *testing.T) {
+		orderService.EXPECT().UpdateOrderStatus(gomock.Any(), "ORDER001", consts.SHIPPED, consts.TransitionInput{
+			Actor:      consts.ActorMerchant,
+			TrackingNo: "SF123",
+		}).Return(nil)
+
+		resp, _ := server.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+			OrderNo:    "ORDER001",
+			NewStatus:  int32(consts.SHIPPED),
+			Actor:      orderpb.Actor_MERCHANT,
+			TrackingNo: "SF123",
+		})
+		if resp.Code != int32(orderpb.RespCode_SUCCESS) {
+			t.Errorf("expected SUCCESS, got %d: %s", resp.Code, resp.ErrorMsg)
+		}
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		orderService.EXPECT().UpdateOrderStatus(gomock.Any(), "ORDER001", consts.DELIVERED, gomock.Any()).
+			Return(consts.ErrInvalidTransition)
+
+		resp, _ := server.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+			OrderNo:   "ORDER001",
+			NewStatus: int32(consts.DELIVERED),
+			Actor:     orderpb.Actor_SYSTEM,
+		})
+		if resp.Code != int32(orderpb.RespCode_INVALID_TRANSITION) {
+			t.Errorf("expected INVALID_TRANSITION, got %d", resp.Code)
+		}
+	})
+
+	t.Run("refund status rejected", func(t *testing.T) {
+		// REFUNDING --> REFUNDED 只能通过退款审核完成，不会调用 service
+		resp, _ := server.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+			OrderNo:   "ORDER001",
+			NewStatus: int32(consts.REFUNDED),
+			Actor:     orderpb.Actor_MERCHANT,
+		})
+		if resp.Code != int32(orderpb.RespCode_INVALID_TRANSITION) {
+			t.Errorf("expected INVALID_TRANSITION, got %d", resp.Code)
+		}
+	})
+
+	t.Run("internal transition", func(t *testing.T) {
+		orderService.EXPECT().UpdateOrderStatus(gomock.Any(), "ORDER001", consts.PAYED, gomock.Any()).
+			Return(consts.ErrInternalTransition)
+
+		resp, _ := server.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+			OrderNo:   "ORDER001",
+			NewStatus: int32(consts.PAYED),
+			Actor:     orderpb.Actor_MERCHANT,
+		})
+		if resp.Code != int32(orderpb.RespCode_INVALID_TRANSITION) {
+			t.Errorf("expected INVALID_TRANSITION, got %d", resp.Code)
+		}
+	})
+
+	t.Run("unspecified actor", func(t *testing.T) {
+		resp, _ := server.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{OrderNo: "ORDER001"})
+		if resp.Code != int32(orderpb.RespCode_BAD_REQUEST) {
+			t.Errorf("expected BAD_REQUEST, got %d", resp.Code)
+		}
+	})
+}
+
+func TestOrderServer_GetOrderStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderService := mocks.NewMockOrderService(ctrl)
+	server := NewOrderServer(orderService)
+
+	orderService.EXPECT().GetOrderStats(gomock.Any()).Return(types.OrderStats{}, errors.New("db error"))
+
+	resp, _ := server.GetOrderStats(context.Background(), &orderpb.GetOrderStatsRequest{})
+	if resp.Code != int32(orderpb.RespCode_UNKNOWN_ERROR) {
+		t.Errorf("expected UNKNOWN_ERROR, got %d", resp.Code)
+	}
+}
@@ -5,12 +5,20 @@ import (
 )
 
 const (
-	SUCCESS = 0
-	ERROR   = 500
+	SUCCESS       = 0
+	ERROR         = 500
+	PRICE_CHANGED = 40901
+
+	IDEMPOTENCY_KEY_REUSED      = 40902
+	IDEMPOTENCY_KEY_IN_PROGRESS = 40903
 )
 
 var MsgFlags = map[int]string{
-	SUCCESS: "ok",
+	SUCCESS:       "ok",
+	PRICE_CHANGED: "price changed",
+
+	IDEMPOTENCY_KEY_REUSED:      "idempotency key reused",
+	IDEMPOTENCY_KEY_IN_PROGRESS: "request in progress",
 }
 
 // GetMsg 获取状态码对应信息
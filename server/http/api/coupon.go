@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
+)
+
+// CreateCoupon godoc
+// @Summary 创建优惠券
+// @Description 创建比例折扣、固定减免、免运费或买X送Y 优惠券，可设置最低消费及总使用次数和每个用户的使用次数上限
+// @Tags Coupon
+// @Accept json
+// @Produce json
+// @Param request body types.CreateCouponRequest true "优惠券"
+// @Success 200 {object} Response{data=types.CouponInfo}
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/coupons [post]
+func CreateCoupon(ctx *gin.Context) {
+	var req types.CreateCouponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	info, err := service.GetCouponServiceInstance().CreateCoupon(ctx, req)
+	if errors.Is(err, service.ErrInvalidCoupon) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if errors.Is(err, service.ErrCouponCodeExists) {
+		ctx.JSON(http.StatusConflict, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, info))
+}
+
+// ListCoupons godoc
+// @Summary 查询优惠券列表
+// @Description 分页查询优惠券，按创建时间倒序
+// @Tags Coupon
+// @Accept json
+// @Produce json
+// @Param status query int false "状态 (1-可用； 2-已停用)"
+// @Param type query int false "类型 (1-比例折扣； 2-固定减免； 3-免运费； 4-买X送Y)"
+// @Param limit query int false "分页限制，默认 20，最大 100"
+// @Param offset query int false "分页偏移"
+// @Success 200 {object} Response{data=types.ListCouponResponse}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/coupons [get]
+func ListCoupons(ctx *gin.Context) {
+	var req types.ListCouponRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	// 设置默认分页参数
+	if req.Limit <= 0 {
+		req.Limit = 20 // 默认每页20条
+	}
+	if req.Limit > 100 {
+		req.Limit = 100 // 最大每页100条
+	}
+
+	resp, err := service.GetCouponServiceInstance().ListCoupons(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, resp))
+}
+
+// UpdateCouponStatus godoc
+// @Summary 启用或停用优惠券
+// @Description 停用后优惠码不能再用于下单，已下单的订单不受影响
+// @Tags Coupon
+// @Accept json
+// @Produce json
+// @Param code path string true "优惠码"
+// @Param request body types.UpdateCouponStatusRequest true "状态"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/coupons/{code}/status [patch]
+func UpdateCouponStatus(ctx *gin.Context) {
+	code := ctx.Param("code")
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("优惠码不能为空")))
+		return
+	}
+	var req types.UpdateCouponStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	err := service.GetCouponServiceInstance().UpdateCouponStatus(ctx, code, req.Status)
+	if errors.Is(err, service.ErrInvalidCoupon) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "更新优惠券状态成功"))
+}
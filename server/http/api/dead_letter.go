@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
+)
+
+// ReplayDeadLetters godoc
+// @Summary 重放死信队列
+// @Description 将 <topic>.dlq 中的消息重新投递到原 topic，由原消费者重新处理；读不到新的死信或达到 limit 时结束，死信队列为空时需要等待加入消费组的超时
+// @Tags DeadLetter
+// @Accept json
+// @Produce json
+// @Param topic path string true "原 topic，目前支持 order_status_changed 和 payment_result"
+// @Param limit query int false "最多重放的条数，默认 100，最大 1000"
+// @Success 200 {object} Response{data=types.ReplayDeadLetterResponse}
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response{data=types.ReplayDeadLetterResponse} "重放中途失败，data 为已重放的结果"
+// @Router /merchant/dead-letters/{topic}/replay [post]
+func ReplayDeadLetters(ctx *gin.Context) {
+	var req types.ReplayDeadLetterRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = service.DEAD_LETTER_REPLAY_LIMIT
+	}
+	if req.Limit > service.DEAD_LETTER_REPLAY_MAX_LIMIT {
+		req.Limit = service.DEAD_LETTER_REPLAY_MAX_LIMIT
+	}
+
+	resp, err := service.GetDeadLetterReplayerInstance().Replay(ctx, ctx.Param("topic"), req.Limit)
+	if errors.Is(err, service.ErrUnknownDeadLetterTopic) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if errors.Is(err, service.ErrReplayInProgress) {
+		ctx.JSON(http.StatusConflict, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		errResp := RespError(ctx, err)
+		errResp.Data = resp
+		ctx.JSON(http.StatusInternalServerError, errResp)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, resp))
+}
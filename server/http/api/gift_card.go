@@ -0,0 +1,173 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
+)
+
+// CreateGiftCard godoc
+// @Summary 发行礼品卡
+// @Description 发行指定面额的礼品卡，卡号为空时随机生成，SC- 开头的卡号保留给店铺余额
+// @Tags GiftCard
+// @Accept json
+// @Produce json
+// @Param request body types.CreateGiftCardRequest true "礼品卡"
+// @Success 200 {object} Response{data=types.GiftCardInfo}
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/gift-cards [post]
+func CreateGiftCard(ctx *gin.Context) {
+	var req types.CreateGiftCardRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	info, err := service.GetGiftCardServiceInstance().CreateGiftCard(ctx, req)
+	if errors.Is(err, service.ErrInvalidGiftCard) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if errors.Is(err, service.ErrGiftCardCodeExists) {
+		ctx.JSON(http.StatusConflict, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, info))
+}
+
+// GetGiftCard godoc
+// @Summary 查询礼品卡
+// @Description 查询礼品卡或店铺余额（卡号 SC-用户ID）的余额及最近的流水
+// @Tags GiftCard
+// @Accept json
+// @Produce json
+// @Param code path string true "卡号"
+// @Success 200 {object} Response{data=types.GiftCardInfo}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/gift-cards/{code} [get]
+func GetGiftCard(ctx *gin.Context) {
+	code := ctx.Param("code")
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("卡号不能为空")))
+		return
+	}
+
+	info, err := service.GetGiftCardServiceInstance().GetGiftCard(ctx, code)
+	if errors.Is(err, service.ErrGiftCardNotFound) {
+		ctx.JSON(http.StatusNotFound, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, info))
+}
+
+// UpdateGiftCardStatus godoc
+// @Summary 启用或停用礼品卡
+// @Description 停用后不能再用于下单，已下单冻结的金额不受影响
+// @Tags GiftCard
+// @Accept json
+// @Produce json
+// @Param code path string true "卡号"
+// @Param request body types.UpdateGiftCardStatusRequest true "状态"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/gift-cards/{code}/status [patch]
+func UpdateGiftCardStatus(ctx *gin.Context) {
+	code := ctx.Param("code")
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("卡号不能为空")))
+		return
+	}
+	var req types.UpdateGiftCardStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	err := service.GetGiftCardServiceInstance().UpdateGiftCardStatus(ctx, code, req.Status)
+	if errors.Is(err, service.ErrInvalidGiftCard) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if errors.Is(err, service.ErrGiftCardNotFound) {
+		ctx.JSON(http.StatusNotFound, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "更新礼品卡状态成功"))
+}
+
+// CustomerGetGiftCard godoc
+// @Summary 用户侧查询礼品卡余额
+// @Description 下单前查询礼品卡的可用余额，不返回流水
+// @Tags GiftCard
+// @Accept json
+// @Produce json
+// @Param code path string true "卡号"
+// @Success 200 {object} Response{data=types.GiftCardInfo}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/gift-cards/{code} [get]
+func CustomerGetGiftCard(ctx *gin.Context) {
+	code := ctx.Param("code")
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("卡号不能为空")))
+		return
+	}
+
+	userID := ctx.Value("userID").(int)
+	info, err := service.GetGiftCardServiceInstance().CustomerGetGiftCard(ctx, code, userID)
+	if errors.Is(err, service.ErrGiftCardNotFound) {
+		ctx.JSON(http.StatusNotFound, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, info))
+}
+
+// GetStoreCredit godoc
+// @Summary 查询店铺余额
+// @Description 查询退款发放的店铺余额及最近的流水
+// @Tags GiftCard
+// @Accept json
+// @Produce json
+// @Success 200 {object} Response{data=types.GiftCardInfo}
+// @Failure 500 {object} Response
+// @Router /customer/store-credit [get]
+func GetStoreCredit(ctx *gin.Context) {
+	userID := ctx.Value("userID").(int)
+	info, err := service.GetGiftCardServiceInstance().GetStoreCredit(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, info))
+}
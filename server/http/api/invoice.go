@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
+)
+
+const INVOICE_FORMAT_JSON = "json"
+
+// GetInvoice godoc
+// @Summary 下载订单发票
+// @Description 订单付款后开具的发票，format 为 pdf（默认）、html 或 json
+// @Tags Invoice
+// @Produce application/pdf,text/html,json
+// @Param order_no path string true "订单号"
+// @Param format query string false "文件格式 (pdf, html, json)"
+// @Success 200 {object} Response{data=types.Invoice} "format 为 json 时返回发票内容，否则返回文件"
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/orders/{order_no}/invoice [get]
+func GetInvoice(ctx *gin.Context) {
+	getInvoice(ctx, "", 0)
+}
+
+// CustomerGetInvoice godoc
+// @Summary 用户侧下载订单发票
+// @Description 订单付款后开具的发票，format 为 pdf（默认）、html 或 json
+// @Tags Invoice
+// @Produce application/pdf,text/html,json
+// @Param order_no path string true "订单号"
+// @Param format query string false "文件格式 (pdf, html, json)"
+// @Success 200 {object} Response{data=types.Invoice} "format 为 json 时返回发票内容，否则返回文件"
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/{order_no}/invoice [get]
+func CustomerGetInvoice(ctx *gin.Context) {
+	getInvoice(ctx, "", ctx.Value("userID").(int))
+}
+
+// GetCreditNote godoc
+// @Summary 下载贷项通知单
+// @Description 退款时开具的贷项通知单，format 为 pdf（默认）、html 或 json
+// @Tags Invoice
+// @Produce application/pdf,text/html,json
+// @Param order_no path string true "订单号"
+// @Param credit_note_no path string true "贷项通知单号"
+// @Param format query string false "文件格式 (pdf, html, json)"
+// @Success 200 {object} Response{data=types.Invoice} "format 为 json 时返回贷项通知单内容，否则返回文件"
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/orders/{order_no}/credit-notes/{credit_note_no} [get]
+func GetCreditNote(ctx *gin.Context) {
+	getInvoice(ctx, ctx.Param("credit_note_no"), 0)
+}
+
+// CustomerGetCreditNote godoc
+// @Summary 用户侧下载贷项通知单
+// @Description 退款时开具的贷项通知单，format 为 pdf（默认）、html 或 json
+// @Tags Invoice
+// @Produce application/pdf,text/html,json
+// @Param order_no path string true "订单号"
+// @Param credit_note_no path string true "贷项通知单号"
+// @Param format query string false "文件格式 (pdf, html, json)"
+// @Success 200 {object} Response{data=types.Invoice} "format 为 json 时返回贷项通知单内容，否则返回文件"
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/{order_no}/credit-notes/{credit_note_no} [get]
+func CustomerGetCreditNote(ctx *gin.Context) {
+	getInvoice(ctx, ctx.Param("credit_note_no"), ctx.Value("userID").(int))
+}
+
+// getInvoice invoiceNo 为空时返回订单发票，userID 为 0 时不校验订单归属
+func getInvoice(ctx *gin.Context, invoiceNo string, userID int) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	var invoice *types.Invoice
+	var err error
+	if userID > 0 {
+		invoice, err = service.GetOrderServiceInstance().CustomerGetInvoice(ctx, orderNo, invoiceNo, userID)
+	} else {
+		invoice, err = service.GetOrderServiceInstance().GetInvoice(ctx, orderNo, invoiceNo)
+	}
+	if errors.Is(err, service.ErrInvoiceNotFound) {
+		ctx.JSON(http.StatusNotFound, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+	if invoiceNo != "" && invoice.Type != consts.INVOICE_TYPE_CREDIT_NOTE {
+		ctx.JSON(http.StatusNotFound, RespError(ctx, fmt.Errorf("%w: %s", service.ErrInvoiceNotFound, invoiceNo)))
+		return
+	}
+
+	format := strings.ToLower(ctx.DefaultQuery("format", service.INVOICE_FORMAT_PDF))
+	if format == INVOICE_FORMAT_JSON {
+		ctx.JSON(http.StatusOK, RespSuccess(ctx, invoice))
+		return
+	}
+	content, contentType, err := service.RenderInvoice(invoice, format)
+	if errors.Is(err, service.ErrUnsupportedInvoiceFormat) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+	// PDF 作为附件下载，HTML 直接在浏览器中打开
+	disposition := "inline"
+	if format == service.INVOICE_FORMAT_PDF {
+		disposition = "attachment"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, invoice.InvoiceNo+"."+format))
+	ctx.Data(http.StatusOK, contentType, content)
+}
+
+// ListCreditNotes godoc
+// @Summary 查询订单的贷项通知单
+// @Description 按开具时间返回订单退款产生的贷项通知单
+// @Tags Invoice
+// @Accept json
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Success 200 {object} Response{data=[]types.Invoice}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/orders/{order_no}/credit-notes [get]
+func ListCreditNotes(ctx *gin.Context) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	creditNotes, err := service.GetOrderServiceInstance().ListCreditNotes(ctx, orderNo)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, creditNotes))
+}
+
+// CustomerListCreditNotes godoc
+// @Summary 用户侧查询订单的贷项通知单
+// @Description 按开具时间返回订单退款产生的贷项通知单
+// @Tags Invoice
+// @Accept json
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Success 200 {object} Response{data=[]types.Invoice}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/{order_no}/credit-notes [get]
+func CustomerListCreditNotes(ctx *gin.Context) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	userID := ctx.Value("userID").(int)
+	creditNotes, err := service.GetOrderServiceInstance().CustomerListCreditNotes(ctx, orderNo, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, creditNotes))
+}
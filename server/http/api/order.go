@@ -10,14 +10,23 @@ import (
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
 )
 
+const (
+	IDEMPOTENCY_KEY_HEADER     = "Idempotency-Key"
+	IDEMPOTENT_REPLAYED_HEADER = "Idempotent-Replayed"
+	MAX_IDEMPOTENCY_KEY_LEN    = 128
+)
+
 // CreateOrder godoc
 // @Summary 创建订单
-// @Description 创建一个新订单
+// @Description 创建一个新订单，coupon_code 不为空时使用优惠券
 // @Tags Order
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，相同的键重放返回原订单号，用于不同的请求返回 409"
 // @Param order body types.OrderInfo true "订单信息"
 // @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response{data=types.PriceChangedInfo} "商品价格已变化，返回重新报价；或幂等键已被其他请求使用"
 // @Failure 500 {object} Response
 // @Router /customer/orders [post]
 func CreateOrder(ctx *gin.Context) {
@@ -26,8 +35,44 @@ func CreateOrder(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
 		return
 	}
+	idempotencyKey := ctx.GetHeader(IDEMPOTENCY_KEY_HEADER)
+	if len(idempotencyKey) > MAX_IDEMPOTENCY_KEY_LEN {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("Idempotency-Key 过长")))
+		return
+	}
+
 	userId := ctx.Value("userID").(int)
-	orderNo, err := service.GetOrderServiceInstance().CreateOrder(ctx, req, userId)
+	var orderNo string
+	var err error
+	if idempotencyKey == "" {
+		orderNo, err = service.GetOrderServiceInstance().CreateOrder(ctx, req, userId)
+	} else {
+		var replayed bool
+		orderNo, replayed, err = service.GetOrderServiceInstance().CreateOrderIdempotent(ctx, idempotencyKey, req, userId)
+		if replayed {
+			ctx.Header(IDEMPOTENT_REPLAYED_HEADER, "true")
+		}
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		ctx.JSON(http.StatusConflict, RespError(ctx, err, IDEMPOTENCY_KEY_REUSED))
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyInProgress) {
+		ctx.JSON(http.StatusConflict, RespError(ctx, err, IDEMPOTENCY_KEY_IN_PROGRESS))
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCoupon) || errors.Is(err, service.ErrInvalidOrderItems) ||
+		errors.Is(err, service.ErrUnsupportedCurrency) || errors.Is(err, service.ErrInvalidGiftCard) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	var priceErr *service.PriceChangedError
+	if errors.As(err, &priceErr) {
+		resp := RespError(ctx, err, PRICE_CHANGED)
+		resp.Data = priceErr.Info
+		ctx.JSON(http.StatusConflict, resp)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
 		return
@@ -36,6 +81,76 @@ func CreateOrder(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, RespSuccess(ctx, orderNo))
 }
 
+// QuoteOrder godoc
+// @Summary 下单前报价
+// @Description 按与创建订单相同的计算过程返回商品价格、库存、运费、优惠、税费和总金额，不创建订单也不扣款
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order body types.OrderInfo true "订单信息"
+// @Success 200 {object} Response{data=types.OrderQuote}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/quote [post]
+func QuoteOrder(ctx *gin.Context) {
+	var req types.OrderInfo
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if len(req.OrderItemList) == 0 {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("order_item_list 不能为空")))
+		return
+	}
+
+	userId := ctx.Value("userID").(int)
+	quote, err := service.GetOrderServiceInstance().QuoteOrder(ctx, req, userId)
+	if errors.Is(err, service.ErrInvalidCoupon) || errors.Is(err, service.ErrInvalidOrderItems) ||
+		errors.Is(err, service.ErrUnsupportedCurrency) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, quote))
+}
+
+// QuoteShipping godoc
+// @Summary 查询运费
+// @Description 购物车页面下单前按收货国家、商品重量和尺寸查询运费，不创建订单
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param request body types.ShippingQuoteRequest true "收货地址和商品列表"
+// @Success 200 {object} Response{data=types.ShippingQuote}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/shipping/quote [post]
+func QuoteShipping(ctx *gin.Context) {
+	var req types.ShippingQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if len(req.OrderItemList) == 0 {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("order_item_list 不能为空")))
+		return
+	}
+
+	quote, err := service.GetOrderServiceInstance().QuoteShipping(ctx, req)
+	if errors.Is(err, service.ErrInvalidOrderItems) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, quote))
+}
+
 // ListOrders godoc
 // @Summary 查询订单列表
 // @Description 根据条件查询订单列表，支持分页
@@ -200,7 +315,10 @@ func ShipOrder(ctx *gin.Context) {
 	}
 
 	// 调用 service 层更新订单状态为已发货
-	err := service.GetOrderServiceInstance().UpdateOrderStatus(ctx, orderNo, consts.SHIPPED, req.TrackingNo) // 3 表示 SHIPPED
+	err := service.GetOrderServiceInstance().UpdateOrderStatus(ctx, orderNo, consts.SHIPPED, consts.TransitionInput{
+		Actor:      consts.ActorMerchant,
+		TrackingNo: req.TrackingNo,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
 		return
@@ -228,7 +346,11 @@ func ConfirmOrder(ctx *gin.Context) {
 	}
 
 	// 调用 service 层更新订单状态为已收货
-	err := service.GetOrderServiceInstance().UpdateOrderStatus(ctx, orderNo, consts.DELIVERED, "")
+	userID := ctx.Value("userID").(int)
+	err := service.GetOrderServiceInstance().UpdateOrderStatus(ctx, orderNo, consts.DELIVERED, consts.TransitionInput{
+		Actor:  consts.ActorCustomer,
+		UserID: userID,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
 		return
@@ -237,6 +359,328 @@ func ConfirmOrder(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, RespSuccess(ctx, "确认收货成功"))
 }
 
+// CustomerCancelOrder godoc
+// @Summary 用户取消订单
+// @Description 用户取消未发货的订单，回补库存，已付款的订单会发起退款
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Param request body types.CancelOrderRequest true "取消原因"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/{order_no}/cancel [patch]
+func CustomerCancelOrder(ctx *gin.Context) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	var req types.CancelOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	userID := ctx.Value("userID").(int)
+	err := service.GetOrderServiceInstance().UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorCustomer,
+		UserID: userID,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "订单取消成功"))
+}
+
+// MerchantCancelOrder godoc
+// @Summary 商家取消订单
+// @Description 商家取消未发货的订单，回补库存，已付款的订单会发起退款
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Param request body types.CancelOrderRequest true "取消原因"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/orders/{order_no}/cancel [patch]
+func MerchantCancelOrder(ctx *gin.Context) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	var req types.CancelOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	err := service.GetOrderServiceInstance().UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorMerchant,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "订单取消成功"))
+}
+
+// RequestRefund godoc
+// @Summary 用户申请退款
+// @Description 用户对已付款的订单申请全部或部分商品退款，items 为空时退还剩余全部商品，订单进入退款中等待商家审核
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Param request body types.RefundRequest true "退款信息"
+// @Success 200 {object} Response{data=string} "退款单号"
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/{order_no}/refunds [post]
+func RequestRefund(ctx *gin.Context) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	var req types.RefundRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	userID := ctx.Value("userID").(int)
+	refundNo, err := service.GetOrderServiceInstance().RequestRefund(ctx, orderNo, userID, req)
+	if errors.Is(err, service.ErrInvalidRefundItems) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, refundNo))
+}
+
+// ApproveRefund godoc
+// @Summary 商家同意退款
+// @Description 商家同意退款申请，通知支付服务退款，订单变为已退款或部分退款
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param refund_no path string true "退款单号"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/refunds/{refund_no}/approve [patch]
+func ApproveRefund(ctx *gin.Context) {
+	refundNo := ctx.Param("refund_no")
+	if refundNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("退款单号不能为空")))
+		return
+	}
+
+	err := service.GetOrderServiceInstance().ApproveRefund(ctx, refundNo)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "同意退款成功"))
+}
+
+// RejectRefund godoc
+// @Summary 商家拒绝退款
+// @Description 商家拒绝退款申请，订单回到申请退款前的状态
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param refund_no path string true "退款单号"
+// @Param request body types.RejectRefundRequest true "拒绝原因"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/refunds/{refund_no}/reject [patch]
+func RejectRefund(ctx *gin.Context) {
+	refundNo := ctx.Param("refund_no")
+	if refundNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("退款单号不能为空")))
+		return
+	}
+
+	var req types.RejectRefundRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	err := service.GetOrderServiceInstance().RejectRefund(ctx, refundNo, req.Reason)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "拒绝退款成功"))
+}
+
+// RequestReturn godoc
+// @Summary 用户申请退货
+// @Description 用户对已收货的订单申请退货，需提供原因和商品照片，items 为空时退回剩余全部商品
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Param request body types.CreateReturnRequest true "退货信息"
+// @Success 200 {object} Response{data=string} "退货单号"
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /customer/orders/{order_no}/returns [post]
+func RequestReturn(ctx *gin.Context) {
+	orderNo := ctx.Param("order_no")
+	if orderNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("订单号不能为空")))
+		return
+	}
+
+	var req types.CreateReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	userID := ctx.Value("userID").(int)
+	returnNo, err := service.GetOrderServiceInstance().RequestReturn(ctx, orderNo, userID, req)
+	if errors.Is(err, service.ErrInvalidReturnRequest) || errors.Is(err, service.ErrInvalidRefundItems) {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, returnNo))
+}
+
+// ApproveReturn godoc
+// @Summary 商家同意退货
+// @Description 商家同意退货申请，提供退货面单和退货物流单号
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param return_no path string true "退货单号"
+// @Param request body types.ApproveReturnRequest true "退货物流信息"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/returns/{return_no}/approve [patch]
+func ApproveReturn(ctx *gin.Context) {
+	returnNo := ctx.Param("return_no")
+	if returnNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("退货单号不能为空")))
+		return
+	}
+
+	var req types.ApproveReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	if req.TrackingNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("退货物流单号不能为空")))
+		return
+	}
+
+	err := service.GetOrderServiceInstance().ApproveReturn(ctx, returnNo, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "同意退货成功"))
+}
+
+// RejectReturn godoc
+// @Summary 商家拒绝退货
+// @Description 商家拒绝退货申请，订单回到已收货
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param return_no path string true "退货单号"
+// @Param request body types.RejectReturnRequest true "拒绝原因"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/returns/{return_no}/reject [patch]
+func RejectReturn(ctx *gin.Context) {
+	returnNo := ctx.Param("return_no")
+	if returnNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("退货单号不能为空")))
+		return
+	}
+
+	var req types.RejectReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	err := service.GetOrderServiceInstance().RejectReturn(ctx, returnNo, req.Reason)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "拒绝退货成功"))
+}
+
+// ReceiveReturn godoc
+// @Summary 商家确认收到退货
+// @Description 商家收到退回的商品后退款，可选择将商品加回库存
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param return_no path string true "退货单号"
+// @Param request body types.ReceiveReturnRequest true "是否回补库存"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/returns/{return_no}/receive [patch]
+func ReceiveReturn(ctx *gin.Context) {
+	returnNo := ctx.Param("return_no")
+	if returnNo == "" {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, errors.New("退货单号不能为空")))
+		return
+	}
+
+	var req types.ReceiveReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	err := service.GetOrderServiceInstance().ReceiveReturn(ctx, returnNo, req.Restock)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, "确认收到退货成功"))
+}
+
 // GetOrderStats godoc
 // @Summary get Order Stats
 // @Description get Order Stats
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/service"
+)
+
+// ListDiscrepancies godoc
+// @Summary 查询对账差异
+// @Description 分页查询对账任务发现的订单与支付记录之间的差异，按发现时间倒序
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param type query int false "差异类型 (1-缺少支付单； 2-支付单无对应已付款订单； 3-金额不一致)"
+// @Param order_no query string false "订单号"
+// @Param start_time query string false "发现时间开始范围 (RFC3339)"
+// @Param end_time query string false "发现时间结束范围 (RFC3339)"
+// @Param limit query int false "分页限制，默认 20，最大 100"
+// @Param offset query int false "分页偏移"
+// @Success 200 {object} Response{data=types.ListDiscrepancyResponse}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /merchant/reconciliation [get]
+func ListDiscrepancies(ctx *gin.Context) {
+	var req types.ListDiscrepancyRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, RespError(ctx, err))
+		return
+	}
+
+	// 设置默认分页参数
+	if req.Limit <= 0 {
+		req.Limit = 20 // 默认每页20条
+	}
+	if req.Limit > 100 {
+		req.Limit = 100 // 最大每页100条
+	}
+
+	resp, err := service.GetReconcilerInstance().ListDiscrepancies(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, RespError(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RespSuccess(ctx, resp))
+}
@@ -38,9 +38,26 @@ func NewRouter() *gin.Engine {
 		{
 			merchantGroup.Use(middleware.AuthMiddleware())
 			merchantGroup.POST("/orders/list", api.ListOrders)
-			merchantGroup.GET("/orders/:order_no", api.GetOrderDetail)   // get order detail
-			merchantGroup.PATCH("/orders/:order_no/ship", api.ShipOrder) // ship order
-			merchantGroup.GET("/order-stats", api.GetOrderStats)         // get order stats
+			merchantGroup.GET("/orders/:order_no", api.GetOrderDetail)                             // get order detail
+			merchantGroup.PATCH("/orders/:order_no/ship", api.ShipOrder)                           // ship order
+			merchantGroup.PATCH("/orders/:order_no/cancel", api.MerchantCancelOrder)               // cancel order
+			merchantGroup.GET("/orders/:order_no/invoice", api.GetInvoice)                         // download invoice
+			merchantGroup.GET("/orders/:order_no/credit-notes", api.ListCreditNotes)               // list credit notes
+			merchantGroup.GET("/orders/:order_no/credit-notes/:credit_note_no", api.GetCreditNote) // download credit note
+			merchantGroup.GET("/order-stats", api.GetOrderStats)                                   // get order stats
+			merchantGroup.PATCH("/refunds/:refund_no/approve", api.ApproveRefund)                  // approve refund
+			merchantGroup.PATCH("/refunds/:refund_no/reject", api.RejectRefund)                    // reject refund
+			merchantGroup.PATCH("/returns/:return_no/approve", api.ApproveReturn)                  // approve return
+			merchantGroup.PATCH("/returns/:return_no/reject", api.RejectReturn)                    // reject return
+			merchantGroup.PATCH("/returns/:return_no/receive", api.ReceiveReturn)                  // receive returned goods
+			merchantGroup.GET("/reconciliation", api.ListDiscrepancies)                            // list reconciliation discrepancies
+			merchantGroup.POST("/dead-letters/:topic/replay", api.ReplayDeadLetters)               // replay dead letter queue
+			merchantGroup.POST("/coupons", api.CreateCoupon)                                       // create coupon
+			merchantGroup.GET("/coupons", api.ListCoupons)                                         // list coupons
+			merchantGroup.PATCH("/coupons/:code/status", api.UpdateCouponStatus)                   // enable or disable coupon
+			merchantGroup.POST("/gift-cards", api.CreateGiftCard)                                  // issue gift card
+			merchantGroup.GET("/gift-cards/:code", api.GetGiftCard)                                // get gift card with transactions
+			merchantGroup.PATCH("/gift-cards/:code/status", api.UpdateGiftCardStatus)              // enable or disable gift card
 		}
 
 		customerGroup := basicGroup.Group("/customer")
@@ -48,8 +65,18 @@ func NewRouter() *gin.Engine {
 			customerGroup.Use(middleware.AuthMiddleware())
 			customerGroup.POST("/orders", api.CreateOrder) // create order
 			customerGroup.POST("/orders/list", api.CustomerListOrders)
-			customerGroup.GET("/orders/:order_no", api.CustomerGetOrderDetail) // get order detail
-			customerGroup.PATCH("/orders/:order_no/confirm", api.ConfirmOrder) // confirm order
+			customerGroup.POST("/orders/quote", api.QuoteOrder)                                            // price preview before ordering
+			customerGroup.GET("/orders/:order_no", api.CustomerGetOrderDetail)                             // get order detail
+			customerGroup.PATCH("/orders/:order_no/confirm", api.ConfirmOrder)                             // confirm order
+			customerGroup.PATCH("/orders/:order_no/cancel", api.CustomerCancelOrder)                       // cancel order
+			customerGroup.GET("/orders/:order_no/invoice", api.CustomerGetInvoice)                         // download invoice
+			customerGroup.GET("/orders/:order_no/credit-notes", api.CustomerListCreditNotes)               // list credit notes
+			customerGroup.GET("/orders/:order_no/credit-notes/:credit_note_no", api.CustomerGetCreditNote) // download credit note
+			customerGroup.POST("/orders/:order_no/refunds", api.RequestRefund)                             // request refund
+			customerGroup.POST("/orders/:order_no/returns", api.RequestReturn)                             // request return
+			customerGroup.POST("/shipping/quote", api.QuoteShipping)                                       // quote shipping fee before ordering
+			customerGroup.GET("/gift-cards/:code", api.CustomerGetGiftCard)                                // check gift card balance
+			customerGroup.GET("/store-credit", api.GetStoreCredit)                                         // get store credit balance
 		}
 	}
 	return r
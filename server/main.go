@@ -37,15 +37,32 @@ func main() {
 	metrics.RegisterMetrics()
 	go grpc.Init(sigCh)
 	go http.Init(sigCh)
-	go utils.GetReader().ConsumeMessage(context.Background())
+	consumerCtx, stopConsumers := context.WithCancel(context.Background())
+	startConsumers(consumerCtx, service.GetOrderServiceInstance())
 	startAutoConfirmJob(context.Background(), service.GetOrderServiceInstance())
+	startAutoCancelUnpaidJob(context.Background(), service.GetOrderServiceInstance())
+	startSagaRecoveryJob(context.Background(), service.GetOrderServiceInstance())
+	startOutboxRelayJob(context.Background(), service.GetOutboxRelayInstance())
+	startReconciliationJob(context.Background(), service.GetReconcilerInstance())
 	// listen terminage signal
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-sigCh // Block until signal is received
 	log.Logger.Infof("Received signal: %v, shutting down...", sig)
+	stopConsumers()
 	utils.CloseKafka()
 }
 
+// startConsumers 注册各 topic 的消息处理器，并按配置启动消费组
+func startConsumers(ctx context.Context, orderService *service.OrderServiceImpl) {
+	utils.RegisterHandler(utils.ORDER_STATUS_CHANGED_TOPIC, utils.NewOrderStatusLogHandler())
+	utils.RegisterHandler(utils.PAYMENT_RESULT_TOPIC, utils.NewPaymentResultMessageHandler(orderService))
+	if err := utils.StartConsumers(ctx); err != nil {
+		panic(err)
+	}
+
+	log.Logger.Info("Kafka consumers started")
+}
+
 func startAutoConfirmJob(ctx context.Context, orderService *service.OrderServiceImpl) {
 	timer := utils.NewMyTimer(30 * time.Second)
 
@@ -58,3 +75,51 @@ func startAutoConfirmJob(ctx context.Context, orderService *service.OrderService
 
 	log.Logger.Info("Auto confirm job started")
 }
+
+func startAutoCancelUnpaidJob(ctx context.Context, orderService *service.OrderServiceImpl) {
+	timer := utils.NewMyTimer(time.Minute)
+
+	task := func() {
+		orderService.OrderAutoCancelUnpaid(ctx)
+	}
+
+	go timer.Start(ctx, task)
+
+	log.Logger.Info("Auto cancel unpaid job started")
+}
+
+func startSagaRecoveryJob(ctx context.Context, orderService *service.OrderServiceImpl) {
+	timer := utils.NewMyTimer(time.Minute)
+
+	task := func() {
+		orderService.RecoverOrderSagas(ctx)
+	}
+
+	go timer.Start(ctx, task)
+
+	log.Logger.Info("Saga recovery job started")
+}
+
+func startOutboxRelayJob(ctx context.Context, relay *service.OutboxRelay) {
+	timer := utils.NewMyTimer(time.Second)
+
+	task := func() {
+		relay.Relay(ctx)
+	}
+
+	go timer.Start(ctx, task)
+
+	log.Logger.Info("Outbox relay job started")
+}
+
+func startReconciliationJob(ctx context.Context, reconciler *service.Reconciler) {
+	timer := utils.NewMyTimer(service.RECONCILE_INTERVAL)
+
+	task := func() {
+		reconciler.Reconcile(ctx)
+	}
+
+	go timer.Start(ctx, task)
+
+	log.Logger.Info("Reconciliation job started")
+}
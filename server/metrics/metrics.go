@@ -30,8 +30,90 @@ var (
 		},
 		[]string{"method", "path", "status"},
 	)
+
+	// outbox 积压的待发送消息数
+	OutboxPendingMessages = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "order_service_outbox_pending_messages",
+			Help: "Number of outbox messages waiting to be published.(outbox 待发送消息数)",
+		},
+	)
+
+	// outbox 中最早一条待发送消息已等待的时间
+	OutboxOldestPendingSeconds = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "order_service_outbox_oldest_pending_seconds",
+			Help: "Age in seconds of the oldest pending outbox message.(outbox 最早待发送消息的等待时间)",
+		},
+	)
+
+	// outbox 投递结果 (sent/retry/failed)
+	OutboxPublishTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "order_service_outbox_publish_total",
+			Help: "Total number of outbox publish attempts by result.(outbox 投递次数)",
+		},
+		[]string{"topic", "result"},
+	)
+
+	// kafka 消费延迟，分区最新 offset 与当前处理的 offset 之差
+	KafkaConsumerLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "order_service_kafka_consumer_lag",
+			Help: "Number of messages behind the partition high water mark.(kafka 消费延迟)",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	// kafka 消息处理结果 (processed/duplicate/dead_lettered/replayed)
+	KafkaConsumedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "order_service_kafka_consumed_total",
+			Help: "Total number of consumed kafka messages by result.(kafka 消息处理数)",
+		},
+		[]string{"topic", "result"},
+	)
+
+	// kafka 消费失败次数 (fetch/parse/write/commit/dead_letter)
+	KafkaConsumeFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "order_service_kafka_consume_failures_total",
+			Help: "Total number of kafka consume failures by reason.(kafka 消费失败次数)",
+		},
+		[]string{"topic", "reason"},
+	)
+
+	// kafka 消息发送结果 (success/failure/dropped)，mode 为 sync/async
+	KafkaProducedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "order_service_kafka_produced_total",
+			Help: "Total number of produced kafka messages by result.(kafka 消息发送数)",
+		},
+		[]string{"topic", "mode", "result"},
+	)
+
+	// kafka 消息发送耗时，异步模式从进入缓冲区开始计算
+	KafkaProduceDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "order_service_kafka_produce_duration_milliseconds",
+			Help:    "Histogram of kafka produce latency (milliseconds).(kafka 消息发送耗时ms)",
+			Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+		},
+		[]string{"topic", "mode"},
+	)
+
+	// 异步发送缓冲区中等待发送的消息数
+	KafkaProducerBuffered = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "order_service_kafka_producer_buffered_messages",
+			Help: "Number of messages waiting in the async producer buffer.(kafka 异步发送缓冲区消息数)",
+		},
+	)
 )
 
 func RegisterMetrics() {
 	prometheus.MustRegister(HttpRequestsTotal, HttpRequestDuration, HttpRequestsErrors)
+	prometheus.MustRegister(OutboxPendingMessages, OutboxOldestPendingSeconds, OutboxPublishTotal)
+	prometheus.MustRegister(KafkaConsumerLag, KafkaConsumedTotal, KafkaConsumeFailuresTotal)
+	prometheus.MustRegister(KafkaProducedTotal, KafkaProduceDuration, KafkaProducerBuffered)
 }
@@ -0,0 +1,89 @@
+package consts
+
+// 储值卡类型，两者都可以在下单时抵扣订单金额
+const (
+	_                           = iota
+	GIFT_CARD_TYPE_GIFT_CARD    // 礼品卡，凭卡号使用
+	GIFT_CARD_TYPE_STORE_CREDIT // 店铺余额，退款时发放，只能本人使用
+)
+
+// 储值卡状态
+const (
+	_                  = iota
+	GIFT_CARD_ACTIVE   // 可用
+	GIFT_CARD_DISABLED // 已停用
+)
+
+// 储值卡余额流水类型
+const (
+	_                     = iota
+	GIFT_CARD_TXN_ISSUE   // 发卡
+	GIFT_CARD_TXN_HOLD    // 下单冻结
+	GIFT_CARD_TXN_RELEASE // 付款失败或未付款订单取消，解冻
+	GIFT_CARD_TXN_RESTORE // 已付款订单取消或退款，退回原卡
+	GIFT_CARD_TXN_CREDIT  // 退款发放为店铺余额
+)
+
+// 订单使用储值卡的状态
+const (
+	_               = iota
+	TENDER_HELD     // 已冻结，等待付款完成
+	TENDER_CAPTURED // 订单已付款
+	TENDER_RELEASED // 已解冻
+)
+
+var giftCardTypeNames = map[int]string{
+	GIFT_CARD_TYPE_GIFT_CARD:    "GiftCard",
+	GIFT_CARD_TYPE_STORE_CREDIT: "StoreCredit",
+}
+
+var giftCardStatusNames = map[int]string{
+	GIFT_CARD_ACTIVE:   "Active",
+	GIFT_CARD_DISABLED: "Disabled",
+}
+
+var giftCardTxnTypeNames = map[int]string{
+	GIFT_CARD_TXN_ISSUE:   "Issue",
+	GIFT_CARD_TXN_HOLD:    "Hold",
+	GIFT_CARD_TXN_RELEASE: "Release",
+	GIFT_CARD_TXN_RESTORE: "Restore",
+	GIFT_CARD_TXN_CREDIT:  "Credit",
+}
+
+var tenderStatusNames = map[int]string{
+	TENDER_HELD:     "Held",
+	TENDER_CAPTURED: "Captured",
+	TENDER_RELEASED: "Released",
+}
+
+// GetGiftCardTypeName 获取储值卡类型名称
+func GetGiftCardTypeName(cardType int) string {
+	if name, ok := giftCardTypeNames[cardType]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// GetGiftCardStatusName 获取储值卡状态名称
+func GetGiftCardStatusName(status int) string {
+	if name, ok := giftCardStatusNames[status]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// GetGiftCardTxnTypeName 获取余额流水类型名称
+func GetGiftCardTxnTypeName(txnType int) string {
+	if name, ok := giftCardTxnTypeNames[txnType]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// GetTenderStatusName 获取订单储值卡抵扣状态名称
+func GetTenderStatusName(status int) string {
+	if name, ok := tenderStatusNames[status]; ok {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,21 @@
+package consts
+
+// 发票类型
+const (
+	_                        = iota
+	INVOICE_TYPE_INVOICE     // 发票，订单付款时开具
+	INVOICE_TYPE_CREDIT_NOTE // 贷项通知单，退款时开具，引用原发票
+)
+
+var invoiceTypeNames = map[int]string{
+	INVOICE_TYPE_INVOICE:     "Tax Invoice",
+	INVOICE_TYPE_CREDIT_NOTE: "Credit Note",
+}
+
+// GetInvoiceTypeName 获取发票类型名称
+func GetInvoiceTypeName(invoiceType int) string {
+	if name, ok := invoiceTypeNames[invoiceType]; ok {
+		return name
+	}
+	return "Unknown"
+}
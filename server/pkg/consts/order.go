@@ -1,10 +1,38 @@
 package consts
 
 const (
-	_ = iota
+	NONE = iota // 订单尚未创建
 	CREATED
 	PAYED
 	SHIPPED
 	DELIVERED
 	CANCELED
-)
\ No newline at end of file
+	REFUNDING          // 退款申请待商家审核
+	REFUNDED           // 已全额退款
+	PARTIALLY_REFUNDED // 已部分退款
+	RETURN_REQUESTED   // 退货申请待商家审核
+	RETURNING          // 商家已同意退货，等待收到退回的商品
+)
+
+var orderStatusNames = map[int]string{
+	CREATED:   "Created",
+	PAYED:     "Paid",
+	SHIPPED:   "Shipped",
+	DELIVERED: "Delivered",
+	CANCELED:  "Canceled",
+
+	REFUNDING:          "Refunding",
+	REFUNDED:           "Refunded",
+	PARTIALLY_REFUNDED: "PartiallyRefunded",
+
+	RETURN_REQUESTED: "ReturnRequested",
+	RETURNING:        "Returning",
+}
+
+// GetOrderStatusName 获取订单状态名称
+func GetOrderStatusName(status int) string {
+	if name, ok := orderStatusNames[status]; ok {
+		return name
+	}
+	return "Unknown"
+}
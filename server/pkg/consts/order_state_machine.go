@@ -0,0 +1,336 @@
+package consts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Actor 触发订单状态变更的角色，可按位组合
+type Actor int
+
+const (
+	ActorCustomer Actor = 1 << iota
+	ActorMerchant
+	ActorSystem
+)
+
+func (a Actor) String() string {
+	names := make([]string, 0, 3)
+	if a&ActorCustomer != 0 {
+		names = append(names, "Customer")
+	}
+	if a&ActorMerchant != 0 {
+		names = append(names, "Merchant")
+	}
+	if a&ActorSystem != 0 {
+		names = append(names, "System")
+	}
+	if len(names) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(names, "|")
+}
+
+// TransitionInput 状态变更请求的上下文，供守卫条件校验
+type TransitionInput struct {
+	Actor       Actor  // 触发者
+	UserID      int    // 触发者用户ID，Actor 为 ActorCustomer 时必填
+	OwnerUserID int    // 订单所属用户ID
+	TrackingNo  string // 物流单号
+	Reason      string // 变更原因
+	// 置为已付款时的支付信息
+	PayAmount        int    // 实际支付金额
+	PayTransactionID string // 支付服务的支付单号
+	PayMethod        string // 支付方式
+	// 订单的发货和收货确认时间，部分退款的订单据此判断退款前的履约进度
+	DeliveryTime time.Time
+	ConfirmTime  time.Time
+}
+
+// Guard 状态变更的守卫条件，返回非 nil 表示不允许变更
+type Guard func(in TransitionInput) error
+
+// Transition 描述一条合法的订单状态变更
+type Transition struct {
+	From   int
+	To     int
+	Actors Actor    // 允许触发的角色
+	Guards []Guard  // 守卫条件
+	Stamps []string // 变更时写入当前时间的订单字段
+	// Fields 根据请求生成需要一并更新的订单字段，可为空
+	Fields func(in TransitionInput) map[string]interface{}
+	// Internal 只能由退款、退货流程与退款单、退货单一起变更，不能通过 UpdateOrderStatus 直接变更
+	Internal bool
+}
+
+var (
+	ErrInvalidTransition  = errors.New("invalid order status transition")
+	ErrActorNotAllowed    = errors.New("actor not allowed to change order status")
+	ErrInternalTransition = errors.New("order status can only be changed by the refund or return flow")
+)
+
+// GuardOwner 用户只能操作属于自己的订单
+func GuardOwner(in TransitionInput) error {
+	if in.Actor == ActorCustomer && in.UserID != in.OwnerUserID {
+		return errors.New("invalid user ID")
+	}
+	return nil
+}
+
+// GuardTrackingNo 发货时必须提供物流单号
+func GuardTrackingNo(in TransitionInput) error {
+	if in.TrackingNo == "" {
+		return errors.New("tracking number is required")
+	}
+	return nil
+}
+
+// GuardNotShipped 部分退款的订单只有在发货前退款的才能发货
+func GuardNotShipped(in TransitionInput) error {
+	if !in.DeliveryTime.IsZero() {
+		return errors.New("order has already been shipped")
+	}
+	return nil
+}
+
+// GuardShippedNotDelivered 部分退款的订单只有在发货后、收货前退款的才能确认收货
+func GuardShippedNotDelivered(in TransitionInput) error {
+	if in.DeliveryTime.IsZero() {
+		return errors.New("order has not been shipped")
+	}
+	if !in.ConfirmTime.IsZero() {
+		return errors.New("order has already been delivered")
+	}
+	return nil
+}
+
+// GuardDelivered 部分退款的订单只有在收货后退款的才能申请退货
+func GuardDelivered(in TransitionInput) error {
+	if in.ConfirmTime.IsZero() {
+		return errors.New("order has not been delivered")
+	}
+	return nil
+}
+
+func shippingFields(in TransitionInput) map[string]interface{} {
+	return map[string]interface{}{"logistics_no": in.TrackingNo}
+}
+
+func paymentFields(in TransitionInput) map[string]interface{} {
+	payMethod := in.PayMethod
+	if payMethod == "" {
+		payMethod = PAY_METHOD_BALANCE
+	}
+	return map[string]interface{}{
+		"pay_amount":         in.PayAmount,
+		"pay_transaction_id": in.PayTransactionID,
+		"pay_method":         payMethod,
+	}
+}
+
+func cancelFields(in TransitionInput) map[string]interface{} {
+	return map[string]interface{}{"cancel_reason": in.Reason}
+}
+
+// OrderTransitions 订单状态机定义，新增状态流转只需在此追加配置
+var OrderTransitions = []Transition{
+	{
+		From:   NONE,
+		To:     CREATED,
+		Actors: ActorCustomer,
+	},
+	{
+		From:   CREATED,
+		To:     PAYED,
+		Actors: ActorSystem,
+		Stamps: []string{"pay_time"},
+		Fields: paymentFields,
+	},
+	{
+		From:   PAYED,
+		To:     SHIPPED,
+		Actors: ActorMerchant,
+		Guards: []Guard{GuardTrackingNo},
+		Stamps: []string{"delivery_time"},
+		Fields: shippingFields,
+	},
+	{
+		From:   SHIPPED,
+		To:     DELIVERED,
+		Actors: ActorCustomer | ActorSystem,
+		Guards: []Guard{GuardOwner},
+		Stamps: []string{"confirm_time"},
+	},
+	{
+		From:   CREATED,
+		To:     CANCELED,
+		Actors: ActorCustomer | ActorMerchant | ActorSystem,
+		Guards: []Guard{GuardOwner},
+		Stamps: []string{"cancel_time"},
+		Fields: cancelFields,
+	},
+	{
+		From:   PAYED,
+		To:     CANCELED,
+		Actors: ActorCustomer | ActorMerchant | ActorSystem,
+		Guards: []Guard{GuardOwner},
+		Stamps: []string{"cancel_time"},
+		Fields: cancelFields,
+	},
+	// 申请退款，审核期间订单处于退款中
+	{
+		From:     PAYED,
+		To:       REFUNDING,
+		Actors:   ActorCustomer,
+		Guards:   []Guard{GuardOwner},
+		Internal: true,
+	},
+	{
+		From:     SHIPPED,
+		To:       REFUNDING,
+		Actors:   ActorCustomer,
+		Guards:   []Guard{GuardOwner},
+		Internal: true,
+	},
+	{
+		From:     DELIVERED,
+		To:       REFUNDING,
+		Actors:   ActorCustomer,
+		Guards:   []Guard{GuardOwner},
+		Internal: true,
+	},
+	{
+		From:     PARTIALLY_REFUNDED,
+		To:       REFUNDING,
+		Actors:   ActorCustomer,
+		Guards:   []Guard{GuardOwner},
+		Internal: true,
+	},
+	// 商家同意退款
+	{
+		From:     REFUNDING,
+		To:       REFUNDED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	// 同时用于拒绝已部分退款订单的再次申请
+	{
+		From:     REFUNDING,
+		To:       PARTIALLY_REFUNDED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	// 商家拒绝退款，订单回到申请退款前的状态
+	{
+		From:     REFUNDING,
+		To:       PAYED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	{
+		From:     REFUNDING,
+		To:       SHIPPED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	{
+		From:     REFUNDING,
+		To:       DELIVERED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	// 部分退款后剩余商品从退款前的进度继续履约
+	{
+		From:   PARTIALLY_REFUNDED,
+		To:     SHIPPED,
+		Actors: ActorMerchant,
+		Guards: []Guard{GuardNotShipped, GuardTrackingNo},
+		Stamps: []string{"delivery_time"},
+		Fields: shippingFields,
+	},
+	{
+		From:   PARTIALLY_REFUNDED,
+		To:     DELIVERED,
+		Actors: ActorCustomer | ActorSystem,
+		Guards: []Guard{GuardShippedNotDelivered, GuardOwner},
+		Stamps: []string{"confirm_time"},
+	},
+	// 收货后申请退货
+	{
+		From:     DELIVERED,
+		To:       RETURN_REQUESTED,
+		Actors:   ActorCustomer,
+		Guards:   []Guard{GuardOwner},
+		Internal: true,
+	},
+	{
+		From:     PARTIALLY_REFUNDED,
+		To:       RETURN_REQUESTED,
+		Actors:   ActorCustomer,
+		Guards:   []Guard{GuardDelivered, GuardOwner},
+		Internal: true,
+	},
+	// 商家同意退货并提供退货物流单号
+	{
+		From:     RETURN_REQUESTED,
+		To:       RETURNING,
+		Actors:   ActorMerchant,
+		Guards:   []Guard{GuardTrackingNo},
+		Internal: true,
+	},
+	// 商家拒绝退货，订单回到申请退货前的状态
+	{
+		From:     RETURN_REQUESTED,
+		To:       DELIVERED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	{
+		From:     RETURN_REQUESTED,
+		To:       PARTIALLY_REFUNDED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	// 商家收到退货后退款
+	{
+		From:     RETURNING,
+		To:       REFUNDED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+	{
+		From:     RETURNING,
+		To:       PARTIALLY_REFUNDED,
+		Actors:   ActorMerchant,
+		Internal: true,
+	},
+}
+
+// FindTransition 查找 from --> to 的状态变更定义
+func FindTransition(from, to int) (*Transition, error) {
+	for idx := range OrderTransitions {
+		if OrderTransitions[idx].From == from && OrderTransitions[idx].To == to {
+			return &OrderTransitions[idx], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s --> %s", ErrInvalidTransition, GetOrderStatusName(from), GetOrderStatusName(to))
+}
+
+// CheckTransition 校验状态变更是否合法：存在对应的变更定义、触发者有权限且守卫条件全部通过
+func CheckTransition(from, to int, in TransitionInput) (*Transition, error) {
+	transition, err := FindTransition(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if in.Actor == 0 || transition.Actors&in.Actor != in.Actor {
+		return nil, fmt.Errorf("%w: %s can not change %s --> %s", ErrActorNotAllowed, in.Actor, GetOrderStatusName(from), GetOrderStatusName(to))
+	}
+	for _, guard := range transition.Guards {
+		if err = guard(in); err != nil {
+			return nil, err
+		}
+	}
+	return transition, nil
+}
@@ -0,0 +1,86 @@
+package consts
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckTransition(t *testing.T) {
+	shipped := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	delivered := shipped.Add(72 * time.Hour)
+	cases := []struct {
+		name    string
+		from    int
+		to      int
+		in      TransitionInput
+		wantErr error
+	}{
+		{"create by customer", NONE, CREATED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, nil},
+		{"pay by system", CREATED, PAYED, TransitionInput{Actor: ActorSystem}, nil},
+		{"pay by customer", CREATED, PAYED, TransitionInput{Actor: ActorCustomer}, ErrActorNotAllowed},
+		{"ship by merchant", PAYED, SHIPPED, TransitionInput{Actor: ActorMerchant, TrackingNo: "SF1"}, nil},
+		{"ship without tracking no", PAYED, SHIPPED, TransitionInput{Actor: ActorMerchant}, errors.New("")},
+		{"confirm by owner", SHIPPED, DELIVERED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, nil},
+		{"confirm by other user", SHIPPED, DELIVERED, TransitionInput{Actor: ActorCustomer, UserID: 2, OwnerUserID: 1}, errors.New("")},
+		{"auto confirm by system", SHIPPED, DELIVERED, TransitionInput{Actor: ActorSystem}, nil},
+		{"skip shipping", PAYED, DELIVERED, TransitionInput{Actor: ActorSystem}, ErrInvalidTransition},
+		{"cancel paid by merchant", PAYED, CANCELED, TransitionInput{Actor: ActorMerchant}, nil},
+		{"cancel shipped", SHIPPED, CANCELED, TransitionInput{Actor: ActorMerchant}, ErrInvalidTransition},
+		{"no actor", CREATED, CANCELED, TransitionInput{}, ErrActorNotAllowed},
+		{"refund request by owner", DELIVERED, REFUNDING, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, nil},
+		{"refund request by other user", DELIVERED, REFUNDING, TransitionInput{Actor: ActorCustomer, UserID: 2, OwnerUserID: 1}, errors.New("")},
+		{"refund unpaid order", CREATED, REFUNDING, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, ErrInvalidTransition},
+		{"approve refund by customer", REFUNDING, REFUNDED, TransitionInput{Actor: ActorCustomer}, ErrActorNotAllowed},
+		{"reject refund by merchant", REFUNDING, SHIPPED, TransitionInput{Actor: ActorMerchant}, nil},
+		{"return delivered order", DELIVERED, RETURN_REQUESTED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, nil},
+		{"return shipped order", SHIPPED, RETURN_REQUESTED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, ErrInvalidTransition},
+		{"approve return without tracking no", RETURN_REQUESTED, RETURNING, TransitionInput{Actor: ActorMerchant}, errors.New("")},
+		{"refund after full refund", REFUNDED, REFUNDING, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, ErrInvalidTransition},
+		{"ship partially refunded unshipped order", PARTIALLY_REFUNDED, SHIPPED, TransitionInput{Actor: ActorMerchant, TrackingNo: "SF1"}, nil},
+		{"ship partially refunded shipped order", PARTIALLY_REFUNDED, SHIPPED, TransitionInput{Actor: ActorMerchant, TrackingNo: "SF1", DeliveryTime: shipped}, errors.New("")},
+		{"confirm partially refunded shipped order", PARTIALLY_REFUNDED, DELIVERED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1, DeliveryTime: shipped}, nil},
+		{"confirm partially refunded unshipped order", PARTIALLY_REFUNDED, DELIVERED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1}, errors.New("")},
+		{"confirm partially refunded delivered order", PARTIALLY_REFUNDED, DELIVERED, TransitionInput{Actor: ActorSystem, DeliveryTime: shipped, ConfirmTime: delivered}, errors.New("")},
+		{"return partially refunded delivered order", PARTIALLY_REFUNDED, RETURN_REQUESTED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1, DeliveryTime: shipped, ConfirmTime: delivered}, nil},
+		{"return partially refunded shipped order", PARTIALLY_REFUNDED, RETURN_REQUESTED, TransitionInput{Actor: ActorCustomer, UserID: 1, OwnerUserID: 1, DeliveryTime: shipped}, errors.New("")},
+		{"reject return of partially refunded order", RETURN_REQUESTED, PARTIALLY_REFUNDED, TransitionInput{Actor: ActorMerchant}, nil},
+	}
+	for _, c := range cases {
+		_, err := CheckTransition(c.from, c.to, c.in)
+		switch {
+		case c.wantErr == nil && err != nil:
+			t.Errorf("%s: expected no error, got: %v", c.name, err)
+		case c.wantErr != nil && err == nil:
+			t.Errorf("%s: expected error, got nil", c.name)
+		case c.wantErr == ErrInvalidTransition || c.wantErr == ErrActorNotAllowed:
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("%s: expected %v, got: %v", c.name, c.wantErr, err)
+			}
+		}
+	}
+}
+
+func TestCheckTransition_StampsAndFields(t *testing.T) {
+	transition, err := CheckTransition(PAYED, SHIPPED, TransitionInput{Actor: ActorMerchant, TrackingNo: "SF1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(transition.Stamps) != 1 || transition.Stamps[0] != "delivery_time" {
+		t.Errorf("Stamps mismatch: %v", transition.Stamps)
+	}
+	if fields := transition.Fields(TransitionInput{TrackingNo: "SF1"}); fields["logistics_no"] != "SF1" {
+		t.Errorf("Fields mismatch: %v", fields)
+	}
+}
+
+func TestCheckTransition_PaymentFields(t *testing.T) {
+	transition, err := CheckTransition(CREATED, PAYED, TransitionInput{Actor: ActorSystem})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	fields := transition.Fields(TransitionInput{PayAmount: 3000, PayTransactionID: "PAY001"})
+	if fields["pay_amount"] != 3000 || fields["pay_transaction_id"] != "PAY001" || fields["pay_method"] != PAY_METHOD_BALANCE {
+		t.Errorf("Fields mismatch: %v", fields)
+	}
+}
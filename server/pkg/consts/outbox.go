@@ -0,0 +1,9 @@
+package consts
+
+// outbox 消息状态
+const (
+	_ = iota
+	OUTBOX_PENDING
+	OUTBOX_SENT
+	OUTBOX_FAILED
+)
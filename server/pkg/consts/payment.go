@@ -0,0 +1,24 @@
+package consts
+
+// payment_result 消息中的支付结果
+const (
+	PAYMENT_SUCCESS = "SUCCESS"
+	PAYMENT_FAILED  = "FAILED"
+)
+
+// 支付方式，同步扣款从账户余额中扣除
+const (
+	PAY_METHOD_BALANCE   = "BALANCE"
+	PAY_METHOD_GIFT_CARD = "GIFT_CARD" // 礼品卡和店铺余额抵扣了全部金额
+	PAY_METHOD_SPLIT     = "SPLIT"     // 礼品卡和店铺余额抵扣后，剩余金额从账户余额中扣除
+)
+
+// 支付结果的处理方式
+const (
+	_                       = iota
+	PAYMENT_ACTION_PAYED    // 订单置为已付款
+	PAYMENT_ACTION_CANCELED // 支付失败，订单已取消
+	PAYMENT_ACTION_REFUNDED // 订单取消后才扣款成功，已自动退款
+	PAYMENT_ACTION_IGNORED  // 订单状态已反映该结果，无需处理
+	PAYMENT_ACTION_SAGA     // 下单 saga 同步拿到扣款结果，由 saga 处理
+)
@@ -0,0 +1,52 @@
+package consts
+
+// 优惠券类型
+const (
+	_                    = iota
+	COUPON_PERCENTAGE    // 商品金额按比例折扣
+	COUPON_FIXED         // 商品金额减免固定金额
+	COUPON_FREE_SHIPPING // 免运费
+	COUPON_BUY_X_GET_Y   // 买 X 件送 Y 件，赠送单价最低的商品
+)
+
+// 优惠券状态
+const (
+	_               = iota
+	COUPON_ACTIVE   // 可用
+	COUPON_DISABLED // 已停用
+)
+
+// 优惠券使用记录状态
+const (
+	_               = iota
+	COUPON_REDEEMED // 已使用
+	COUPON_RELEASED // 订单取消，已退回
+)
+
+var couponTypeNames = map[int]string{
+	COUPON_PERCENTAGE:    "Percentage",
+	COUPON_FIXED:         "Fixed",
+	COUPON_FREE_SHIPPING: "FreeShipping",
+	COUPON_BUY_X_GET_Y:   "BuyXGetY",
+}
+
+var couponStatusNames = map[int]string{
+	COUPON_ACTIVE:   "Active",
+	COUPON_DISABLED: "Disabled",
+}
+
+// GetCouponTypeName 获取优惠券类型名称
+func GetCouponTypeName(couponType int) string {
+	if name, ok := couponTypeNames[couponType]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// GetCouponStatusName 获取优惠券状态名称
+func GetCouponStatusName(status int) string {
+	if name, ok := couponStatusNames[status]; ok {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,23 @@
+package consts
+
+// 对账差异类型
+const (
+	_                       = iota
+	RECON_MISSING_PAYMENT   // 订单已付款，支付服务中没有对应的支付单
+	RECON_UNMATCHED_PAYMENT // 支付服务中有支付单，订单未付款且未自动退款
+	RECON_AMOUNT_MISMATCH   // 支付金额与订单金额不一致
+)
+
+var discrepancyTypeNames = map[int]string{
+	RECON_MISSING_PAYMENT:   "MissingPayment",
+	RECON_UNMATCHED_PAYMENT: "UnmatchedPayment",
+	RECON_AMOUNT_MISMATCH:   "AmountMismatch",
+}
+
+// GetDiscrepancyTypeName 获取对账差异类型名称
+func GetDiscrepancyTypeName(discrepancyType int) string {
+	if name, ok := discrepancyTypeNames[discrepancyType]; ok {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,23 @@
+package consts
+
+// 退款单状态
+const (
+	_               = iota
+	REFUND_PENDING  // 待商家审核
+	REFUND_APPROVED // 已同意，退款已提交支付服务
+	REFUND_REJECTED // 已拒绝
+)
+
+var refundStatusNames = map[int]string{
+	REFUND_PENDING:  "Pending",
+	REFUND_APPROVED: "Approved",
+	REFUND_REJECTED: "Rejected",
+}
+
+// GetRefundStatusName 获取退款单状态名称
+func GetRefundStatusName(status int) string {
+	if name, ok := refundStatusNames[status]; ok {
+		return name
+	}
+	return "Unknown"
+}
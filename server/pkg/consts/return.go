@@ -0,0 +1,25 @@
+package consts
+
+// 退货单状态
+const (
+	_             = iota
+	RMA_REQUESTED // 待商家审核
+	RMA_APPROVED  // 已同意，等待退回商品
+	RMA_REJECTED  // 已拒绝
+	RMA_RECEIVED  // 已收到退货并退款
+)
+
+var returnStatusNames = map[int]string{
+	RMA_REQUESTED: "Requested",
+	RMA_APPROVED:  "Approved",
+	RMA_REJECTED:  "Rejected",
+	RMA_RECEIVED:  "Received",
+}
+
+// GetReturnStatusName 获取退货单状态名称
+func GetReturnStatusName(status int) string {
+	if name, ok := returnStatusNames[status]; ok {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,19 @@
+package consts
+
+// 下单 saga 已完成的步骤，补偿时按相反顺序回退
+const (
+	SAGA_STEP_STARTED = iota
+	SAGA_STEP_STOCK_RESERVED
+	SAGA_STEP_ORDER_PERSISTED
+	SAGA_STEP_PAYMENT_CHARGED
+	SAGA_STEP_CONFIRMED
+)
+
+// 下单 saga 状态
+const (
+	_ = iota
+	SAGA_RUNNING
+	SAGA_COMPENSATING
+	SAGA_COMPLETED
+	SAGA_COMPENSATED
+)
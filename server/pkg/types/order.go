@@ -4,14 +4,19 @@ import "time"
 
 // OrderInfo service layer input
 type OrderInfo struct {
-	ReceiverFirstName string           `json:"receiver_first_name"` // 收货人姓名
-	ReceiverLastName  string           `json:"receiver_last_name"`  // 收货人姓名
-	ReceiverPhone     string           `json:"receiver_phone"`      // 收货人电话
-	ReceiverAddress   string           `json:"receiver_address"`    // 收货地址
-	ReceiverCountry   string           `json:"receiver_country"`    // 收货人国家
-	ReceiverZipCode   int              `json:"receiver_zip_code"`   // 收货人邮政编码
-	Remark            string           `json:"remark"`              // 备注
-	OrderItemList     []*OrderItemInfo `json:"order_item_list"`     // 订单商品列表
+	ReceiverFirstName   string           `json:"receiver_first_name"`   // 收货人姓名
+	ReceiverLastName    string           `json:"receiver_last_name"`    // 收货人姓名
+	ReceiverPhone       string           `json:"receiver_phone"`        // 收货人电话
+	ReceiverAddress     string           `json:"receiver_address"`      // 收货地址
+	ReceiverCountry     string           `json:"receiver_country"`      // 收货人国家
+	ReceiverZipCode     int              `json:"receiver_zip_code"`     // 收货人邮政编码
+	Remark              string           `json:"remark"`                // 备注
+	OrderItemList       []*OrderItemInfo `json:"order_item_list"`       // 订单商品列表
+	ExpectedTotalAmount int              `json:"expected_total_amount"` // 客户端展示的订单总金额，非 0 时需与服务端一致
+	CouponCode          string           `json:"coupon_code"`           // 优惠码，可为空
+	Currency            string           `json:"currency"`              // 订单货币，为空时按收货国家选择
+	GiftCardCodes       []string         `json:"gift_card_codes"`       // 使用的礼品卡号，按顺序抵扣订单金额
+	UseStoreCredit      bool             `json:"use_store_credit"`      // 是否使用店铺余额，在礼品卡之后抵扣
 }
 
 type OrderItemInfo struct {
@@ -21,6 +26,23 @@ type OrderItemInfo struct {
 	Price       int    `json:"price"`
 }
 
+// PriceChangedItem 价格发生变化的商品
+type PriceChangedItem struct {
+	ProductID     int    `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	ExpectedPrice int    `json:"expected_price"` // 客户端提交的单价
+	CurrentPrice  int    `json:"current_price"`  // 当前单价
+}
+
+// PriceChangedInfo 价格变化时返回给前端的重新报价
+type PriceChangedInfo struct {
+	Items               []*PriceChangedItem `json:"items"`
+	ExpectedTotalAmount int                 `json:"expected_total_amount"` // 客户端预期总金额
+	TotalAmount         int                 `json:"total_amount"`          // 按当前价格计算的总金额
+	OrderItemList       []*OrderItemInfo    `json:"order_item_list"`       // 按当前价格生成的商品列表
+}
+
+// OrderMessage order_created、order_canceled 事件的数据，格式由 common/proto/events.proto 定义
 type OrderMessage struct {
 	UserID            int              `json:"user_id"`             // 下单用户
 	OrderID           string           `json:"order_id"`            // 订单ID
@@ -34,11 +56,14 @@ type OrderMessage struct {
 	OrderItemList     []*OrderItemInfo `json:"order_item_list"`
 }
 
+// OrderStatusChangedMessage order_status_changed 事件的数据，格式由 common/proto/events.proto 定义
 type OrderStatusChangedMessage struct {
-	OrderNo       string `json:"order_no"`
-	UserId        int    `json:"user_id"`
-	CurrentStatus int    `json:"current_status"`
-	Remark        string `json:"remark"`
+	EventID       string    `json:"-"` // 事件ID，取自事件信封
+	OrderNo       string    `json:"order_no"`
+	UserId        int       `json:"user_id"`
+	CurrentStatus int       `json:"current_status"`
+	Remark        string    `json:"remark"`
+	OccurredAt    time.Time `json:"-"` // 状态变更发生时间，取自事件信封
 }
 
 // list order
@@ -82,6 +107,31 @@ type OrderDetail struct {
 	UpdateTime   time.Time `json:"update_time"`   // 更新时间
 	DeliveryTime time.Time `json:"delivery_time"` // 发货时间
 	ConfirmTime  time.Time `json:"confirm_time"`  // 收货确认时间
+	CancelTime   time.Time `json:"cancel_time"`   // 取消时间
+	CancelReason string    `json:"cancel_reason"` // 取消原因
+
+	// 优惠信息
+	DiscountAmount int                    `json:"discount_amount"` // 优惠减免金额，包括商品和运费
+	CouponCode     string                 `json:"coupon_code"`     // 使用的优惠码
+	Discounts      []*OrderDiscountDetail `json:"discounts"`       // 优惠明细
+
+	// 税费信息
+	TaxRegion    string `json:"tax_region"`    // 下单时匹配的税率规则
+	TaxRate      int    `json:"tax_rate"`      // 下单时的标准税率，万分之一
+	TaxInclusive bool   `json:"tax_inclusive"` // 商品价格是否已含税
+
+	// 货币信息，金额字段均为基础货币，Amounts 按下单时的汇率换算为订单货币
+	Currency     string        `json:"currency"`      // 订单货币
+	BaseCurrency string        `json:"base_currency"` // 基础货币
+	ExchangeRate int64         `json:"exchange_rate"` // 下单时的汇率快照，1e6 个基础货币最小单位可兑换的订单货币最小单位
+	Amounts      *OrderAmounts `json:"amounts"`       // 订单货币的格式化金额
+	BaseAmounts  *OrderAmounts `json:"base_amounts"`  // 基础货币的格式化金额
+
+	// 支付信息，PayAmount 为支付服务扣款的金额，不包括礼品卡和店铺余额抵扣的部分
+	PayTransactionID string               `json:"pay_transaction_id"` // 支付服务的支付单号
+	PayMethod        string               `json:"pay_method"`         // 支付方式
+	TenderAmount     int                  `json:"tender_amount"`      // 礼品卡和店铺余额抵扣的金额
+	Tenders          []*OrderTenderDetail `json:"tenders"`            // 礼品卡和店铺余额抵扣明细
 
 	// 收货信息
 	ReceiverFirstName string `json:"receiver_first_name"` // 收货人姓名
@@ -100,17 +150,47 @@ type OrderDetail struct {
 
 	// 订单状态变更日志
 	StatusLogs []*OrderStatusLogDetail `json:"status_logs"`
+
+	// 退款记录
+	RefundedAmount int             `json:"refunded_amount"` // 已退款金额
+	Refunds        []*RefundDetail `json:"refunds"`
+
+	// 退货记录
+	Returns []*ReturnDetail `json:"returns"`
 }
 
 type OrderItemDetail struct {
-	ID          int       `json:"id"`           // 订单商品ID
-	ProductID   int       `json:"product_id"`   // 商品ID
-	ProductName string    `json:"product_name"` // 商品名称
-	Price       int       `json:"price"`        // 商品单价
-	Quantity    int       `json:"quantity"`     // 商品数量
-	TotalPrice  int       `json:"total_price"`  // 商品总价
-	CreateTime  time.Time `json:"create_time"`  // 创建时间
-	UpdateTime  time.Time `json:"update_time"`  // 更新时间
+	ID             int       `json:"id"`              // 订单商品ID
+	ProductID      int       `json:"product_id"`      // 商品ID
+	ProductName    string    `json:"product_name"`    // 商品名称
+	Price          int       `json:"price"`           // 商品单价
+	Quantity       int       `json:"quantity"`        // 商品数量
+	TotalPrice     int       `json:"total_price"`     // 商品总价
+	DiscountAmount int       `json:"discount_amount"` // 分摊到该商品的优惠金额
+	TaxCategory    string    `json:"tax_category"`    // 税务类别，标准税率时为空
+	TaxRate        int       `json:"tax_rate"`        // 适用税率，万分之一
+	TaxAmount      int       `json:"tax_amount"`      // 分摊到该商品的税额
+	CreateTime     time.Time `json:"create_time"`     // 创建时间
+	UpdateTime     time.Time `json:"update_time"`     // 更新时间
+}
+
+type OrderDiscountDetail struct {
+	CouponCode       string `json:"coupon_code"`       // 优惠码
+	Type             int    `json:"type"`              // 优惠券类型
+	TypeName         string `json:"type_name"`         // 优惠券类型名称
+	ItemDiscount     int    `json:"item_discount"`     // 商品金额减免
+	ShippingDiscount int    `json:"shipping_discount"` // 运费减免
+	Description      string `json:"description"`       // 优惠说明
+}
+
+type OrderTenderDetail struct {
+	Code           string `json:"code"`            // 卡号，只显示最后 4 位
+	Type           int    `json:"type"`            // 储值卡类型
+	TypeName       string `json:"type_name"`       // 储值卡类型名称
+	Amount         int    `json:"amount"`          // 抵扣金额
+	RefundedAmount int    `json:"refunded_amount"` // 已退回原卡的金额
+	Status         int    `json:"status"`          // 状态
+	StatusName     string `json:"status_name"`     // 状态名称
 }
 
 type OrderStatusLogDetail struct {
@@ -118,7 +198,8 @@ type OrderStatusLogDetail struct {
 	CurrentStatus int       `json:"current_status"` // 当前状态
 	StatusName    string    `json:"status_name"`    // 状态名称
 	Remark        string    `json:"remark"`         // 备注
-	CreateTime    time.Time `json:"create_time"`    // 变更时间
+	OccurredAt    time.Time `json:"occurred_at"`    // 变更发生时间
+	CreateTime    time.Time `json:"create_time"`    // 日志写入时间
 }
 
 type CustomerListOrderRequest struct {
@@ -132,18 +213,421 @@ type ShipOrderRequest struct {
 	TrackingNo string `json:"tracking_no"`
 }
 
+type CancelOrderRequest struct {
+	Reason string `json:"reason"` // 取消原因
+}
+
 type ConfirmOrderRequest struct {
 	OrderNo string `json:"order_no"`
 }
 
+// SagaItem 下单 saga 中需要扣减或回补库存的商品
+type SagaItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// RefundMessage is published on the order_refund topic and settled by the payment service
+type RefundMessage struct {
+	OrderNo  string `json:"order_no"`
+	RefundNo string `json:"refund_no,omitempty"` // 退款单号，取消订单的整单退款为空
+	UserID   int    `json:"user_id"`
+	Amount   int    `json:"amount"`
+	Reason   string `json:"reason"`
+}
+
+// PaymentResultMessage 支付服务发送的 payment_result 消息，异步支付完成或失败时通知订单服务
+type PaymentResultMessage struct {
+	PayOrderID string `json:"pay_order_id"` // 支付单号
+	BizID      string `json:"biz_id"`       // 订单编号
+	UserID     int    `json:"user_id"`
+	Amount     int    `json:"amount"`
+	Result     string `json:"result"`               // SUCCESS / FAILED
+	PayMethod  string `json:"pay_method,omitempty"` // 支付方式，为空时为 BALANCE
+	ErrorMsg   string `json:"error_msg,omitempty"`  // 支付失败原因
+}
+
+// RefundRequest 用户申请退款，Items 为空表示退还剩余全部商品
+type RefundRequest struct {
+	Reason        string               `json:"reason"`          // 退款原因
+	Items         []*RefundItemRequest `json:"items"`           // 退款商品
+	ToStoreCredit bool                 `json:"to_store_credit"` // 是否退为店铺余额，否则退回原支付方式
+}
+
+type RefundItemRequest struct {
+	OrderProductID int `json:"order_product_id"` // 订单商品ID，即订单详情中 order_items 的 id
+	Quantity       int `json:"quantity"`         // 退款数量
+}
+
+type RejectRefundRequest struct {
+	Reason string `json:"reason"` // 拒绝原因
+}
+
+type RefundDetail struct {
+	RefundNo      string              `json:"refund_no"`       // 退款单号
+	Amount        int                 `json:"amount"`          // 退款金额
+	Status        int                 `json:"status"`          // 退款状态
+	StatusName    string              `json:"status_name"`     // 退款状态名称
+	Reason        string              `json:"reason"`          // 退款原因
+	ToStoreCredit bool                `json:"to_store_credit"` // 是否退为店铺余额
+	RejectReason  string              `json:"reject_reason"`   // 拒绝原因
+	ReviewTime    time.Time           `json:"review_time"`     // 审核时间
+	CreateTime    time.Time           `json:"create_time"`     // 申请时间
+	Items         []*RefundItemDetail `json:"items"`           // 退款商品
+}
+
+type RefundItemDetail struct {
+	OrderProductID int `json:"order_product_id"` // 订单商品ID
+	ProductID      int `json:"product_id"`       // 商品ID
+	Quantity       int `json:"quantity"`         // 退款数量
+	Amount         int `json:"amount"`           // 退款金额
+}
+
+// CreateReturnRequest 用户申请退货，Items 为空表示退回剩余全部商品
+type CreateReturnRequest struct {
+	Reason        string               `json:"reason"`          // 退货原因
+	PhotoUrls     []string             `json:"photo_urls"`      // 商品照片
+	Items         []*RefundItemRequest `json:"items"`           // 退货商品
+	ToStoreCredit bool                 `json:"to_store_credit"` // 收货后是否退为店铺余额，否则退回原支付方式
+}
+
+type ApproveReturnRequest struct {
+	ReturnLabel string `json:"return_label"` // 退货面单
+	TrackingNo  string `json:"tracking_no"`  // 退货物流单号
+}
+
+type RejectReturnRequest struct {
+	Reason string `json:"reason"` // 拒绝原因
+}
+
+type ReceiveReturnRequest struct {
+	Restock bool `json:"restock"` // 是否将退回的商品加回库存
+}
+
+type ReturnDetail struct {
+	ReturnNo         string              `json:"return_no"`          // 退货单号
+	Status           int                 `json:"status"`             // 退货状态
+	StatusName       string              `json:"status_name"`        // 退货状态名称
+	Reason           string              `json:"reason"`             // 退货原因
+	PhotoUrls        []string            `json:"photo_urls"`         // 商品照片
+	RefundAmount     int                 `json:"refund_amount"`      // 收货后的退款金额
+	ReturnLabel      string              `json:"return_label"`       // 退货面单
+	ReturnTrackingNo string              `json:"return_tracking_no"` // 退货物流单号
+	RejectReason     string              `json:"reject_reason"`      // 拒绝原因
+	RefundNo         string              `json:"refund_no"`          // 退款单号
+	Restock          bool                `json:"restock"`            // 是否回补库存
+	ReviewTime       time.Time           `json:"review_time"`        // 审核时间
+	ReceiveTime      time.Time           `json:"receive_time"`       // 收货时间
+	CreateTime       time.Time           `json:"create_time"`        // 申请时间
+	Items            []*ReturnItemDetail `json:"items"`              // 退货商品
+}
+
+type ReturnItemDetail struct {
+	OrderProductID int `json:"order_product_id"` // 订单商品ID
+	ProductID      int `json:"product_id"`       // 商品ID
+	Quantity       int `json:"quantity"`         // 退货数量
+}
+
+// IdempotencyRecord 下单请求的幂等记录，OrderNo 为空表示请求仍在处理中
+type IdempotencyRecord struct {
+	RequestHash string `json:"request_hash"`
+	OrderNo     string `json:"order_no"`
+}
+
 type OrderNoAndUserId struct {
 	OrderNo string `json:"order_no"`
 	UserID  int    `json:"user_id"`
 }
 
 type OrderStats struct {
-	TotalOrders      int `json:"total_orders"`
-	TotalSales       int `json:"total_sales"`
-	TotalCustomers   int `json:"total_customers"`
-	AvgSalesPerOrder int `json:"avg_sales_per_order"`
+	TotalOrders      int              `json:"total_orders"`
+	TotalSales       int              `json:"total_sales"` // 基础货币
+	TotalCustomers   int              `json:"total_customers"`
+	AvgSalesPerOrder int              `json:"avg_sales_per_order"`
+	BaseCurrency     string           `json:"base_currency"`
+	SalesByCurrency  []*CurrencySales `json:"sales_by_currency"` // 按订单货币分组
+}
+
+// CurrencySales 一种订单货币的销售额，记录货币之前的订单计入基础货币
+type CurrencySales struct {
+	Currency    string `json:"currency"`
+	TotalOrders int    `json:"total_orders"`
+	TotalSales  int    `json:"total_sales"` // 基础货币
+	LocalSales  int    `json:"local_sales"` // 按各订单下单时的汇率换算为该货币
+}
+
+// ListDiscrepancyRequest 对账差异查询条件
+type ListDiscrepancyRequest struct {
+	Type      int       `form:"type"`       // 差异类型筛选 (1-缺少支付单； 2-支付单无对应已付款订单； 3-金额不一致)
+	OrderNo   string    `form:"order_no"`   // 订单号筛选
+	StartTime time.Time `form:"start_time"` // 发现时间开始范围 (RFC3339)
+	EndTime   time.Time `form:"end_time"`   // 发现时间结束范围 (RFC3339)
+	Limit     int       `form:"limit"`      // 分页限制
+	Offset    int       `form:"offset"`     // 分页偏移
+}
+
+type ListDiscrepancyResponse struct {
+	Discrepancies []*DiscrepancyInfo `json:"discrepancies"`
+	Total         int                `json:"total"`
+}
+
+type DiscrepancyInfo struct {
+	ID              int       `json:"id"`
+	OrderNo         string    `json:"order_no"`          // 订单编号
+	Type            int       `json:"type"`              // 差异类型
+	TypeName        string    `json:"type_name"`         // 差异类型名称
+	UserID          int       `json:"user_id"`           // 下单用户
+	OrderStatus     int       `json:"order_status"`      // 对账时的订单状态
+	OrderStatusName string    `json:"order_status_name"` // 对账时的订单状态名称
+	OrderAmount     int       `json:"order_amount"`      // 订单金额
+	PaidAmount      int       `json:"paid_amount"`       // 支付服务中的支付金额合计
+	PayOrderIDs     []string  `json:"pay_order_ids"`     // 支付单号
+	OrderTime       time.Time `json:"order_time"`        // 下单时间
+	CreateTime      time.Time `json:"create_time"`       // 发现时间
+}
+
+// CreateCouponRequest 商家创建优惠券
+type CreateCouponRequest struct {
+	Code         string    `json:"code" binding:"required,max=64"` // 优惠码
+	Name         string    `json:"name" binding:"max=128"`         // 名称
+	Type         int       `json:"type" binding:"required"`        // 类型 (1-比例折扣； 2-固定减免； 3-免运费； 4-买X送Y)
+	Value        int       `json:"value"`                          // 比例折扣为折扣百分比 (1-100)，固定减免为减免金额
+	MaxDiscount  int       `json:"max_discount"`                   // 比例折扣的最高减免金额，0 表示不限
+	MinSpend     int       `json:"min_spend"`                      // 最低消费
+	ProductID    int       `json:"product_id"`                     // 买X送Y 限定的商品，0 表示所有商品
+	BuyQuantity  int       `json:"buy_quantity"`                   // 买X送Y 中的 X
+	GetQuantity  int       `json:"get_quantity"`                   // 买X送Y 中的 Y
+	UsageLimit   int       `json:"usage_limit"`                    // 总使用次数上限，0 表示不限
+	PerUserLimit int       `json:"per_user_limit"`                 // 每个用户的使用次数上限，0 表示不限
+	StartTime    time.Time `json:"start_time"`                     // 生效时间，为空表示立即生效
+	EndTime      time.Time `json:"end_time"`                       // 失效时间，为空表示长期有效
+}
+
+// ListCouponRequest 优惠券查询条件
+type ListCouponRequest struct {
+	Status int `form:"status"` // 状态筛选 (1-可用； 2-已停用)
+	Type   int `form:"type"`   // 类型筛选
+	Limit  int `form:"limit"`  // 分页限制
+	Offset int `form:"offset"` // 分页偏移
+}
+
+type ListCouponResponse struct {
+	Coupons []*CouponInfo `json:"coupons"`
+	Total   int           `json:"total"`
+}
+
+// UpdateCouponStatusRequest 启用或停用优惠券
+type UpdateCouponStatusRequest struct {
+	Status int `json:"status" binding:"required"` // 1-可用； 2-已停用
+}
+
+type CouponInfo struct {
+	ID           int       `json:"id"`
+	Code         string    `json:"code"`           // 优惠码
+	Name         string    `json:"name"`           // 名称
+	Type         int       `json:"type"`           // 类型
+	TypeName     string    `json:"type_name"`      // 类型名称
+	Value        int       `json:"value"`          // 折扣百分比或减免金额
+	MaxDiscount  int       `json:"max_discount"`   // 比例折扣的最高减免金额
+	MinSpend     int       `json:"min_spend"`      // 最低消费
+	ProductID    int       `json:"product_id"`     // 买X送Y 限定的商品
+	BuyQuantity  int       `json:"buy_quantity"`   // 买X送Y 中的 X
+	GetQuantity  int       `json:"get_quantity"`   // 买X送Y 中的 Y
+	UsageLimit   int       `json:"usage_limit"`    // 总使用次数上限
+	PerUserLimit int       `json:"per_user_limit"` // 每个用户的使用次数上限
+	UsedCount    int       `json:"used_count"`     // 已使用次数
+	Status       int       `json:"status"`         // 状态
+	StatusName   string    `json:"status_name"`    // 状态名称
+	StartTime    time.Time `json:"start_time"`     // 生效时间
+	EndTime      time.Time `json:"end_time"`       // 失效时间
+	CreateTime   time.Time `json:"create_time"`    // 创建时间
+}
+
+// ShippingQuoteRequest 购物车页面在下单前查询运费
+type ShippingQuoteRequest struct {
+	ReceiverCountry string           `json:"receiver_country"`  // 收货人国家
+	ReceiverZipCode int              `json:"receiver_zip_code"` // 收货人邮政编码
+	OrderItemList   []*OrderItemInfo `json:"order_item_list"`   // 商品列表，只使用商品ID和数量
+}
+
+// ShippingQuote 运费明细，重量单位为克
+type ShippingQuote struct {
+	Zone             string `json:"zone"`              // 运费区域
+	Weight           int    `json:"weight"`            // 实际重量
+	ChargeableWeight int    `json:"chargeable_weight"` // 计费重量，取实际重量和体积重中较大者
+	BaseFee          int    `json:"base_fee"`          // 首重运费
+	WeightFee        int    `json:"weight_fee"`        // 续重运费
+	FragileSurcharge int    `json:"fragile_surcharge"` // 易碎商品附加费
+	FreeShipping     bool   `json:"free_shipping"`     // 是否达到免运费门槛，免首重及续重运费
+	ShippingFee      int    `json:"shipping_fee"`      // 运费合计
+}
+
+// OrderQuoteItem 报价中的商品，单价以商品服务为准
+type OrderQuoteItem struct {
+	ProductID      int    `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	Price          int    `json:"price"`           // 当前单价
+	ExpectedPrice  int    `json:"expected_price"`  // 客户端提交的单价
+	PriceChanged   bool   `json:"price_changed"`   // 客户端提交的单价与当前单价不一致
+	Quantity       int    `json:"quantity"`        // 商品数量
+	TotalPrice     int    `json:"total_price"`     // 商品总价
+	DiscountAmount int    `json:"discount_amount"` // 分摊到该商品的优惠金额
+	TaxRate        int    `json:"tax_rate"`        // 适用税率，万分之一
+	TaxAmount      int    `json:"tax_amount"`      // 分摊到该商品的税额
+	Stock          int    `json:"stock"`           // 当前库存
+	Available      bool   `json:"available"`       // 库存是否足够
+}
+
+// OrderQuote 下单前的价格明细，与创建订单使用相同的计算过程
+type OrderQuote struct {
+	Items           []*OrderQuoteItem      `json:"items"`
+	ItemTotalAmount int                    `json:"item_total_amount"` // 商品金额
+	ShippingFee     int                    `json:"shipping_fee"`      // 运费
+	Shipping        *ShippingQuote         `json:"shipping"`          // 运费明细
+	DiscountAmount  int                    `json:"discount_amount"`   // 优惠减免金额，包括商品和运费
+	CouponCode      string                 `json:"coupon_code"`       // 使用的优惠码
+	Discounts       []*OrderDiscountDetail `json:"discounts"`         // 优惠明细
+	Tax             int                    `json:"tax"`               // 税费
+	TaxRegion       string                 `json:"tax_region"`        // 匹配的税率规则
+	TaxRate         int                    `json:"tax_rate"`          // 标准税率，万分之一
+	TaxInclusive    bool                   `json:"tax_inclusive"`     // 商品价格是否已含税
+	TotalAmount     int                    `json:"total_amount"`      // 应付总金额，可作为 expected_total_amount 提交
+	Available       bool                   `json:"available"`         // 所有商品库存是否足够
+	Currency        string                 `json:"currency"`          // 订单货币
+	BaseCurrency    string                 `json:"base_currency"`     // 金额字段使用的基础货币
+	ExchangeRate    int64                  `json:"exchange_rate"`     // 当前汇率，下单时保存为快照
+	Amounts         *OrderAmounts          `json:"amounts"`           // 订单货币的格式化金额
+}
+
+// Money 以最小货币单位表示的金额
+type Money struct {
+	Amount    int    `json:"amount"`
+	Currency  string `json:"currency"`
+	Formatted string `json:"formatted"` // 如 S$12.50
+}
+
+// OrderAmounts 订单金额的格式化展示
+type OrderAmounts struct {
+	ItemTotalAmount Money `json:"item_total_amount"` // 商品金额
+	ShippingFee     Money `json:"shipping_fee"`      // 运费
+	Tax             Money `json:"tax"`               // 税费
+	DiscountAmount  Money `json:"discount_amount"`   // 优惠减免金额
+	TotalAmount     Money `json:"total_amount"`      // 总金额
+	PayAmount       Money `json:"pay_amount"`        // 实际支付金额
+	RefundedAmount  Money `json:"refunded_amount"`   // 已退款金额
+}
+
+// InvoiceParty 发票上的商家或收货方
+type InvoiceParty struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Country string `json:"country"`
+	ZipCode int    `json:"zip_code"`
+	Phone   string `json:"phone"`
+	TaxID   string `json:"tax_id"` // 税务登记号
+}
+
+// InvoiceLine 发票明细行，贷项通知单中为退还的数量和金额
+type InvoiceLine struct {
+	ProductID   int    `json:"product_id"` // 运费及调整行为 0
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   int    `json:"unit_price"`
+	Discount    int    `json:"discount"`   // 优惠减免
+	NetAmount   int    `json:"net_amount"` // 优惠后金额，含税价格时包含税额
+	TaxRate     int    `json:"tax_rate"`   // 税率，万分之一
+	TaxAmount   int    `json:"tax_amount"` // 税额
+}
+
+// InvoiceTaxLine 按税率汇总的税额
+type InvoiceTaxLine struct {
+	TaxRate       int `json:"tax_rate"`       // 税率，万分之一
+	TaxableAmount int `json:"taxable_amount"` // 不含税金额
+	TaxAmount     int `json:"tax_amount"`     // 税额
+}
+
+// Invoice 发票或贷项通知单的内容，开具时保存为快照，之后订单变化不影响已开具的发票
+type Invoice struct {
+	InvoiceNo        string            `json:"invoice_no"`         // 发票编号
+	Type             int               `json:"type"`               // 类型 (1-发票； 2-贷项通知单)
+	TypeName         string            `json:"type_name"`          // 类型名称
+	OrderNo          string            `json:"order_no"`           // 订单编号
+	RefundNo         string            `json:"refund_no"`          // 贷项通知单对应的退款单号
+	RelatedInvoiceNo string            `json:"related_invoice_no"` // 贷项通知单引用的原发票编号
+	Reason           string            `json:"reason"`             // 退款原因
+	UserID           int               `json:"user_id"`            // 下单用户
+	IssueTime        time.Time         `json:"issue_time"`         // 开具时间
+	Seller           *InvoiceParty     `json:"seller"`             // 商家
+	BillTo           *InvoiceParty     `json:"bill_to"`            // 收货方
+	Lines            []*InvoiceLine    `json:"lines"`              // 明细
+	TaxBreakdown     []*InvoiceTaxLine `json:"tax_breakdown"`      // 按税率汇总
+	TaxRegion        string            `json:"tax_region"`         // 税率规则
+	TaxInclusive     bool              `json:"tax_inclusive"`      // 商品价格是否已含税
+	Currency         string            `json:"currency"`           // 金额使用的基础货币
+	DiscountAmount   int               `json:"discount_amount"`    // 优惠减免合计
+	Tax              int               `json:"tax"`                // 税额合计
+	TotalAmount      int               `json:"total_amount"`       // 含税总金额，贷项通知单为退款金额
+	OrderCurrency    string            `json:"order_currency"`     // 订单货币
+	ExchangeRate     int64             `json:"exchange_rate"`      // 下单时的汇率快照
+	PayMethod        string            `json:"pay_method"`         // 支付方式
+	PayTransactionID string            `json:"pay_transaction_id"` // 支付单号
+}
+
+// CreateGiftCardRequest 商家发行礼品卡
+type CreateGiftCardRequest struct {
+	Code       string    `json:"code" binding:"max=64"`     // 卡号，为空时随机生成
+	Amount     int       `json:"amount" binding:"required"` // 面额
+	ExpireTime time.Time `json:"expire_time"`               // 过期时间，为空表示长期有效
+}
+
+// UpdateGiftCardStatusRequest 启用或停用礼品卡
+type UpdateGiftCardStatusRequest struct {
+	Status int `json:"status" binding:"required"` // 1-可用； 2-已停用
+}
+
+type GiftCardInfo struct {
+	Code           string                     `json:"code"`                   // 卡号
+	Type           int                        `json:"type"`                   // 类型
+	TypeName       string                     `json:"type_name"`              // 类型名称
+	InitialBalance int                        `json:"initial_balance"`        // 面额，店铺余额为 0
+	Balance        int                        `json:"balance"`                // 可用余额
+	Status         int                        `json:"status"`                 // 状态
+	StatusName     string                     `json:"status_name"`            // 状态名称
+	ExpireTime     time.Time                  `json:"expire_time"`            // 过期时间
+	CreateTime     time.Time                  `json:"create_time"`            // 创建时间
+	Transactions   []*GiftCardTransactionInfo `json:"transactions,omitempty"` // 最近的余额流水
+}
+
+type GiftCardTransactionInfo struct {
+	OrderNo      string    `json:"order_no"`      // 订单编号
+	RefundNo     string    `json:"refund_no"`     // 退款单号
+	Type         int       `json:"type"`          // 流水类型
+	TypeName     string    `json:"type_name"`     // 流水类型名称
+	Amount       int       `json:"amount"`        // 余额变动，扣减为负数
+	BalanceAfter int       `json:"balance_after"` // 变动后的余额
+	CreateTime   time.Time `json:"create_time"`   // 时间
+}
+
+// DeadLetterMessage 重试后仍无法处理的消息，原样包装后写入 <topic>.dlq，可通过重放接口重新投递到原 topic
+type DeadLetterMessage struct {
+	Topic     string    `json:"topic"`     // 原 topic
+	Partition int       `json:"partition"` // 原分区
+	Offset    int64     `json:"offset"`    // 原 offset
+	Key       string    `json:"key"`       // 原消息 key
+	Value     string    `json:"value"`     // 原消息内容
+	Error     string    `json:"error"`     // 最后一次处理失败的原因
+	Attempts  int       `json:"attempts"`  // 处理次数，无法解析的消息为 0
+	FailedAt  time.Time `json:"failed_at"` // 写入死信队列的时间
+}
+
+// ReplayDeadLetterRequest 重放死信队列
+type ReplayDeadLetterRequest struct {
+	Limit int `form:"limit"` // 最多重放的条数
+}
+
+// ReplayDeadLetterResponse 死信队列重放结果
+type ReplayDeadLetterResponse struct {
+	Topic    string `json:"topic"`    // 原 topic
+	Replayed int    `json:"replayed"` // 重新投递到原 topic 的消息数
+	Skipped  int    `json:"skipped"`  // 无法解析而丢弃的死信数
 }
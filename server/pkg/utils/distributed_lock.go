@@ -18,6 +18,9 @@ type Locker interface {
 
 	// Unlock releases the distributed lock
 	Unlock(ctx context.Context) error
+
+	// Renew resets the lock TTL, returns ErrLockNotHeld if the lock has expired or is held by another instance
+	Renew(ctx context.Context) error
 }
 
 // DistributedLock represents a Redis-based distributed lock
@@ -43,6 +46,15 @@ else
 end
 `
 
+// Lua script for atomic renew (only extend the TTL if the lock is held by this instance)
+const renewScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+    return redis.call("pexpire", KEYS[1], ARGV[2])
+else
+    return 0
+end
+`
+
 // GetDistributedLock creates a new distributed lock instance
 // key: the lock key in Redis
 // value: unique identifier for this lock holder (e.g., UUID or instance ID)
@@ -91,3 +103,20 @@ func (l *DistributedLock) Unlock(ctx context.Context) error {
 
 	return nil
 }
+
+// Renew resets the lock TTL using Lua script, holders running longer than the TTL
+// call it between units of work so that another instance can not take over the lock
+func (l *DistributedLock) Renew(ctx context.Context) error {
+	script := goredis.NewScript(renewScript)
+	result, err := script.Run(ctx, redis.RedisClient, []string{l.key}, l.value, l.expiration.Milliseconds()).Result()
+	if err != nil {
+		return err
+	}
+
+	renewed, ok := result.(int64)
+	if !ok || renewed == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/events"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// WrapEvent 按 topic 的事件契约校验 value，并包装为以 subject 为主题的事件信封；
+// 没有事件契约的 topic 原样返回
+func WrapEvent(topic, subject, value string) (string, error) {
+	if _, ok := events.Lookup(topic); !ok {
+		return value, nil
+	}
+	envelope, err := events.New(topic, uuid.New().String(), time.Now(), subject, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	return JSONEncode(envelope)
+}
+
+// decodeStatusChangedMsg 解析 order_status_changed 消息，事件ID和发生时间取自信封；
+// 兼容信封上线前生产的、没有信封的消息
+func decodeStatusChangedMsg(msgRaw kafka.Message) (*types.OrderStatusChangedMessage, error) {
+	var msg types.OrderStatusChangedMessage
+	envelope, err := events.Parse(msgRaw.Value)
+	if err != nil {
+		if err = JSONDecode(string(msgRaw.Value), &msg); err != nil {
+			return nil, err
+		}
+		fillLegacyEvent(&msg, msgRaw)
+		return &msg, nil
+	}
+	if err = JSONDecode(string(envelope.Data), &msg); err != nil {
+		return nil, err
+	}
+	msg.EventID, msg.OccurredAt = envelope.ID, envelope.Time
+	return &msg, nil
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/common/events"
+)
+
+// TestWrapEvent tests contract topics are validated and wrapped while other topics pass through
+func TestWrapEvent(t *testing.T) {
+	value, err := WrapEvent(ORDER_STATUS_CHANGED_TOPIC, "ORDER001", `{"order_no":"ORDER001","user_id":1,"current_status":2,"remark":""}`)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	envelope, err := events.Parse([]byte(value))
+	if err != nil || envelope.Subject != "ORDER001" || envelope.Source != events.SOURCE || envelope.SchemaVersion != "1.0" {
+		t.Errorf("Unexpected envelope: %+v, err: %v", envelope, err)
+	}
+
+	if _, err = WrapEvent(ORDER_STATUS_CHANGED_TOPIC, "ORDER001", `{"order_no":"ORDER001","status":2}`); !errors.Is(err, events.ErrInvalidData) {
+		t.Errorf("Expected ErrInvalidData, got: %v", err)
+	}
+
+	refundMsg := `{"order_no":"ORDER001","amount":100}`
+	if value, err = WrapEvent("order_refund", "ORDER001", refundMsg); err != nil || value != refundMsg {
+		t.Errorf("Expected order_refund unchanged, got: %s, err: %v", value, err)
+	}
+}
+
+// TestDecodeStatusChangedMsg tests the event ID and time come from the envelope, with bare legacy payloads still accepted
+func TestDecodeStatusChangedMsg(t *testing.T) {
+	value, _ := WrapEvent(ORDER_STATUS_CHANGED_TOPIC, "ORDER001", `{"order_no":"ORDER001","user_id":1,"current_status":2,"remark":"r"}`)
+	envelope, _ := events.Parse([]byte(value))
+
+	msg, err := decodeStatusChangedMsg(statusChangedMessage(0, value))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if msg.EventID != envelope.ID || !msg.OccurredAt.Equal(envelope.Time) || msg.OrderNo != "ORDER001" || msg.CurrentStatus != 2 || msg.Remark != "r" {
+		t.Errorf("Unexpected message: %+v", msg)
+	}
+
+	legacy := statusChangedMessage(5, `{"order_no":"ORDER001","current_status":2}`)
+	legacy.Time = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if msg, err = decodeStatusChangedMsg(legacy); err != nil || msg.EventID == "" || !msg.OccurredAt.Equal(legacy.Time) {
+		t.Errorf("Expected legacy message with derived event, got: %+v, err: %v", msg, err)
+	}
+
+	if _, err = decodeStatusChangedMsg(kafka.Message{Value: []byte(`not json`)}); err == nil {
+		t.Error("Expected error for malformed message, got nil")
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base32"
 	"fmt"
 	"sync"
 	"time"
@@ -13,10 +15,32 @@ var (
 
 // GenerateOrderID 生成唯一订单号，格式 No-20251004-163102-001
 func GenerateOrderID() string {
+	return generateID("No-")
+}
+
+// GenerateRefundNo 生成唯一退款单号，格式 Rf-20251004-163102-001
+func GenerateRefundNo() string {
+	return generateID("Rf-")
+}
+
+// GenerateReturnNo 生成唯一退货单号，格式 Rt-20251004-163102-001
+func GenerateReturnNo() string {
+	return generateID("Rt-")
+}
+
+// GenerateGiftCardCode 生成随机礼品卡号，格式 GC-XXXX-XXXX-XXXX-XXXX
+// 礼品卡凭卡号使用，不能像订单号一样按时间递增以免被猜到
+func GenerateGiftCardCode() string {
+	buf := make([]byte, 10)
+	_, _ = rand.Read(buf)
+	code := base32.StdEncoding.EncodeToString(buf)
+	return fmt.Sprintf("GC-%s-%s-%s-%s", code[0:4], code[4:8], code[8:12], code[12:16])
+}
+
+func generateID(prefix string) string {
 	now := time.Now()
-	prefix := "No-"
-	timeStr := now.Format("20060102-150405") // 年月日-时分秒
-	key := now.Format("20060102150405")      // 用于计数的秒级key
+	timeStr := now.Format("20060102-150405")     // 年月日-时分秒
+	key := prefix + now.Format("20060102150405") // 用于计数的秒级key
 
 	orderIdMutex.Lock()
 	defer orderIdMutex.Unlock()
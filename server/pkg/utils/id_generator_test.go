@@ -20,3 +20,32 @@ func TestGenerateOrderID(t *testing.T) {
 		idSet[id] = struct{}{}
 	}
 }
+
+func TestGenerateRefundNo(t *testing.T) {
+	re := regexp.MustCompile(`^Rf-\d{8}-\d{6}-\d{3}$`)
+	first, second := GenerateRefundNo(), GenerateRefundNo()
+	if !re.MatchString(first) {
+		t.Errorf("RefundNo format error: %s", first)
+	}
+	if first == second {
+		t.Errorf("Duplicate RefundNo generated: %s", first)
+	}
+}
+
+func TestGenerateReturnNo(t *testing.T) {
+	re := regexp.MustCompile(`^Rt-\d{8}-\d{6}-\d{3}$`)
+	if id := GenerateReturnNo(); !re.MatchString(id) {
+		t.Errorf("ReturnNo format error: %s", id)
+	}
+}
+
+func TestGenerateGiftCardCode(t *testing.T) {
+	re := regexp.MustCompile(`^GC-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}$`)
+	first, second := GenerateGiftCardCode(), GenerateGiftCardCode()
+	if !re.MatchString(first) {
+		t.Errorf("GiftCardCode format error: %s", first)
+	}
+	if first == second {
+		t.Errorf("Duplicate GiftCardCode generated: %s", first)
+	}
+}
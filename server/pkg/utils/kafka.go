@@ -3,142 +3,271 @@ package utils
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/metrics"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
 )
 
+// Writer 发送消息；返回 nil 表示消息已被接受：同步写入时 broker 已确认，
+// 异步写入时已进入发送缓冲区，之后的发送失败只记录日志和指标
 type Writer interface {
 	SendMsg(ctx context.Context, topic, key, value string) error
 }
 
-type MyWriter struct {
-	kafkaWriter *kafka.Writer
+// TxWriter 可以绑定到数据库事务的 Writer，消息随事务一起提交或回滚
+type TxWriter interface {
+	Writer
+	WithTx(tx *gorm.DB) TxWriter
 }
 
-var (
-	writer     *MyWriter
-	writerOnce sync.Once
-	reader     *MyConsumer
-	readerOnce sync.Once
+const (
+	PAYMENT_RESULT_TOPIC        = "payment_result"
+	PAYMENT_RESULT_MAX_ATTEMPTS = 3
+	PAYMENT_RESULT_RETRY_DELAY  = time.Second
+
+	ORDER_STATUS_CHANGED_TOPIC    = "order_status_changed"
+	ORDER_STATUS_LOG_MAX_ATTEMPTS = 5
+	ORDER_STATUS_LOG_RETRY_DELAY  = 200 * time.Millisecond
+	ORDER_STATUS_LOG_RETRY_MAX    = 5 * time.Second
+
+	DEAD_LETTER_TOPIC_SUFFIX        = ".dlq"
+	DEAD_LETTER_RETRY_MAX           = 5 * time.Second
+	DEAD_LETTER_REPLAY_GROUP_PREFIX = "consume_group_order_dlq_replay_"
+)
+
+// 消费指标的 result 和 reason 标签
+const (
+	CONSUME_RESULT_PROCESSED     = "processed"
+	CONSUME_RESULT_DEAD_LETTERED = "dead_lettered"
+	CONSUME_RESULT_REPLAYED      = "replayed"
+	CONSUME_RESULT_DUPLICATE     = "duplicate"
+	CONSUME_FAILURE_FETCH        = "fetch"
+	CONSUME_FAILURE_PARSE        = "parse"
+	CONSUME_FAILURE_WRITE        = "write"
+	CONSUME_FAILURE_COMMIT       = "commit"
+	CONSUME_FAILURE_DEAD_LETTER  = "dead_letter"
 )
 
-type MyConsumer struct {
-	r           *kafka.Reader
+// MessageReader kafka.Reader 中消费者用到的方法，offset 只在处理完成后显式提交
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// OrderStatusLogHandler 处理 order_status_changed 消息，写入订单状态日志；
+// 写库失败时有限次重试，无法解析或重试耗尽的消息写入死信队列后再提交 offset
+type OrderStatusLogHandler struct {
 	orderLogDao dao.OrderLogDao
+	dlqWriter   Writer
+	retryDelay  time.Duration
+}
+
+// PaymentResultHandler 处理 payment_result 消息，由订单服务实现
+type PaymentResultHandler interface {
+	HandlePaymentResult(ctx context.Context, msg *types.PaymentResultMessage) error
+}
+
+// PaymentResultMessageHandler 处理支付服务的 payment_result 消息；
+// 处理失败时有限次重试，无法解析或重试耗尽的结果写入死信队列后再提交 offset
+type PaymentResultMessageHandler struct {
+	handler    PaymentResultHandler
+	dlqWriter  Writer
+	retryDelay time.Duration
 }
 
 func InitKafka() {
 	initKafkaWriter()
-	initKafkaReader()
 }
 
+// CloseKafka 等待消费者处理完已取出的消息后关闭 writer，消费者写死信队列时仍需要 writer
 func CloseKafka() {
+	waitConsumers()
 	closeKafkaWriter()
-	closeKafkaReader()
-}
-
-func initKafkaWriter() {
-	writerOnce.Do(func() {
-		brokerAddr := fmt.Sprintf("%s:%d", config.Config.KafkaConfig.Host, config.Config.KafkaConfig.Port)
-		kafkaWriter := &kafka.Writer{
-			Addr:                   kafka.TCP(brokerAddr),
-			Balancer:               &kafka.LeastBytes{},
-			RequiredAcks:           kafka.RequireAll,
-			Async:                  false,
-			AllowAutoTopicCreation: true,
-		}
-		writer = &MyWriter{
-			kafkaWriter: kafkaWriter,
-		}
-	})
 }
 
-func closeKafkaWriter() {
-	if writer != nil && writer.kafkaWriter != nil {
-		if err := writer.kafkaWriter.Close(); err != nil {
-			log.Logger.Errorf("CloseKafkaWriter: failed to close writer, err %s", err.Error())
-		}
-	}
+func brokerAddr() string {
+	return fmt.Sprintf("%s:%d", config.Config.KafkaConfig.Host, config.Config.KafkaConfig.Port)
 }
 
-func (myWriter *MyWriter) SendMsg(ctx context.Context, topic, key, value string) error {
-	go func() {
-		err := myWriter.kafkaWriter.WriteMessages(ctx, kafka.Message{
-			Topic: topic, // 这里可以覆盖默认 topic
-			Key:   []byte(key),
-			Value: []byte(value),
-		})
-		if err != nil {
-			log.Logger.Errorf("SendMsg: failed, err %s", err.Error())
-		}
-	}()
-	return nil
+func NewOrderStatusLogHandler() *OrderStatusLogHandler {
+	return &OrderStatusLogHandler{
+		orderLogDao: dao.GetOrderLogDao(),
+		dlqWriter:   writer,
+		retryDelay:  ORDER_STATUS_LOG_RETRY_DELAY,
+	}
 }
 
-func GetWriter() *MyWriter {
-	return writer
+// NewDeadLetterReader 读取 topic 对应死信队列的消费者，用于重放，使用完后需要关闭
+func NewDeadLetterReader(topic string) MessageReader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  []string{brokerAddr()},
+		GroupID:  DEAD_LETTER_REPLAY_GROUP_PREFIX + topic,
+		Topic:    DeadLetterTopic(topic),
+		MaxBytes: 10e6,
+	})
 }
 
-func initKafkaReader() {
-	brokerAddr := fmt.Sprintf("%s:%d", config.Config.KafkaConfig.Host, config.Config.KafkaConfig.Port)
-	readerOnce.Do(func() {
-		kafkaReader := kafka.NewReader(kafka.ReaderConfig{
-			Brokers:   []string{brokerAddr},
-			GroupID:   "consume_group_order_status_change",
-			Topic:     "order_status_changed",
-			Partition: 0,
-			MaxBytes:  10e6,
-		})
-		reader = &MyConsumer{
-			r:           kafkaReader,
-			orderLogDao: dao.GetOrderLogDao(),
-		}
-	})
+// DeadLetterTopic 返回 topic 对应的死信队列
+func DeadLetterTopic(topic string) string {
+	return topic + DEAD_LETTER_TOPIC_SUFFIX
 }
 
-func closeKafkaReader() {
-	if reader != nil && reader.r != nil {
-		if err := reader.r.Close(); err != nil {
-			log.Logger.Errorf("failed to close reader: %s", err.Error())
+// HandleMessage 写入一条状态日志，写入成功、重复或转入死信队列后返回 nil；只有 ctx 取消时返回错误
+func (mc *OrderStatusLogHandler) HandleMessage(ctx context.Context, msgRaw kafka.Message) error {
+	log.Logger.Infof("ConsumeOrderStatusChanged: get message: %s", string(msgRaw.Value))
+	msg, err := decodeStatusChangedMsg(msgRaw)
+	if err != nil {
+		log.Logger.Errorf("ConsumeOrderStatusChanged: parse json failed, offset: %d, err = %s", msgRaw.Offset, err.Error())
+		metrics.KafkaConsumeFailuresTotal.WithLabelValues(ORDER_STATUS_CHANGED_TOPIC, CONSUME_FAILURE_PARSE).Inc()
+		return sendDeadLetter(ctx, mc.dlqWriter, ORDER_STATUS_CHANGED_TOPIC, msgRaw, err, 0, mc.retryDelay)
+	}
+
+	created, err := mc.writeOrderLog(ctx, msg)
+	if err == nil {
+		result := CONSUME_RESULT_PROCESSED
+		if !created {
+			log.Logger.Infof("ConsumeOrderStatusChanged: skip duplicate event, orderNo: %s, eventId: %s", msg.OrderNo, msg.EventID)
+			result = CONSUME_RESULT_DUPLICATE
 		}
+		metrics.KafkaConsumedTotal.WithLabelValues(ORDER_STATUS_CHANGED_TOPIC, result).Inc()
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
+	log.Logger.Errorf("ConsumeOrderStatusChanged: give up after %d attempts, orderNo: %s, err = %s", ORDER_STATUS_LOG_MAX_ATTEMPTS, msg.OrderNo, err.Error())
+	return sendDeadLetter(ctx, mc.dlqWriter, ORDER_STATUS_CHANGED_TOPIC, msgRaw, err, ORDER_STATUS_LOG_MAX_ATTEMPTS, mc.retryDelay)
 }
 
-func GetReader() *MyConsumer {
-	return reader
+// fillLegacyEvent 为没有信封的消息补全事件信息：
+// 事件ID由消息在 kafka 中的位置确定，重复投递时保持不变；发生时间取消息的写入时间
+func fillLegacyEvent(msg *types.OrderStatusChangedMessage, msgRaw kafka.Message) {
+	if msg.EventID == "" {
+		msg.EventID = uuid.NewSHA1(uuid.NameSpaceURL, fmt.Appendf(nil, "kafka://%s/%d/%d", msgRaw.Topic, msgRaw.Partition, msgRaw.Offset)).String()
+	}
+	if msg.OccurredAt.IsZero() {
+		msg.OccurredAt = msgRaw.Time
+	}
 }
 
-func (mc *MyConsumer) ConsumeMessage(ctx context.Context) {
-	for {
-		msgRaw, err := mc.r.ReadMessage(ctx)
-		if err != nil {
-			log.Logger.Errorf("read message failed, err = %s", err.Error())
-			break
-		}
-		log.Logger.Infof("get message: %s", string(msgRaw.Value))
-		var msg types.OrderStatusChangedMessage
-		err = JSONDecode(string(msgRaw.Value), &msg)
-		if err != nil {
-			log.Logger.Errorf("parse json failed, err = %s", err.Error())
-			continue
-		}
-		_, err = mc.orderLogDao.Create(ctx, &model.OrderStatusLog{
+// writeOrderLog 幂等写入状态日志，失败时按指数退避重试，返回最后一次的错误；
+// created=false 表示该事件已写入过
+func (mc *OrderStatusLogHandler) writeOrderLog(ctx context.Context, msg *types.OrderStatusChangedMessage) (created bool, err error) {
+	delay := mc.retryDelay
+	for attempt := 1; ; attempt++ {
+		created, err = mc.orderLogDao.Upsert(ctx, &model.OrderStatusLog{
+			EventID:       msg.EventID,
 			OrderNo:       msg.OrderNo,
 			UserID:        msg.UserId,
 			CurrentStatus: msg.CurrentStatus,
 			Remark:        msg.Remark,
+			OccurredAt:    msg.OccurredAt,
 			CreateTime:    time.Now(),
 		})
-		if err != nil {
-			log.Logger.Errorf("create order log failed, err = %s", err.Error())
-			break
+		if err == nil {
+			return created, nil
+		}
+		log.Logger.Warnf("ConsumeOrderStatusChanged: create order log failed, attempt: %d, orderNo: %s, err = %s", attempt, msg.OrderNo, err.Error())
+		metrics.KafkaConsumeFailuresTotal.WithLabelValues(ORDER_STATUS_CHANGED_TOPIC, CONSUME_FAILURE_WRITE).Inc()
+		if attempt >= ORDER_STATUS_LOG_MAX_ATTEMPTS || !sleepCtx(ctx, delay) {
+			return false, err
+		}
+		delay = min(delay*2, ORDER_STATUS_LOG_RETRY_MAX)
+	}
+}
+
+// sendDeadLetter 将 topic 的消息写入其死信队列；写入失败时一直重试，期间不提交 offset，避免消息丢失
+func sendDeadLetter(ctx context.Context, dlqWriter Writer, topic string, msgRaw kafka.Message, cause error, attempts int, retryDelay time.Duration) error {
+	value, err := JSONEncode(types.DeadLetterMessage{
+		Topic:     msgRaw.Topic,
+		Partition: msgRaw.Partition,
+		Offset:    msgRaw.Offset,
+		Key:       string(msgRaw.Key),
+		Value:     string(msgRaw.Value),
+		Error:     cause.Error(),
+		Attempts:  attempts,
+		FailedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	dlqTopic := DeadLetterTopic(topic)
+	delay := retryDelay
+	for {
+		if err = dlqWriter.SendMsg(ctx, dlqTopic, string(msgRaw.Key), value); err == nil {
+			log.Logger.Warnf("DeadLetter: %s offset %d moved to %s, err = %s", topic, msgRaw.Offset, dlqTopic, cause.Error())
+			metrics.KafkaConsumedTotal.WithLabelValues(topic, CONSUME_RESULT_DEAD_LETTERED).Inc()
+			return nil
+		}
+		log.Logger.Errorf("DeadLetter: write %s failed, offset: %d, err = %s", dlqTopic, msgRaw.Offset, err.Error())
+		metrics.KafkaConsumeFailuresTotal.WithLabelValues(topic, CONSUME_FAILURE_DEAD_LETTER).Inc()
+		if !sleepCtx(ctx, delay) {
+			return ctx.Err()
+		}
+		delay = min(delay*2, DEAD_LETTER_RETRY_MAX)
+	}
+}
+
+// sleepCtx 等待 d，ctx 取消时提前返回 false
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func NewPaymentResultMessageHandler(handler PaymentResultHandler) *PaymentResultMessageHandler {
+	return &PaymentResultMessageHandler{
+		handler:    handler,
+		dlqWriter:  writer,
+		retryDelay: PAYMENT_RESULT_RETRY_DELAY,
+	}
+}
+
+// HandleMessage 处理一条支付结果，处理成功或转入死信队列后返回 nil；只有 ctx 取消时返回错误
+func (pc *PaymentResultMessageHandler) HandleMessage(ctx context.Context, msgRaw kafka.Message) error {
+	log.Logger.Infof("ConsumePaymentResult: get message: %s", string(msgRaw.Value))
+	var msg types.PaymentResultMessage
+	if err := JSONDecode(string(msgRaw.Value), &msg); err != nil {
+		log.Logger.Errorf("ConsumePaymentResult: parse json failed, offset: %d, err = %s", msgRaw.Offset, err.Error())
+		metrics.KafkaConsumeFailuresTotal.WithLabelValues(PAYMENT_RESULT_TOPIC, CONSUME_FAILURE_PARSE).Inc()
+		return sendDeadLetter(ctx, pc.dlqWriter, PAYMENT_RESULT_TOPIC, msgRaw, err, 0, pc.retryDelay)
+	}
+
+	err := pc.handleWithRetry(ctx, &msg)
+	if err == nil {
+		metrics.KafkaConsumedTotal.WithLabelValues(PAYMENT_RESULT_TOPIC, CONSUME_RESULT_PROCESSED).Inc()
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	log.Logger.Errorf("ConsumePaymentResult: give up after %d attempts, orderNo: %s, result: %s, err = %s", PAYMENT_RESULT_MAX_ATTEMPTS, msg.BizID, msg.Result, err.Error())
+	return sendDeadLetter(ctx, pc.dlqWriter, PAYMENT_RESULT_TOPIC, msgRaw, err, PAYMENT_RESULT_MAX_ATTEMPTS, pc.retryDelay)
+}
+
+// handleWithRetry 处理支付结果，失败时重试，返回最后一次的错误
+func (pc *PaymentResultMessageHandler) handleWithRetry(ctx context.Context, msg *types.PaymentResultMessage) error {
+	for attempt := 1; ; attempt++ {
+		err := pc.handler.HandlePaymentResult(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		log.Logger.Warnf("ConsumePaymentResult: handle failed, attempt: %d, orderNo: %s, err = %s", attempt, msg.BizID, err.Error())
+		metrics.KafkaConsumeFailuresTotal.WithLabelValues(PAYMENT_RESULT_TOPIC, CONSUME_FAILURE_WRITE).Inc()
+		if attempt >= PAYMENT_RESULT_MAX_ATTEMPTS || !sleepCtx(ctx, pc.retryDelay*time.Duration(attempt)) {
+			return err
 		}
 	}
 }
@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/metrics"
+)
+
+const (
+	CONSUMER_DEFAULT_CONCURRENCY   = 1
+	CONSUMER_DEFAULT_DRAIN_TIMEOUT = 10 * time.Second
+	CONSUMER_FETCH_RETRY_DELAY     = time.Second
+	// 每个 worker 已取出未处理的消息数上限，worker 忙时暂停拉取
+	CONSUMER_QUEUE_SIZE = 16
+)
+
+// defaultConsumers 未配置 kafka.consumers 时使用的消费组
+var defaultConsumers = []*config.KafkaConsumerConfig{
+	{GroupID: "consume_group_order_status_change", Topics: []string{ORDER_STATUS_CHANGED_TOPIC}},
+	{GroupID: "consume_group_order_payment_result", Topics: []string{PAYMENT_RESULT_TOPIC}},
+}
+
+// MessageHandler 处理一个 topic 的消息；返回 nil 后提交 offset。
+// 只有 ctx 取消时返回错误，此时不提交，消息由之后分配到该分区的消费者重新处理，因此处理需要幂等
+type MessageHandler interface {
+	HandleMessage(ctx context.Context, msg kafka.Message) error
+}
+
+var (
+	handlers      = map[string]MessageHandler{}
+	handlersLock  sync.Mutex
+	consumersWait sync.WaitGroup
+)
+
+// RegisterHandler 注册 topic 的消息处理器，需要在 StartConsumers 之前调用
+func RegisterHandler(topic string, handler MessageHandler) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	handlers[topic] = handler
+}
+
+// StartConsumers 按 kafka.consumers 配置启动消费组，ctx 取消后消费者停止拉取、处理完已取出的消息后离开消费组；
+// 配置的 topic 没有注册 handler 时返回错误
+func StartConsumers(ctx context.Context) error {
+	consumerConfigs := config.Config.KafkaConfig.Consumers
+	if len(consumerConfigs) == 0 {
+		consumerConfigs = defaultConsumers
+	}
+
+	// 先检查所有配置，避免部分消费组已启动
+	groupHandlers := make([]map[string]MessageHandler, 0, len(consumerConfigs))
+	for _, conf := range consumerConfigs {
+		if conf.GroupID == "" || len(conf.Topics) == 0 {
+			return fmt.Errorf("consumer group %q: group_id and topics are required", conf.GroupID)
+		}
+		topicHandlers, err := lookupHandlers(conf.Topics)
+		if err != nil {
+			return fmt.Errorf("consumer group %s: %w", conf.GroupID, err)
+		}
+		groupHandlers = append(groupHandlers, topicHandlers)
+	}
+
+	for i, conf := range consumerConfigs {
+		group := NewGroupConsumer(conf, newGroupReader(conf), groupHandlers[i])
+		consumersWait.Add(1)
+		go func(group *GroupConsumer) {
+			defer consumersWait.Done()
+			group.Run(ctx)
+		}(group)
+		log.Logger.Infof("StartConsumers: group %s started, topics: %v, concurrency: %d", group.groupID, group.topics, group.concurrency)
+	}
+	return nil
+}
+
+func lookupHandlers(topics []string) (map[string]MessageHandler, error) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	groupHandlers := make(map[string]MessageHandler, len(topics))
+	for _, topic := range topics {
+		if handlers[topic] == nil {
+			return nil, fmt.Errorf("no handler registered for topic %s", topic)
+		}
+		groupHandlers[topic] = handlers[topic]
+	}
+	return groupHandlers, nil
+}
+
+// waitConsumers 等待所有消费者退出，需要先取消 StartConsumers 的 ctx
+func waitConsumers() {
+	consumersWait.Wait()
+}
+
+// newGroupReader 创建消费组 reader：不指定分区，由 kafka 在组内副本之间分配 GroupTopics 的分区，
+// 副本加入或退出时自动重新分配
+func newGroupReader(conf *config.KafkaConsumerConfig) MessageReader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:           []string{brokerAddr()},
+		GroupID:           conf.GroupID,
+		GroupTopics:       conf.Topics,
+		MaxBytes:          10e6,
+		SessionTimeout:    time.Duration(conf.SessionTimeout) * time.Second,
+		RebalanceTimeout:  time.Duration(conf.RebalanceTimeout) * time.Second,
+		HeartbeatInterval: time.Duration(conf.HeartbeatInterval) * time.Second,
+	})
+}
+
+// GroupConsumer 从一个消费组拉取消息，按 topic 分发给 handler。
+// 同一分区的消息总是由同一个 worker 按顺序处理并提交 offset，不同分区最多 concurrency 个并行处理；
+// 重新分配分区时，已取出但尚未提交的消息会由新的分区持有者重新处理
+type GroupConsumer struct {
+	groupID      string
+	topics       []string
+	r            MessageReader
+	handlers     map[string]MessageHandler
+	concurrency  int
+	retryDelay   time.Duration
+	drainTimeout time.Duration
+}
+
+func NewGroupConsumer(conf *config.KafkaConsumerConfig, r MessageReader, handlers map[string]MessageHandler) *GroupConsumer {
+	gc := &GroupConsumer{
+		groupID:      conf.GroupID,
+		topics:       conf.Topics,
+		r:            r,
+		handlers:     handlers,
+		concurrency:  conf.Concurrency,
+		retryDelay:   CONSUMER_FETCH_RETRY_DELAY,
+		drainTimeout: time.Duration(conf.DrainTimeout) * time.Second,
+	}
+	if gc.concurrency <= 0 {
+		gc.concurrency = CONSUMER_DEFAULT_CONCURRENCY
+	}
+	if gc.drainTimeout <= 0 {
+		gc.drainTimeout = CONSUMER_DEFAULT_DRAIN_TIMEOUT
+	}
+	return gc
+}
+
+// Run 拉取并处理消息直到 ctx 取消或 reader 关闭；退出时不再处理排队中的消息，
+// 正在处理的消息最多等待 drainTimeout，之后关闭 reader 离开消费组，分区立即分配给其他副本
+func (gc *GroupConsumer) Run(ctx context.Context) {
+	stopCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	// 正在处理的消息不随 ctx 立即取消，超过 drainTimeout 后才取消
+	handleCtx, cancelHandle := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandle()
+
+	var wg sync.WaitGroup
+	queues := make([]chan kafka.Message, gc.concurrency)
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, CONSUMER_QUEUE_SIZE)
+		wg.Add(1)
+		go func(queue chan kafka.Message) {
+			defer wg.Done()
+			gc.work(stopCtx, stop, handleCtx, queue)
+		}(queues[i])
+	}
+
+	gc.fetch(stopCtx, queues)
+	stop()
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(gc.drainTimeout):
+		log.Logger.Warnf("GroupConsumer: group %s drain timeout, canceling in-flight messages", gc.groupID)
+		cancelHandle()
+		<-drained
+	}
+
+	if err := gc.r.Close(); err != nil {
+		log.Logger.Errorf("GroupConsumer: close reader failed, group: %s, err = %s", gc.groupID, err.Error())
+	}
+	log.Logger.Infof("GroupConsumer: group %s stopped", gc.groupID)
+}
+
+// fetch 拉取消息并按分区分发给 worker，worker 忙时阻塞
+func (gc *GroupConsumer) fetch(ctx context.Context, queues []chan kafka.Message) {
+	for {
+		msg, err := gc.r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				log.Logger.Infof("GroupConsumer: group %s stopped fetching, err = %s", gc.groupID, err.Error())
+				return
+			}
+			log.Logger.Errorf("GroupConsumer: fetch message failed, group: %s, err = %s", gc.groupID, err.Error())
+			metrics.KafkaConsumeFailuresTotal.WithLabelValues(strings.Join(gc.topics, ","), CONSUME_FAILURE_FETCH).Inc()
+			if !sleepCtx(ctx, gc.retryDelay) {
+				return
+			}
+			continue
+		}
+		metrics.KafkaConsumerLag.WithLabelValues(msg.Topic, strconv.Itoa(msg.Partition)).
+			Set(float64(max(msg.HighWaterMark-msg.Offset-1, 0)))
+
+		select {
+		case queues[gc.workerIndex(msg)] <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// workerIndex 同一分区的消息总是分配给同一个 worker
+func (gc *GroupConsumer) workerIndex(msg kafka.Message) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg.Topic))
+	return int((h.Sum32() + uint32(msg.Partition)) % uint32(gc.concurrency))
+}
+
+// work 顺序处理分配到的消息，处理完成后提交 offset；handler 出错时停止整个消费者，
+// 避免之后的 offset 提交跳过未处理的消息
+func (gc *GroupConsumer) work(stopCtx context.Context, stop context.CancelFunc, handleCtx context.Context, queue chan kafka.Message) {
+	for {
+		select {
+		case <-stopCtx.Done():
+			return
+		case msg := <-queue:
+			if stopCtx.Err() != nil {
+				return
+			}
+			handler := gc.handlers[msg.Topic]
+			if handler == nil {
+				log.Logger.Errorf("GroupConsumer: no handler for topic %s, group: %s", msg.Topic, gc.groupID)
+				stop()
+				return
+			}
+			if err := handler.HandleMessage(handleCtx, msg); err != nil {
+				log.Logger.Infof("GroupConsumer: stopped before offset %d of %s/%d was handled, err = %s", msg.Offset, msg.Topic, msg.Partition, err.Error())
+				stop()
+				return
+			}
+			// 提交失败时(例如分区已被重新分配)消息会被重新投递，由 handler 保证幂等
+			if err := gc.r.CommitMessages(handleCtx, msg); err != nil {
+				log.Logger.Errorf("GroupConsumer: commit message failed, topic: %s, partition: %d, offset: %d, err = %s", msg.Topic, msg.Partition, msg.Offset, err.Error())
+				metrics.KafkaConsumeFailuresTotal.WithLabelValues(msg.Topic, CONSUME_FAILURE_COMMIT).Inc()
+			}
+		}
+	}
+}
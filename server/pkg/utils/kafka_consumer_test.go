@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// recordingHandler 记录处理过的消息，handle 不为空时由它处理
+type recordingHandler struct {
+	mu      sync.Mutex
+	handled []kafka.Message
+	handle  func(ctx context.Context, msg kafka.Message) error
+}
+
+func (h *recordingHandler) HandleMessage(ctx context.Context, msg kafka.Message) error {
+	if h.handle != nil {
+		if err := h.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handled = append(h.handled, msg)
+	return nil
+}
+
+func newTestGroupConsumer(concurrency int, reader MessageReader, handlers map[string]MessageHandler) *GroupConsumer {
+	consumer := NewGroupConsumer(&config.KafkaConsumerConfig{GroupID: "test_group", Topics: []string{"a", "b"}, Concurrency: concurrency}, reader, handlers)
+	consumer.drainTimeout = 10 * time.Millisecond
+	return consumer
+}
+
+// TestGroupConsumer_Run_DispatchByTopic tests each topic goes to its handler and every partition keeps its order
+func TestGroupConsumer_Run_DispatchByTopic(t *testing.T) {
+	reader := &fakeReader{msgs: []kafka.Message{
+		{Topic: "a", Partition: 0, Offset: 0},
+		{Topic: "b", Partition: 0, Offset: 0},
+		{Topic: "a", Partition: 1, Offset: 0},
+		{Topic: "a", Partition: 0, Offset: 1},
+		{Topic: "a", Partition: 1, Offset: 1},
+		{Topic: "a", Partition: 0, Offset: 2},
+	}}
+	handlerA, handlerB := &recordingHandler{}, &recordingHandler{}
+
+	newTestGroupConsumer(3, reader, map[string]MessageHandler{"a": handlerA, "b": handlerB}).Run(context.Background())
+	if len(handlerA.handled) != 5 || len(handlerB.handled) != 1 || len(reader.committed) != 6 || !reader.closed {
+		t.Fatalf("Expected 5+1 messages handled, committed and reader closed, got %d, %d, commits %v", len(handlerA.handled), len(handlerB.handled), reader.committed)
+	}
+	next := map[int]int64{}
+	for _, msg := range handlerA.handled {
+		if msg.Offset != next[msg.Partition] {
+			t.Errorf("Partition %d handled out of order: %v", msg.Partition, handlerA.handled)
+		}
+		next[msg.Partition]++
+	}
+}
+
+// TestGroupConsumer_Run_ConcurrentPartitions tests a slow partition does not block another partition's worker
+func TestGroupConsumer_Run_ConcurrentPartitions(t *testing.T) {
+	reader := &fakeReader{msgs: []kafka.Message{
+		{Topic: "a", Partition: 0, Offset: 0},
+		{Topic: "a", Partition: 1, Offset: 0},
+	}}
+	partition1Done := make(chan struct{})
+	handler := &recordingHandler{handle: func(ctx context.Context, msg kafka.Message) error {
+		if msg.Partition == 1 {
+			close(partition1Done)
+			return nil
+		}
+		select {
+		case <-partition1Done:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("partition 1 was blocked behind partition 0")
+		}
+	}}
+
+	newTestGroupConsumer(2, reader, map[string]MessageHandler{"a": handler}).Run(context.Background())
+	if len(handler.handled) != 2 || len(reader.committed) != 2 {
+		t.Errorf("Expected both partitions handled in parallel, got %v", handler.handled)
+	}
+}
+
+// TestGroupConsumer_Run_GracefulShutdown tests the in-flight message finishes and is committed while queued messages are left for the next owner
+func TestGroupConsumer_Run_GracefulShutdown(t *testing.T) {
+	reader := &fakeReader{msgs: []kafka.Message{
+		{Topic: "a", Partition: 0, Offset: 0},
+		{Topic: "a", Partition: 0, Offset: 1},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := &recordingHandler{handle: func(handleCtx context.Context, msg kafka.Message) error {
+		if msg.Offset == 0 {
+			cancel()
+			time.Sleep(5 * time.Millisecond)
+		}
+		return handleCtx.Err()
+	}}
+	consumer := newTestGroupConsumer(1, reader, map[string]MessageHandler{"a": handler})
+	consumer.drainTimeout = time.Second
+
+	consumer.Run(ctx)
+	if len(reader.committed) != 1 || reader.committed[0] != 0 || !reader.closed {
+		t.Errorf("Expected only the in-flight offset committed and reader closed, got commits %v", reader.committed)
+	}
+}
+
+// TestGroupConsumer_Run_HandlerError tests a handler error stops the consumer without committing
+func TestGroupConsumer_Run_HandlerError(t *testing.T) {
+	reader := &fakeReader{msgs: []kafka.Message{
+		{Topic: "a", Partition: 0, Offset: 0},
+		{Topic: "a", Partition: 0, Offset: 1},
+	}}
+	handler := &recordingHandler{handle: func(context.Context, kafka.Message) error {
+		return errors.New("handler failed")
+	}}
+
+	newTestGroupConsumer(1, reader, map[string]MessageHandler{"a": handler}).Run(context.Background())
+	if len(reader.committed) != 0 || !reader.closed {
+		t.Errorf("Expected nothing committed and reader closed, got commits %v", reader.committed)
+	}
+}
+
+// TestStartConsumers_Invalid tests a topic without a registered handler is rejected before any group starts
+func TestStartConsumers_Invalid(t *testing.T) {
+	config.Config.KafkaConfig = &config.KafkaConfig{Consumers: []*config.KafkaConsumerConfig{
+		{GroupID: "test_group", Topics: []string{"unregistered_topic"}},
+	}}
+	defer func() { config.Config.KafkaConfig = nil }()
+
+	if err := StartConsumers(context.Background()); err == nil || !strings.Contains(err.Error(), "unregistered_topic") {
+		t.Errorf("Expected missing handler error, got: %v", err)
+	}
+}
+
+type fakePaymentResultHandler struct {
+	failures int
+	calls    int
+}
+
+func (h *fakePaymentResultHandler) HandlePaymentResult(ctx context.Context, msg *types.PaymentResultMessage) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("db error")
+	}
+	return nil
+}
+
+// TestPaymentResultMessageHandler_HandleMessage tests unparseable or repeatedly failing results are dead-lettered and cancellation mid-retry leaves the offset uncommitted
+func TestPaymentResultMessageHandler_HandleMessage(t *testing.T) {
+	handler := &fakePaymentResultHandler{failures: PAYMENT_RESULT_MAX_ATTEMPTS}
+	dlqWriter := &fakeWriter{failures: 1}
+	pc := &PaymentResultMessageHandler{handler: handler, dlqWriter: dlqWriter}
+	dlqTopic := DeadLetterTopic(PAYMENT_RESULT_TOPIC) + ":"
+
+	if err := pc.HandleMessage(context.Background(), kafka.Message{Topic: PAYMENT_RESULT_TOPIC, Offset: 1, Value: []byte(`not json`)}); err != nil || handler.calls != 0 {
+		t.Errorf("Expected unparseable result dead-lettered, got err %v, calls %d", err, handler.calls)
+	}
+	if err := pc.HandleMessage(context.Background(), kafka.Message{Topic: PAYMENT_RESULT_TOPIC, Offset: 2, Key: []byte("ORDER001"), Value: []byte(`{"biz_id":"ORDER001"}`)}); err != nil || handler.calls != PAYMENT_RESULT_MAX_ATTEMPTS {
+		t.Errorf("Expected result dead-lettered after %d attempts, got err %v, calls %d", PAYMENT_RESULT_MAX_ATTEMPTS, err, handler.calls)
+	}
+	if len(dlqWriter.sent) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %v", dlqWriter.sent)
+	}
+	var deadLetter types.DeadLetterMessage
+	if err := JSONDecode(dlqWriter.sent[1][len(dlqTopic):], &deadLetter); err != nil {
+		t.Fatalf("Expected dead letter envelope, got: %s", dlqWriter.sent[1])
+	}
+	if deadLetter.Topic != PAYMENT_RESULT_TOPIC || deadLetter.Offset != 2 || deadLetter.Attempts != PAYMENT_RESULT_MAX_ATTEMPTS || deadLetter.Error != "db error" {
+		t.Errorf("Unexpected dead letter: %+v", deadLetter)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler = &fakePaymentResultHandler{failures: 1}
+	pc = &PaymentResultMessageHandler{handler: handler, dlqWriter: dlqWriter, retryDelay: time.Second}
+	if err := pc.HandleMessage(ctx, kafka.Message{Value: []byte(`{"biz_id":"ORDER001"}`)}); !errors.Is(err, context.Canceled) || len(dlqWriter.sent) != 2 {
+		t.Errorf("Expected context.Canceled without dead letter, got: %v", err)
+	}
+}
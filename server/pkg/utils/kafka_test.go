@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"go.uber.org/zap"
+)
+
+func init() {
+	// 初始化测试用logger
+	logger, _ := zap.NewDevelopment()
+	log.Logger = logger.Sugar()
+}
+
+// fakeReader 依次返回 msgs；读完后等到已取出的消息都提交或 ctx 取消，再返回 io.EOF 模拟 reader 被关闭
+type fakeReader struct {
+	mu        sync.Mutex
+	msgs      []kafka.Message
+	fetched   int
+	committed []int64
+	closed    bool
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.mu.Lock()
+		if len(r.msgs) > 0 {
+			msg := r.msgs[0]
+			r.msgs = r.msgs[1:]
+			r.fetched++
+			r.mu.Unlock()
+			return msg, nil
+		}
+		done := len(r.committed) == r.fetched
+		r.mu.Unlock()
+		if done {
+			return kafka.Message{}, io.EOF
+		}
+		if !sleepCtx(ctx, time.Millisecond) {
+			return kafka.Message{}, ctx.Err()
+		}
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+// fakeWriter 记录写入的消息，前 failures 次写入失败
+type fakeWriter struct {
+	failures int
+	sent     []string
+}
+
+func (w *fakeWriter) SendMsg(ctx context.Context, topic, key, value string) error {
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("broker unavailable")
+	}
+	w.sent = append(w.sent, topic+":"+value)
+	return nil
+}
+
+func statusChangedMessage(offset int64, value string) kafka.Message {
+	return kafka.Message{Topic: ORDER_STATUS_CHANGED_TOPIC, Offset: offset, HighWaterMark: 3, Key: []byte("ORDER001"), Value: []byte(value)}
+}
+
+func newTestConsumer(ctrl *gomock.Controller, msgs ...kafka.Message) (*GroupConsumer, *fakeReader, *fakeWriter, *daoMocks.MockOrderLogDao) {
+	reader := &fakeReader{msgs: msgs}
+	dlqWriter := &fakeWriter{}
+	orderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
+	handler := &OrderStatusLogHandler{orderLogDao: orderLogDao, dlqWriter: dlqWriter}
+	consumer := NewGroupConsumer(&config.KafkaConsumerConfig{GroupID: "test_group", Topics: []string{ORDER_STATUS_CHANGED_TOPIC}}, reader,
+		map[string]MessageHandler{ORDER_STATUS_CHANGED_TOPIC: handler})
+	consumer.drainTimeout = 10 * time.Millisecond
+	return consumer, reader, dlqWriter, orderLogDao
+}
+
+// TestOrderStatusLogHandler_RetryThenCommit tests a transient DB error is retried and the offset committed only after the write
+func TestOrderStatusLogHandler_RetryThenCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	consumer, reader, dlqWriter, orderLogDao := newTestConsumer(ctrl, statusChangedMessage(0, `{"order_no":"ORDER001","current_status":2}`))
+	gomock.InOrder(
+		orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(false, errors.New("db error")),
+		orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(true, nil),
+	)
+
+	consumer.Run(context.Background())
+	if len(reader.committed) != 1 || len(dlqWriter.sent) != 0 {
+		t.Errorf("Expected commit without dead letter, got commits %v, dead letters %v", reader.committed, dlqWriter.sent)
+	}
+}
+
+// TestOrderStatusLogHandler_DeadLetter tests unparseable and exhausted messages go to the DLQ and consumption continues
+func TestOrderStatusLogHandler_DeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	consumer, reader, dlqWriter, orderLogDao := newTestConsumer(ctrl,
+		statusChangedMessage(0, `not json`),
+		statusChangedMessage(1, `{"order_no":"ORDER001","current_status":2}`),
+		statusChangedMessage(2, `{"order_no":"ORDER001","current_status":3}`),
+	)
+	// 第一次 DLQ 写入失败后重试
+	dlqWriter.failures = 1
+	gomock.InOrder(
+		orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(false, errors.New("db error")).Times(ORDER_STATUS_LOG_MAX_ATTEMPTS),
+		orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(true, nil),
+	)
+
+	consumer.Run(context.Background())
+	if len(reader.committed) != 3 {
+		t.Errorf("Expected all 3 offsets committed, got %v", reader.committed)
+	}
+	if len(dlqWriter.sent) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %v", dlqWriter.sent)
+	}
+
+	var deadLetter types.DeadLetterMessage
+	topic := DeadLetterTopic(ORDER_STATUS_CHANGED_TOPIC) + ":"
+	if err := JSONDecode(dlqWriter.sent[1][len(topic):], &deadLetter); err != nil {
+		t.Fatalf("Expected dead letter envelope, got: %s", dlqWriter.sent[1])
+	}
+	if deadLetter.Offset != 1 || deadLetter.Attempts != ORDER_STATUS_LOG_MAX_ATTEMPTS || deadLetter.Key != "ORDER001" || deadLetter.Error != "db error" {
+		t.Errorf("Unexpected dead letter: %+v", deadLetter)
+	}
+}
+
+// TestOrderStatusLogHandler_CanceledBeforeDeadLetter tests the offset is not committed when shutdown outlasts the drain timeout mid-retry
+func TestOrderStatusLogHandler_CanceledBeforeDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	consumer, reader, dlqWriter, orderLogDao := newTestConsumer(ctrl, statusChangedMessage(0, `{"order_no":"ORDER001"}`))
+	consumer.handlers[ORDER_STATUS_CHANGED_TOPIC].(*OrderStatusLogHandler).retryDelay = ORDER_STATUS_LOG_RETRY_MAX
+	ctx, cancel := context.WithCancel(context.Background())
+	orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, interface{}) (bool, error) {
+			cancel()
+			return false, errors.New("db error")
+		})
+
+	consumer.Run(ctx)
+	if len(reader.committed) != 0 || len(dlqWriter.sent) != 0 {
+		t.Errorf("Expected nothing committed, got commits %v, dead letters %v", reader.committed, dlqWriter.sent)
+	}
+}
+
+// TestOrderStatusLogHandler_Duplicate tests a redelivered event is written once and its offset still committed
+func TestOrderStatusLogHandler_Duplicate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	value := `{"specversion":"1.0","id":"8b0e0a3c-6a43-4c8e-9d0b-1f6f3c2b9a11","type":"com.ceramicraft.order.status_changed.v1","time":"2026-01-02T03:04:05Z","data":{"order_no":"ORDER001","user_id":1,"current_status":2}}`
+	consumer, reader, _, orderLogDao := newTestConsumer(ctrl, statusChangedMessage(0, value), statusChangedMessage(1, value))
+	occurredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	gomock.InOrder(
+		orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, orderLog *model.OrderStatusLog) (bool, error) {
+				if orderLog.EventID != "8b0e0a3c-6a43-4c8e-9d0b-1f6f3c2b9a11" || !orderLog.OccurredAt.Equal(occurredAt) {
+					t.Errorf("Unexpected order log: %+v", orderLog)
+				}
+				return true, nil
+			}),
+		orderLogDao.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(false, nil),
+	)
+
+	consumer.Run(context.Background())
+	if len(reader.committed) != 2 {
+		t.Errorf("Expected both offsets committed, got %v", reader.committed)
+	}
+}
+
+// TestFillLegacyEvent tests messages without an event ID get a stable ID from their kafka position
+func TestFillLegacyEvent(t *testing.T) {
+	msgTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msgRaw := statusChangedMessage(7, `{}`)
+	msgRaw.Time = msgTime
+
+	first, redelivered := types.OrderStatusChangedMessage{}, types.OrderStatusChangedMessage{}
+	fillLegacyEvent(&first, msgRaw)
+	fillLegacyEvent(&redelivered, msgRaw)
+	if first.EventID == "" || first.EventID != redelivered.EventID || !first.OccurredAt.Equal(msgTime) {
+		t.Errorf("Expected stable event ID and message time, got %+v and %+v", first, redelivered)
+	}
+
+	msgRaw.Offset = 8
+	next := types.OrderStatusChangedMessage{}
+	fillLegacyEvent(&next, msgRaw)
+	if next.EventID == first.EventID {
+		t.Errorf("Expected different event ID for a different offset, got %s", next.EventID)
+	}
+
+	withID := types.OrderStatusChangedMessage{EventID: "evt-1"}
+	fillLegacyEvent(&withID, msgRaw)
+	if withID.EventID != "evt-1" {
+		t.Errorf("Expected event ID kept, got %s", withID.EventID)
+	}
+}
@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/metrics"
+)
+
+const (
+	WRITER_MODE_SYNC  = "sync"
+	WRITER_MODE_ASYNC = "async"
+
+	WRITER_DEFAULT_BUFFER_SIZE   = 1000
+	WRITER_DEFAULT_BATCH_SIZE    = 100
+	WRITER_DEFAULT_BATCH_TIMEOUT = 10 * time.Millisecond
+	WRITER_DEFAULT_FLUSH_TIMEOUT = 10 * time.Second
+	// 异步模式发送一批消息的超时
+	WRITER_BATCH_SEND_TIMEOUT = 10 * time.Second
+)
+
+// 发送指标的 result 标签
+const (
+	PRODUCE_RESULT_SUCCESS = "success"
+	PRODUCE_RESULT_FAILURE = "failure"
+	PRODUCE_RESULT_DROPPED = "dropped" // 异步缓冲区已满或 writer 已关闭，消息未被接受
+)
+
+var (
+	ErrWriterBufferFull = errors.New("kafka writer buffer is full")
+	ErrWriterClosed     = errors.New("kafka writer is closed")
+)
+
+// kafkaMessageWriter kafka.Writer 中 MyWriter 用到的方法
+type kafkaMessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// MyWriter 向 kafka 发送消息。
+// 同步模式等待 broker 确认后返回，返回 broker 的错误，用于 outbox 投递、死信等需要确认的场景；
+// 异步模式写入有界缓冲区后立即返回，由后台任务批量发送，退出时发送缓冲区中剩余的消息
+type MyWriter struct {
+	kafkaWriter kafkaMessageWriter
+	mode        string
+
+	// 以下只用于异步模式
+	buffer       chan bufferedMessage
+	batchSize    int
+	batchTimeout time.Duration
+	flushTimeout time.Duration
+	mu           sync.RWMutex
+	closed       bool
+	ctx          context.Context // 取消后正在发送的批次立即失败
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+type bufferedMessage struct {
+	msg      kafka.Message
+	enqueued time.Time
+}
+
+var (
+	kafkaWriter *kafka.Writer
+	writer      *MyWriter
+	writerOnce  sync.Once
+)
+
+func initKafkaWriter() {
+	writerOnce.Do(func() {
+		conf := producerConfig()
+		kafkaWriter = &kafka.Writer{
+			Addr:                   kafka.TCP(brokerAddr()),
+			Balancer:               &kafka.Hash{}, // 相同 key 的消息进入同一分区，保证同一订单的消息有序
+			RequiredAcks:           kafka.RequireAll,
+			Async:                  false,
+			AllowAutoTopicCreation: true,
+			BatchSize:              conf.BatchSize,
+			// 同步写入时单条消息最多等待 BatchTimeout 与同时写入的消息合并发送，默认的 1s 过长
+			BatchTimeout: time.Duration(conf.BatchTimeout) * time.Millisecond,
+		}
+		writer = NewSyncWriter(kafkaWriter)
+	})
+}
+
+// producerConfig 返回填充默认值后的发送配置
+func producerConfig() config.KafkaProducerConfig {
+	var conf config.KafkaProducerConfig
+	if config.Config.KafkaConfig.Producer != nil {
+		conf = *config.Config.KafkaConfig.Producer
+	}
+	if conf.BufferSize <= 0 {
+		conf.BufferSize = WRITER_DEFAULT_BUFFER_SIZE
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = WRITER_DEFAULT_BATCH_SIZE
+	}
+	if conf.BatchTimeout <= 0 {
+		conf.BatchTimeout = int(WRITER_DEFAULT_BATCH_TIMEOUT / time.Millisecond)
+	}
+	if conf.FlushTimeout <= 0 {
+		conf.FlushTimeout = int(WRITER_DEFAULT_FLUSH_TIMEOUT / time.Second)
+	}
+	return conf
+}
+
+// closeKafkaWriter 关闭 kafka writer，异步 writer 由创建者在此之前 Close
+func closeKafkaWriter() {
+	if kafkaWriter != nil {
+		if err := kafkaWriter.Close(); err != nil {
+			log.Logger.Errorf("CloseKafkaWriter: failed to close writer, err %s", err.Error())
+		}
+	}
+}
+
+// GetWriter 返回同步模式的 writer
+func GetWriter() *MyWriter {
+	return writer
+}
+
+func NewSyncWriter(w kafkaMessageWriter) *MyWriter {
+	return &MyWriter{kafkaWriter: w, mode: WRITER_MODE_SYNC}
+}
+
+// NewAsyncWriter 创建异步 writer 并启动后台发送任务，使用完后需要 Close；
+// 只用于不需要确认、允许在 broker 不可用时丢失的消息，目前的发送方都需要确认，都使用同步 writer
+func NewAsyncWriter(w kafkaMessageWriter, conf config.KafkaProducerConfig) *MyWriter {
+	ctx, cancel := context.WithCancel(context.Background())
+	aw := &MyWriter{
+		kafkaWriter:  w,
+		mode:         WRITER_MODE_ASYNC,
+		buffer:       make(chan bufferedMessage, conf.BufferSize),
+		batchSize:    conf.BatchSize,
+		batchTimeout: time.Duration(conf.BatchTimeout) * time.Millisecond,
+		flushTimeout: time.Duration(conf.FlushTimeout) * time.Second,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	go aw.run()
+	return aw
+}
+
+// SendMsg 同步模式返回 broker 的写入结果；异步模式不等待发送，缓冲区已满或 writer 已关闭时返回错误。
+// 异步发送使用 writer 自己的 ctx，请求的 ctx 取消不影响已接受的消息
+func (myWriter *MyWriter) SendMsg(ctx context.Context, topic, key, value string) error {
+	msg := kafka.Message{
+		Topic: topic, // 这里可以覆盖默认 topic
+		Key:   []byte(key),
+		Value: []byte(value),
+	}
+	if myWriter.mode == WRITER_MODE_ASYNC {
+		return myWriter.enqueue(msg)
+	}
+
+	start := time.Now()
+	err := myWriter.kafkaWriter.WriteMessages(ctx, msg)
+	myWriter.observe(topic, start, err)
+	if err != nil {
+		log.Logger.Errorf("SendMsg: failed, topic: %s, key: %s, err %s", topic, key, err.Error())
+	}
+	return err
+}
+
+func (myWriter *MyWriter) enqueue(msg kafka.Message) error {
+	myWriter.mu.RLock()
+	defer myWriter.mu.RUnlock()
+	err := ErrWriterClosed
+	if !myWriter.closed {
+		select {
+		case myWriter.buffer <- bufferedMessage{msg: msg, enqueued: time.Now()}:
+			metrics.KafkaProducerBuffered.Inc()
+			return nil
+		default:
+			err = ErrWriterBufferFull
+		}
+	}
+	metrics.KafkaProducedTotal.WithLabelValues(msg.Topic, myWriter.mode, PRODUCE_RESULT_DROPPED).Inc()
+	log.Logger.Errorf("SendMsg: message dropped, topic: %s, key: %s, err %s", msg.Topic, string(msg.Key), err.Error())
+	return err
+}
+
+// run 攒够 batchSize 条或等待 batchTimeout 后发送一批，缓冲区关闭后发送剩余消息并退出
+func (myWriter *MyWriter) run() {
+	defer close(myWriter.done)
+	batch := make([]bufferedMessage, 0, myWriter.batchSize)
+	ticker := time.NewTicker(myWriter.batchTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-myWriter.buffer:
+			if !ok {
+				myWriter.flush(batch)
+				return
+			}
+			batch = append(batch, msg)
+			if len(batch) >= myWriter.batchSize {
+				myWriter.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			myWriter.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (myWriter *MyWriter) flush(batch []bufferedMessage) {
+	if len(batch) == 0 {
+		return
+	}
+	msgs := make([]kafka.Message, len(batch))
+	for i, buffered := range batch {
+		msgs[i] = buffered.msg
+	}
+	ctx, cancel := context.WithTimeout(myWriter.ctx, WRITER_BATCH_SEND_TIMEOUT)
+	defer cancel()
+	err := myWriter.kafkaWriter.WriteMessages(ctx, msgs...)
+	metrics.KafkaProducerBuffered.Sub(float64(len(batch)))
+
+	// 部分消息失败时 kafka-go 返回每条消息的错误
+	var writeErrs kafka.WriteErrors
+	partial := errors.As(err, &writeErrs) && len(writeErrs) == len(batch)
+	for i, buffered := range batch {
+		msgErr := err
+		if partial {
+			msgErr = writeErrs[i]
+		}
+		myWriter.observe(buffered.msg.Topic, buffered.enqueued, msgErr)
+		if msgErr != nil {
+			log.Logger.Errorf("SendMsg: async send failed, topic: %s, key: %s, err %s", buffered.msg.Topic, string(buffered.msg.Key), msgErr.Error())
+		}
+	}
+}
+
+func (myWriter *MyWriter) observe(topic string, start time.Time, err error) {
+	result := PRODUCE_RESULT_SUCCESS
+	if err != nil {
+		result = PRODUCE_RESULT_FAILURE
+	}
+	metrics.KafkaProducedTotal.WithLabelValues(topic, myWriter.mode, result).Inc()
+	metrics.KafkaProduceDuration.WithLabelValues(topic, myWriter.mode).Observe(float64(time.Since(start).Milliseconds()))
+}
+
+// Close 异步模式下停止接受新消息，并在 flushTimeout 内发送缓冲区中剩余的消息，超时后放弃发送；
+// 不关闭底层的 kafka writer
+func (myWriter *MyWriter) Close() {
+	if myWriter.mode != WRITER_MODE_ASYNC {
+		return
+	}
+	myWriter.mu.Lock()
+	if !myWriter.closed {
+		myWriter.closed = true
+		close(myWriter.buffer)
+	}
+	myWriter.mu.Unlock()
+
+	select {
+	case <-myWriter.done:
+	case <-time.After(myWriter.flushTimeout):
+		log.Logger.Errorf("CloseKafkaWriter: flush timeout, canceling sends of %d buffered messages", len(myWriter.buffer))
+		myWriter.cancel()
+		<-myWriter.done
+	}
+	myWriter.cancel()
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+)
+
+// fakeKafkaWriter 记录每次写入的批次，err 不为空时返回该错误；block 不为空时写入阻塞到 block 关闭或 ctx 取消
+type fakeKafkaWriter struct {
+	mu      sync.Mutex
+	batches [][]kafka.Message
+	err     error
+	block   chan struct{}
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.block != nil {
+		select {
+		case <-w.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, msgs)
+	return w.err
+}
+
+func (w *fakeKafkaWriter) sent() (batches [][]kafka.Message) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append(batches, w.batches...)
+}
+
+func newTestAsyncWriter(kw kafkaMessageWriter, bufferSize, batchSize int) *MyWriter {
+	return NewAsyncWriter(kw, config.KafkaProducerConfig{BufferSize: bufferSize, BatchSize: batchSize, BatchTimeout: 1000, FlushTimeout: 1})
+}
+
+// TestMyWriter_SendMsg_Sync tests the sync mode returns the broker error with the message already written
+func TestMyWriter_SendMsg_Sync(t *testing.T) {
+	kw := &fakeKafkaWriter{err: errors.New("broker unavailable")}
+	if err := NewSyncWriter(kw).SendMsg(context.Background(), "order_refund", "ORDER001", "{}"); err == nil {
+		t.Fatal("Expected broker error, got nil")
+	}
+	if batches := kw.sent(); len(batches) != 1 || string(batches[0][0].Key) != "ORDER001" {
+		t.Errorf("Unexpected writes: %v", batches)
+	}
+}
+
+// TestMyWriter_SendMsg_AsyncBatch tests async messages are sent in batches on a background context and the rest flushed on Close
+func TestMyWriter_SendMsg_AsyncBatch(t *testing.T) {
+	kw := &fakeKafkaWriter{}
+	aw := newTestAsyncWriter(kw, 10, 2)
+
+	// 请求的 ctx 已取消也不影响已接受的消息
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, key := range []string{"1", "2", "3"} {
+		if err := aw.SendMsg(ctx, "order_refund", key, "{}"); err != nil {
+			t.Fatalf("Expected message accepted, got: %v", err)
+		}
+	}
+	aw.Close()
+
+	batches := kw.sent()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 || string(batches[1][0].Key) != "3" {
+		t.Errorf("Expected a full batch and a flushed batch, got %v", batches)
+	}
+	if err := aw.SendMsg(context.Background(), "order_refund", "4", "{}"); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed after Close, got: %v", err)
+	}
+}
+
+// TestMyWriter_SendMsg_AsyncBufferFull tests a full buffer rejects new messages instead of blocking the caller
+func TestMyWriter_SendMsg_AsyncBufferFull(t *testing.T) {
+	kw := &fakeKafkaWriter{block: make(chan struct{})}
+	aw := newTestAsyncWriter(kw, 1, 1)
+	defer aw.Close()
+	defer close(kw.block)
+
+	var err error
+	// 第一条被后台任务取出后阻塞在发送中，第二条占满缓冲区
+	for i := 0; i < 3 && err == nil; i++ {
+		err = aw.SendMsg(context.Background(), "order_refund", "ORDER001", "{}")
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !errors.Is(err, ErrWriterBufferFull) {
+		t.Errorf("Expected ErrWriterBufferFull, got: %v", err)
+	}
+}
+
+// TestMyWriter_Close_FlushTimeout tests Close gives up on a stuck broker after the flush timeout
+func TestMyWriter_Close_FlushTimeout(t *testing.T) {
+	kw := &fakeKafkaWriter{block: make(chan struct{})}
+	aw := newTestAsyncWriter(kw, 10, 1)
+	aw.flushTimeout = 10 * time.Millisecond
+	if err := aw.SendMsg(context.Background(), "order_refund", "ORDER001", "{}"); err != nil {
+		t.Fatalf("Expected message accepted, got: %v", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		aw.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to return after the flush timeout")
+	}
+}
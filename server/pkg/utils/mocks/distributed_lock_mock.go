@@ -48,6 +48,20 @@ func (mr *MockLockerMockRecorder) Lock(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLocker)(nil).Lock), ctx)
 }
 
+// Renew mocks base method.
+func (m *MockLocker) Renew(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Renew", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Renew indicates an expected call of Renew.
+func (mr *MockLockerMockRecorder) Renew(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Renew", reflect.TypeOf((*MockLocker)(nil).Renew), ctx)
+}
+
 // Unlock mocks base method.
 func (m *MockLocker) Unlock(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	utils "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	gorm "gorm.io/gorm"
 )
 
 // MockWriter is a mock of Writer interface.
@@ -47,3 +49,54 @@ func (mr *MockWriterMockRecorder) SendMsg(ctx, topic, key, value interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockWriter)(nil).SendMsg), ctx, topic, key, value)
 }
+
+// MockTxWriter is a mock of TxWriter interface.
+type MockTxWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxWriterMockRecorder
+}
+
+// MockTxWriterMockRecorder is the mock recorder for MockTxWriter.
+type MockTxWriterMockRecorder struct {
+	mock *MockTxWriter
+}
+
+// NewMockTxWriter creates a new mock instance.
+func NewMockTxWriter(ctrl *gomock.Controller) *MockTxWriter {
+	mock := &MockTxWriter{ctrl: ctrl}
+	mock.recorder = &MockTxWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxWriter) EXPECT() *MockTxWriterMockRecorder {
+	return m.recorder
+}
+
+// SendMsg mocks base method.
+func (m *MockTxWriter) SendMsg(ctx context.Context, topic, key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMsg", ctx, topic, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockTxWriterMockRecorder) SendMsg(ctx, topic, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockTxWriter)(nil).SendMsg), ctx, topic, key, value)
+}
+
+// WithTx mocks base method.
+func (m *MockTxWriter) WithTx(tx *gorm.DB) utils.TxWriter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(utils.TxWriter)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxWriterMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxWriter)(nil).WithTx), tx)
+}
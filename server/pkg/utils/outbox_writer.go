@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+// OutboxWriter 将消息写入 outbox 表而不是直接发送 kafka，
+// 绑定事务后消息与订单数据一起提交，提交后由 relay 任务投递
+type OutboxWriter struct {
+	outboxDao dao.OutboxDao
+}
+
+var (
+	outboxWriter     *OutboxWriter
+	outboxWriterOnce sync.Once
+)
+
+func GetOutboxWriter() *OutboxWriter {
+	outboxWriterOnce.Do(func() {
+		outboxWriter = &OutboxWriter{
+			outboxDao: dao.GetOutboxDao(),
+		}
+	})
+	return outboxWriter
+}
+
+func (w *OutboxWriter) WithTx(tx *gorm.DB) TxWriter {
+	return &OutboxWriter{
+		outboxDao: w.outboxDao.WithTx(tx),
+	}
+}
+
+// SendMsg 有事件契约的 topic 先按契约校验消息，并包装为 CloudEvents 信封；
+// 事件ID在写入 outbox 时确定，relay 重试时不变
+func (w *OutboxWriter) SendMsg(ctx context.Context, topic, key, value string) error {
+	payload, err := WrapEvent(topic, key, value)
+	if err != nil {
+		log.Logger.Errorf("OutboxWriter: invalid event, topic: %s, key: %s, err: %s", topic, key, err.Error())
+		return err
+	}
+	_, err = w.outboxDao.Create(ctx, &model.Outbox{
+		Topic:   topic,
+		MsgKey:  key,
+		Payload: payload,
+		Status:  consts.OUTBOX_PENDING,
+	})
+	return err
+}
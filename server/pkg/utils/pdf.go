@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfPageWidth  = 595 // A4，单位为 pt
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfFontSize   = 9
+	pdfLineHeight = 12
+	// PDFLineChars 每行可容纳的字符数，Courier 字符宽度为字号的 0.6 倍
+	PDFLineChars = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+)
+
+// PDFWriter 生成只包含文本的 A4 PDF。使用 PDF 内置的 Courier 字体，不需要嵌入字体文件；
+// 字符等宽，按字符数即可对齐表格。只支持 WinAnsi 字符，其他字符输出为 ?
+type PDFWriter struct {
+	pages []*bytes.Buffer
+	y     int
+}
+
+func NewPDFWriter() *PDFWriter {
+	w := &PDFWriter{}
+	w.newPage()
+	return w
+}
+
+func (w *PDFWriter) newPage() {
+	w.pages = append(w.pages, &bytes.Buffer{})
+	w.y = pdfPageHeight - pdfMargin
+}
+
+// WriteLine 输出一行文本，超出页面时换页，超出行宽的部分截断
+func (w *PDFWriter) WriteLine(text string, bold bool) {
+	if w.y < pdfMargin {
+		w.newPage()
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	encoded := encodeWinAnsi(text)
+	if len(encoded) > PDFLineChars {
+		encoded = encoded[:PDFLineChars]
+	}
+	page := w.pages[len(w.pages)-1]
+	fmt.Fprintf(page, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, pdfFontSize, pdfMargin, w.y, escapePDFString(encoded))
+	w.y -= pdfLineHeight
+}
+
+// Bytes 生成 PDF 文件内容
+func (w *PDFWriter) Bytes() []byte {
+	var out bytes.Buffer
+	offsets := make([]int, 0, 4+2*len(w.pages))
+	writeObject := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	// 1 目录，2 页面树，3、4 字体，之后每页依次为页面和内容流
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range w.pages {
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+	return out.Bytes()
+}
+
+// encodeWinAnsi 转换为 WinAnsi 编码，Latin-1 字符与 WinAnsi 相同
+func encodeWinAnsi(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < 0x20:
+			encoded = append(encoded, ' ')
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			encoded = append(encoded, byte(r))
+		case r == '€':
+			encoded = append(encoded, 0x80)
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+func escapePDFString(text []byte) string {
+	var sb strings.Builder
+	for _, b := range text {
+		if b == '(' || b == ')' || b == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/redis"
+)
+
+const (
+	// 处理中的记录在实例宕机时自动过期，之后可以用同一个 key 重试
+	IDEMPOTENCY_PROCESSING_TTL = 5 * time.Minute
+	// 已完成的记录保留时间，期间的重放都返回原结果
+	IDEMPOTENCY_COMPLETED_TTL = 24 * time.Hour
+)
+
+type IIdempotencyCache interface {
+	// Reserve 占用幂等键；键已被占用时返回已保存的记录，占用成功时返回 nil
+	Reserve(ctx context.Context, userID int, key string, record types.IdempotencyRecord) (existing *types.IdempotencyRecord, err error)
+	// Complete 保存请求结果
+	Complete(ctx context.Context, userID int, key string, record types.IdempotencyRecord) error
+	// Release 请求失败时释放幂等键，允许客户端用同一个 key 重试
+	Release(ctx context.Context, userID int, key string) error
+}
+
+type idempotencyCache struct {
+	client *goredis.Client
+}
+
+var (
+	idempotencyCacheInstance IIdempotencyCache
+	idempotencyCacheSyncOnce sync.Once
+)
+
+func GetIdempotencyCache() IIdempotencyCache {
+	idempotencyCacheSyncOnce.Do(func() {
+		idempotencyCacheInstance = &idempotencyCache{
+			client: redis.RedisClient,
+		}
+	})
+	return idempotencyCacheInstance
+}
+
+func idempotencyRedisKey(userID int, key string) string {
+	return fmt.Sprintf("order:idempotency:%d:%s", userID, key)
+}
+
+// Reserve implements IIdempotencyCache.
+func (c *idempotencyCache) Reserve(ctx context.Context, userID int, key string, record types.IdempotencyRecord) (*types.IdempotencyRecord, error) {
+	value, err := utils.JSONEncode(record)
+	if err != nil {
+		return nil, err
+	}
+	redisKey := idempotencyRedisKey(userID, key)
+	// 已有记录恰好在 SETNX 和 GET 之间过期时重试一次
+	for i := 0; i < 2; i++ {
+		ok, err := c.client.SetNX(ctx, redisKey, value, IDEMPOTENCY_PROCESSING_TTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		saved, err := c.client.Get(ctx, redisKey).Result()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		existing := &types.IdempotencyRecord{}
+		if err = utils.JSONDecode(saved, existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	return nil, fmt.Errorf("reserve idempotency key failed, key: %s", redisKey)
+}
+
+// Complete implements IIdempotencyCache.
+func (c *idempotencyCache) Complete(ctx context.Context, userID int, key string, record types.IdempotencyRecord) error {
+	value, err := utils.JSONEncode(record)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, idempotencyRedisKey(userID, key), value, IDEMPOTENCY_COMPLETED_TTL).Err()
+}
+
+// Release implements IIdempotencyCache.
+func (c *idempotencyCache) Release(ctx context.Context, userID int, key string) error {
+	return c.client.Del(ctx, idempotencyRedisKey(userID, key)).Err()
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./cache/idempotency_cache.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// MockIIdempotencyCache is a mock of IIdempotencyCache interface.
+type MockIIdempotencyCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockIIdempotencyCacheMockRecorder
+}
+
+// MockIIdempotencyCacheMockRecorder is the mock recorder for MockIIdempotencyCache.
+type MockIIdempotencyCacheMockRecorder struct {
+	mock *MockIIdempotencyCache
+}
+
+// NewMockIIdempotencyCache creates a new mock instance.
+func NewMockIIdempotencyCache(ctrl *gomock.Controller) *MockIIdempotencyCache {
+	mock := &MockIIdempotencyCache{ctrl: ctrl}
+	mock.recorder = &MockIIdempotencyCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIIdempotencyCache) EXPECT() *MockIIdempotencyCacheMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockIIdempotencyCache) Complete(ctx context.Context, userID int, key string, record types.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, userID, key, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIIdempotencyCacheMockRecorder) Complete(ctx, userID, key, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIIdempotencyCache)(nil).Complete), ctx, userID, key, record)
+}
+
+// Release mocks base method.
+func (m *MockIIdempotencyCache) Release(ctx context.Context, userID int, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIIdempotencyCacheMockRecorder) Release(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIIdempotencyCache)(nil).Release), ctx, userID, key)
+}
+
+// Reserve mocks base method.
+func (m *MockIIdempotencyCache) Reserve(ctx context.Context, userID int, key string, record types.IdempotencyRecord) (*types.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, userID, key, record)
+	ret0, _ := ret[0].(*types.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockIIdempotencyCacheMockRecorder) Reserve(ctx, userID, key, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockIIdempotencyCache)(nil).Reserve), ctx, userID, key, record)
+}
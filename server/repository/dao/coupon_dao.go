@@ -0,0 +1,174 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CouponDao interface {
+	WithTx(tx *gorm.DB) CouponDao
+	Create(ctx context.Context, coupon *model.Coupon) (created bool, err error)
+	GetByCode(ctx context.Context, code string) (coupon *model.Coupon, err error)
+	List(ctx context.Context, query CouponQuery) (couponList []*model.Coupon, total int, err error)
+	UpdateStatus(ctx context.Context, code string, status int) (rows int, err error)
+	CountUserRedemptions(ctx context.Context, couponID int, userID int) (count int, err error)
+	Redeem(ctx context.Context, redemption *model.CouponRedemption) (redeemed bool, err error)
+	Release(ctx context.Context, orderNo string) (released int, err error)
+}
+
+// CouponQuery 优惠券查询条件，零值表示不筛选
+type CouponQuery struct {
+	Status int
+	Type   int
+	Limit  int
+	Offset int
+}
+
+var (
+	couponOnce            sync.Once
+	couponDaoImplInstance *CouponDaoImpl
+)
+
+type CouponDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetCouponDao() *CouponDaoImpl {
+	couponOnce.Do(func() {
+		if couponDaoImplInstance == nil {
+			couponDaoImplInstance = &CouponDaoImpl{repository.DB}
+		}
+	})
+	return couponDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *CouponDaoImpl) WithTx(tx *gorm.DB) CouponDao {
+	return &CouponDaoImpl{tx}
+}
+
+// Create 创建优惠券，优惠码已存在时不写入并返回 created = false
+func (d *CouponDaoImpl) Create(ctx context.Context, coupon *model.Coupon) (created bool, err error) {
+	res := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(coupon)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (d *CouponDaoImpl) GetByCode(ctx context.Context, code string) (coupon *model.Coupon, err error) {
+	coupon = &model.Coupon{}
+	err = d.db.WithContext(ctx).Where("code = ?", code).First(coupon).Error
+	return
+}
+
+func (d *CouponDaoImpl) List(ctx context.Context, query CouponQuery) (couponList []*model.Coupon, total int, err error) {
+	db := d.db.WithContext(ctx).Model(&model.Coupon{})
+	if query.Status != 0 {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Type != 0 {
+		db = db.Where("type = ?", query.Type)
+	}
+
+	var count int64
+	if err = db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err = db.Order("id DESC").Limit(query.Limit).Offset(query.Offset).Find(&couponList).Error
+	return couponList, int(count), err
+}
+
+func (d *CouponDaoImpl) UpdateStatus(ctx context.Context, code string, status int) (rows int, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.Coupon{}).
+		Where("code = ?", code).
+		Update("status", status)
+	return int(result.RowsAffected), result.Error
+}
+
+// CountUserRedemptions 统计用户未退回的使用次数
+func (d *CouponDaoImpl) CountUserRedemptions(ctx context.Context, couponID int, userID int) (count int, err error) {
+	var c int64
+	err = d.db.WithContext(ctx).
+		Model(&model.CouponRedemption{}).
+		Where("coupon_id = ? AND user_id = ?", couponID, userID).
+		Where("status = ?", consts.COUPON_REDEEMED).
+		Count(&c).Error
+	return int(c), err
+}
+
+// Redeem 占用一次使用次数并记录使用，总使用次数或用户的使用次数已达上限时返回 redeemed = false，应在事务中调用；
+// 先锁住优惠券行，同一优惠券的使用串行执行，统计用户使用次数和写入使用记录之间不会插入其他使用
+func (d *CouponDaoImpl) Redeem(ctx context.Context, redemption *model.CouponRedemption) (redeemed bool, err error) {
+	coupon := &model.Coupon{}
+	err = d.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", redemption.CouponID).
+		First(coupon).Error
+	if err != nil {
+		return false, err
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return false, nil
+	}
+	if coupon.PerUserLimit > 0 {
+		used, err := d.CountUserRedemptions(ctx, coupon.ID, redemption.UserID)
+		if err != nil || used >= coupon.PerUserLimit {
+			return false, err
+		}
+	}
+	err = d.db.WithContext(ctx).
+		Model(&model.Coupon{}).
+		Where("id = ?", redemption.CouponID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+	if err != nil {
+		return false, err
+	}
+	redemption.Status = consts.COUPON_REDEEMED
+	if err = d.db.WithContext(ctx).Create(redemption).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release 退回订单使用的优惠券，返回退回的数量；已退回的不会重复退回，应在事务中调用
+func (d *CouponDaoImpl) Release(ctx context.Context, orderNo string) (released int, err error) {
+	var redemptions []*model.CouponRedemption
+	err = d.db.WithContext(ctx).
+		Where("order_no = ?", orderNo).
+		Where("status = ?", consts.COUPON_REDEEMED).
+		Find(&redemptions).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, redemption := range redemptions {
+		result := d.db.WithContext(ctx).
+			Model(&model.CouponRedemption{}).
+			Where("id = ?", redemption.ID).
+			Where("status = ?", consts.COUPON_REDEEMED).
+			Update("status", consts.COUPON_RELEASED)
+		if result.Error != nil {
+			return released, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		err = d.db.WithContext(ctx).
+			Model(&model.Coupon{}).
+			Where("id = ?", redemption.CouponID).
+			Where("used_count > 0").
+			Update("used_count", gorm.Expr("used_count - 1")).Error
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
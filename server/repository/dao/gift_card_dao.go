@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GiftCardDao interface {
+	WithTx(tx *gorm.DB) GiftCardDao
+	Create(ctx context.Context, card *model.GiftCard) (created bool, err error)
+	GetByCode(ctx context.Context, code string) (card *model.GiftCard, err error)
+	UpdateStatus(ctx context.Context, code string, status int) (rows int, err error)
+	AdjustBalance(ctx context.Context, txn *model.GiftCardTransaction) (adjusted bool, err error)
+	ListTransactions(ctx context.Context, giftCardID int, limit int) (txns []*model.GiftCardTransaction, err error)
+	CreateTenders(ctx context.Context, tenders []model.OrderTender) (count int, err error)
+	GetTendersByOrderNo(ctx context.Context, orderNo string) (tenders []*model.OrderTender, err error)
+	UpdateTenderStatus(ctx context.Context, id int, oldStatus int, newStatus int) (rows int, err error)
+	AddTenderRefund(ctx context.Context, id int, amount int) (rows int, err error)
+}
+
+var (
+	giftCardOnce            sync.Once
+	giftCardDaoImplInstance *GiftCardDaoImpl
+)
+
+type GiftCardDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetGiftCardDao() *GiftCardDaoImpl {
+	giftCardOnce.Do(func() {
+		if giftCardDaoImplInstance == nil {
+			giftCardDaoImplInstance = &GiftCardDaoImpl{repository.DB}
+		}
+	})
+	return giftCardDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *GiftCardDaoImpl) WithTx(tx *gorm.DB) GiftCardDao {
+	return &GiftCardDaoImpl{tx}
+}
+
+// Create 创建储值卡，卡号已存在时不写入并返回 created = false
+func (d *GiftCardDaoImpl) Create(ctx context.Context, card *model.GiftCard) (created bool, err error) {
+	res := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(card)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (d *GiftCardDaoImpl) GetByCode(ctx context.Context, code string) (card *model.GiftCard, err error) {
+	card = &model.GiftCard{}
+	err = d.db.WithContext(ctx).Where("code = ?", code).First(card).Error
+	return
+}
+
+func (d *GiftCardDaoImpl) UpdateStatus(ctx context.Context, code string, status int) (rows int, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.GiftCard{}).
+		Where("code = ?", code).
+		Update("status", status)
+	return int(result.RowsAffected), result.Error
+}
+
+// AdjustBalance 按 txn.Amount 调整余额并写入流水，余额不足或扣减已停用的卡时返回 adjusted = false，应在事务中调用
+func (d *GiftCardDaoImpl) AdjustBalance(ctx context.Context, txn *model.GiftCardTransaction) (adjusted bool, err error) {
+	db := d.db.WithContext(ctx).
+		Model(&model.GiftCard{}).
+		Where("id = ?", txn.GiftCardID).
+		Where("balance + ? >= 0", txn.Amount)
+	if txn.Amount < 0 {
+		db = db.Where("status = ?", consts.GIFT_CARD_ACTIVE)
+	}
+	result := db.Update("balance", gorm.Expr("balance + ?", txn.Amount))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	card := &model.GiftCard{}
+	if err = d.db.WithContext(ctx).Select("balance").Where("id = ?", txn.GiftCardID).First(card).Error; err != nil {
+		return false, err
+	}
+	txn.BalanceAfter = card.Balance
+	if err = d.db.WithContext(ctx).Create(txn).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListTransactions 按时间倒序返回最近的余额流水
+func (d *GiftCardDaoImpl) ListTransactions(ctx context.Context, giftCardID int, limit int) (txns []*model.GiftCardTransaction, err error) {
+	err = d.db.WithContext(ctx).
+		Where("gift_card_id = ?", giftCardID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&txns).Error
+	return
+}
+
+func (d *GiftCardDaoImpl) CreateTenders(ctx context.Context, tenders []model.OrderTender) (count int, err error) {
+	result := d.db.WithContext(ctx).Create(&tenders)
+	return int(result.RowsAffected), result.Error
+}
+
+// GetTendersByOrderNo 按使用顺序返回订单使用的储值卡
+func (d *GiftCardDaoImpl) GetTendersByOrderNo(ctx context.Context, orderNo string) (tenders []*model.OrderTender, err error) {
+	err = d.db.WithContext(ctx).
+		Where("order_no = ?", orderNo).
+		Order("id").
+		Find(&tenders).Error
+	return
+}
+
+// UpdateTenderStatus 只有状态未被并发修改时才会成功
+func (d *GiftCardDaoImpl) UpdateTenderStatus(ctx context.Context, id int, oldStatus int, newStatus int) (rows int, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.OrderTender{}).
+		Where("id = ? AND status = ?", id, oldStatus).
+		Update("status", newStatus)
+	return int(result.RowsAffected), result.Error
+}
+
+// AddTenderRefund 增加已退回原卡的金额，超过抵扣金额时不更新
+func (d *GiftCardDaoImpl) AddTenderRefund(ctx context.Context, id int, amount int) (rows int, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.OrderTender{}).
+		Where("id = ? AND refunded_amount + ? <= amount", id, amount).
+		Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount))
+	return int(result.RowsAffected), result.Error
+}
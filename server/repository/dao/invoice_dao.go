@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceDao interface {
+	WithTx(tx *gorm.DB) InvoiceDao
+	NextSequence(ctx context.Context, series string) (value int, err error)
+	Create(ctx context.Context, invoice *model.Invoice) (invoiceNo string, err error)
+	GetByInvoiceNo(ctx context.Context, invoiceNo string) (invoice *model.Invoice, err error)
+	GetByOrderNo(ctx context.Context, orderNo string, invoiceType int) (invoiceList []*model.Invoice, err error)
+}
+
+var (
+	invoiceOnce            sync.Once
+	invoiceDaoImplInstance *InvoiceDaoImpl
+)
+
+type InvoiceDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetInvoiceDao() *InvoiceDaoImpl {
+	invoiceOnce.Do(func() {
+		if invoiceDaoImplInstance == nil {
+			invoiceDaoImplInstance = &InvoiceDaoImpl{repository.DB}
+		}
+	})
+	return invoiceDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *InvoiceDaoImpl) WithTx(tx *gorm.DB) InvoiceDao {
+	return &InvoiceDaoImpl{tx}
+}
+
+// NextSequence 锁定序列并返回下一个序号，必须在开具发票的事务中调用，事务提交前其他事务会等待该序列
+func (d *InvoiceDaoImpl) NextSequence(ctx context.Context, series string) (value int, err error) {
+	db := d.db.WithContext(ctx)
+	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.InvoiceSequence{Series: series}).Error
+	if err != nil {
+		return 0, err
+	}
+	sequence := &model.InvoiceSequence{}
+	err = db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("series = ?", series).First(sequence).Error
+	if err != nil {
+		return 0, err
+	}
+	sequence.LastValue++
+	err = db.Model(&model.InvoiceSequence{}).Where("series = ?", series).Update("last_value", sequence.LastValue).Error
+	return sequence.LastValue, err
+}
+
+func (d *InvoiceDaoImpl) Create(ctx context.Context, invoice *model.Invoice) (invoiceNo string, err error) {
+	err = d.db.WithContext(ctx).Create(invoice).Error
+	return invoice.InvoiceNo, err
+}
+
+func (d *InvoiceDaoImpl) GetByInvoiceNo(ctx context.Context, invoiceNo string) (invoice *model.Invoice, err error) {
+	invoice = &model.Invoice{}
+	err = d.db.WithContext(ctx).Where("invoice_no = ?", invoiceNo).First(invoice).Error
+	return
+}
+
+// GetByOrderNo 查询订单的某类发票，按开具顺序返回
+func (d *InvoiceDaoImpl) GetByOrderNo(ctx context.Context, orderNo string, invoiceType int) (invoiceList []*model.Invoice, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ? AND type = ?", orderNo, invoiceType).Order("id ASC").Find(&invoiceList).Error
+	return
+}
@@ -0,0 +1,158 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/coupon_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockCouponDao is a mock of CouponDao interface.
+type MockCouponDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockCouponDaoMockRecorder
+}
+
+// MockCouponDaoMockRecorder is the mock recorder for MockCouponDao.
+type MockCouponDaoMockRecorder struct {
+	mock *MockCouponDao
+}
+
+// NewMockCouponDao creates a new mock instance.
+func NewMockCouponDao(ctrl *gomock.Controller) *MockCouponDao {
+	mock := &MockCouponDao{ctrl: ctrl}
+	mock.recorder = &MockCouponDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCouponDao) EXPECT() *MockCouponDaoMockRecorder {
+	return m.recorder
+}
+
+// CountUserRedemptions mocks base method.
+func (m *MockCouponDao) CountUserRedemptions(ctx context.Context, couponID, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserRedemptions", ctx, couponID, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserRedemptions indicates an expected call of CountUserRedemptions.
+func (mr *MockCouponDaoMockRecorder) CountUserRedemptions(ctx, couponID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserRedemptions", reflect.TypeOf((*MockCouponDao)(nil).CountUserRedemptions), ctx, couponID, userID)
+}
+
+// Create mocks base method.
+func (m *MockCouponDao) Create(ctx context.Context, coupon *model.Coupon) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, coupon)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCouponDaoMockRecorder) Create(ctx, coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCouponDao)(nil).Create), ctx, coupon)
+}
+
+// GetByCode mocks base method.
+func (m *MockCouponDao) GetByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCode", ctx, code)
+	ret0, _ := ret[0].(*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCode indicates an expected call of GetByCode.
+func (mr *MockCouponDaoMockRecorder) GetByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCode", reflect.TypeOf((*MockCouponDao)(nil).GetByCode), ctx, code)
+}
+
+// List mocks base method.
+func (m *MockCouponDao) List(ctx context.Context, query dao.CouponQuery) ([]*model.Coupon, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].([]*model.Coupon)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockCouponDaoMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCouponDao)(nil).List), ctx, query)
+}
+
+// Redeem mocks base method.
+func (m *MockCouponDao) Redeem(ctx context.Context, redemption *model.CouponRedemption) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeem", ctx, redemption)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeem indicates an expected call of Redeem.
+func (mr *MockCouponDaoMockRecorder) Redeem(ctx, redemption interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeem", reflect.TypeOf((*MockCouponDao)(nil).Redeem), ctx, redemption)
+}
+
+// Release mocks base method.
+func (m *MockCouponDao) Release(ctx context.Context, orderNo string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, orderNo)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockCouponDaoMockRecorder) Release(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockCouponDao)(nil).Release), ctx, orderNo)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockCouponDao) UpdateStatus(ctx context.Context, code string, status int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, code, status)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockCouponDaoMockRecorder) UpdateStatus(ctx, code, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockCouponDao)(nil).UpdateStatus), ctx, code, status)
+}
+
+// WithTx mocks base method.
+func (m *MockCouponDao) WithTx(tx *gorm.DB) dao.CouponDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.CouponDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockCouponDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockCouponDao)(nil).WithTx), tx)
+}
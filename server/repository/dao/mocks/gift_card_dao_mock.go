@@ -0,0 +1,187 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/gift_card_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockGiftCardDao is a mock of GiftCardDao interface.
+type MockGiftCardDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockGiftCardDaoMockRecorder
+}
+
+// MockGiftCardDaoMockRecorder is the mock recorder for MockGiftCardDao.
+type MockGiftCardDaoMockRecorder struct {
+	mock *MockGiftCardDao
+}
+
+// NewMockGiftCardDao creates a new mock instance.
+func NewMockGiftCardDao(ctrl *gomock.Controller) *MockGiftCardDao {
+	mock := &MockGiftCardDao{ctrl: ctrl}
+	mock.recorder = &MockGiftCardDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGiftCardDao) EXPECT() *MockGiftCardDaoMockRecorder {
+	return m.recorder
+}
+
+// AddTenderRefund mocks base method.
+func (m *MockGiftCardDao) AddTenderRefund(ctx context.Context, id, amount int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTenderRefund", ctx, id, amount)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTenderRefund indicates an expected call of AddTenderRefund.
+func (mr *MockGiftCardDaoMockRecorder) AddTenderRefund(ctx, id, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTenderRefund", reflect.TypeOf((*MockGiftCardDao)(nil).AddTenderRefund), ctx, id, amount)
+}
+
+// AdjustBalance mocks base method.
+func (m *MockGiftCardDao) AdjustBalance(ctx context.Context, txn *model.GiftCardTransaction) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, txn)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockGiftCardDaoMockRecorder) AdjustBalance(ctx, txn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockGiftCardDao)(nil).AdjustBalance), ctx, txn)
+}
+
+// Create mocks base method.
+func (m *MockGiftCardDao) Create(ctx context.Context, card *model.GiftCard) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, card)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGiftCardDaoMockRecorder) Create(ctx, card interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGiftCardDao)(nil).Create), ctx, card)
+}
+
+// CreateTenders mocks base method.
+func (m *MockGiftCardDao) CreateTenders(ctx context.Context, tenders []model.OrderTender) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTenders", ctx, tenders)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTenders indicates an expected call of CreateTenders.
+func (mr *MockGiftCardDaoMockRecorder) CreateTenders(ctx, tenders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTenders", reflect.TypeOf((*MockGiftCardDao)(nil).CreateTenders), ctx, tenders)
+}
+
+// GetByCode mocks base method.
+func (m *MockGiftCardDao) GetByCode(ctx context.Context, code string) (*model.GiftCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCode", ctx, code)
+	ret0, _ := ret[0].(*model.GiftCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCode indicates an expected call of GetByCode.
+func (mr *MockGiftCardDaoMockRecorder) GetByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCode", reflect.TypeOf((*MockGiftCardDao)(nil).GetByCode), ctx, code)
+}
+
+// GetTendersByOrderNo mocks base method.
+func (m *MockGiftCardDao) GetTendersByOrderNo(ctx context.Context, orderNo string) ([]*model.OrderTender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTendersByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.OrderTender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTendersByOrderNo indicates an expected call of GetTendersByOrderNo.
+func (mr *MockGiftCardDaoMockRecorder) GetTendersByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTendersByOrderNo", reflect.TypeOf((*MockGiftCardDao)(nil).GetTendersByOrderNo), ctx, orderNo)
+}
+
+// ListTransactions mocks base method.
+func (m *MockGiftCardDao) ListTransactions(ctx context.Context, giftCardID, limit int) ([]*model.GiftCardTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, giftCardID, limit)
+	ret0, _ := ret[0].([]*model.GiftCardTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockGiftCardDaoMockRecorder) ListTransactions(ctx, giftCardID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockGiftCardDao)(nil).ListTransactions), ctx, giftCardID, limit)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockGiftCardDao) UpdateStatus(ctx context.Context, code string, status int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, code, status)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockGiftCardDaoMockRecorder) UpdateStatus(ctx, code, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockGiftCardDao)(nil).UpdateStatus), ctx, code, status)
+}
+
+// UpdateTenderStatus mocks base method.
+func (m *MockGiftCardDao) UpdateTenderStatus(ctx context.Context, id, oldStatus, newStatus int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTenderStatus", ctx, id, oldStatus, newStatus)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTenderStatus indicates an expected call of UpdateTenderStatus.
+func (mr *MockGiftCardDaoMockRecorder) UpdateTenderStatus(ctx, id, oldStatus, newStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTenderStatus", reflect.TypeOf((*MockGiftCardDao)(nil).UpdateTenderStatus), ctx, id, oldStatus, newStatus)
+}
+
+// WithTx mocks base method.
+func (m *MockGiftCardDao) WithTx(tx *gorm.DB) dao.GiftCardDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.GiftCardDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockGiftCardDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockGiftCardDao)(nil).WithTx), tx)
+}
@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/invoice_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockInvoiceDao is a mock of InvoiceDao interface.
+type MockInvoiceDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvoiceDaoMockRecorder
+}
+
+// MockInvoiceDaoMockRecorder is the mock recorder for MockInvoiceDao.
+type MockInvoiceDaoMockRecorder struct {
+	mock *MockInvoiceDao
+}
+
+// NewMockInvoiceDao creates a new mock instance.
+func NewMockInvoiceDao(ctrl *gomock.Controller) *MockInvoiceDao {
+	mock := &MockInvoiceDao{ctrl: ctrl}
+	mock.recorder = &MockInvoiceDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvoiceDao) EXPECT() *MockInvoiceDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockInvoiceDao) Create(ctx context.Context, invoice *model.Invoice) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, invoice)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockInvoiceDaoMockRecorder) Create(ctx, invoice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInvoiceDao)(nil).Create), ctx, invoice)
+}
+
+// GetByInvoiceNo mocks base method.
+func (m *MockInvoiceDao) GetByInvoiceNo(ctx context.Context, invoiceNo string) (*model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByInvoiceNo", ctx, invoiceNo)
+	ret0, _ := ret[0].(*model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByInvoiceNo indicates an expected call of GetByInvoiceNo.
+func (mr *MockInvoiceDaoMockRecorder) GetByInvoiceNo(ctx, invoiceNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByInvoiceNo", reflect.TypeOf((*MockInvoiceDao)(nil).GetByInvoiceNo), ctx, invoiceNo)
+}
+
+// GetByOrderNo mocks base method.
+func (m *MockInvoiceDao) GetByOrderNo(ctx context.Context, orderNo string, invoiceType int) ([]*model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo, invoiceType)
+	ret0, _ := ret[0].([]*model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockInvoiceDaoMockRecorder) GetByOrderNo(ctx, orderNo, invoiceType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockInvoiceDao)(nil).GetByOrderNo), ctx, orderNo, invoiceType)
+}
+
+// NextSequence mocks base method.
+func (m *MockInvoiceDao) NextSequence(ctx context.Context, series string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextSequence", ctx, series)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextSequence indicates an expected call of NextSequence.
+func (mr *MockInvoiceDaoMockRecorder) NextSequence(ctx, series interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextSequence", reflect.TypeOf((*MockInvoiceDao)(nil).NextSequence), ctx, series)
+}
+
+// WithTx mocks base method.
+func (m *MockInvoiceDao) WithTx(tx *gorm.DB) dao.InvoiceDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.InvoiceDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockInvoiceDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockInvoiceDao)(nil).WithTx), tx)
+}
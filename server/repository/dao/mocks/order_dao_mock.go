@@ -13,6 +13,7 @@ import (
 	types "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
 	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
 	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
 )
 
 // MockOrderDao is a mock of OrderDao interface.
@@ -39,18 +40,18 @@ func (m *MockOrderDao) EXPECT() *MockOrderDaoMockRecorder {
 }
 
 // AutoConfirmShippedOrders mocks base method.
-func (m *MockOrderDao) AutoConfirmShippedOrders(ctx context.Context, shippedStatus, deliveredStatus, daysThreshold int) ([]types.OrderNoAndUserId, error) {
+func (m *MockOrderDao) AutoConfirmShippedOrders(ctx context.Context, shippedStatus, deliveredStatus, daysThreshold int, stamps []string) ([]types.OrderNoAndUserId, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AutoConfirmShippedOrders", ctx, shippedStatus, deliveredStatus, daysThreshold)
+	ret := m.ctrl.Call(m, "AutoConfirmShippedOrders", ctx, shippedStatus, deliveredStatus, daysThreshold, stamps)
 	ret0, _ := ret[0].([]types.OrderNoAndUserId)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AutoConfirmShippedOrders indicates an expected call of AutoConfirmShippedOrders.
-func (mr *MockOrderDaoMockRecorder) AutoConfirmShippedOrders(ctx, shippedStatus, deliveredStatus, daysThreshold interface{}) *gomock.Call {
+func (mr *MockOrderDaoMockRecorder) AutoConfirmShippedOrders(ctx, shippedStatus, deliveredStatus, daysThreshold, stamps interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoConfirmShippedOrders", reflect.TypeOf((*MockOrderDao)(nil).AutoConfirmShippedOrders), ctx, shippedStatus, deliveredStatus, daysThreshold)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoConfirmShippedOrders", reflect.TypeOf((*MockOrderDao)(nil).AutoConfirmShippedOrders), ctx, shippedStatus, deliveredStatus, daysThreshold, stamps)
 }
 
 // Create mocks base method.
@@ -68,6 +69,21 @@ func (mr *MockOrderDaoMockRecorder) Create(ctx, o interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderDao)(nil).Create), ctx, o)
 }
 
+// GetByCreateTimeRange mocks base method.
+func (m *MockOrderDao) GetByCreateTimeRange(ctx context.Context, start, end time.Time, afterID, limit int) ([]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCreateTimeRange", ctx, start, end, afterID, limit)
+	ret0, _ := ret[0].([]*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCreateTimeRange indicates an expected call of GetByCreateTimeRange.
+func (mr *MockOrderDaoMockRecorder) GetByCreateTimeRange(ctx, start, end, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCreateTimeRange", reflect.TypeOf((*MockOrderDao)(nil).GetByCreateTimeRange), ctx, start, end, afterID, limit)
+}
+
 // GetByOrderNo mocks base method.
 func (m *MockOrderDao) GetByOrderNo(ctx context.Context, orderNo string) (*model.Order, error) {
 	m.ctrl.T.Helper()
@@ -83,6 +99,36 @@ func (mr *MockOrderDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockOrderDao)(nil).GetByOrderNo), ctx, orderNo)
 }
 
+// GetByOrderNoForUpdate mocks base method.
+func (m *MockOrderDao) GetByOrderNoForUpdate(ctx context.Context, orderNo string) (*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNoForUpdate", ctx, orderNo)
+	ret0, _ := ret[0].(*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNoForUpdate indicates an expected call of GetByOrderNoForUpdate.
+func (mr *MockOrderDaoMockRecorder) GetByOrderNoForUpdate(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNoForUpdate", reflect.TypeOf((*MockOrderDao)(nil).GetByOrderNoForUpdate), ctx, orderNo)
+}
+
+// GetByOrderNos mocks base method.
+func (m *MockOrderDao) GetByOrderNos(ctx context.Context, orderNos []string) ([]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNos", ctx, orderNos)
+	ret0, _ := ret[0].([]*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNos indicates an expected call of GetByOrderNos.
+func (mr *MockOrderDaoMockRecorder) GetByOrderNos(ctx, orderNos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNos", reflect.TypeOf((*MockOrderDao)(nil).GetByOrderNos), ctx, orderNos)
+}
+
 // GetByOrderQuery mocks base method.
 func (m *MockOrderDao) GetByOrderQuery(ctx context.Context, query dao.OrderQuery) ([]*model.Order, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +144,21 @@ func (mr *MockOrderDaoMockRecorder) GetByOrderQuery(ctx, query interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderQuery", reflect.TypeOf((*MockOrderDao)(nil).GetByOrderQuery), ctx, query)
 }
 
+// GetExpiredUnpaidOrders mocks base method.
+func (m *MockOrderDao) GetExpiredUnpaidOrders(ctx context.Context, createdStatus int, createdBefore time.Time, limit int) ([]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredUnpaidOrders", ctx, createdStatus, createdBefore, limit)
+	ret0, _ := ret[0].([]*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredUnpaidOrders indicates an expected call of GetExpiredUnpaidOrders.
+func (mr *MockOrderDaoMockRecorder) GetExpiredUnpaidOrders(ctx, createdStatus, createdBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredUnpaidOrders", reflect.TypeOf((*MockOrderDao)(nil).GetExpiredUnpaidOrders), ctx, createdStatus, createdBefore, limit)
+}
+
 // GetOrderStats mocks base method.
 func (m *MockOrderDao) GetOrderStats() (types.OrderStats, error) {
 	m.ctrl.T.Helper()
@@ -113,44 +174,31 @@ func (mr *MockOrderDaoMockRecorder) GetOrderStats() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStats", reflect.TypeOf((*MockOrderDao)(nil).GetOrderStats))
 }
 
-// UpdateStatusAndConfirmTime mocks base method.
-func (m *MockOrderDao) UpdateStatusAndConfirmTime(ctx context.Context, orderNo string, status int, t time.Time) error {
+// UpdateStatus mocks base method.
+func (m *MockOrderDao) UpdateStatus(ctx context.Context, orderNo string, fromStatus int, updates map[string]interface{}) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatusAndConfirmTime", ctx, orderNo, status, t)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// UpdateStatusAndConfirmTime indicates an expected call of UpdateStatusAndConfirmTime.
-func (mr *MockOrderDaoMockRecorder) UpdateStatusAndConfirmTime(ctx, orderNo, status, t interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusAndConfirmTime", reflect.TypeOf((*MockOrderDao)(nil).UpdateStatusAndConfirmTime), ctx, orderNo, status, t)
-}
-
-// UpdateStatusAndPayment mocks base method.
-func (m *MockOrderDao) UpdateStatusAndPayment(ctx context.Context, orderNo string, status int, payTime time.Time) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatusAndPayment", ctx, orderNo, status, payTime)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, orderNo, fromStatus, updates)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateStatusAndPayment indicates an expected call of UpdateStatusAndPayment.
-func (mr *MockOrderDaoMockRecorder) UpdateStatusAndPayment(ctx, orderNo, status, payTime interface{}) *gomock.Call {
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockOrderDaoMockRecorder) UpdateStatus(ctx, orderNo, fromStatus, updates interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusAndPayment", reflect.TypeOf((*MockOrderDao)(nil).UpdateStatusAndPayment), ctx, orderNo, status, payTime)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockOrderDao)(nil).UpdateStatus), ctx, orderNo, fromStatus, updates)
 }
 
-// UpdateStatusWithDeliveryInfo mocks base method.
-func (m *MockOrderDao) UpdateStatusWithDeliveryInfo(ctx context.Context, orderNo string, status int, t time.Time, shippingNo string) error {
+// WithTx mocks base method.
+func (m *MockOrderDao) WithTx(tx *gorm.DB) dao.OrderDao {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatusWithDeliveryInfo", ctx, orderNo, status, t, shippingNo)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.OrderDao)
 	return ret0
 }
 
-// UpdateStatusWithDeliveryInfo indicates an expected call of UpdateStatusWithDeliveryInfo.
-func (mr *MockOrderDaoMockRecorder) UpdateStatusWithDeliveryInfo(ctx, orderNo, status, t, shippingNo interface{}) *gomock.Call {
+// WithTx indicates an expected call of WithTx.
+func (mr *MockOrderDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusWithDeliveryInfo", reflect.TypeOf((*MockOrderDao)(nil).UpdateStatusWithDeliveryInfo), ctx, orderNo, status, t, shippingNo)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockOrderDao)(nil).WithTx), tx)
 }
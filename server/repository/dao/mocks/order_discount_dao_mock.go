@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/order_discount_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockOrderDiscountDao is a mock of OrderDiscountDao interface.
+type MockOrderDiscountDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderDiscountDaoMockRecorder
+}
+
+// MockOrderDiscountDaoMockRecorder is the mock recorder for MockOrderDiscountDao.
+type MockOrderDiscountDaoMockRecorder struct {
+	mock *MockOrderDiscountDao
+}
+
+// NewMockOrderDiscountDao creates a new mock instance.
+func NewMockOrderDiscountDao(ctrl *gomock.Controller) *MockOrderDiscountDao {
+	mock := &MockOrderDiscountDao{ctrl: ctrl}
+	mock.recorder = &MockOrderDiscountDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderDiscountDao) EXPECT() *MockOrderDiscountDaoMockRecorder {
+	return m.recorder
+}
+
+// CreateBatch mocks base method.
+func (m *MockOrderDiscountDao) CreateBatch(ctx context.Context, discounts []model.OrderDiscount) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, discounts)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockOrderDiscountDaoMockRecorder) CreateBatch(ctx, discounts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockOrderDiscountDao)(nil).CreateBatch), ctx, discounts)
+}
+
+// GetByOrderNo mocks base method.
+func (m *MockOrderDiscountDao) GetByOrderNo(ctx context.Context, orderNo string) ([]*model.OrderDiscount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.OrderDiscount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockOrderDiscountDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockOrderDiscountDao)(nil).GetByOrderNo), ctx, orderNo)
+}
+
+// WithTx mocks base method.
+func (m *MockOrderDiscountDao) WithTx(tx *gorm.DB) dao.OrderDiscountDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.OrderDiscountDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockOrderDiscountDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockOrderDiscountDao)(nil).WithTx), tx)
+}
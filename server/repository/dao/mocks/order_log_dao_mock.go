@@ -35,32 +35,32 @@ func (m *MockOrderLogDao) EXPECT() *MockOrderLogDaoMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method.
-func (m *MockOrderLogDao) Create(ctx context.Context, orderLog *model.OrderStatusLog) (int, error) {
+// GetByOrderNo mocks base method.
+func (m *MockOrderLogDao) GetByOrderNo(ctx context.Context, orderNo string) ([]*model.OrderStatusLog, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, orderLog)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.OrderStatusLog)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockOrderLogDaoMockRecorder) Create(ctx, orderLog interface{}) *gomock.Call {
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockOrderLogDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderLogDao)(nil).Create), ctx, orderLog)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockOrderLogDao)(nil).GetByOrderNo), ctx, orderNo)
 }
 
-// GetByOrderNo mocks base method.
-func (m *MockOrderLogDao) GetByOrderNo(ctx context.Context, orderNo string) ([]*model.OrderStatusLog, error) {
+// Upsert mocks base method.
+func (m *MockOrderLogDao) Upsert(ctx context.Context, orderLog *model.OrderStatusLog) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
-	ret0, _ := ret[0].([]*model.OrderStatusLog)
+	ret := m.ctrl.Call(m, "Upsert", ctx, orderLog)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByOrderNo indicates an expected call of GetByOrderNo.
-func (mr *MockOrderLogDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+// Upsert indicates an expected call of Upsert.
+func (mr *MockOrderLogDaoMockRecorder) Upsert(ctx, orderLog interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockOrderLogDao)(nil).GetByOrderNo), ctx, orderNo)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockOrderLogDao)(nil).Upsert), ctx, orderLog)
 }
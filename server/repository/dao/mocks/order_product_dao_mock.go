@@ -9,7 +9,9 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
 	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
 )
 
 // MockOrderProductDao is a mock of OrderProductDao interface.
@@ -79,3 +81,32 @@ func (mr *MockOrderProductDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockOrderProductDao)(nil).GetByOrderNo), ctx, orderNo)
 }
+
+// GetByOrderNos mocks base method.
+func (m *MockOrderProductDao) GetByOrderNos(ctx context.Context, orderNos []string) ([]*model.OrderProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNos", ctx, orderNos)
+	ret0, _ := ret[0].([]*model.OrderProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNos indicates an expected call of GetByOrderNos.
+func (mr *MockOrderProductDaoMockRecorder) GetByOrderNos(ctx, orderNos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNos", reflect.TypeOf((*MockOrderProductDao)(nil).GetByOrderNos), ctx, orderNos)
+}
+
+// WithTx mocks base method.
+func (m *MockOrderProductDao) WithTx(tx *gorm.DB) dao.OrderProductDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.OrderProductDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockOrderProductDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockOrderProductDao)(nil).WithTx), tx)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/order_saga_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+// MockOrderSagaDao is a mock of OrderSagaDao interface.
+type MockOrderSagaDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderSagaDaoMockRecorder
+}
+
+// MockOrderSagaDaoMockRecorder is the mock recorder for MockOrderSagaDao.
+type MockOrderSagaDaoMockRecorder struct {
+	mock *MockOrderSagaDao
+}
+
+// NewMockOrderSagaDao creates a new mock instance.
+func NewMockOrderSagaDao(ctrl *gomock.Controller) *MockOrderSagaDao {
+	mock := &MockOrderSagaDao{ctrl: ctrl}
+	mock.recorder = &MockOrderSagaDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderSagaDao) EXPECT() *MockOrderSagaDaoMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockOrderSagaDao) Claim(ctx context.Context, id int, updatedBefore time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, id, updatedBefore)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockOrderSagaDaoMockRecorder) Claim(ctx, id, updatedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockOrderSagaDao)(nil).Claim), ctx, id, updatedBefore)
+}
+
+// Create mocks base method.
+func (m *MockOrderSagaDao) Create(ctx context.Context, saga *model.OrderSaga) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, saga)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOrderSagaDaoMockRecorder) Create(ctx, saga interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderSagaDao)(nil).Create), ctx, saga)
+}
+
+// GetByOrderNo mocks base method.
+func (m *MockOrderSagaDao) GetByOrderNo(ctx context.Context, orderNo string) (*model.OrderSaga, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].(*model.OrderSaga)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockOrderSagaDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockOrderSagaDao)(nil).GetByOrderNo), ctx, orderNo)
+}
+
+// GetUnfinished mocks base method.
+func (m *MockOrderSagaDao) GetUnfinished(ctx context.Context, updatedBefore time.Time, limit int) ([]*model.OrderSaga, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnfinished", ctx, updatedBefore, limit)
+	ret0, _ := ret[0].([]*model.OrderSaga)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnfinished indicates an expected call of GetUnfinished.
+func (mr *MockOrderSagaDaoMockRecorder) GetUnfinished(ctx, updatedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnfinished", reflect.TypeOf((*MockOrderSagaDao)(nil).GetUnfinished), ctx, updatedBefore, limit)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockOrderSagaDao) UpdateProgress(ctx context.Context, saga *model.OrderSaga) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", ctx, saga)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockOrderSagaDaoMockRecorder) UpdateProgress(ctx, saga interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockOrderSagaDao)(nil).UpdateProgress), ctx, saga)
+}
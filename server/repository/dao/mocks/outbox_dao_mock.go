@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/outbox_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockOutboxDao is a mock of OutboxDao interface.
+type MockOutboxDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxDaoMockRecorder
+}
+
+// MockOutboxDaoMockRecorder is the mock recorder for MockOutboxDao.
+type MockOutboxDaoMockRecorder struct {
+	mock *MockOutboxDao
+}
+
+// NewMockOutboxDao creates a new mock instance.
+func NewMockOutboxDao(ctrl *gomock.Controller) *MockOutboxDao {
+	mock := &MockOutboxDao{ctrl: ctrl}
+	mock.recorder = &MockOutboxDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxDao) EXPECT() *MockOutboxDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOutboxDao) Create(ctx context.Context, msg *model.Outbox) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, msg)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOutboxDaoMockRecorder) Create(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOutboxDao)(nil).Create), ctx, msg)
+}
+
+// GetPending mocks base method.
+func (m *MockOutboxDao) GetPending(ctx context.Context, limit int) ([]*model.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPending", ctx, limit)
+	ret0, _ := ret[0].([]*model.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPending indicates an expected call of GetPending.
+func (mr *MockOutboxDaoMockRecorder) GetPending(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPending", reflect.TypeOf((*MockOutboxDao)(nil).GetPending), ctx, limit)
+}
+
+// GetPendingStats mocks base method.
+func (m *MockOutboxDao) GetPendingStats(ctx context.Context) (int64, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingStats", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPendingStats indicates an expected call of GetPendingStats.
+func (mr *MockOutboxDaoMockRecorder) GetPendingStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingStats", reflect.TypeOf((*MockOutboxDao)(nil).GetPendingStats), ctx)
+}
+
+// MarkSent mocks base method.
+func (m *MockOutboxDao) MarkSent(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockOutboxDaoMockRecorder) MarkSent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockOutboxDao)(nil).MarkSent), ctx, id)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockOutboxDao) UpdateDelivery(ctx context.Context, msg *model.Outbox) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockOutboxDaoMockRecorder) UpdateDelivery(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockOutboxDao)(nil).UpdateDelivery), ctx, msg)
+}
+
+// WithTx mocks base method.
+func (m *MockOutboxDao) WithTx(tx *gorm.DB) dao.OutboxDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.OutboxDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockOutboxDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockOutboxDao)(nil).WithTx), tx)
+}
@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/payment_result_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockPaymentResultDao is a mock of PaymentResultDao interface.
+type MockPaymentResultDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentResultDaoMockRecorder
+}
+
+// MockPaymentResultDaoMockRecorder is the mock recorder for MockPaymentResultDao.
+type MockPaymentResultDaoMockRecorder struct {
+	mock *MockPaymentResultDao
+}
+
+// NewMockPaymentResultDao creates a new mock instance.
+func NewMockPaymentResultDao(ctrl *gomock.Controller) *MockPaymentResultDao {
+	mock := &MockPaymentResultDao{ctrl: ctrl}
+	mock.recorder = &MockPaymentResultDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentResultDao) EXPECT() *MockPaymentResultDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPaymentResultDao) Create(ctx context.Context, result *model.PaymentResult) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, result)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPaymentResultDaoMockRecorder) Create(ctx, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentResultDao)(nil).Create), ctx, result)
+}
+
+// GetByOrderNo mocks base method.
+func (m *MockPaymentResultDao) GetByOrderNo(ctx context.Context, orderNo string) ([]*model.PaymentResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.PaymentResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockPaymentResultDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockPaymentResultDao)(nil).GetByOrderNo), ctx, orderNo)
+}
+
+// UpdateAction mocks base method.
+func (m *MockPaymentResultDao) UpdateAction(ctx context.Context, orderNo, result string, fromAction, action int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAction", ctx, orderNo, result, fromAction, action)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAction indicates an expected call of UpdateAction.
+func (mr *MockPaymentResultDaoMockRecorder) UpdateAction(ctx, orderNo, result, fromAction, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAction", reflect.TypeOf((*MockPaymentResultDao)(nil).UpdateAction), ctx, orderNo, result, fromAction, action)
+}
+
+// WithTx mocks base method.
+func (m *MockPaymentResultDao) WithTx(tx *gorm.DB) dao.PaymentResultDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.PaymentResultDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockPaymentResultDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockPaymentResultDao)(nil).WithTx), tx)
+}
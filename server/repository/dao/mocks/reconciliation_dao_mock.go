@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/reconciliation_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+// MockReconciliationDao is a mock of ReconciliationDao interface.
+type MockReconciliationDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationDaoMockRecorder
+}
+
+// MockReconciliationDaoMockRecorder is the mock recorder for MockReconciliationDao.
+type MockReconciliationDaoMockRecorder struct {
+	mock *MockReconciliationDao
+}
+
+// NewMockReconciliationDao creates a new mock instance.
+func NewMockReconciliationDao(ctrl *gomock.Controller) *MockReconciliationDao {
+	mock := &MockReconciliationDao{ctrl: ctrl}
+	mock.recorder = &MockReconciliationDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationDao) EXPECT() *MockReconciliationDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReconciliationDao) Create(ctx context.Context, discrepancy *model.ReconciliationDiscrepancy) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, discrepancy)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReconciliationDaoMockRecorder) Create(ctx, discrepancy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReconciliationDao)(nil).Create), ctx, discrepancy)
+}
+
+// List mocks base method.
+func (m *MockReconciliationDao) List(ctx context.Context, query dao.DiscrepancyQuery) ([]*model.ReconciliationDiscrepancy, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].([]*model.ReconciliationDiscrepancy)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockReconciliationDaoMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReconciliationDao)(nil).List), ctx, query)
+}
@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/refund_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockRefundDao is a mock of RefundDao interface.
+type MockRefundDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundDaoMockRecorder
+}
+
+// MockRefundDaoMockRecorder is the mock recorder for MockRefundDao.
+type MockRefundDaoMockRecorder struct {
+	mock *MockRefundDao
+}
+
+// NewMockRefundDao creates a new mock instance.
+func NewMockRefundDao(ctrl *gomock.Controller) *MockRefundDao {
+	mock := &MockRefundDao{ctrl: ctrl}
+	mock.recorder = &MockRefundDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefundDao) EXPECT() *MockRefundDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefundDao) Create(ctx context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, refund, items)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefundDaoMockRecorder) Create(ctx, refund, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefundDao)(nil).Create), ctx, refund, items)
+}
+
+// GetByOrderNo mocks base method.
+func (m *MockRefundDao) GetByOrderNo(ctx context.Context, orderNo string) ([]*model.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockRefundDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockRefundDao)(nil).GetByOrderNo), ctx, orderNo)
+}
+
+// GetByRefundNo mocks base method.
+func (m *MockRefundDao) GetByRefundNo(ctx context.Context, refundNo string) (*model.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByRefundNo", ctx, refundNo)
+	ret0, _ := ret[0].(*model.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByRefundNo indicates an expected call of GetByRefundNo.
+func (mr *MockRefundDaoMockRecorder) GetByRefundNo(ctx, refundNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByRefundNo", reflect.TypeOf((*MockRefundDao)(nil).GetByRefundNo), ctx, refundNo)
+}
+
+// GetItemsByOrderNo mocks base method.
+func (m *MockRefundDao) GetItemsByOrderNo(ctx context.Context, orderNo string) ([]*model.RefundItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.RefundItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsByOrderNo indicates an expected call of GetItemsByOrderNo.
+func (mr *MockRefundDaoMockRecorder) GetItemsByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByOrderNo", reflect.TypeOf((*MockRefundDao)(nil).GetItemsByOrderNo), ctx, orderNo)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockRefundDao) UpdateStatus(ctx context.Context, refundNo string, fromStatus int, updates map[string]interface{}) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, refundNo, fromStatus, updates)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockRefundDaoMockRecorder) UpdateStatus(ctx, refundNo, fromStatus, updates interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRefundDao)(nil).UpdateStatus), ctx, refundNo, fromStatus, updates)
+}
+
+// WithTx mocks base method.
+func (m *MockRefundDao) WithTx(tx *gorm.DB) dao.RefundDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.RefundDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockRefundDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockRefundDao)(nil).WithTx), tx)
+}
@@ -0,0 +1,142 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./dao/return_dao.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dao "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	model "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	gorm "gorm.io/gorm"
+)
+
+// MockReturnDao is a mock of ReturnDao interface.
+type MockReturnDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockReturnDaoMockRecorder
+}
+
+// MockReturnDaoMockRecorder is the mock recorder for MockReturnDao.
+type MockReturnDaoMockRecorder struct {
+	mock *MockReturnDao
+}
+
+// NewMockReturnDao creates a new mock instance.
+func NewMockReturnDao(ctrl *gomock.Controller) *MockReturnDao {
+	mock := &MockReturnDao{ctrl: ctrl}
+	mock.recorder = &MockReturnDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReturnDao) EXPECT() *MockReturnDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReturnDao) Create(ctx context.Context, ret *model.ReturnRequest, items []model.ReturnItem) (string, error) {
+	m.ctrl.T.Helper()
+	ret_2 := m.ctrl.Call(m, "Create", ctx, ret, items)
+	ret0, _ := ret_2[0].(string)
+	ret1, _ := ret_2[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReturnDaoMockRecorder) Create(ctx, ret, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReturnDao)(nil).Create), ctx, ret, items)
+}
+
+// GetByOrderNo mocks base method.
+func (m *MockReturnDao) GetByOrderNo(ctx context.Context, orderNo string) ([]*model.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderNo indicates an expected call of GetByOrderNo.
+func (mr *MockReturnDaoMockRecorder) GetByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderNo", reflect.TypeOf((*MockReturnDao)(nil).GetByOrderNo), ctx, orderNo)
+}
+
+// GetByReturnNo mocks base method.
+func (m *MockReturnDao) GetByReturnNo(ctx context.Context, returnNo string) (*model.ReturnRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByReturnNo", ctx, returnNo)
+	ret0, _ := ret[0].(*model.ReturnRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByReturnNo indicates an expected call of GetByReturnNo.
+func (mr *MockReturnDaoMockRecorder) GetByReturnNo(ctx, returnNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByReturnNo", reflect.TypeOf((*MockReturnDao)(nil).GetByReturnNo), ctx, returnNo)
+}
+
+// GetItemsByOrderNo mocks base method.
+func (m *MockReturnDao) GetItemsByOrderNo(ctx context.Context, orderNo string) ([]*model.ReturnItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsByOrderNo", ctx, orderNo)
+	ret0, _ := ret[0].([]*model.ReturnItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsByOrderNo indicates an expected call of GetItemsByOrderNo.
+func (mr *MockReturnDaoMockRecorder) GetItemsByOrderNo(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByOrderNo", reflect.TypeOf((*MockReturnDao)(nil).GetItemsByOrderNo), ctx, orderNo)
+}
+
+// GetItemsByReturnNo mocks base method.
+func (m *MockReturnDao) GetItemsByReturnNo(ctx context.Context, returnNo string) ([]*model.ReturnItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsByReturnNo", ctx, returnNo)
+	ret0, _ := ret[0].([]*model.ReturnItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsByReturnNo indicates an expected call of GetItemsByReturnNo.
+func (mr *MockReturnDaoMockRecorder) GetItemsByReturnNo(ctx, returnNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByReturnNo", reflect.TypeOf((*MockReturnDao)(nil).GetItemsByReturnNo), ctx, returnNo)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockReturnDao) UpdateStatus(ctx context.Context, returnNo string, fromStatus int, updates map[string]interface{}) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, returnNo, fromStatus, updates)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockReturnDaoMockRecorder) UpdateStatus(ctx, returnNo, fromStatus, updates interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockReturnDao)(nil).UpdateStatus), ctx, returnNo, fromStatus, updates)
+}
+
+// WithTx mocks base method.
+func (m *MockReturnDao) WithTx(tx *gorm.DB) dao.ReturnDao {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(dao.ReturnDao)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockReturnDaoMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockReturnDao)(nil).WithTx), tx)
+}
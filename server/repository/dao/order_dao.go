@@ -11,16 +11,20 @@ import (
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderDao interface {
+	WithTx(tx *gorm.DB) OrderDao
 	Create(ctx context.Context, o *model.Order) (orderNo string, err error)
-	UpdateStatusAndPayment(ctx context.Context, orderNo string, status int, payTime time.Time) error
+	UpdateStatus(ctx context.Context, orderNo string, fromStatus int, updates map[string]interface{}) (rows int, err error)
 	GetByOrderNo(ctx context.Context, orderNo string) (o *model.Order, err error)
+	GetByOrderNoForUpdate(ctx context.Context, orderNo string) (o *model.Order, err error)
+	GetByOrderNos(ctx context.Context, orderNos []string) (oList []*model.Order, err error)
 	GetByOrderQuery(ctx context.Context, query OrderQuery) (oList []*model.Order, err error)
-	UpdateStatusAndConfirmTime(ctx context.Context, orderNo string, status int, t time.Time) (err error)
-	UpdateStatusWithDeliveryInfo(ctx context.Context, orderNo string, status int, t time.Time, shippingNo string) (err error)
-	AutoConfirmShippedOrders(ctx context.Context, shippedStatus int, deliveredStatus int, daysThreshold int) (orderNos []types.OrderNoAndUserId, err error)
+	AutoConfirmShippedOrders(ctx context.Context, shippedStatus int, deliveredStatus int, daysThreshold int, stamps []string) (orderNos []types.OrderNoAndUserId, err error)
+	GetExpiredUnpaidOrders(ctx context.Context, createdStatus int, createdBefore time.Time, limit int) (oList []*model.Order, err error)
+	GetByCreateTimeRange(ctx context.Context, start, end time.Time, afterID int, limit int) (oList []*model.Order, err error)
 	GetOrderStats() (types.OrderStats, error)
 }
 
@@ -42,45 +46,43 @@ func GetOrderDao() *OrderDaoImpl {
 	return orderDaoImplInstance
 }
 
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *OrderDaoImpl) WithTx(tx *gorm.DB) OrderDao {
+	return &OrderDaoImpl{tx}
+}
+
 func (d *OrderDaoImpl) Create(ctx context.Context, o *model.Order) (orderNo string, err error) {
 	result := d.db.WithContext(ctx).Create(o)
 	return o.OrderNo, result.Error
 }
 
-func (d *OrderDaoImpl) UpdateStatusAndPayment(ctx context.Context, orderNo string, status int, payTime time.Time) error {
-	return d.db.WithContext(ctx).
+// UpdateStatus 更新订单状态及相关字段，仅当订单当前状态为 fromStatus 时才会更新
+// 返回受影响的行数，为 0 表示订单状态已被其他请求修改
+func (d *OrderDaoImpl) UpdateStatus(ctx context.Context, orderNo string, fromStatus int, updates map[string]interface{}) (rows int, err error) {
+	result := d.db.WithContext(ctx).
 		Model(&model.Order{}).
 		Where("order_no = ?", orderNo).
-		Updates(map[string]interface{}{
-			"status":   status,
-			"pay_time": payTime,
-		}).Error
+		Where("status = ?", fromStatus).
+		Updates(updates)
+	return int(result.RowsAffected), result.Error
 }
 
-func (d *OrderDaoImpl) UpdateStatusAndConfirmTime(ctx context.Context, orderNo string, status int, t time.Time) (err error) {
-	return d.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("order_no = ?", orderNo).
-		Updates(map[string]interface{}{
-			"status":       status,
-			"confirm_time": t,
-		}).Error
+func (d *OrderDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (o *model.Order, err error) {
+	o = &model.Order{}
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).First(o).Error
+	return
 }
 
-func (d *OrderDaoImpl) UpdateStatusWithDeliveryInfo(ctx context.Context, orderNo string, status int, t time.Time, shippingNo string) (err error) {
-	return d.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("order_no = ?", orderNo).
-		Updates(map[string]interface{}{
-			"status":        status,
-			"delivery_time": t,
-			"logistics_no":  shippingNo,
-		}).Error
+// GetByOrderNoForUpdate 查询订单并锁住该行直到事务结束，应在事务中调用
+func (d *OrderDaoImpl) GetByOrderNoForUpdate(ctx context.Context, orderNo string) (o *model.Order, err error) {
+	o = &model.Order{}
+	err = d.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_no = ?", orderNo).First(o).Error
+	return
 }
 
-func (d *OrderDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (o *model.Order, err error) {
-	o = &model.Order{}
-	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).First(o).Error
+// GetByOrderNos 批量查询订单，不存在的订单号不会出现在结果中
+func (d *OrderDaoImpl) GetByOrderNos(ctx context.Context, orderNos []string) (oList []*model.Order, err error) {
+	err = d.db.WithContext(ctx).Where("order_no IN ?", orderNos).Find(&oList).Error
 	return
 }
 
@@ -121,10 +123,38 @@ func (d *OrderDaoImpl) GetByOrderQuery(ctx context.Context, query OrderQuery) (o
 	return
 }
 
+// GetExpiredUnpaidOrders 查询 createdBefore 之前创建且仍为 createdStatus 的订单
+// 下单 saga 未结束的订单由 saga 恢复任务处理，不在此返回
+func (d *OrderDaoImpl) GetExpiredUnpaidOrders(ctx context.Context, createdStatus int, createdBefore time.Time, limit int) (oList []*model.Order, err error) {
+	unfinishedSaga := d.db.Model(&model.OrderSaga{}).
+		Select("1").
+		Where("order_sagas.order_no = orders.order_no").
+		Where("order_sagas.status IN ?", []int{consts.SAGA_RUNNING, consts.SAGA_COMPENSATING})
+	err = d.db.WithContext(ctx).
+		Where("status = ?", createdStatus).
+		Where("create_time <= ?", createdBefore).
+		Where("NOT EXISTS (?)", unfinishedSaga).
+		Order("id ASC").
+		Limit(limit).
+		Find(&oList).Error
+	return
+}
+
+// GetByCreateTimeRange 按 id 顺序分页查询 [start, end) 之间创建的订单，afterID 为上一页最后一个订单的 id
+func (d *OrderDaoImpl) GetByCreateTimeRange(ctx context.Context, start, end time.Time, afterID int, limit int) (oList []*model.Order, err error) {
+	err = d.db.WithContext(ctx).
+		Where("create_time >= ? AND create_time < ?", start, end).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&oList).Error
+	return
+}
+
 // AutoConfirmShippedOrders 自动确认已发货超过指定天数的订单
 // 查询 status = shippedStatus 且 delivery_time 距离当前时间大于 daysThreshold 天的订单
-// 将它们的状态更新为 deliveredStatus，并返回更新成功的订单号列表
-func (d *OrderDaoImpl) AutoConfirmShippedOrders(ctx context.Context, shippedStatus int, deliveredStatus int, daysThreshold int) (orderNos []types.OrderNoAndUserId, err error) {
+// 将它们的状态更新为 deliveredStatus，stamps 中的字段写入当前时间，并返回更新成功的订单号列表
+func (d *OrderDaoImpl) AutoConfirmShippedOrders(ctx context.Context, shippedStatus int, deliveredStatus int, daysThreshold int, stamps []string) (orderNos []types.OrderNoAndUserId, err error) {
 	// 1. 计算截止时间：当前时间 - daysThreshold 天
 	thresholdTime := time.Now().Add(-time.Duration(daysThreshold) * 24 * time.Hour)
 
@@ -158,13 +188,15 @@ func (d *OrderDaoImpl) AutoConfirmShippedOrders(ctx context.Context, shippedStat
 
 	// 4. 批量更新订单状态
 	now := time.Now()
+	updates := map[string]interface{}{"status": deliveredStatus}
+	for _, stamp := range stamps {
+		updates[stamp] = now
+	}
 	err = d.db.WithContext(ctx).
 		Model(&model.Order{}).
 		Where("order_no IN ?", orderNo).
-		Updates(map[string]interface{}{
-			"status":       deliveredStatus,
-			"confirm_time": now,
-		}).Error
+		Where("status = ?", shippedStatus).
+		Updates(updates).Error
 	if err != nil {
 		return nil, err
 	}
@@ -172,18 +204,80 @@ func (d *OrderDaoImpl) AutoConfirmShippedOrders(ctx context.Context, shippedStat
 	return orderNosAndUserIDs, nil
 }
 
+// GetOrderStats 统计已付款订单，销售额按实际支付金额计算并扣除已同意的退款；全额退款的订单不计入
+// 记录支付信息之前付款的订单没有 pay_method，按订单总金额计算
+// 按订单货币分组时使用各订单的汇率快照换算，记录货币之前的订单 currency 为空，由 service 层归入基础货币
 func (d *OrderDaoImpl) GetOrderStats() (types.OrderStats, error) {
 	var stats types.OrderStats
+	paidStatus := []int{consts.DELIVERED, consts.PAYED, consts.SHIPPED, consts.REFUNDING, consts.PARTIALLY_REFUNDED,
+		consts.RETURN_REQUESTED, consts.RETURNING}
+	const paidAmount = "CASE WHEN pay_method <> '' THEN pay_amount + tender_amount ELSE total_amount END"
+	const exchangeRate = "CASE WHEN exchange_rate > 0 THEN exchange_rate ELSE 1000000 END"
+	// SalesByCurrency 不是数据库字段，汇总结果先扫描到不含切片的结构体
+	var totals struct {
+		TotalOrders    int
+		TotalSales     int
+		TotalCustomers int
+	}
 	err := d.db.WithContext(context.Background()).
 		Model(&model.Order{}).
 		Select([]string{
 			"COUNT(order_no) AS total_orders",
-			"sum(total_amount) as total_sales",
+			"sum(" + paidAmount + ") as total_sales",
 			"count(distinct user_id) as total_customers",
-		}).Where("status in (?)", []int{consts.DELIVERED, consts.PAYED, consts.SHIPPED}).
-		Scan(&stats).Error
+		}).Where("status in (?)", paidStatus).
+		Scan(&totals).Error
 	if err != nil {
 		log.Logger.Errorf("Failed to get order stats: %v", err)
+		return stats, err
+	}
+	stats.TotalOrders, stats.TotalSales, stats.TotalCustomers = totals.TotalOrders, totals.TotalSales, totals.TotalCustomers
+
+	var currencySales []*types.CurrencySales
+	err = d.db.WithContext(context.Background()).
+		Model(&model.Order{}).
+		Select([]string{
+			"currency",
+			"COUNT(order_no) AS total_orders",
+			"COALESCE(SUM(" + paidAmount + "), 0) AS total_sales",
+			"COALESCE(FLOOR(SUM((" + paidAmount + ") * " + exchangeRate + ") / 1000000), 0) AS local_sales",
+		}).Where("status in (?)", paidStatus).
+		Group("currency").
+		Scan(&currencySales).Error
+	if err != nil {
+		log.Logger.Errorf("Failed to get order stats by currency: %v", err)
+		return stats, err
+	}
+
+	var currencyRefunds []*types.CurrencySales
+	err = d.db.WithContext(context.Background()).
+		Model(&model.Refund{}).
+		Select([]string{
+			"orders.currency AS currency",
+			"COALESCE(SUM(refunds.amount), 0) AS total_sales",
+			"COALESCE(FLOOR(SUM(refunds.amount * CASE WHEN orders.exchange_rate > 0 THEN orders.exchange_rate ELSE 1000000 END) / 1000000), 0) AS local_sales",
+		}).
+		Joins("JOIN orders ON orders.order_no = refunds.order_no").
+		Where("orders.status in (?)", paidStatus).
+		Where("refunds.status = ?", consts.REFUND_APPROVED).
+		Group("orders.currency").
+		Scan(&currencyRefunds).Error
+	if err != nil {
+		log.Logger.Errorf("Failed to get refunded amount: %v", err)
+		return stats, err
+	}
+
+	refundByCurrency := make(map[string]*types.CurrencySales, len(currencyRefunds))
+	for _, refund := range currencyRefunds {
+		stats.TotalSales -= refund.TotalSales
+		refundByCurrency[refund.Currency] = refund
+	}
+	for _, sales := range currencySales {
+		if refund, ok := refundByCurrency[sales.Currency]; ok {
+			sales.TotalSales -= refund.TotalSales
+			sales.LocalSales -= refund.LocalSales
+		}
 	}
-	return stats, err
+	stats.SalesByCurrency = currencySales
+	return stats, nil
 }
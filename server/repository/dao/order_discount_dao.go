@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+type OrderDiscountDao interface {
+	WithTx(tx *gorm.DB) OrderDiscountDao
+	CreateBatch(ctx context.Context, discounts []model.OrderDiscount) (rows int, err error)
+	GetByOrderNo(ctx context.Context, orderNo string) (discountList []*model.OrderDiscount, err error)
+}
+
+var (
+	orderDiscountOnce            sync.Once
+	orderDiscountDaoImplInstance *OrderDiscountDaoImpl
+)
+
+type OrderDiscountDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetOrderDiscountDao() *OrderDiscountDaoImpl {
+	orderDiscountOnce.Do(func() {
+		if orderDiscountDaoImplInstance == nil {
+			orderDiscountDaoImplInstance = &OrderDiscountDaoImpl{repository.DB}
+		}
+	})
+	return orderDiscountDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *OrderDiscountDaoImpl) WithTx(tx *gorm.DB) OrderDiscountDao {
+	return &OrderDiscountDaoImpl{tx}
+}
+
+func (d *OrderDiscountDaoImpl) CreateBatch(ctx context.Context, discounts []model.OrderDiscount) (rows int, err error) {
+	if len(discounts) == 0 {
+		return 0, nil
+	}
+	result := d.db.WithContext(ctx).Create(&discounts)
+	return int(result.RowsAffected), result.Error
+}
+
+func (d *OrderDiscountDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (discountList []*model.OrderDiscount, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Order("id ASC").Find(&discountList).Error
+	return
+}
@@ -7,10 +7,13 @@ import (
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderLogDao interface {
-	Create(ctx context.Context, orderLog *model.OrderStatusLog) (id int, err error)
+	// Upsert 按 event_id 幂等写入，同一事件已写入时不重复写入并返回 created=false
+	Upsert(ctx context.Context, orderLog *model.OrderStatusLog) (created bool, err error)
+	// GetByOrderNo 按事件发生时间排序返回订单的状态日志
 	GetByOrderNo(ctx context.Context, orderNo string) (orderLogList []*model.OrderStatusLog, err error)
 }
 
@@ -32,12 +35,17 @@ func GetOrderLogDao() *OrderLogDaoImpl {
 	return orderLogDao
 }
 
-func (d *OrderLogDaoImpl) Create(ctx context.Context, orderLog *model.OrderStatusLog) (id int, err error) {
-	result := d.db.WithContext(ctx).Create(orderLog)
-	return orderLog.ID, result.Error
+func (d *OrderLogDaoImpl) Upsert(ctx context.Context, orderLog *model.OrderStatusLog) (created bool, err error) {
+	res := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoNothing: true,
+	}).Create(orderLog)
+	return res.RowsAffected > 0, res.Error
 }
 
 func (d *OrderLogDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (orderLogList []*model.OrderStatusLog, err error) {
-	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Find(&orderLogList).Error
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).
+		Order("COALESCE(occurred_at, create_time), id").
+		Find(&orderLogList).Error
 	return
 }
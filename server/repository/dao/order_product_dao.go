@@ -10,9 +10,11 @@ import (
 )
 
 type OrderProductDao interface {
+	WithTx(tx *gorm.DB) OrderProductDao
 	Create(ctx context.Context, orderProduct *model.OrderProduct) (id int, err error)
 	CreateBatch(ctx context.Context, products []model.OrderProduct) (rows int, err error)
 	GetByOrderNo(ctx context.Context, orderNo string) (orderProductList []*model.OrderProduct, err error)
+	GetByOrderNos(ctx context.Context, orderNos []string) (orderProductList []*model.OrderProduct, err error)
 }
 
 var (
@@ -33,6 +35,11 @@ func GetOrderProductDao() *OrderProductDaoImpl {
 	return orderProductDaoImplInstance
 }
 
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *OrderProductDaoImpl) WithTx(tx *gorm.DB) OrderProductDao {
+	return &OrderProductDaoImpl{tx}
+}
+
 func (d *OrderProductDaoImpl) Create(ctx context.Context, orderProduct *model.OrderProduct) (id int, err error) {
 	result := d.db.WithContext(ctx).Create(orderProduct)
 	return orderProduct.ID, result.Error
@@ -52,3 +59,8 @@ func (d *OrderProductDaoImpl) GetByOrderNo(ctx context.Context, orderNo string)
 	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Find(&orderProductList).Error
 	return
 }
+
+func (d *OrderProductDaoImpl) GetByOrderNos(ctx context.Context, orderNos []string) (orderProductList []*model.OrderProduct, err error) {
+	err = d.db.WithContext(ctx).Where("order_no IN ?", orderNos).Find(&orderProductList).Error
+	return
+}
@@ -0,0 +1,86 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+type OrderSagaDao interface {
+	Create(ctx context.Context, saga *model.OrderSaga) (id int, err error)
+	UpdateProgress(ctx context.Context, saga *model.OrderSaga) error
+	GetUnfinished(ctx context.Context, updatedBefore time.Time, limit int) (sagaList []*model.OrderSaga, err error)
+	Claim(ctx context.Context, id int, updatedBefore time.Time) (claimed bool, err error)
+	GetByOrderNo(ctx context.Context, orderNo string) (saga *model.OrderSaga, err error)
+}
+
+var (
+	orderSagaOnce            sync.Once
+	orderSagaDaoImplInstance *OrderSagaDaoImpl
+)
+
+type OrderSagaDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetOrderSagaDao() *OrderSagaDaoImpl {
+	orderSagaOnce.Do(func() {
+		if orderSagaDaoImplInstance == nil {
+			orderSagaDaoImplInstance = &OrderSagaDaoImpl{repository.DB}
+		}
+	})
+	return orderSagaDaoImplInstance
+}
+
+func (d *OrderSagaDaoImpl) Create(ctx context.Context, saga *model.OrderSaga) (id int, err error) {
+	result := d.db.WithContext(ctx).Create(saga)
+	return saga.ID, result.Error
+}
+
+// UpdateProgress 保存 saga 的步骤、状态、已扣减库存的商品及失败原因
+func (d *OrderSagaDaoImpl) UpdateProgress(ctx context.Context, saga *model.OrderSaga) error {
+	return d.db.WithContext(ctx).
+		Model(&model.OrderSaga{}).
+		Where("id = ?", saga.ID).
+		Updates(map[string]interface{}{
+			"step":           saga.Step,
+			"status":         saga.Status,
+			"reserved_items": saga.ReservedItems,
+			"last_error":     saga.LastError,
+			"update_time":    time.Now(),
+		}).Error
+}
+
+// GetUnfinished 查询 updatedBefore 之前就停止推进的执行中或补偿中的 saga
+func (d *OrderSagaDaoImpl) GetUnfinished(ctx context.Context, updatedBefore time.Time, limit int) (sagaList []*model.OrderSaga, err error) {
+	err = d.db.WithContext(ctx).
+		Where("status IN ?", []int{consts.SAGA_RUNNING, consts.SAGA_COMPENSATING}).
+		Where("update_time <= ?", updatedBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&sagaList).Error
+	return
+}
+
+// Claim 仍未结束且 updatedBefore 之后没有推进过的 saga 才能被认领，认领时刷新更新时间，
+// 其他实例之后的 GetUnfinished 和 Claim 不会再取到该 saga，直到它再次停止推进
+func (d *OrderSagaDaoImpl) Claim(ctx context.Context, id int, updatedBefore time.Time) (claimed bool, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.OrderSaga{}).
+		Where("id = ?", id).
+		Where("status IN ?", []int{consts.SAGA_RUNNING, consts.SAGA_COMPENSATING}).
+		Where("update_time <= ?", updatedBefore).
+		Update("update_time", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (d *OrderSagaDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (saga *model.OrderSaga, err error) {
+	saga = &model.OrderSaga{}
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).First(saga).Error
+	return
+}
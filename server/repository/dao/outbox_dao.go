@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+type OutboxDao interface {
+	WithTx(tx *gorm.DB) OutboxDao
+	Create(ctx context.Context, msg *model.Outbox) (id int, err error)
+	GetPending(ctx context.Context, limit int) (msgList []*model.Outbox, err error)
+	MarkSent(ctx context.Context, id int) error
+	UpdateDelivery(ctx context.Context, msg *model.Outbox) error
+	GetPendingStats(ctx context.Context) (count int64, oldest time.Time, err error)
+}
+
+var (
+	outboxOnce            sync.Once
+	outboxDaoImplInstance *OutboxDaoImpl
+)
+
+type OutboxDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetOutboxDao() *OutboxDaoImpl {
+	outboxOnce.Do(func() {
+		if outboxDaoImplInstance == nil {
+			outboxDaoImplInstance = &OutboxDaoImpl{repository.DB}
+		}
+	})
+	return outboxDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *OutboxDaoImpl) WithTx(tx *gorm.DB) OutboxDao {
+	return &OutboxDaoImpl{tx}
+}
+
+func (d *OutboxDaoImpl) Create(ctx context.Context, msg *model.Outbox) (id int, err error) {
+	result := d.db.WithContext(ctx).Create(msg)
+	return msg.ID, result.Error
+}
+
+// GetPending 按 id 顺序查询待发送的消息，包括还未到重试时间的消息，由调用方保证同一 key 的顺序
+func (d *OutboxDaoImpl) GetPending(ctx context.Context, limit int) (msgList []*model.Outbox, err error) {
+	err = d.db.WithContext(ctx).
+		Where("status = ?", consts.OUTBOX_PENDING).
+		Order("id ASC").
+		Limit(limit).
+		Find(&msgList).Error
+	return
+}
+
+func (d *OutboxDaoImpl) MarkSent(ctx context.Context, id int) error {
+	return d.db.WithContext(ctx).
+		Model(&model.Outbox{}).
+		Where("id = ?", id).
+		Update("status", consts.OUTBOX_SENT).Error
+}
+
+// UpdateDelivery 保存投递失败的结果：状态、失败次数、下次重试时间及失败原因
+func (d *OutboxDaoImpl) UpdateDelivery(ctx context.Context, msg *model.Outbox) error {
+	return d.db.WithContext(ctx).
+		Model(&model.Outbox{}).
+		Where("id = ?", msg.ID).
+		Updates(map[string]interface{}{
+			"status":          msg.Status,
+			"attempts":        msg.Attempts,
+			"next_retry_time": msg.NextRetryTime,
+			"last_error":      msg.LastError,
+		}).Error
+}
+
+// GetPendingStats 统计待发送消息数量及最早一条的创建时间
+func (d *OutboxDaoImpl) GetPendingStats(ctx context.Context) (count int64, oldest time.Time, err error) {
+	var stats struct {
+		Count  int64
+		Oldest *time.Time
+	}
+	err = d.db.WithContext(ctx).
+		Model(&model.Outbox{}).
+		Select("COUNT(*) AS count, MIN(create_time) AS oldest").
+		Where("status = ?", consts.OUTBOX_PENDING).
+		Scan(&stats).Error
+	if err != nil || stats.Oldest == nil {
+		return stats.Count, time.Time{}, err
+	}
+	return stats.Count, *stats.Oldest, nil
+}
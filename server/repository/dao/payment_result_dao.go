@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentResultDao interface {
+	WithTx(tx *gorm.DB) PaymentResultDao
+	Create(ctx context.Context, result *model.PaymentResult) (created bool, err error)
+	GetByOrderNo(ctx context.Context, orderNo string) (resultList []*model.PaymentResult, err error)
+	UpdateAction(ctx context.Context, orderNo string, result string, fromAction int, action int) (rows int, err error)
+}
+
+var (
+	paymentResultOnce            sync.Once
+	paymentResultDaoImplInstance *PaymentResultDaoImpl
+)
+
+type PaymentResultDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetPaymentResultDao() *PaymentResultDaoImpl {
+	paymentResultOnce.Do(func() {
+		if paymentResultDaoImplInstance == nil {
+			paymentResultDaoImplInstance = &PaymentResultDaoImpl{repository.DB}
+		}
+	})
+	return paymentResultDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *PaymentResultDaoImpl) WithTx(tx *gorm.DB) PaymentResultDao {
+	return &PaymentResultDaoImpl{tx}
+}
+
+// Create 记录支付结果，该订单的同一结果已记录过时不写入并返回 created = false
+func (d *PaymentResultDaoImpl) Create(ctx context.Context, result *model.PaymentResult) (created bool, err error) {
+	res := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(result)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (d *PaymentResultDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (resultList []*model.PaymentResult, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Order("id ASC").Find(&resultList).Error
+	return
+}
+
+// UpdateAction 处理方式为 fromAction 时改为 action，返回更新的行数；用于保证同一支付结果只被处理一次
+func (d *PaymentResultDaoImpl) UpdateAction(ctx context.Context, orderNo string, result string, fromAction int, action int) (rows int, err error) {
+	res := d.db.WithContext(ctx).
+		Model(&model.PaymentResult{}).
+		Where("order_no = ? AND result = ?", orderNo, result).
+		Where("action = ?", fromAction).
+		Update("action", action)
+	return int(res.RowsAffected), res.Error
+}
@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReconciliationDao interface {
+	Create(ctx context.Context, discrepancy *model.ReconciliationDiscrepancy) (created bool, err error)
+	List(ctx context.Context, query DiscrepancyQuery) (dList []*model.ReconciliationDiscrepancy, total int, err error)
+}
+
+// DiscrepancyQuery 对账差异查询条件，零值表示不筛选
+type DiscrepancyQuery struct {
+	Type      int
+	OrderNo   string
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+	Offset    int
+}
+
+var (
+	reconciliationOnce            sync.Once
+	reconciliationDaoImplInstance *ReconciliationDaoImpl
+)
+
+type ReconciliationDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetReconciliationDao() *ReconciliationDaoImpl {
+	reconciliationOnce.Do(func() {
+		if reconciliationDaoImplInstance == nil {
+			reconciliationDaoImplInstance = &ReconciliationDaoImpl{repository.DB}
+		}
+	})
+	return reconciliationDaoImplInstance
+}
+
+// Create 记录对账差异，已记录过的差异不重复写入并返回 created = false
+func (d *ReconciliationDaoImpl) Create(ctx context.Context, discrepancy *model.ReconciliationDiscrepancy) (created bool, err error) {
+	res := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(discrepancy)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// List 按发现时间倒序分页查询对账差异
+func (d *ReconciliationDaoImpl) List(ctx context.Context, query DiscrepancyQuery) (dList []*model.ReconciliationDiscrepancy, total int, err error) {
+	db := d.db.WithContext(ctx).Model(&model.ReconciliationDiscrepancy{})
+	if query.Type != 0 {
+		db = db.Where("type = ?", query.Type)
+	}
+	if query.OrderNo != "" {
+		db = db.Where("order_no = ?", query.OrderNo)
+	}
+	if !query.StartTime.IsZero() {
+		db = db.Where("create_time >= ?", query.StartTime)
+	}
+	if !query.EndTime.IsZero() {
+		db = db.Where("create_time <= ?", query.EndTime)
+	}
+
+	var count int64
+	if err = db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err = db.Order("id DESC").Limit(query.Limit).Offset(query.Offset).Find(&dList).Error
+	return dList, int(count), err
+}
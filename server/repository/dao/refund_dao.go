@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+type RefundDao interface {
+	WithTx(tx *gorm.DB) RefundDao
+	Create(ctx context.Context, refund *model.Refund, items []model.RefundItem) (refundNo string, err error)
+	GetByRefundNo(ctx context.Context, refundNo string) (refund *model.Refund, err error)
+	GetByOrderNo(ctx context.Context, orderNo string) (refundList []*model.Refund, err error)
+	GetItemsByOrderNo(ctx context.Context, orderNo string) (itemList []*model.RefundItem, err error)
+	UpdateStatus(ctx context.Context, refundNo string, fromStatus int, updates map[string]interface{}) (rows int, err error)
+}
+
+var (
+	refundOnce            sync.Once
+	refundDaoImplInstance *RefundDaoImpl
+)
+
+type RefundDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetRefundDao() *RefundDaoImpl {
+	refundOnce.Do(func() {
+		if refundDaoImplInstance == nil {
+			refundDaoImplInstance = &RefundDaoImpl{repository.DB}
+		}
+	})
+	return refundDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *RefundDaoImpl) WithTx(tx *gorm.DB) RefundDao {
+	return &RefundDaoImpl{tx}
+}
+
+// Create 保存退款单及退款商品
+func (d *RefundDaoImpl) Create(ctx context.Context, refund *model.Refund, items []model.RefundItem) (refundNo string, err error) {
+	if err = d.db.WithContext(ctx).Create(refund).Error; err != nil {
+		return "", err
+	}
+	if len(items) > 0 {
+		if err = d.db.WithContext(ctx).Create(&items).Error; err != nil {
+			return "", err
+		}
+	}
+	return refund.RefundNo, nil
+}
+
+func (d *RefundDaoImpl) GetByRefundNo(ctx context.Context, refundNo string) (refund *model.Refund, err error) {
+	refund = &model.Refund{}
+	err = d.db.WithContext(ctx).Where("refund_no = ?", refundNo).First(refund).Error
+	return
+}
+
+func (d *RefundDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (refundList []*model.Refund, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Order("id ASC").Find(&refundList).Error
+	return
+}
+
+func (d *RefundDaoImpl) GetItemsByOrderNo(ctx context.Context, orderNo string) (itemList []*model.RefundItem, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Order("id ASC").Find(&itemList).Error
+	return
+}
+
+// UpdateStatus 更新退款单状态及相关字段，仅当退款单当前状态为 fromStatus 时才会更新
+func (d *RefundDaoImpl) UpdateStatus(ctx context.Context, refundNo string, fromStatus int, updates map[string]interface{}) (rows int, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.Refund{}).
+		Where("refund_no = ?", refundNo).
+		Where("status = ?", fromStatus).
+		Updates(updates)
+	return int(result.RowsAffected), result.Error
+}
@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+type ReturnDao interface {
+	WithTx(tx *gorm.DB) ReturnDao
+	Create(ctx context.Context, ret *model.ReturnRequest, items []model.ReturnItem) (returnNo string, err error)
+	GetByReturnNo(ctx context.Context, returnNo string) (ret *model.ReturnRequest, err error)
+	GetByOrderNo(ctx context.Context, orderNo string) (returnList []*model.ReturnRequest, err error)
+	GetItemsByReturnNo(ctx context.Context, returnNo string) (itemList []*model.ReturnItem, err error)
+	GetItemsByOrderNo(ctx context.Context, orderNo string) (itemList []*model.ReturnItem, err error)
+	UpdateStatus(ctx context.Context, returnNo string, fromStatus int, updates map[string]interface{}) (rows int, err error)
+}
+
+var (
+	returnOnce            sync.Once
+	returnDaoImplInstance *ReturnDaoImpl
+)
+
+type ReturnDaoImpl struct {
+	db *gorm.DB
+}
+
+func GetReturnDao() *ReturnDaoImpl {
+	returnOnce.Do(func() {
+		if returnDaoImplInstance == nil {
+			returnDaoImplInstance = &ReturnDaoImpl{repository.DB}
+		}
+	})
+	return returnDaoImplInstance
+}
+
+// WithTx 返回在事务 tx 中执行的 dao
+func (d *ReturnDaoImpl) WithTx(tx *gorm.DB) ReturnDao {
+	return &ReturnDaoImpl{tx}
+}
+
+// Create 保存退货单及退货商品
+func (d *ReturnDaoImpl) Create(ctx context.Context, ret *model.ReturnRequest, items []model.ReturnItem) (returnNo string, err error) {
+	if err = d.db.WithContext(ctx).Create(ret).Error; err != nil {
+		return "", err
+	}
+	if len(items) > 0 {
+		if err = d.db.WithContext(ctx).Create(&items).Error; err != nil {
+			return "", err
+		}
+	}
+	return ret.ReturnNo, nil
+}
+
+func (d *ReturnDaoImpl) GetByReturnNo(ctx context.Context, returnNo string) (ret *model.ReturnRequest, err error) {
+	ret = &model.ReturnRequest{}
+	err = d.db.WithContext(ctx).Where("return_no = ?", returnNo).First(ret).Error
+	return
+}
+
+func (d *ReturnDaoImpl) GetByOrderNo(ctx context.Context, orderNo string) (returnList []*model.ReturnRequest, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Order("id ASC").Find(&returnList).Error
+	return
+}
+
+func (d *ReturnDaoImpl) GetItemsByReturnNo(ctx context.Context, returnNo string) (itemList []*model.ReturnItem, err error) {
+	err = d.db.WithContext(ctx).Where("return_no = ?", returnNo).Order("id ASC").Find(&itemList).Error
+	return
+}
+
+func (d *ReturnDaoImpl) GetItemsByOrderNo(ctx context.Context, orderNo string) (itemList []*model.ReturnItem, err error) {
+	err = d.db.WithContext(ctx).Where("order_no = ?", orderNo).Order("id ASC").Find(&itemList).Error
+	return
+}
+
+// UpdateStatus 更新退货单状态及相关字段，仅当退货单当前状态为 fromStatus 时才会更新
+func (d *ReturnDaoImpl) UpdateStatus(ctx context.Context, returnNo string, fromStatus int, updates map[string]interface{}) (rows int, err error) {
+	result := d.db.WithContext(ctx).
+		Model(&model.ReturnRequest{}).
+		Where("return_no = ?", returnNo).
+		Where("status = ?", fromStatus).
+		Updates(updates)
+	return int(result.RowsAffected), result.Error
+}
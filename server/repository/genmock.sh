@@ -3,6 +3,17 @@
 mockgen -source=./dao/order_dao.go -destination=dao/mocks/order_dao_mock.go -package=mocks
 mockgen -source=./dao/order_product_dao.go -destination=dao/mocks/order_product_dao_mock.go -package=mocks
 mockgen -source=./dao/order_log_dao.go -destination=dao/mocks/order_log_dao_mock.go -package=mocks
+mockgen -source=./dao/order_saga_dao.go -destination=dao/mocks/order_saga_dao_mock.go -package=mocks
+mockgen -source=./dao/outbox_dao.go -destination=dao/mocks/outbox_dao_mock.go -package=mocks
+mockgen -source=./dao/refund_dao.go -destination=dao/mocks/refund_dao_mock.go -package=mocks
+mockgen -source=./dao/return_dao.go -destination=dao/mocks/return_dao_mock.go -package=mocks
+mockgen -source=./dao/payment_result_dao.go -destination=dao/mocks/payment_result_dao_mock.go -package=mocks
+mockgen -source=./dao/reconciliation_dao.go -destination=dao/mocks/reconciliation_dao_mock.go -package=mocks
+mockgen -source=./dao/coupon_dao.go -destination=dao/mocks/coupon_dao_mock.go -package=mocks
+mockgen -source=./dao/order_discount_dao.go -destination=dao/mocks/order_discount_dao_mock.go -package=mocks
+mockgen -source=./dao/invoice_dao.go -destination=dao/mocks/invoice_dao_mock.go -package=mocks
+mockgen -source=./dao/gift_card_dao.go -destination=dao/mocks/gift_card_dao_mock.go -package=mocks
 mockgen -source=./cache/order_stats_cache.go -destination=cache/mocks/order_stats_cache_mock.go -package=mocks
+mockgen -source=./cache/idempotency_cache.go -destination=cache/mocks/idempotency_cache_mock.go -package=mocks
 
 echo "Mocks generated successfully."
\ No newline at end of file
@@ -14,6 +14,16 @@ import (
 // mockgen -source=dao/order_dao.go -destination=dao/mocks/order_dao_mock.go -package=mocks
 // mockgen -source=dao/order_product_dao.go -destination=dao/mocks/order_product_dao_mock.go -package=mocks
 // mockgen -source=dao/order_log_dao.go -destination=dao/mocks/order_log_dao_mock.go -package=mocks
+// mockgen -source=dao/order_saga_dao.go -destination=dao/mocks/order_saga_dao_mock.go -package=mocks
+// mockgen -source=dao/outbox_dao.go -destination=dao/mocks/outbox_dao_mock.go -package=mocks
+// mockgen -source=dao/refund_dao.go -destination=dao/mocks/refund_dao_mock.go -package=mocks
+// mockgen -source=dao/return_dao.go -destination=dao/mocks/return_dao_mock.go -package=mocks
+// mockgen -source=dao/payment_result_dao.go -destination=dao/mocks/payment_result_dao_mock.go -package=mocks
+// mockgen -source=dao/reconciliation_dao.go -destination=dao/mocks/reconciliation_dao_mock.go -package=mocks
+// mockgen -source=dao/coupon_dao.go -destination=dao/mocks/coupon_dao_mock.go -package=mocks
+// mockgen -source=dao/order_discount_dao.go -destination=dao/mocks/order_discount_dao_mock.go -package=mocks
+// mockgen -source=dao/invoice_dao.go -destination=dao/mocks/invoice_dao_mock.go -package=mocks
+// mockgen -source=dao/gift_card_dao.go -destination=dao/mocks/gift_card_dao_mock.go -package=mocks
 
 var (
 	DB  *gorm.DB
@@ -47,6 +57,22 @@ func mysqlInit() {
 		&model.Order{},
 		&model.OrderProduct{},
 		&model.OrderStatusLog{},
+		&model.OrderSaga{},
+		&model.Outbox{},
+		&model.Refund{},
+		&model.RefundItem{},
+		&model.ReturnRequest{},
+		&model.ReturnItem{},
+		&model.PaymentResult{},
+		&model.ReconciliationDiscrepancy{},
+		&model.Coupon{},
+		&model.CouponRedemption{},
+		&model.OrderDiscount{},
+		&model.Invoice{},
+		&model.InvoiceSequence{},
+		&model.GiftCard{},
+		&model.GiftCardTransaction{},
+		&model.OrderTender{},
 	)
 	if err != nil {
 		panic(err)
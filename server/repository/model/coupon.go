@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Coupon 优惠券，订单通过优惠码使用
+type Coupon struct {
+	ID           int       `gorm:"primaryKey;autoIncrement"`
+	Code         string    `gorm:"type:varchar(64);unique;not null"` // 优惠码
+	Name         string    `gorm:"type:varchar(128)"`                // 名称
+	Type         int       `gorm:"type:int;not null"`                // 类型 (1-比例折扣； 2-固定减免； 3-免运费； 4-买X送Y)
+	Value        int       `gorm:"type:int;not null"`                // 比例折扣为折扣百分比 (1-100)，固定减免为减免金额
+	MaxDiscount  int       `gorm:"type:int;not null"`                // 比例折扣的最高减免金额，0 表示不限
+	MinSpend     int       `gorm:"type:int;not null"`                // 最低消费，按优惠前的商品金额计算
+	ProductID    int       `gorm:"not null"`                         // 买X送Y 限定的商品，0 表示所有商品
+	BuyQuantity  int       `gorm:"not null"`                         // 买X送Y 中的 X
+	GetQuantity  int       `gorm:"not null"`                         // 买X送Y 中的 Y
+	UsageLimit   int       `gorm:"not null"`                         // 总使用次数上限，0 表示不限
+	PerUserLimit int       `gorm:"not null"`                         // 每个用户的使用次数上限，0 表示不限
+	UsedCount    int       `gorm:"not null"`                         // 已使用次数，订单取消后退回
+	Status       int       `gorm:"type:int;not null"`                // 状态 (1-可用； 2-已停用)
+	StartTime    time.Time `gorm:"default:null"`                     // 生效时间，为空表示立即生效
+	EndTime      time.Time `gorm:"default:null"`                     // 失效时间，为空表示长期有效
+	CreateTime   time.Time `gorm:"autoCreateTime"`                   // 创建时间
+	UpdateTime   time.Time `gorm:"autoUpdateTime"`                   // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (Coupon) TableName() string {
+	return "coupons"
+}
+
+// CouponRedemption 优惠券使用记录，用于统计每个用户的使用次数及取消订单时退回
+type CouponRedemption struct {
+	ID         int       `gorm:"primaryKey;autoIncrement"`
+	CouponID   int       `gorm:"not null;uniqueIndex:idx_coupon_order;index:idx_coupon_user"`            // 优惠券ID
+	OrderNo    string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_coupon_order;index:idx_order"` // 订单编号
+	UserID     int       `gorm:"not null;index:idx_coupon_user"`                                         // 用户ID
+	Code       string    `gorm:"type:varchar(64);not null"`                                              // 优惠码
+	Discount   int       `gorm:"type:int;not null"`                                                      // 减免金额
+	Status     int       `gorm:"type:int;not null"`                                                      // 状态 (1-已使用； 2-已退回)
+	CreateTime time.Time `gorm:"autoCreateTime"`                                                         // 创建时间
+	UpdateTime time.Time `gorm:"autoUpdateTime"`                                                         // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (CouponRedemption) TableName() string {
+	return "coupon_redemptions"
+}
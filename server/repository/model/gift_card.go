@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// GiftCard 礼品卡或店铺余额账户，余额的每次变动都记录在 GiftCardTransaction 中
+type GiftCard struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	Code           string    `gorm:"type:varchar(64);unique;not null"` // 卡号，店铺余额为 SC-用户ID
+	Type           int       `gorm:"type:int;not null"`                // 类型 (1-礼品卡； 2-店铺余额)
+	UserID         int       `gorm:"not null;index"`                   // 店铺余额所属用户，礼品卡为 0
+	InitialBalance int       `gorm:"type:int;not null"`                // 发卡金额，店铺余额为 0
+	Balance        int       `gorm:"type:int;not null"`                // 可用余额，下单冻结的金额已扣除
+	Status         int       `gorm:"type:int;not null"`                // 状态 (1-可用； 2-已停用)
+	ExpireTime     time.Time `gorm:"default:null"`                     // 过期时间，为空表示长期有效
+	CreateTime     time.Time `gorm:"autoCreateTime"`                   // 创建时间
+	UpdateTime     time.Time `gorm:"autoUpdateTime"`                   // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (GiftCard) TableName() string {
+	return "gift_cards"
+}
+
+// GiftCardTransaction 余额流水，与余额变动在同一事务中写入
+type GiftCardTransaction struct {
+	ID           int       `gorm:"primaryKey;autoIncrement"`
+	GiftCardID   int       `gorm:"not null;index"`         // 储值卡ID
+	OrderNo      string    `gorm:"type:varchar(64);index"` // 订单编号，发卡时为空
+	RefundNo     string    `gorm:"type:varchar(64)"`       // 退款单号，取消订单的整单退款为空
+	Type         int       `gorm:"type:int;not null"`      // 类型 (1-发卡； 2-冻结； 3-解冻； 4-退回； 5-退款发放)
+	Amount       int       `gorm:"type:int;not null"`      // 余额变动，扣减为负数
+	BalanceAfter int       `gorm:"type:int;not null"`      // 变动后的余额
+	CreateTime   time.Time `gorm:"autoCreateTime"`         // 创建时间
+}
+
+// TableName sets the insert table name for this struct type
+func (GiftCardTransaction) TableName() string {
+	return "gift_card_transactions"
+}
+
+// OrderTender 订单使用的礼品卡或店铺余额，下单时冻结，付款后扣除，付款失败或取消时退回
+type OrderTender struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	OrderNo        string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_order_card"` // 订单编号
+	GiftCardID     int       `gorm:"not null;uniqueIndex:idx_order_card"`                  // 储值卡ID
+	Code           string    `gorm:"type:varchar(64);not null"`                            // 卡号
+	Type           int       `gorm:"type:int;not null"`                                    // 储值卡类型 (1-礼品卡； 2-店铺余额)
+	Amount         int       `gorm:"type:int;not null"`                                    // 抵扣金额
+	RefundedAmount int       `gorm:"type:int;not null"`                                    // 已退回原卡的金额
+	Status         int       `gorm:"type:int;not null"`                                    // 状态 (1-已冻结； 2-已扣除； 3-已解冻)
+	CreateTime     time.Time `gorm:"autoCreateTime"`                                       // 创建时间
+	UpdateTime     time.Time `gorm:"autoUpdateTime"`                                       // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (OrderTender) TableName() string {
+	return "order_tenders"
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Invoice 发票或贷项通知单，开具后不再修改；Snapshot 保存开具时的完整内容 (json)，下载时按快照生成
+type Invoice struct {
+	ID               int       `gorm:"primaryKey;autoIncrement"`
+	InvoiceNo        string    `gorm:"type:varchar(32);unique;not null"`                                  // 发票编号，按类型和年份连续编号
+	Type             int       `gorm:"type:int;not null;uniqueIndex:idx_type_order_refund"`               // 类型 (1-发票； 2-贷项通知单)
+	OrderNo          string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_type_order_refund;index"` // 订单编号
+	RefundNo         string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_type_order_refund"`       // 贷项通知单对应的退款单号，取消订单的整单退款为空
+	RelatedInvoiceNo string    `gorm:"type:varchar(32)"`                                                  // 贷项通知单引用的原发票编号
+	UserID           int       `gorm:"not null"`                                                          // 下单用户
+	Currency         string    `gorm:"type:varchar(3)"`                                                   // 金额使用的货币
+	TotalAmount      int       `gorm:"type:int;not null"`                                                 // 含税总金额
+	Tax              int       `gorm:"type:int;not null"`                                                 // 税额
+	Snapshot         string    `gorm:"type:text"`                                                         // 开具时的发票内容 (json)
+	IssueTime        time.Time `gorm:"not null"`                                                          // 开具时间
+	CreateTime       time.Time `gorm:"autoCreateTime"`                                                    // 创建时间
+}
+
+// TableName sets the insert table name for this struct type
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceSequence 发票编号序列，与发票在同一事务中递增，事务回滚时序号一并回滚，保证编号连续
+type InvoiceSequence struct {
+	Series    string `gorm:"type:varchar(32);primaryKey"` // 序列名称，如 INV-2025
+	LastValue int    `gorm:"not null"`                    // 已使用的最大序号
+}
+
+// TableName sets the insert table name for this struct type
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
@@ -8,8 +8,11 @@ type Order struct {
 	UserID            int       `gorm:"not null"`                         // 下单用户
 	Status            int       `gorm:"not null"`                         // 订单状态 (0-无效状态，不应该有此状态； 1-创建； 2-已付款； 3-已发货； 4-已收获； 5-取消)
 	TotalAmount       int       `gorm:"type:int;not null"`                // 总金额
-	PayAmount         int       `gorm:"type:int;not null"`                // 实际支付金额
+	PayAmount         int       `gorm:"type:int;not null"`                // 支付服务实际扣款金额
+	TenderAmount      int       `gorm:"type:int;not null;default:0"`      // 礼品卡和店铺余额抵扣的金额
 	PayTime           time.Time `gorm:"default:null"`                     // 支付时间
+	PayTransactionID  string    `gorm:"type:varchar(64)"`                 // 支付服务的支付单号
+	PayMethod         string    `gorm:"type:varchar(32)"`                 // 支付方式，为空表示未付款或记录支付信息之前付款
 	CreateTime        time.Time `gorm:"autoCreateTime"`                   // 创建时间
 	UpdateTime        time.Time `gorm:"autoUpdateTime"`                   // 更新时间
 	ReceiverFirstName string    `gorm:"type:varchar(64)"`                 // 收货人姓名
@@ -20,10 +23,20 @@ type Order struct {
 	ReceiverZipCode   int       `gorm:"type:int"`                         // 收货人邮政编码
 	ShippingFee       int       `gorm:"type:int;not null"`                // 运费
 	Tax               int       `gorm:"type:int;not null"`                // 税
+	TaxRegion         string    `gorm:"type:varchar(32)"`                 // 下单时匹配的税率规则，为空表示按 9% 计税的历史订单
+	TaxRate           int       `gorm:"type:int;not null"`                // 下单时的标准税率，万分之一
+	TaxInclusive      bool      `gorm:"not null"`                         // 商品价格是否已含税
+	Currency          string    `gorm:"type:varchar(3)"`                  // 展示给客户的订单货币，为空表示记录货币之前的订单
+	BaseCurrency      string    `gorm:"type:varchar(3)"`                  // 金额字段使用的基础货币
+	ExchangeRate      int64     `gorm:"not null"`                         // 下单时的汇率快照，见 service.EXCHANGE_RATE_SCALE
+	DiscountAmount    int       `gorm:"type:int;not null"`                // 优惠减免金额，包括商品和运费
+	CouponCode        string    `gorm:"type:varchar(64)"`                 // 使用的优惠码
 	Remark            string    `gorm:"type:varchar(256)"`                // 备注
 	LogisticsNo       string    `gorm:"type:varchar(64)"`                 // 物流单号
 	DeliveryTime      time.Time `gorm:"default:null"`                     // 发货时间
 	ConfirmTime       time.Time `gorm:"default:null"`                     // 收货确认时间
+	CancelTime        time.Time `gorm:"default:null"`                     // 取消时间
+	CancelReason      string    `gorm:"type:varchar(256)"`                // 取消原因
 }
 
 // TableName sets the insert table name for this struct type
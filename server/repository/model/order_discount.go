@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OrderDiscount 订单的优惠明细，商品减免按金额分摊到 order_products.discount_amount
+type OrderDiscount struct {
+	ID               int       `gorm:"primaryKey;autoIncrement"`
+	OrderNo          string    `gorm:"type:varchar(64);not null;index"` // 订单编号
+	CouponID         int       `gorm:"not null"`                        // 优惠券ID
+	CouponCode       string    `gorm:"type:varchar(64);not null"`       // 优惠码
+	Type             int       `gorm:"type:int;not null"`               // 优惠券类型
+	ItemDiscount     int       `gorm:"type:int;not null"`               // 商品金额减免
+	ShippingDiscount int       `gorm:"type:int;not null"`               // 运费减免
+	Description      string    `gorm:"type:varchar(256)"`               // 优惠说明
+	CreateTime       time.Time `gorm:"autoCreateTime"`                  // 创建时间
+}
+
+// TableName sets the insert table name for this struct type
+func (OrderDiscount) TableName() string {
+	return "order_discounts"
+}
@@ -4,11 +4,13 @@ import "time"
 
 type OrderStatusLog struct {
 	ID            int       `gorm:"primaryKey;autoIncrement"`
-	OrderNo       string    `gorm:"not null;index"`    // 订单号
-	UserID        int       `gorm:"int;not null"`      // 关联用户ID
-	CurrentStatus int       `gorm:"type:int;not null"` // 当前状态
-	Remark        string    `gorm:"type:varchar(256)"` // 备注
-	CreateTime    time.Time `gorm:"autoCreateTime"`    // 变更时间
+	EventID       string    `gorm:"type:varchar(36);uniqueIndex;default:null"` // 状态变更事件ID，用于消息重复投递时去重
+	OrderNo       string    `gorm:"not null;index"`                            // 订单号
+	UserID        int       `gorm:"int;not null"`                              // 关联用户ID
+	CurrentStatus int       `gorm:"type:int;not null"`                         // 当前状态
+	Remark        string    `gorm:"type:varchar(256)"`                         // 备注
+	OccurredAt    time.Time `gorm:"default:null"`                              // 状态变更发生时间
+	CreateTime    time.Time `gorm:"autoCreateTime"`                            // 日志写入时间
 }
 
 // TableName sets the insert table name for this struct type
@@ -3,15 +3,19 @@ package model
 import "time"
 
 type OrderProduct struct {
-	ID          int       `gorm:"primaryKey;autoIncrement"`
-	OrderNo     string    `gorm:"type:varchar(255);not null;index"` // 订单号
-	ProductID   int       `gorm:"not null"`                         // 商品ID
-	ProductName string    `gorm:"type:varchar(128);not null"`       // 商品名称
-	Price       int       `gorm:"type:int;not null"`                // 商品单价
-	Quantity    int       `gorm:"not null"`                         // 商品数量
-	TotalPrice  int       `gorm:"type:int;not null"`                // 商品总价
-	CreateTime  time.Time `gorm:"autoCreateTime"`                   // 创建时间
-	UpdateTime  time.Time `gorm:"autoUpdateTime"`                   // 更新时间
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	OrderNo        string    `gorm:"type:varchar(255);not null;index"` // 订单号
+	ProductID      int       `gorm:"not null"`                         // 商品ID
+	ProductName    string    `gorm:"type:varchar(128);not null"`       // 商品名称
+	Price          int       `gorm:"type:int;not null"`                // 商品单价
+	Quantity       int       `gorm:"not null"`                         // 商品数量
+	TotalPrice     int       `gorm:"type:int;not null"`                // 商品总价
+	DiscountAmount int       `gorm:"type:int;not null"`                // 分摊到该商品的优惠金额
+	TaxCategory    string    `gorm:"type:varchar(32)"`                 // 税务类别，标准税率时为空
+	TaxRate        int       `gorm:"type:int;not null"`                // 适用税率，万分之一
+	TaxAmount      int       `gorm:"type:int;not null"`                // 分摊到该商品的税额
+	CreateTime     time.Time `gorm:"autoCreateTime"`                   // 创建时间
+	UpdateTime     time.Time `gorm:"autoUpdateTime"`                   // 更新时间
 }
 
 // TableName sets the insert table name for this struct type
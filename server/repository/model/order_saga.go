@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// OrderSaga 下单 saga 的持久化步骤状态，用于实例宕机后恢复或补偿
+type OrderSaga struct {
+	ID            int       `gorm:"primaryKey;autoIncrement"`
+	OrderNo       string    `gorm:"type:varchar(64);unique;not null"` // 订单编号
+	UserID        int       `gorm:"not null"`                         // 下单用户
+	Amount        int       `gorm:"type:int;not null"`                // 需要支付服务扣款的金额，已扣除礼品卡和店铺余额抵扣的部分
+	Step          int       `gorm:"type:int;not null"`                // 已完成的步骤
+	Status        int       `gorm:"type:int;not null;index"`          // saga 状态 (1-执行中； 2-补偿中； 3-已完成； 4-已补偿)
+	Items         string    `gorm:"type:text"`                        // 下单商品及数量 (json)
+	ReservedItems string    `gorm:"type:text"`                        // 已扣减库存的商品及数量 (json)
+	LastError     string    `gorm:"type:varchar(512)"`                // 最近一次失败原因
+	CreateTime    time.Time `gorm:"autoCreateTime"`                   // 创建时间
+	UpdateTime    time.Time `gorm:"autoUpdateTime;index"`             // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (OrderSaga) TableName() string {
+	return "order_sagas"
+}
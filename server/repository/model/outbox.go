@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Outbox 与订单数据在同一事务中写入的待发送 kafka 消息，由 relay 任务投递
+type Outbox struct {
+	ID            int       `gorm:"primaryKey;autoIncrement"`
+	Topic         string    `gorm:"type:varchar(128);not null"` // kafka topic
+	MsgKey        string    `gorm:"type:varchar(64);index"`     // 消息 key，通常为订单编号，同一 key 按 id 顺序投递
+	Payload       string    `gorm:"type:text"`                  // 消息内容
+	Status        int       `gorm:"type:int;not null;index"`    // 状态 (1-待发送； 2-已发送； 3-重试耗尽)
+	Attempts      int       `gorm:"type:int;not null"`          // 已投递失败次数
+	NextRetryTime time.Time `gorm:"default:null"`               // 下次可重试时间
+	LastError     string    `gorm:"type:varchar(512)"`          // 最近一次投递失败原因
+	CreateTime    time.Time `gorm:"autoCreateTime"`             // 创建时间
+	UpdateTime    time.Time `gorm:"autoUpdateTime"`             // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (Outbox) TableName() string {
+	return "outbox"
+}
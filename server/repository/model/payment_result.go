@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// PaymentResult 已处理的支付结果，同一订单的同一结果只处理一次，用于对重复通知去重
+type PaymentResult struct {
+	ID         int       `gorm:"primaryKey;autoIncrement"`
+	OrderNo    string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_order_result"` // 订单编号
+	Result     string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_order_result"` // 支付结果 (SUCCESS / FAILED)
+	PayOrderID string    `gorm:"type:varchar(64)"`                                       // 支付单号
+	UserID     int       `gorm:"not null"`                                               // 付款用户
+	Amount     int       `gorm:"type:int;not null"`                                      // 支付金额
+	Action     int       `gorm:"type:int;not null"`                                      // 处理方式 (1-已付款； 2-已取消； 3-已退款； 4-忽略； 5-saga 处理)
+	ErrorMsg   string    `gorm:"type:varchar(256)"`                                      // 支付失败原因
+	CreateTime time.Time `gorm:"autoCreateTime"`                                         // 创建时间
+}
+
+// TableName sets the insert table name for this struct type
+func (PaymentResult) TableName() string {
+	return "payment_results"
+}
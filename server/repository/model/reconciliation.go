@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// ReconciliationDiscrepancy 对账任务发现的订单与支付记录之间的差异，同一订单的同一类差异只记录一次
+type ReconciliationDiscrepancy struct {
+	ID          int       `gorm:"primaryKey;autoIncrement"`
+	OrderNo     string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_order_type"` // 订单编号
+	Type        int       `gorm:"type:int;not null;uniqueIndex:idx_order_type"`         // 差异类型 (1-缺少支付单； 2-支付单无对应已付款订单； 3-金额不一致)
+	UserID      int       `gorm:"not null"`                                             // 下单用户
+	OrderStatus int       `gorm:"type:int;not null"`                                    // 对账时的订单状态
+	OrderAmount int       `gorm:"type:int;not null"`                                    // 订单记录的支付服务扣款金额，不含储值卡抵扣
+	PaidAmount  int       `gorm:"type:int;not null"`                                    // 支付服务中的支付金额合计
+	PayOrderIDs string    `gorm:"type:varchar(512)"`                                    // 支付单号，多个以逗号分隔
+	OrderTime   time.Time `gorm:"not null"`                                             // 下单时间
+	CreateTime  time.Time `gorm:"autoCreateTime;index"`                                 // 发现时间
+}
+
+// TableName sets the insert table name for this struct type
+func (ReconciliationDiscrepancy) TableName() string {
+	return "reconciliation_discrepancies"
+}
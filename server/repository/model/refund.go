@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Refund 退款单，一个订单可以有多次部分退款
+type Refund struct {
+	ID            int       `gorm:"primaryKey;autoIncrement"`
+	RefundNo      string    `gorm:"type:varchar(64);unique;not null"` // 退款单号
+	OrderNo       string    `gorm:"type:varchar(64);not null;index"`  // 订单编号
+	UserID        int       `gorm:"not null"`                         // 申请用户
+	Amount        int       `gorm:"type:int;not null"`                // 退款金额
+	Status        int       `gorm:"type:int;not null"`                // 退款状态 (1-待审核； 2-已同意； 3-已拒绝)
+	PrevStatus    int       `gorm:"type:int;not null"`                // 申请退款前的订单状态，拒绝后恢复
+	Reason        string    `gorm:"type:varchar(256)"`                // 退款原因
+	ToStoreCredit bool      `gorm:"not null;default:false"`           // 是否退为店铺余额
+	RejectReason  string    `gorm:"type:varchar(256)"`                // 拒绝原因
+	ReviewTime    time.Time `gorm:"default:null"`                     // 审核时间
+	CreateTime    time.Time `gorm:"autoCreateTime"`                   // 创建时间
+	UpdateTime    time.Time `gorm:"autoUpdateTime"`                   // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundItem 退款单中的商品，引用 order_products 中的记录
+type RefundItem struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	RefundNo       string    `gorm:"type:varchar(64);not null;index"` // 退款单号
+	OrderNo        string    `gorm:"type:varchar(64);not null;index"` // 订单编号
+	OrderProductID int       `gorm:"not null"`                        // 订单商品ID (order_products.id)
+	ProductID      int       `gorm:"not null"`                        // 商品ID
+	Quantity       int       `gorm:"not null"`                        // 退款数量
+	Amount         int       `gorm:"type:int;not null"`               // 该商品的退款金额
+	CreateTime     time.Time `gorm:"autoCreateTime"`                  // 创建时间
+}
+
+// TableName sets the insert table name for this struct type
+func (RefundItem) TableName() string {
+	return "refund_items"
+}
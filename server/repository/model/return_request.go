@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// ReturnRequest 退货单，商家收到退回的商品后退款
+type ReturnRequest struct {
+	ID               int       `gorm:"primaryKey;autoIncrement"`
+	ReturnNo         string    `gorm:"type:varchar(64);unique;not null"` // 退货单号
+	OrderNo          string    `gorm:"type:varchar(64);not null;index"`  // 订单编号
+	UserID           int       `gorm:"not null"`                         // 申请用户
+	Status           int       `gorm:"type:int;not null"`                // 退货状态 (1-待审核； 2-已同意； 3-已拒绝； 4-已收货)
+	PrevStatus       int       `gorm:"type:int;not null;default:0"`      // 申请退货前的订单状态，拒绝后恢复，为 0 时是已收货
+	Reason           string    `gorm:"type:varchar(256)"`                // 退货原因
+	PhotoUrls        string    `gorm:"type:text"`                        // 商品照片，JSON 数组
+	RefundAmount     int       `gorm:"type:int;not null"`                // 收货后的退款金额
+	ReturnLabel      string    `gorm:"type:varchar(512)"`                // 退货面单
+	ReturnTrackingNo string    `gorm:"type:varchar(64)"`                 // 退货物流单号
+	RejectReason     string    `gorm:"type:varchar(256)"`                // 拒绝原因
+	RefundNo         string    `gorm:"type:varchar(64)"`                 // 收货后生成的退款单号
+	Restock          bool      `gorm:"not null;default:false"`           // 收货时是否回补库存
+	ToStoreCredit    bool      `gorm:"not null;default:false"`           // 收货后是否退为店铺余额
+	ReviewTime       time.Time `gorm:"default:null"`                     // 审核时间
+	ReceiveTime      time.Time `gorm:"default:null"`                     // 收货时间
+	CreateTime       time.Time `gorm:"autoCreateTime"`                   // 创建时间
+	UpdateTime       time.Time `gorm:"autoUpdateTime"`                   // 更新时间
+}
+
+// TableName sets the insert table name for this struct type
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
+
+// ReturnItem 退货单中的商品，引用 order_products 中的记录
+type ReturnItem struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	ReturnNo       string    `gorm:"type:varchar(64);not null;index"` // 退货单号
+	OrderNo        string    `gorm:"type:varchar(64);not null;index"` // 订单编号
+	OrderProductID int       `gorm:"not null"`                        // 订单商品ID (order_products.id)
+	ProductID      int       `gorm:"not null"`                        // 商品ID
+	Quantity       int       `gorm:"not null"`                        // 退货数量
+	CreateTime     time.Time `gorm:"autoCreateTime"`                  // 创建时间
+}
+
+// TableName sets the insert table name for this struct type
+func (ReturnItem) TableName() string {
+	return "return_items"
+}
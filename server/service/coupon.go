@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+var ErrCouponCodeExists = errors.New("coupon code already exists")
+
+// CouponService 商家管理优惠券，下单时的使用由 OrderServiceImpl 处理
+type CouponService struct {
+	couponDao dao.CouponDao
+}
+
+func GetCouponServiceInstance() *CouponService {
+	return &CouponService{
+		couponDao: dao.GetCouponDao(),
+	}
+}
+
+// CreateCoupon 校验优惠规则后创建优惠券，新建的优惠券立即可用
+func (c *CouponService) CreateCoupon(ctx context.Context, req types.CreateCouponRequest) (info *types.CouponInfo, err error) {
+	if err = validateCouponRequest(req); err != nil {
+		return nil, err
+	}
+	coupon := &model.Coupon{
+		Code:         req.Code,
+		Name:         req.Name,
+		Type:         req.Type,
+		Value:        req.Value,
+		MaxDiscount:  req.MaxDiscount,
+		MinSpend:     req.MinSpend,
+		ProductID:    req.ProductID,
+		BuyQuantity:  req.BuyQuantity,
+		GetQuantity:  req.GetQuantity,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		Status:       consts.COUPON_ACTIVE,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+	}
+	created, err := c.couponDao.Create(ctx, coupon)
+	if err != nil {
+		log.Logger.Errorf("CreateCoupon: create failed, code: %s, err: %s", req.Code, err.Error())
+		return nil, err
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: %s", ErrCouponCodeExists, req.Code)
+	}
+	return toCouponInfo(coupon), nil
+}
+
+// validateCouponRequest 校验每种优惠券类型需要的参数
+func validateCouponRequest(req types.CreateCouponRequest) error {
+	if req.MinSpend < 0 || req.MaxDiscount < 0 || req.UsageLimit < 0 || req.PerUserLimit < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidCoupon)
+	}
+	if !req.StartTime.IsZero() && !req.EndTime.IsZero() && !req.EndTime.After(req.StartTime) {
+		return fmt.Errorf("%w: end time must be after start time", ErrInvalidCoupon)
+	}
+	switch req.Type {
+	case consts.COUPON_PERCENTAGE:
+		if req.Value <= 0 || req.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 1 and 100", ErrInvalidCoupon)
+		}
+	case consts.COUPON_FIXED:
+		if req.Value <= 0 {
+			return fmt.Errorf("%w: fixed discount must be positive", ErrInvalidCoupon)
+		}
+	case consts.COUPON_FREE_SHIPPING:
+	case consts.COUPON_BUY_X_GET_Y:
+		if req.BuyQuantity <= 0 || req.GetQuantity <= 0 {
+			return fmt.Errorf("%w: buy and get quantities must be positive", ErrInvalidCoupon)
+		}
+	default:
+		return fmt.Errorf("%w: unknown coupon type %d", ErrInvalidCoupon, req.Type)
+	}
+	return nil
+}
+
+// ListCoupons 分页查询优惠券
+func (c *CouponService) ListCoupons(ctx context.Context, req types.ListCouponRequest) (resp *types.ListCouponResponse, err error) {
+	couponList, total, err := c.couponDao.List(ctx, dao.CouponQuery{
+		Status: req.Status,
+		Type:   req.Type,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		log.Logger.Errorf("ListCoupons: list failed, err: %s", err.Error())
+		return nil, err
+	}
+
+	resp = &types.ListCouponResponse{
+		Coupons: make([]*types.CouponInfo, 0, len(couponList)),
+		Total:   total,
+	}
+	for _, coupon := range couponList {
+		resp.Coupons = append(resp.Coupons, toCouponInfo(coupon))
+	}
+	return resp, nil
+}
+
+// UpdateCouponStatus 启用或停用优惠券，停用后已使用的订单不受影响
+func (c *CouponService) UpdateCouponStatus(ctx context.Context, code string, status int) (err error) {
+	if status != consts.COUPON_ACTIVE && status != consts.COUPON_DISABLED {
+		return fmt.Errorf("%w: unknown coupon status %d", ErrInvalidCoupon, status)
+	}
+	coupon, err := c.couponDao.GetByCode(ctx, code)
+	if err != nil {
+		log.Logger.Errorf("UpdateCouponStatus: get coupon failed, code: %s, err: %s", code, err.Error())
+		return err
+	}
+	if coupon.Status == status {
+		return nil
+	}
+	if _, err = c.couponDao.UpdateStatus(ctx, code, status); err != nil {
+		log.Logger.Errorf("UpdateCouponStatus: update failed, code: %s, err: %s", code, err.Error())
+		return err
+	}
+	return nil
+}
+
+func toCouponInfo(coupon *model.Coupon) *types.CouponInfo {
+	return &types.CouponInfo{
+		ID:           coupon.ID,
+		Code:         coupon.Code,
+		Name:         coupon.Name,
+		Type:         coupon.Type,
+		TypeName:     consts.GetCouponTypeName(coupon.Type),
+		Value:        coupon.Value,
+		MaxDiscount:  coupon.MaxDiscount,
+		MinSpend:     coupon.MinSpend,
+		ProductID:    coupon.ProductID,
+		BuyQuantity:  coupon.BuyQuantity,
+		GetQuantity:  coupon.GetQuantity,
+		UsageLimit:   coupon.UsageLimit,
+		PerUserLimit: coupon.PerUserLimit,
+		UsedCount:    coupon.UsedCount,
+		Status:       coupon.Status,
+		StatusName:   consts.GetCouponStatusName(coupon.Status),
+		StartTime:    coupon.StartTime,
+		EndTime:      coupon.EndTime,
+		CreateTime:   coupon.CreateTime,
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+// TestCouponService_CreateCoupon tests a valid coupon is created active
+func TestCouponService_CreateCoupon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	couponDao := daoMocks.NewMockCouponDao(ctrl)
+	service := &CouponService{couponDao: couponDao}
+	ctx := context.Background()
+
+	couponDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, coupon *model.Coupon) (bool, error) {
+			if coupon.Status != consts.COUPON_ACTIVE || coupon.Value != 15 || coupon.MaxDiscount != 2000 {
+				t.Errorf("Unexpected coupon: %+v", coupon)
+			}
+			return true, nil
+		})
+
+	info, err := service.CreateCoupon(ctx, types.CreateCouponRequest{Code: "SPRING15", Type: consts.COUPON_PERCENTAGE, Value: 15, MaxDiscount: 2000})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if info.TypeName != "Percentage" || info.StatusName != "Active" {
+		t.Errorf("Unexpected coupon info: %+v", info)
+	}
+}
+
+// TestCouponService_CreateCoupon_Invalid tests coupon rules are validated per type
+func TestCouponService_CreateCoupon_Invalid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		req  types.CreateCouponRequest
+	}{
+		{name: "percentage over 100", req: types.CreateCouponRequest{Type: consts.COUPON_PERCENTAGE, Value: 120}},
+		{name: "fixed without value", req: types.CreateCouponRequest{Type: consts.COUPON_FIXED}},
+		{name: "buy x get y without get", req: types.CreateCouponRequest{Type: consts.COUPON_BUY_X_GET_Y, BuyQuantity: 2}},
+		{name: "negative limit", req: types.CreateCouponRequest{Type: consts.COUPON_FREE_SHIPPING, PerUserLimit: -1}},
+		{name: "end before start", req: types.CreateCouponRequest{Type: consts.COUPON_FREE_SHIPPING, StartTime: now, EndTime: now.Add(-time.Hour)}},
+		{name: "unknown type", req: types.CreateCouponRequest{Type: 99}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			couponDao := daoMocks.NewMockCouponDao(ctrl)
+			couponDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+			service := &CouponService{couponDao: couponDao}
+
+			tt.req.Code = "BAD"
+			if _, err := service.CreateCoupon(context.Background(), tt.req); !errors.Is(err, ErrInvalidCoupon) {
+				t.Errorf("Expected ErrInvalidCoupon, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestCouponService_CreateCoupon_Duplicate tests an existing code is reported
+func TestCouponService_CreateCoupon_Duplicate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	couponDao := daoMocks.NewMockCouponDao(ctrl)
+	service := &CouponService{couponDao: couponDao}
+	ctx := context.Background()
+
+	couponDao.EXPECT().Create(ctx, gomock.Any()).Return(false, nil)
+
+	_, err := service.CreateCoupon(ctx, types.CreateCouponRequest{Code: "FREESHIP", Type: consts.COUPON_FREE_SHIPPING})
+	if !errors.Is(err, ErrCouponCodeExists) {
+		t.Errorf("Expected ErrCouponCodeExists, got: %v", err)
+	}
+}
+
+// TestCouponService_UpdateCouponStatus tests a coupon is disabled and an unchanged status is not written
+func TestCouponService_UpdateCouponStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	couponDao := daoMocks.NewMockCouponDao(ctrl)
+	service := &CouponService{couponDao: couponDao}
+	ctx := context.Background()
+
+	couponDao.EXPECT().GetByCode(ctx, "SAVE10").Return(&model.Coupon{Code: "SAVE10", Status: consts.COUPON_ACTIVE}, nil)
+	couponDao.EXPECT().UpdateStatus(ctx, "SAVE10", consts.COUPON_DISABLED).Return(1, nil)
+	if err := service.UpdateCouponStatus(ctx, "SAVE10", consts.COUPON_DISABLED); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	couponDao.EXPECT().GetByCode(ctx, "OFF").Return(&model.Coupon{Code: "OFF", Status: consts.COUPON_DISABLED}, nil)
+	if err := service.UpdateCouponStatus(ctx, "OFF", consts.COUPON_DISABLED); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := service.UpdateCouponStatus(ctx, "SAVE10", 9); !errors.Is(err, ErrInvalidCoupon) {
+		t.Errorf("Expected ErrInvalidCoupon, got: %v", err)
+	}
+}
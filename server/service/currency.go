@@ -0,0 +1,252 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+const (
+	DEFAULT_BASE_CURRENCY = "SGD"
+	DEFAULT_MINOR_UNIT    = 100
+	// EXCHANGE_RATE_SCALE 汇率按最小货币单位保存：1e6 个基础货币最小单位可兑换 ExchangeRate 个订单货币最小单位
+	EXCHANGE_RATE_SCALE = 1000000
+)
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// Currency 订单货币，ExchangeRate 为相对基础货币的汇率，见 EXCHANGE_RATE_SCALE
+type Currency struct {
+	Code         string
+	Symbol       string
+	MinorUnit    int
+	ExchangeRate int64
+}
+
+// CurrencyProvider 提供基础货币、订单货币及汇率
+type CurrencyProvider interface {
+	Base() *Currency
+	// Resolve 优先使用客户指定的货币，其次使用收货国家的货币，都没有时使用基础货币
+	Resolve(requested string, country string) (*Currency, error)
+	// Get 按货币代码查询，未配置的货币按 2 位小数、以货币代码为前缀格式化
+	Get(code string) *Currency
+}
+
+type configCurrencyProvider struct {
+	base       *Currency
+	currencies map[string]*Currency
+	countries  map[string]*Currency
+}
+
+// NewCurrencyProvider 由配置生成 CurrencyProvider，cfg 为空时所有订单使用基础货币
+func NewCurrencyProvider(cfg *config.CurrencyConfig) CurrencyProvider {
+	provider := &configCurrencyProvider{
+		currencies: make(map[string]*Currency),
+		countries:  make(map[string]*Currency),
+	}
+	baseCode := DEFAULT_BASE_CURRENCY
+	if cfg != nil && cfg.BaseCurrency != "" {
+		baseCode = strings.ToUpper(cfg.BaseCurrency)
+	}
+	baseMinorUnit := DEFAULT_MINOR_UNIT
+	if cfg != nil {
+		for _, rate := range cfg.Currencies {
+			if strings.EqualFold(rate.Code, baseCode) && rate.MinorUnit > 0 {
+				baseMinorUnit = rate.MinorUnit
+			}
+		}
+	}
+	provider.base = &Currency{Code: baseCode, Symbol: baseCode + " ", MinorUnit: baseMinorUnit, ExchangeRate: EXCHANGE_RATE_SCALE}
+	provider.currencies[baseCode] = provider.base
+	if cfg == nil {
+		return provider
+	}
+
+	for _, rate := range cfg.Currencies {
+		code := strings.ToUpper(rate.Code)
+		currency := &Currency{Code: code, Symbol: rate.Symbol, MinorUnit: rate.MinorUnit}
+		if currency.Symbol == "" {
+			currency.Symbol = code + " "
+		}
+		if currency.MinorUnit <= 0 {
+			currency.MinorUnit = DEFAULT_MINOR_UNIT
+		}
+		if code == baseCode {
+			currency.ExchangeRate = EXCHANGE_RATE_SCALE
+			provider.base = currency
+		} else {
+			// 换算为最小单位之间的汇率
+			currency.ExchangeRate = int64(math.Round(rate.Rate * EXCHANGE_RATE_SCALE * float64(currency.MinorUnit) / float64(baseMinorUnit)))
+		}
+		provider.currencies[code] = currency
+		for _, country := range rate.Countries {
+			provider.countries[strings.ToUpper(country)] = currency
+		}
+	}
+	return provider
+}
+
+func getCurrencyProvider() CurrencyProvider {
+	return NewCurrencyProvider(config.Config.CurrencyConfig)
+}
+
+func (p *configCurrencyProvider) Base() *Currency {
+	return p.base
+}
+
+func (p *configCurrencyProvider) Resolve(requested string, country string) (*Currency, error) {
+	if requested != "" {
+		currency, ok := p.currencies[strings.ToUpper(requested)]
+		if !ok || currency.ExchangeRate <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, requested)
+		}
+		return currency, nil
+	}
+	if currency, ok := p.countries[strings.ToUpper(country)]; ok && currency.ExchangeRate > 0 {
+		return currency, nil
+	}
+	return p.base, nil
+}
+
+func (p *configCurrencyProvider) Get(code string) *Currency {
+	if code == "" {
+		return p.base
+	}
+	if currency, ok := p.currencies[strings.ToUpper(code)]; ok {
+		return currency
+	}
+	return &Currency{Code: code, Symbol: code + " ", MinorUnit: DEFAULT_MINOR_UNIT}
+}
+
+// getCurrencies 未注入 currencyProvider 时只使用基础货币
+func (o *OrderServiceImpl) getCurrencies() CurrencyProvider {
+	if o.currencyProvider == nil {
+		return NewCurrencyProvider(nil)
+	}
+	return o.currencyProvider
+}
+
+// convertAmount 按汇率快照把基础货币金额换算为订单货币，四舍五入到最小单位
+func convertAmount(amount int, exchangeRate int64) int {
+	if exchangeRate <= 0 {
+		return amount
+	}
+	converted := int64(amount) * exchangeRate
+	if converted >= 0 {
+		return int((converted + EXCHANGE_RATE_SCALE/2) / EXCHANGE_RATE_SCALE)
+	}
+	return int((converted - EXCHANGE_RATE_SCALE/2) / EXCHANGE_RATE_SCALE)
+}
+
+// formatMoney 按货币的最小单位格式化金额，如 S$1,234.50、¥1,234
+func formatMoney(amount int, currency *Currency) types.Money {
+	sign := ""
+	abs := amount
+	if abs < 0 {
+		sign, abs = "-", -abs
+	}
+	minorUnit := max(currency.MinorUnit, 1)
+	formatted := groupThousands(abs / minorUnit)
+	if decimals := len(strconv.Itoa(minorUnit)) - 1; decimals > 0 {
+		formatted += fmt.Sprintf(".%0*d", decimals, abs%minorUnit)
+	}
+	return types.Money{
+		Amount:    amount,
+		Currency:  currency.Code,
+		Formatted: sign + currency.Symbol + formatted,
+	}
+}
+
+func groupThousands(n int) string {
+	digits := strconv.Itoa(n)
+	var sb strings.Builder
+	for idx, digit := range digits {
+		if idx > 0 && (len(digits)-idx)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	return sb.String()
+}
+
+// orderAmountValues 以基础货币计算的订单金额
+type orderAmountValues struct {
+	itemTotalAmount int
+	shippingFee     int
+	tax             int
+	discountAmount  int
+	totalAmount     int
+	payAmount       int
+	refundedAmount  int
+}
+
+// formatOrderAmounts 按汇率快照把订单金额换算为 currency 并格式化，历史订单不受之后汇率变化影响
+func formatOrderAmounts(values orderAmountValues, currency *Currency, exchangeRate int64) *types.OrderAmounts {
+	money := func(amount int) types.Money {
+		return formatMoney(convertAmount(amount, exchangeRate), currency)
+	}
+	return &types.OrderAmounts{
+		ItemTotalAmount: money(values.itemTotalAmount),
+		ShippingFee:     money(values.shippingFee),
+		Tax:             money(values.tax),
+		DiscountAmount:  money(values.discountAmount),
+		TotalAmount:     money(values.totalAmount),
+		PayAmount:       money(values.payAmount),
+		RefundedAmount:  money(values.refundedAmount),
+	}
+}
+
+// setOrderDetailAmounts 填充订单详情的格式化金额，记录货币之前的订单按基础货币展示
+func (o *OrderServiceImpl) setOrderDetailAmounts(detail *types.OrderDetail) {
+	currencies := o.getCurrencies()
+	if detail.BaseCurrency == "" {
+		detail.BaseCurrency = currencies.Base().Code
+	}
+	if detail.Currency == "" || detail.ExchangeRate <= 0 {
+		detail.Currency, detail.ExchangeRate = detail.BaseCurrency, EXCHANGE_RATE_SCALE
+	}
+	values := orderAmountValues{
+		shippingFee:    detail.ShippingFee,
+		tax:            detail.Tax,
+		discountAmount: detail.DiscountAmount,
+		totalAmount:    detail.TotalAmount,
+		payAmount:      detail.PayAmount,
+		refundedAmount: detail.RefundedAmount,
+	}
+	for _, item := range detail.OrderItems {
+		values.itemTotalAmount += item.TotalPrice
+	}
+	detail.Amounts = formatOrderAmounts(values, currencies.Get(detail.Currency), detail.ExchangeRate)
+	detail.BaseAmounts = formatOrderAmounts(values, currencies.Get(detail.BaseCurrency), EXCHANGE_RATE_SCALE)
+}
+
+// mergeCurrencySales 把记录货币之前的订单归入基础货币，按货币代码排序；返回新的切片，不修改缓存中的数据
+func mergeCurrencySales(salesList []*types.CurrencySales, baseCurrency string) []*types.CurrencySales {
+	merged := make(map[string]*types.CurrencySales, len(salesList))
+	for _, sales := range salesList {
+		currency := sales.Currency
+		if currency == "" {
+			currency = baseCurrency
+		}
+		if _, ok := merged[currency]; !ok {
+			merged[currency] = &types.CurrencySales{Currency: currency}
+		}
+		merged[currency].TotalOrders += sales.TotalOrders
+		merged[currency].TotalSales += sales.TotalSales
+		merged[currency].LocalSales += sales.LocalSales
+	}
+	result := make([]*types.CurrencySales, 0, len(merged))
+	for _, sales := range merged {
+		result = append(result, sales)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Currency < result[j].Currency
+	})
+	return result
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// testCurrencyConfig 基础货币 SGD，1 SGD = 0.74 USD = 112 JPY
+func testCurrencyConfig() *config.CurrencyConfig {
+	return &config.CurrencyConfig{
+		BaseCurrency: "SGD",
+		Currencies: []*config.CurrencyRate{
+			{Code: "SGD", Symbol: "S$", Rate: 1, Countries: []string{"SG"}},
+			{Code: "USD", Symbol: "US$", Rate: 0.74, Countries: []string{"US", "USA"}},
+			{Code: "JPY", Symbol: "¥", MinorUnit: 1, Rate: 112, Countries: []string{"JP"}},
+		},
+	}
+}
+
+// TestCurrencyProvider_Resolve tests the requested currency wins over the receiver country and unknown codes are rejected
+func TestCurrencyProvider_Resolve(t *testing.T) {
+	provider := NewCurrencyProvider(testCurrencyConfig())
+	tests := []struct {
+		name         string
+		requested    string
+		country      string
+		code         string
+		exchangeRate int64
+		err          error
+	}{
+		{name: "by country", country: "usa", code: "USD", exchangeRate: 740000},
+		{name: "minor unit difference", country: "JP", code: "JPY", exchangeRate: 1120000},
+		{name: "requested overrides country", requested: "jpy", country: "US", code: "JPY", exchangeRate: 1120000},
+		{name: "unknown country uses base", country: "FR", code: "SGD", exchangeRate: EXCHANGE_RATE_SCALE},
+		{name: "unsupported currency", requested: "EUR", country: "SG", err: ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currency, err := provider.Resolve(tt.requested, tt.country)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v, got: %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if currency.Code != tt.code || currency.ExchangeRate != tt.exchangeRate {
+				t.Errorf("Expected %s at %d, got %+v", tt.code, tt.exchangeRate, currency)
+			}
+		})
+	}
+}
+
+// TestFormatMoney tests minor units, thousand separators and negative amounts
+func TestFormatMoney(t *testing.T) {
+	provider := NewCurrencyProvider(testCurrencyConfig())
+	tests := []struct {
+		amount    int
+		code      string
+		formatted string
+	}{
+		{amount: 123450, code: "SGD", formatted: "S$1,234.50"},
+		{amount: 5, code: "USD", formatted: "US$0.05"},
+		{amount: 1234567, code: "JPY", formatted: "¥1,234,567"},
+		{amount: -250, code: "SGD", formatted: "-S$2.50"},
+		{amount: 100, code: "EUR", formatted: "EUR 1.00"},
+	}
+
+	for _, tt := range tests {
+		if money := formatMoney(tt.amount, provider.Get(tt.code)); money.Formatted != tt.formatted {
+			t.Errorf("formatMoney(%d, %s): expected %s, got %s", tt.amount, tt.code, tt.formatted, money.Formatted)
+		}
+	}
+}
+
+// TestOrderServiceImpl_QuoteOrder_Currency tests the quote is converted with the receiver country's rate
+func TestOrderServiceImpl_QuoteOrder_Currency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	service.currencyProvider = NewCurrencyProvider(testCurrencyConfig())
+	ctx := context.Background()
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.ReceiverCountry = "JP"
+	quote, err := service.QuoteOrder(ctx, orderInfo, 123)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	// 商品 2500 + 运费 800 + 税费 225 = S$35.25，按 112 换算为 ¥3,948
+	if quote.TotalAmount != 3525 || quote.Currency != "JPY" || quote.BaseCurrency != "SGD" || quote.ExchangeRate != 1120000 {
+		t.Errorf("Unexpected quote: %+v", quote)
+	}
+	if quote.Amounts.TotalAmount.Amount != 3948 || quote.Amounts.TotalAmount.Formatted != "¥3,948" {
+		t.Errorf("Unexpected converted total: %+v", quote.Amounts.TotalAmount)
+	}
+}
+
+// TestOrderServiceImpl_SetOrderDetailAmounts tests stored snapshots are used and legacy orders fall back to the base currency
+func TestOrderServiceImpl_SetOrderDetailAmounts(t *testing.T) {
+	service := &OrderServiceImpl{currencyProvider: NewCurrencyProvider(testCurrencyConfig())}
+
+	// 下单时 1 SGD = 0.70 USD，与当前配置的汇率不同
+	detail := &types.OrderDetail{TotalAmount: 3525, RefundedAmount: 1000, Currency: "USD", BaseCurrency: "SGD", ExchangeRate: 700000}
+	service.setOrderDetailAmounts(detail)
+	if detail.Amounts.TotalAmount.Formatted != "US$24.68" || detail.Amounts.RefundedAmount.Formatted != "US$7.00" {
+		t.Errorf("Unexpected local amounts: %+v", detail.Amounts)
+	}
+	if detail.BaseAmounts.TotalAmount.Formatted != "S$35.25" {
+		t.Errorf("Unexpected base amounts: %+v", detail.BaseAmounts)
+	}
+
+	legacy := &types.OrderDetail{TotalAmount: 3525}
+	service.setOrderDetailAmounts(legacy)
+	if legacy.Currency != "SGD" || legacy.ExchangeRate != EXCHANGE_RATE_SCALE || legacy.Amounts.TotalAmount.Formatted != "S$35.25" {
+		t.Errorf("Unexpected legacy amounts: %+v, %+v", legacy, legacy.Amounts)
+	}
+}
+
+// TestMergeCurrencySales tests orders without a currency are counted in the base currency
+func TestMergeCurrencySales(t *testing.T) {
+	cached := []*types.CurrencySales{
+		{Currency: "USD", TotalOrders: 2, TotalSales: 2000, LocalSales: 1480},
+		{Currency: "", TotalOrders: 3, TotalSales: 3000, LocalSales: 3000},
+		{Currency: "SGD", TotalOrders: 1, TotalSales: 500, LocalSales: 500},
+	}
+	merged := mergeCurrencySales(cached, "SGD")
+	expected := []*types.CurrencySales{
+		{Currency: "SGD", TotalOrders: 4, TotalSales: 3500, LocalSales: 3500},
+		{Currency: "USD", TotalOrders: 2, TotalSales: 2000, LocalSales: 1480},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, merged)
+	}
+	if cached[1].Currency != "" {
+		t.Error("Expected cached stats to be left unchanged")
+	}
+}
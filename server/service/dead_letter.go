@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/metrics"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+)
+
+const (
+	DEAD_LETTER_REPLAY_LIMIT     = 100
+	DEAD_LETTER_REPLAY_MAX_LIMIT = 1000
+	// 读到第一条死信后，超过该时间没有读到新的死信则认为死信队列已重放完
+	DEAD_LETTER_IDLE_TIMEOUT = 5 * time.Second
+	// 每次重放都会新加入消费组，加入并分到分区可能需要较长时间，第一条死信使用更长的等待时间
+	DEAD_LETTER_JOIN_TIMEOUT = time.Minute
+)
+
+var (
+	ErrUnknownDeadLetterTopic = errors.New("topic has no dead letter queue")
+	ErrReplayInProgress       = errors.New("dead letter replay already in progress")
+)
+
+// deadLetterTopics 有死信队列的 topic
+var deadLetterTopics = map[string]bool{
+	utils.ORDER_STATUS_CHANGED_TOPIC: true,
+	utils.PAYMENT_RESULT_TOPIC:       true,
+}
+
+// 同一实例同时只允许一个重放任务，多实例之间由 kafka 消费组分配分区
+var deadLetterReplayLock sync.Mutex
+
+// DeadLetterReplayer 将死信队列中的消息重新投递到原 topic，由原消费者重新处理
+type DeadLetterReplayer struct {
+	newReader   func(topic string) utils.MessageReader
+	kafkaWriter utils.Writer
+	joinTimeout time.Duration
+	idleTimeout time.Duration
+}
+
+func GetDeadLetterReplayerInstance() *DeadLetterReplayer {
+	return &DeadLetterReplayer{
+		newReader:   utils.NewDeadLetterReader,
+		kafkaWriter: utils.GetWriter(),
+		joinTimeout: DEAD_LETTER_JOIN_TIMEOUT,
+		idleTimeout: DEAD_LETTER_IDLE_TIMEOUT,
+	}
+}
+
+// Replay 最多重放 limit 条死信，每条重新投递成功后才提交死信的 offset；
+// 投递失败时停止并返回已重放的结果，未提交的死信下次重放时会再次读到；
+// 死信队列为空时要等到 joinTimeout 才返回
+func (r *DeadLetterReplayer) Replay(ctx context.Context, topic string, limit int) (resp *types.ReplayDeadLetterResponse, err error) {
+	if !deadLetterTopics[topic] {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDeadLetterTopic, topic)
+	}
+	if !deadLetterReplayLock.TryLock() {
+		return nil, ErrReplayInProgress
+	}
+	defer deadLetterReplayLock.Unlock()
+
+	reader := r.newReader(topic)
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			log.Logger.Errorf("Replay: close reader failed, topic: %s, err: %s", topic, closeErr.Error())
+		}
+	}()
+
+	resp = &types.ReplayDeadLetterResponse{Topic: topic}
+	// 加入消费组期间读不到消息，空闲超时只在读到第一条死信后生效
+	timeout := r.joinTimeout
+	for resp.Replayed+resp.Skipped < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+		msgRaw, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			break
+		}
+		if err != nil {
+			log.Logger.Errorf("Replay: fetch dead letter failed, topic: %s, err: %s", topic, err.Error())
+			return resp, err
+		}
+		timeout = r.idleTimeout
+
+		var deadLetter types.DeadLetterMessage
+		if err = utils.JSONDecode(string(msgRaw.Value), &deadLetter); err != nil || deadLetter.Topic != topic {
+			// 不是由消费者写入的死信，无法重放
+			log.Logger.Errorf("Replay: skip malformed dead letter, topic: %s, offset: %d, value: %s", topic, msgRaw.Offset, string(msgRaw.Value))
+			resp.Skipped++
+		} else {
+			if err = r.kafkaWriter.SendMsg(ctx, topic, deadLetter.Key, deadLetter.Value); err != nil {
+				log.Logger.Errorf("Replay: republish failed, topic: %s, offset: %d, err: %s", topic, deadLetter.Offset, err.Error())
+				return resp, err
+			}
+			resp.Replayed++
+			metrics.KafkaConsumedTotal.WithLabelValues(topic, utils.CONSUME_RESULT_REPLAYED).Inc()
+		}
+		if err = reader.CommitMessages(ctx, msgRaw); err != nil {
+			log.Logger.Errorf("Replay: commit dead letter failed, topic: %s, offset: %d, err: %s", topic, msgRaw.Offset, err.Error())
+			return resp, err
+		}
+	}
+	log.Logger.Infof("Replay: topic: %s, replayed: %d, skipped: %d", topic, resp.Replayed, resp.Skipped)
+	return resp, nil
+}
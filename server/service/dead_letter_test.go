@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/segmentio/kafka-go"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+)
+
+// fakeDeadLetterReader 等待 joinDelay 模拟加入消费组，之后依次返回 msgs，读完后阻塞到 ctx 超时，模拟死信队列已空
+type fakeDeadLetterReader struct {
+	joinDelay time.Duration
+	msgs      []kafka.Message
+	committed []int64
+	closed    bool
+}
+
+func (r *fakeDeadLetterReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if r.joinDelay > 0 {
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-time.After(r.joinDelay):
+			r.joinDelay = 0
+		}
+	}
+	if len(r.msgs) == 0 {
+		<-ctx.Done()
+		return kafka.Message{}, ctx.Err()
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, nil
+}
+
+func (r *fakeDeadLetterReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeDeadLetterReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func newTestReplayer(reader *fakeDeadLetterReader, writer utils.Writer) *DeadLetterReplayer {
+	return &DeadLetterReplayer{
+		newReader:   func(string) utils.MessageReader { return reader },
+		kafkaWriter: writer,
+		joinTimeout: 200 * time.Millisecond,
+		idleTimeout: 10 * time.Millisecond,
+	}
+}
+
+// TestDeadLetterReplayer_Replay tests dead letters are republished to the original topic until the queue is drained
+func TestDeadLetterReplayer_Replay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reader := &fakeDeadLetterReader{msgs: []kafka.Message{
+		{Offset: 0, Value: []byte(`{"topic":"order_status_changed","key":"ORDER001","value":"{\"order_no\":\"ORDER001\"}"}`)},
+		{Offset: 1, Value: []byte(`garbage`)},
+	}}
+	writer := utilMocks.NewMockWriter(ctrl)
+	writer.EXPECT().SendMsg(gomock.Any(), "order_status_changed", "ORDER001", `{"order_no":"ORDER001"}`).Return(nil)
+
+	resp, err := newTestReplayer(reader, writer).Replay(context.Background(), "order_status_changed", 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Replayed != 1 || resp.Skipped != 1 {
+		t.Errorf("Unexpected replay result: %+v", resp)
+	}
+	if len(reader.committed) != 2 || !reader.closed {
+		t.Errorf("Expected both dead letters committed and reader closed, got commits %v, closed %v", reader.committed, reader.closed)
+	}
+}
+
+// TestDeadLetterReplayer_Replay_SlowJoin tests joining the consumer group longer than the idle timeout does not end the replay early
+func TestDeadLetterReplayer_Replay_SlowJoin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reader := &fakeDeadLetterReader{joinDelay: 50 * time.Millisecond, msgs: []kafka.Message{
+		{Offset: 0, Value: []byte(`{"topic":"payment_result","key":"ORDER001","value":"{\"biz_id\":\"ORDER001\"}"}`)},
+	}}
+	writer := utilMocks.NewMockWriter(ctrl)
+	writer.EXPECT().SendMsg(gomock.Any(), "payment_result", "ORDER001", `{"biz_id":"ORDER001"}`).Return(nil)
+
+	resp, err := newTestReplayer(reader, writer).Replay(context.Background(), "payment_result", 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Replayed != 1 || len(reader.committed) != 1 {
+		t.Errorf("Expected 1 dead letter replayed and committed, got %+v, commits %v", resp, reader.committed)
+	}
+}
+
+// TestDeadLetterReplayer_Replay_PublishFailed tests a dead letter that can not be republished is left uncommitted
+func TestDeadLetterReplayer_Replay_PublishFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reader := &fakeDeadLetterReader{msgs: []kafka.Message{
+		{Offset: 0, Value: []byte(`{"topic":"order_status_changed","key":"ORDER001","value":"{}"}`)},
+	}}
+	writer := utilMocks.NewMockWriter(ctrl)
+	writer.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("broker unavailable"))
+
+	resp, err := newTestReplayer(reader, writer).Replay(context.Background(), "order_status_changed", 10)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if resp.Replayed != 0 || len(reader.committed) != 0 {
+		t.Errorf("Expected nothing replayed or committed, got %+v, commits %v", resp, reader.committed)
+	}
+}
+
+// TestDeadLetterReplayer_Replay_UnknownTopic tests only topics with a dead letter queue can be replayed
+func TestDeadLetterReplayer_Replay_UnknownTopic(t *testing.T) {
+	replayer := newTestReplayer(&fakeDeadLetterReader{}, nil)
+	if _, err := replayer.Replay(context.Background(), "order_created", 10); !errors.Is(err, ErrUnknownDeadLetterTopic) {
+		t.Errorf("Expected ErrUnknownDeadLetterTopic, got: %v", err)
+	}
+}
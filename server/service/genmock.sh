@@ -0,0 +1,5 @@
+#!/bin/bash
+
+mockgen -source=./order.go -destination=mocks/order_service_mock.go -package=mocks
+
+echo "Mocks generated successfully."
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+const (
+	STORE_CREDIT_CODE_PREFIX = "SC-"
+	GIFT_CARD_TXN_LIMIT      = 50 // 查询余额时返回的最近流水条数
+)
+
+var (
+	ErrInvalidGiftCard    = errors.New("invalid gift card")
+	ErrGiftCardNotFound   = errors.New("gift card not found")
+	ErrGiftCardCodeExists = errors.New("gift card code already exists")
+)
+
+// GiftCardService 商家发行礼品卡，用户查询礼品卡和店铺余额；下单抵扣和退款退回由 OrderServiceImpl 处理
+type GiftCardService struct {
+	giftCardDao dao.GiftCardDao
+	txBeginner  repository.TxBeginner
+}
+
+func GetGiftCardServiceInstance() *GiftCardService {
+	return &GiftCardService{
+		giftCardDao: dao.GetGiftCardDao(),
+		txBeginner:  repository.DB,
+	}
+}
+
+// storeCreditCode 每个用户只有一个店铺余额账户
+func storeCreditCode(userID int) string {
+	return fmt.Sprintf("%s%d", STORE_CREDIT_CODE_PREFIX, userID)
+}
+
+// CreateGiftCard 发行礼品卡，面额作为第一条流水写入
+func (g *GiftCardService) CreateGiftCard(ctx context.Context, req types.CreateGiftCardRequest) (info *types.GiftCardInfo, err error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidGiftCard)
+	}
+	if !req.ExpireTime.IsZero() && !req.ExpireTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expire time must be in the future", ErrInvalidGiftCard)
+	}
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		code = utils.GenerateGiftCardCode()
+	}
+	if strings.HasPrefix(code, STORE_CREDIT_CODE_PREFIX) {
+		return nil, fmt.Errorf("%w: code prefix %s is reserved for store credit", ErrInvalidGiftCard, STORE_CREDIT_CODE_PREFIX)
+	}
+
+	card := &model.GiftCard{
+		Code:           code,
+		Type:           consts.GIFT_CARD_TYPE_GIFT_CARD,
+		InitialBalance: req.Amount,
+		Status:         consts.GIFT_CARD_ACTIVE,
+		ExpireTime:     req.ExpireTime,
+	}
+	err = g.txBeginner.Transaction(func(tx *gorm.DB) error {
+		giftCardDao := g.giftCardDao.WithTx(tx)
+		created, err := giftCardDao.Create(ctx, card)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return fmt.Errorf("%w: %s", ErrGiftCardCodeExists, code)
+		}
+		_, err = giftCardDao.AdjustBalance(ctx, &model.GiftCardTransaction{
+			GiftCardID: card.ID,
+			Type:       consts.GIFT_CARD_TXN_ISSUE,
+			Amount:     req.Amount,
+		})
+		return err
+	})
+	if err != nil {
+		log.Logger.Errorf("CreateGiftCard: create failed, code: %s, err: %s", code, err.Error())
+		return nil, err
+	}
+	card.Balance = req.Amount
+	return toGiftCardInfo(card), nil
+}
+
+// GetGiftCard 商家查询礼品卡或店铺余额及最近的流水
+func (g *GiftCardService) GetGiftCard(ctx context.Context, code string) (info *types.GiftCardInfo, err error) {
+	card, err := g.getGiftCard(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return g.withTransactions(ctx, card)
+}
+
+// CustomerGetGiftCard 用户查询礼品卡余额，不返回流水；店铺余额只能查询自己的
+func (g *GiftCardService) CustomerGetGiftCard(ctx context.Context, code string, userID int) (info *types.GiftCardInfo, err error) {
+	card, err := g.getGiftCard(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if card.Type == consts.GIFT_CARD_TYPE_STORE_CREDIT && card.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", ErrGiftCardNotFound, code)
+	}
+	return toGiftCardInfo(card), nil
+}
+
+// GetStoreCredit 用户查询自己的店铺余额及最近的流水，从未获得过店铺余额时余额为 0
+func (g *GiftCardService) GetStoreCredit(ctx context.Context, userID int) (info *types.GiftCardInfo, err error) {
+	code := storeCreditCode(userID)
+	card, err := g.getGiftCard(ctx, code)
+	if errors.Is(err, ErrGiftCardNotFound) {
+		return toGiftCardInfo(&model.GiftCard{Code: code, Type: consts.GIFT_CARD_TYPE_STORE_CREDIT, UserID: userID, Status: consts.GIFT_CARD_ACTIVE}), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g.withTransactions(ctx, card)
+}
+
+// UpdateGiftCardStatus 启用或停用礼品卡，停用后不能再用于下单，已冻结的金额不受影响
+func (g *GiftCardService) UpdateGiftCardStatus(ctx context.Context, code string, status int) (err error) {
+	if status != consts.GIFT_CARD_ACTIVE && status != consts.GIFT_CARD_DISABLED {
+		return fmt.Errorf("%w: unknown gift card status %d", ErrInvalidGiftCard, status)
+	}
+	card, err := g.getGiftCard(ctx, code)
+	if err != nil {
+		return err
+	}
+	if card.Status == status {
+		return nil
+	}
+	if _, err = g.giftCardDao.UpdateStatus(ctx, code, status); err != nil {
+		log.Logger.Errorf("UpdateGiftCardStatus: update failed, code: %s, err: %s", code, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (g *GiftCardService) getGiftCard(ctx context.Context, code string) (*model.GiftCard, error) {
+	card, err := g.giftCardDao.GetByCode(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrGiftCardNotFound, code)
+	}
+	if err != nil {
+		log.Logger.Errorf("getGiftCard: get gift card failed, code: %s, err: %s", code, err.Error())
+		return nil, err
+	}
+	return card, nil
+}
+
+func (g *GiftCardService) withTransactions(ctx context.Context, card *model.GiftCard) (*types.GiftCardInfo, error) {
+	txns, err := g.giftCardDao.ListTransactions(ctx, card.ID, GIFT_CARD_TXN_LIMIT)
+	if err != nil {
+		log.Logger.Errorf("withTransactions: list transactions failed, code: %s, err: %s", card.Code, err.Error())
+		return nil, err
+	}
+	info := toGiftCardInfo(card)
+	info.Transactions = make([]*types.GiftCardTransactionInfo, 0, len(txns))
+	for _, txn := range txns {
+		info.Transactions = append(info.Transactions, &types.GiftCardTransactionInfo{
+			OrderNo:      txn.OrderNo,
+			RefundNo:     txn.RefundNo,
+			Type:         txn.Type,
+			TypeName:     consts.GetGiftCardTxnTypeName(txn.Type),
+			Amount:       txn.Amount,
+			BalanceAfter: txn.BalanceAfter,
+			CreateTime:   txn.CreateTime,
+		})
+	}
+	return info, nil
+}
+
+func toGiftCardInfo(card *model.GiftCard) *types.GiftCardInfo {
+	return &types.GiftCardInfo{
+		Code:           card.Code,
+		Type:           card.Type,
+		TypeName:       consts.GetGiftCardTypeName(card.Type),
+		InitialBalance: card.InitialBalance,
+		Balance:        card.Balance,
+		Status:         card.Status,
+		StatusName:     consts.GetGiftCardStatusName(card.Status),
+		ExpireTime:     card.ExpireTime,
+		CreateTime:     card.CreateTime,
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+func newGiftCardTestService(ctrl *gomock.Controller) (*GiftCardService, *daoMocks.MockGiftCardDao) {
+	giftCardDao := daoMocks.NewMockGiftCardDao(ctrl)
+	giftCardDao.EXPECT().WithTx(gomock.Any()).Return(giftCardDao).AnyTimes()
+	return &GiftCardService{giftCardDao: giftCardDao, txBeginner: testTxBeginner{}}, giftCardDao
+}
+
+// TestGiftCardService_CreateGiftCard tests a generated code is issued with its face value as the first ledger entry
+func TestGiftCardService_CreateGiftCard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, giftCardDao := newGiftCardTestService(ctrl)
+	ctx := context.Background()
+
+	giftCardDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, card *model.GiftCard) (bool, error) {
+			if !strings.HasPrefix(card.Code, "GC-") || card.Balance != 0 || card.InitialBalance != 5000 || card.Status != consts.GIFT_CARD_ACTIVE {
+				t.Errorf("Unexpected gift card: %+v", card)
+			}
+			card.ID = 3
+			return true, nil
+		})
+	giftCardDao.EXPECT().AdjustBalance(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, txn *model.GiftCardTransaction) (bool, error) {
+			if txn.GiftCardID != 3 || txn.Type != consts.GIFT_CARD_TXN_ISSUE || txn.Amount != 5000 {
+				t.Errorf("Unexpected issue transaction: %+v", txn)
+			}
+			return true, nil
+		})
+
+	info, err := service.CreateGiftCard(ctx, types.CreateGiftCardRequest{Amount: 5000})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if info.Balance != 5000 || info.TypeName != "GiftCard" || info.StatusName != "Active" {
+		t.Errorf("Unexpected gift card info: %+v", info)
+	}
+}
+
+// TestGiftCardService_CreateGiftCard_Invalid tests the amount, expiry and reserved store credit prefix are validated
+func TestGiftCardService_CreateGiftCard_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  types.CreateGiftCardRequest
+		err  error
+	}{
+		{name: "zero amount", req: types.CreateGiftCardRequest{}, err: ErrInvalidGiftCard},
+		{name: "expired", req: types.CreateGiftCardRequest{Amount: 100, ExpireTime: time.Now().Add(-time.Hour)}, err: ErrInvalidGiftCard},
+		{name: "store credit prefix", req: types.CreateGiftCardRequest{Code: "SC-1", Amount: 100}, err: ErrInvalidGiftCard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, giftCardDao := newGiftCardTestService(ctrl)
+			giftCardDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+			if _, err := service.CreateGiftCard(context.Background(), tt.req); !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+// TestGiftCardService_CreateGiftCard_Duplicate tests an existing code is rejected without a ledger entry
+func TestGiftCardService_CreateGiftCard_Duplicate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, giftCardDao := newGiftCardTestService(ctrl)
+	ctx := context.Background()
+	giftCardDao.EXPECT().Create(ctx, gomock.Any()).Return(false, nil)
+	giftCardDao.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Times(0)
+
+	if _, err := service.CreateGiftCard(ctx, types.CreateGiftCardRequest{Code: "WELCOME", Amount: 100}); !errors.Is(err, ErrGiftCardCodeExists) {
+		t.Errorf("Expected ErrGiftCardCodeExists, got: %v", err)
+	}
+}
+
+// TestGiftCardService_CustomerGetGiftCard tests customers can not look up another user's store credit
+func TestGiftCardService_CustomerGetGiftCard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, giftCardDao := newGiftCardTestService(ctrl)
+	ctx := context.Background()
+	giftCardDao.EXPECT().GetByCode(ctx, "SC-456").Return(&model.GiftCard{Code: "SC-456", Type: consts.GIFT_CARD_TYPE_STORE_CREDIT, UserID: 456}, nil)
+
+	if _, err := service.CustomerGetGiftCard(ctx, "SC-456", 123); !errors.Is(err, ErrGiftCardNotFound) {
+		t.Errorf("Expected ErrGiftCardNotFound, got: %v", err)
+	}
+}
+
+// TestGiftCardService_GetStoreCredit tests a user without store credit gets a zero balance and the ledger is returned otherwise
+func TestGiftCardService_GetStoreCredit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, giftCardDao := newGiftCardTestService(ctrl)
+	ctx := context.Background()
+
+	giftCardDao.EXPECT().GetByCode(ctx, "SC-123").Return(nil, gorm.ErrRecordNotFound)
+	info, err := service.GetStoreCredit(ctx, 123)
+	if err != nil || info.Balance != 0 || info.Code != "SC-123" {
+		t.Errorf("Expected empty store credit, got: %+v, err: %v", info, err)
+	}
+
+	giftCardDao.EXPECT().GetByCode(ctx, "SC-123").Return(&model.GiftCard{ID: 9, Code: "SC-123", Type: consts.GIFT_CARD_TYPE_STORE_CREDIT, UserID: 123, Balance: 790}, nil)
+	giftCardDao.EXPECT().ListTransactions(ctx, 9, GIFT_CARD_TXN_LIMIT).Return([]*model.GiftCardTransaction{
+		{OrderNo: "ORDER001", RefundNo: "Rf-1", Type: consts.GIFT_CARD_TXN_CREDIT, Amount: 790, BalanceAfter: 790},
+	}, nil)
+	info, err = service.GetStoreCredit(ctx, 123)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if info.Balance != 790 || len(info.Transactions) != 1 || info.Transactions[0].TypeName != "Credit" {
+		t.Errorf("Unexpected store credit: %+v", info)
+	}
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+const (
+	DEFAULT_INVOICE_PREFIX     = "INV"
+	DEFAULT_CREDIT_NOTE_PREFIX = "CN"
+	INVOICE_SHIPPING_LINE      = "Shipping"
+	CREDIT_NOTE_ADJUSTMENT     = "Shipping and adjustments"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+func getInvoiceConfig() *config.InvoiceConfig {
+	if config.Config.InvoiceConfig == nil {
+		return &config.InvoiceConfig{}
+	}
+	return config.Config.InvoiceConfig
+}
+
+// invoicePrefix 发票编号前缀，未配置时使用默认前缀
+func invoicePrefix(cfg *config.InvoiceConfig, invoiceType int) string {
+	if invoiceType == consts.INVOICE_TYPE_CREDIT_NOTE {
+		if cfg.CreditNotePrefix != "" {
+			return cfg.CreditNotePrefix
+		}
+		return DEFAULT_CREDIT_NOTE_PREFIX
+	}
+	if cfg.InvoicePrefix != "" {
+		return cfg.InvoicePrefix
+	}
+	return DEFAULT_INVOICE_PREFIX
+}
+
+// issueOrderInvoice 订单付款后在同一事务中开具发票；拒绝退款后回到已付款时不重新开具
+func (o *OrderServiceImpl) issueOrderInvoice(ctx context.Context, order *model.Order, oldStatus int, in consts.TransitionInput) error {
+	if oldStatus != consts.CREATED {
+		return nil
+	}
+	orderProducts, err := o.orderProductDao.GetByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("issueOrderInvoice: get order products failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return err
+	}
+	quantities := make(map[int]int, len(orderProducts))
+	for _, product := range orderProducts {
+		quantities[product.ID] = product.Quantity
+	}
+
+	cfg := getInvoiceConfig()
+	invoice := &types.Invoice{
+		Type:      consts.INVOICE_TYPE_INVOICE,
+		OrderNo:   order.OrderNo,
+		UserID:    order.UserID,
+		IssueTime: time.Now(),
+		Seller: &types.InvoiceParty{
+			Name:    cfg.SellerName,
+			Address: cfg.SellerAddress,
+			TaxID:   cfg.TaxID,
+		},
+		BillTo: &types.InvoiceParty{
+			Name:    strings.TrimSpace(order.ReceiverFirstName + " " + order.ReceiverLastName),
+			Address: order.ReceiverAddress,
+			Country: order.ReceiverCountry,
+			ZipCode: order.ReceiverZipCode,
+			Phone:   order.ReceiverPhone,
+		},
+		TaxRegion:        order.TaxRegion,
+		TaxInclusive:     order.TaxInclusive,
+		Currency:         order.BaseCurrency,
+		DiscountAmount:   order.DiscountAmount,
+		TotalAmount:      order.TotalAmount,
+		OrderCurrency:    order.Currency,
+		ExchangeRate:     order.ExchangeRate,
+		PayMethod:        in.PayMethod,
+		PayTransactionID: in.PayTransactionID,
+	}
+	if invoice.Currency == "" {
+		invoice.Currency = o.getCurrencies().Base().Code
+	}
+	invoice.Lines = buildInvoiceLines(order, orderProducts, quantities)
+	if shipping := order.TotalAmount - invoiceLinesTotal(invoice.Lines, order.TaxInclusive); order.ShippingFee > 0 || shipping != 0 {
+		// 运费减免按订单优惠中未分摊到商品的部分计算
+		invoice.Lines = append(invoice.Lines, &types.InvoiceLine{
+			Description: INVOICE_SHIPPING_LINE,
+			Quantity:    1,
+			UnitPrice:   order.ShippingFee,
+			Discount:    order.ShippingFee - shipping,
+			NetAmount:   shipping,
+		})
+	}
+	summarizeInvoiceTax(invoice)
+	return o.saveInvoice(ctx, invoice, cfg)
+}
+
+// issueCreditNote 退款时开具引用原发票的贷项通知单，refundNo 为空表示取消订单的整单退款。
+// 没有发票的订单（开具发票之前付款，或未付款就取消）不开具
+func (o *OrderServiceImpl) issueCreditNote(ctx context.Context, order *model.Order, refundNo string, amount int, reason string) error {
+	invoices, err := o.invoiceDao.GetByOrderNo(ctx, order.OrderNo, consts.INVOICE_TYPE_INVOICE)
+	if err != nil {
+		log.Logger.Errorf("issueCreditNote: get invoice failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return err
+	}
+	if len(invoices) == 0 {
+		return nil
+	}
+	original, err := decodeInvoice(invoices[0])
+	if err != nil {
+		return err
+	}
+
+	orderProducts, err := o.orderProductDao.GetByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("issueCreditNote: get order products failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return err
+	}
+	quantities := make(map[int]int, len(orderProducts))
+	if refundNo == "" {
+		for _, product := range orderProducts {
+			quantities[product.ID] = product.Quantity
+		}
+	} else {
+		refundItems, err := o.refundDao.GetItemsByOrderNo(ctx, order.OrderNo)
+		if err != nil {
+			log.Logger.Errorf("issueCreditNote: get refund items failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+			return err
+		}
+		for _, item := range refundItems {
+			if item.RefundNo == refundNo {
+				quantities[item.OrderProductID] += item.Quantity
+			}
+		}
+	}
+
+	// 商家、收货方和货币信息以原发票为准
+	creditNote := &types.Invoice{
+		Type:             consts.INVOICE_TYPE_CREDIT_NOTE,
+		OrderNo:          order.OrderNo,
+		RefundNo:         refundNo,
+		RelatedInvoiceNo: original.InvoiceNo,
+		Reason:           reason,
+		UserID:           order.UserID,
+		IssueTime:        time.Now(),
+		Seller:           original.Seller,
+		BillTo:           original.BillTo,
+		TaxRegion:        original.TaxRegion,
+		TaxInclusive:     original.TaxInclusive,
+		Currency:         original.Currency,
+		TotalAmount:      amount,
+		OrderCurrency:    original.OrderCurrency,
+		ExchangeRate:     original.ExchangeRate,
+		PayMethod:        original.PayMethod,
+		PayTransactionID: original.PayTransactionID,
+	}
+	creditNote.Lines = buildInvoiceLines(order, orderProducts, quantities)
+	for _, line := range creditNote.Lines {
+		creditNote.DiscountAmount += line.Discount
+	}
+	// 全部退完时退款包含运费，部分支付的订单按比例退款，差额记为调整
+	if adjustment := amount - invoiceLinesTotal(creditNote.Lines, creditNote.TaxInclusive); adjustment != 0 {
+		creditNote.Lines = append(creditNote.Lines, &types.InvoiceLine{
+			Description: CREDIT_NOTE_ADJUSTMENT,
+			Quantity:    1,
+			UnitPrice:   adjustment,
+			NetAmount:   adjustment,
+		})
+	}
+	summarizeInvoiceTax(creditNote)
+	return o.saveInvoice(ctx, creditNote, getInvoiceConfig())
+}
+
+// saveInvoice 分配连续的编号并保存快照，编号与发票在同一事务中写入
+func (o *OrderServiceImpl) saveInvoice(ctx context.Context, invoice *types.Invoice, cfg *config.InvoiceConfig) error {
+	series := fmt.Sprintf("%s-%d", invoicePrefix(cfg, invoice.Type), invoice.IssueTime.Year())
+	sequence, err := o.invoiceDao.NextSequence(ctx, series)
+	if err != nil {
+		log.Logger.Errorf("saveInvoice: next sequence failed, orderNo: %s, series: %s, err: %s", invoice.OrderNo, series, err.Error())
+		return err
+	}
+	invoice.InvoiceNo = fmt.Sprintf("%s-%06d", series, sequence)
+	invoice.TypeName = consts.GetInvoiceTypeName(invoice.Type)
+
+	snapshot, err := utils.JSONEncode(invoice)
+	if err != nil {
+		log.Logger.Errorf("saveInvoice: json encode failed, invoiceNo: %s, err: %s", invoice.InvoiceNo, err.Error())
+		return err
+	}
+	_, err = o.invoiceDao.Create(ctx, &model.Invoice{
+		InvoiceNo:        invoice.InvoiceNo,
+		Type:             invoice.Type,
+		OrderNo:          invoice.OrderNo,
+		RefundNo:         invoice.RefundNo,
+		RelatedInvoiceNo: invoice.RelatedInvoiceNo,
+		UserID:           invoice.UserID,
+		Currency:         invoice.Currency,
+		TotalAmount:      invoice.TotalAmount,
+		Tax:              invoice.Tax,
+		Snapshot:         snapshot,
+		IssueTime:        invoice.IssueTime,
+	})
+	if err != nil {
+		log.Logger.Errorf("saveInvoice: create failed, invoiceNo: %s, err: %s", invoice.InvoiceNo, err.Error())
+		return err
+	}
+	log.Logger.Infof("saveInvoice: issued %s %s, orderNo: %s", invoice.TypeName, invoice.InvoiceNo, invoice.OrderNo)
+	return nil
+}
+
+// buildInvoiceLines 按数量生成商品明细行，部分数量时按比例计算优惠和税额，与退款金额的计算一致
+func buildInvoiceLines(order *model.Order, orderProducts []*model.OrderProduct, quantities map[int]int) []*types.InvoiceLine {
+	itemNetTotal := 0
+	for _, product := range orderProducts {
+		itemNetTotal += product.TotalPrice - product.DiscountAmount
+	}
+
+	lines := make([]*types.InvoiceLine, 0, len(orderProducts))
+	for _, product := range orderProducts {
+		quantity := quantities[product.ID]
+		if quantity <= 0 || product.Quantity <= 0 {
+			continue
+		}
+		line := &types.InvoiceLine{
+			ProductID:   product.ProductID,
+			Description: product.ProductName,
+			Quantity:    quantity,
+			UnitPrice:   product.Price,
+			Discount:    product.DiscountAmount * quantity / product.Quantity,
+			NetAmount:   (product.TotalPrice - product.DiscountAmount) * quantity / product.Quantity,
+		}
+		if order.TaxRegion != "" {
+			line.TaxRate = product.TaxRate
+			line.TaxAmount = product.TaxAmount * quantity / product.Quantity
+		} else if itemNetTotal > 0 {
+			// 记录商品税额之前的订单按 9% 计税，税额按优惠后的金额分摊
+			line.TaxRate = DEFAULT_TAX_RATE
+			line.TaxAmount = order.Tax * line.NetAmount / itemNetTotal
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// invoiceLinesTotal 明细行的含税合计
+func invoiceLinesTotal(lines []*types.InvoiceLine, inclusive bool) int {
+	total := 0
+	for _, line := range lines {
+		total += line.NetAmount
+		if !inclusive {
+			total += line.TaxAmount
+		}
+	}
+	return total
+}
+
+// summarizeInvoiceTax 按税率汇总商品明细行的税额，运费及调整行不计税
+func summarizeInvoiceTax(invoice *types.Invoice) {
+	byRate := make(map[int]*types.InvoiceTaxLine)
+	invoice.Tax = 0
+	for _, line := range invoice.Lines {
+		if line.ProductID == 0 {
+			continue
+		}
+		taxLine, ok := byRate[line.TaxRate]
+		if !ok {
+			taxLine = &types.InvoiceTaxLine{TaxRate: line.TaxRate}
+			byRate[line.TaxRate] = taxLine
+		}
+		taxLine.TaxableAmount += line.NetAmount
+		if invoice.TaxInclusive {
+			taxLine.TaxableAmount -= line.TaxAmount
+		}
+		taxLine.TaxAmount += line.TaxAmount
+		invoice.Tax += line.TaxAmount
+	}
+	invoice.TaxBreakdown = make([]*types.InvoiceTaxLine, 0, len(byRate))
+	for _, taxLine := range byRate {
+		invoice.TaxBreakdown = append(invoice.TaxBreakdown, taxLine)
+	}
+	sort.Slice(invoice.TaxBreakdown, func(i, j int) bool {
+		return invoice.TaxBreakdown[i].TaxRate < invoice.TaxBreakdown[j].TaxRate
+	})
+}
+
+func decodeInvoice(record *model.Invoice) (*types.Invoice, error) {
+	invoice := &types.Invoice{}
+	if err := utils.JSONDecode(record.Snapshot, invoice); err != nil {
+		log.Logger.Errorf("decodeInvoice: json decode failed, invoiceNo: %s, err: %s", record.InvoiceNo, err.Error())
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetInvoice 查询订单的发票或贷项通知单，invoiceNo 为空时返回订单的发票
+func (o *OrderServiceImpl) GetInvoice(ctx context.Context, orderNo string, invoiceNo string) (invoice *types.Invoice, err error) {
+	var record *model.Invoice
+	if invoiceNo == "" {
+		invoices, err := o.invoiceDao.GetByOrderNo(ctx, orderNo, consts.INVOICE_TYPE_INVOICE)
+		if err != nil {
+			log.Logger.Errorf("GetInvoice: get invoice failed, orderNo: %s, err: %s", orderNo, err.Error())
+			return nil, err
+		}
+		if len(invoices) == 0 {
+			return nil, fmt.Errorf("%w: order %s has no invoice", ErrInvoiceNotFound, orderNo)
+		}
+		record = invoices[0]
+	} else {
+		record, err = o.invoiceDao.GetByInvoiceNo(ctx, invoiceNo)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && record.OrderNo != orderNo) {
+			return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, invoiceNo)
+		}
+		if err != nil {
+			log.Logger.Errorf("GetInvoice: get invoice failed, invoiceNo: %s, err: %s", invoiceNo, err.Error())
+			return nil, err
+		}
+	}
+	return decodeInvoice(record)
+}
+
+// CustomerGetInvoice 用户查询自己订单的发票或贷项通知单
+func (o *OrderServiceImpl) CustomerGetInvoice(ctx context.Context, orderNo string, invoiceNo string, userID int) (invoice *types.Invoice, err error) {
+	invoice, err = o.GetInvoice(ctx, orderNo, invoiceNo)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.UserID != userID {
+		wrongUserErr := errors.New("invalid user ID")
+		log.Logger.Errorf("CustomerGetInvoice: Invalid userID, err %s", wrongUserErr.Error())
+		return nil, wrongUserErr
+	}
+	return invoice, nil
+}
+
+// ListCreditNotes 按开具顺序返回订单的贷项通知单
+func (o *OrderServiceImpl) ListCreditNotes(ctx context.Context, orderNo string) (creditNotes []*types.Invoice, err error) {
+	records, err := o.invoiceDao.GetByOrderNo(ctx, orderNo, consts.INVOICE_TYPE_CREDIT_NOTE)
+	if err != nil {
+		log.Logger.Errorf("ListCreditNotes: get credit notes failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return nil, err
+	}
+	creditNotes = make([]*types.Invoice, 0, len(records))
+	for _, record := range records {
+		creditNote, err := decodeInvoice(record)
+		if err != nil {
+			return nil, err
+		}
+		creditNotes = append(creditNotes, creditNote)
+	}
+	return creditNotes, nil
+}
+
+// CustomerListCreditNotes 用户查询自己订单的贷项通知单
+func (o *OrderServiceImpl) CustomerListCreditNotes(ctx context.Context, orderNo string, userID int) (creditNotes []*types.Invoice, err error) {
+	creditNotes, err = o.ListCreditNotes(ctx, orderNo)
+	if err != nil {
+		return nil, err
+	}
+	for _, creditNote := range creditNotes {
+		if creditNote.UserID != userID {
+			wrongUserErr := errors.New("invalid user ID")
+			log.Logger.Errorf("CustomerListCreditNotes: Invalid userID, err %s", wrongUserErr.Error())
+			return nil, wrongUserErr
+		}
+	}
+	return creditNotes, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+)
+
+const (
+	INVOICE_FORMAT_PDF  = "pdf"
+	INVOICE_FORMAT_HTML = "html"
+)
+
+var ErrUnsupportedInvoiceFormat = errors.New("unsupported invoice format")
+
+// invoiceView 发票中需要展示的文本，PDF 和 HTML 使用相同的内容
+type invoiceView struct {
+	Title        string
+	InvoiceNo    string
+	Seller       []string
+	BillTo       []string
+	Details      [][2]string
+	Lines        []invoiceLineView
+	Summary      [][2]string
+	Total        [2]string
+	LocalTotal   [2]string
+	IsCreditNote bool
+}
+
+type invoiceLineView struct {
+	Description string
+	Quantity    string
+	UnitPrice   string
+	Discount    string
+	TaxRate     string
+	TaxAmount   string
+	Amount      string
+}
+
+// RenderInvoice 按快照生成发票文件，返回文件内容及 Content-Type
+func RenderInvoice(invoice *types.Invoice, format string) (content []byte, contentType string, err error) {
+	view := newInvoiceView(invoice)
+	switch strings.ToLower(format) {
+	case INVOICE_FORMAT_PDF:
+		return renderInvoicePDF(view), "application/pdf", nil
+	case INVOICE_FORMAT_HTML:
+		content, err = renderInvoiceHTML(view)
+		return content, "text/html; charset=utf-8", err
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedInvoiceFormat, format)
+	}
+}
+
+func newInvoiceView(invoice *types.Invoice) *invoiceView {
+	currencies := getCurrencyProvider()
+	currency := currencies.Get(invoice.Currency)
+	money := func(amount int) string {
+		return formatMoney(amount, currency).Formatted
+	}
+
+	view := &invoiceView{
+		Title:        strings.ToUpper(consts.GetInvoiceTypeName(invoice.Type)),
+		InvoiceNo:    invoice.InvoiceNo,
+		IsCreditNote: invoice.Type == consts.INVOICE_TYPE_CREDIT_NOTE,
+	}
+	if seller := invoice.Seller; seller != nil {
+		view.Seller = nonEmpty(seller.Name, seller.Address)
+		if seller.TaxID != "" {
+			view.Seller = append(view.Seller, "Tax ID: "+seller.TaxID)
+		}
+	}
+	if billTo := invoice.BillTo; billTo != nil {
+		location := billTo.Country
+		if billTo.ZipCode > 0 {
+			location = strings.TrimSpace(fmt.Sprintf("%s %d", location, billTo.ZipCode))
+		}
+		view.BillTo = nonEmpty(billTo.Name, billTo.Address, location, billTo.Phone)
+	}
+
+	view.Details = [][2]string{
+		{"Order No", invoice.OrderNo},
+		{"Issued", invoice.IssueTime.Format("2006-01-02 15:04")},
+	}
+	if invoice.PayMethod != "" {
+		view.Details = append(view.Details, [2]string{"Payment", strings.TrimSpace(invoice.PayMethod + " " + invoice.PayTransactionID)})
+	}
+	if view.IsCreditNote {
+		view.Details = append(view.Details, [2]string{"Credit for", invoice.RelatedInvoiceNo})
+		if invoice.RefundNo != "" {
+			view.Details = append(view.Details, [2]string{"Refund No", invoice.RefundNo})
+		}
+		if invoice.Reason != "" {
+			view.Details = append(view.Details, [2]string{"Reason", invoice.Reason})
+		}
+	}
+
+	subtotal := 0
+	for _, line := range invoice.Lines {
+		subtotal += line.NetAmount
+		lineView := invoiceLineView{
+			Description: line.Description,
+			Quantity:    strconv.Itoa(line.Quantity),
+			UnitPrice:   money(line.UnitPrice),
+			Discount:    money(line.Discount),
+			Amount:      money(line.NetAmount),
+		}
+		if line.ProductID != 0 {
+			lineView.TaxRate = formatTaxRate(line.TaxRate)
+			lineView.TaxAmount = money(line.TaxAmount)
+		}
+		view.Lines = append(view.Lines, lineView)
+	}
+
+	view.Summary = [][2]string{{"Subtotal", money(subtotal)}}
+	taxLabel := "Tax"
+	if invoice.TaxInclusive {
+		taxLabel = "Included tax"
+	}
+	for _, taxLine := range invoice.TaxBreakdown {
+		label := fmt.Sprintf("%s %s on %s", taxLabel, formatTaxRate(taxLine.TaxRate), money(taxLine.TaxableAmount))
+		view.Summary = append(view.Summary, [2]string{label, money(taxLine.TaxAmount)})
+	}
+	totalLabel := "Total"
+	if view.IsCreditNote {
+		totalLabel = "Total credited"
+	}
+	view.Total = [2]string{totalLabel, money(invoice.TotalAmount)}
+	if invoice.OrderCurrency != "" && invoice.OrderCurrency != invoice.Currency && invoice.ExchangeRate > 0 {
+		local := formatMoney(convertAmount(invoice.TotalAmount, invoice.ExchangeRate), currencies.Get(invoice.OrderCurrency))
+		view.LocalTotal = [2]string{fmt.Sprintf("%s in %s", totalLabel, invoice.OrderCurrency), local.Formatted}
+	}
+	return view
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// formatTaxRate 万分之一转换为百分比，如 900 -> 9%，825 -> 8.25%
+func formatTaxRate(rate int) string {
+	return strconv.FormatFloat(float64(rate)/100, 'f', -1, 64) + "%"
+}
+
+const invoiceLineFormat = "%-30.30s %4s %12s %10s %8s %10s %11s"
+
+func renderInvoicePDF(view *invoiceView) []byte {
+	pdf := utils.NewPDFWriter()
+	pdf.WriteLine(fmt.Sprintf("%-45s %45s", view.Title, view.InvoiceNo), true)
+	pdf.WriteLine("", false)
+	for i, line := range view.Seller {
+		pdf.WriteLine(line, i == 0)
+	}
+	pdf.WriteLine("", false)
+
+	// 收货方在左，订单信息在右
+	rows := max(len(view.BillTo)+1, len(view.Details))
+	for i := 0; i < rows; i++ {
+		left, right := "", ""
+		if i == 0 {
+			left = "Bill to:"
+		} else if i-1 < len(view.BillTo) {
+			left = view.BillTo[i-1]
+		}
+		if i < len(view.Details) {
+			right = fmt.Sprintf("%-11s %s", view.Details[i][0]+":", view.Details[i][1])
+		}
+		pdf.WriteLine(fmt.Sprintf("%-45.45s %s", left, right), false)
+	}
+	pdf.WriteLine("", false)
+
+	pdf.WriteLine(fmt.Sprintf(invoiceLineFormat, "Description", "Qty", "Unit price", "Discount", "Tax rate", "Tax", "Amount"), true)
+	pdf.WriteLine(strings.Repeat("-", utils.PDFLineChars), false)
+	for _, line := range view.Lines {
+		pdf.WriteLine(fmt.Sprintf(invoiceLineFormat, line.Description, line.Quantity, line.UnitPrice, line.Discount, line.TaxRate, line.TaxAmount, line.Amount), false)
+	}
+	pdf.WriteLine(strings.Repeat("-", utils.PDFLineChars), false)
+	for _, row := range view.Summary {
+		pdf.WriteLine(fmt.Sprintf("%68s %22s", row[0], row[1]), false)
+	}
+	pdf.WriteLine(fmt.Sprintf("%68s %22s", view.Total[0], view.Total[1]), true)
+	if view.LocalTotal[0] != "" {
+		pdf.WriteLine(fmt.Sprintf("%68s %22s", view.LocalTotal[0], view.LocalTotal[1]), false)
+	}
+	return pdf.Bytes()
+}
+
+var invoiceHTMLTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.InvoiceNo}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 13px; margin: 40px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.lines th { border-bottom: 1px solid #333; }
+.summary td { border: none; }
+.total td { font-weight: bold; border-top: 1px solid #333; }
+.parties { display: flex; justify-content: space-between; margin: 24px 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} <small>{{.InvoiceNo}}</small></h1>
+<div>{{range $i, $line := .Seller}}{{if $i}}<br>{{end}}{{if eq $i 0}}<strong>{{$line}}</strong>{{else}}{{$line}}{{end}}{{end}}</div>
+<div class="parties">
+<div><strong>Bill to</strong>{{range .BillTo}}<br>{{.}}{{end}}</div>
+<table style="width: auto">{{range .Details}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>{{end}}</table>
+</div>
+<table class="lines">
+<tr><th>Description</th><th>Qty</th><th>Unit price</th><th>Discount</th><th>Tax rate</th><th>Tax</th><th>Amount</th></tr>
+{{range .Lines}}<tr><td>{{.Description}}</td><td>{{.Quantity}}</td><td>{{.UnitPrice}}</td><td>{{.Discount}}</td><td>{{.TaxRate}}</td><td>{{.TaxAmount}}</td><td>{{.Amount}}</td></tr>
+{{end}}</table>
+<table class="summary">
+{{range .Summary}}<tr><td></td><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}<tr class="total"><td></td><td>{{index .Total 0}}</td><td>{{index .Total 1}}</td></tr>
+{{if index .LocalTotal 0}}<tr><td></td><td>{{index .LocalTotal 0}}</td><td>{{index .LocalTotal 1}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func renderInvoiceHTML(view *invoiceView) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoiceHTMLTemplate.Execute(&buf, view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
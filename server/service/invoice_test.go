@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+// newIssuingInvoiceDao 付款后开具一张发票，用于只关心下单和付款流程的测试
+func newIssuingInvoiceDao(ctrl *gomock.Controller) *daoMocks.MockInvoiceDao {
+	invoiceDao := daoMocks.NewMockInvoiceDao(ctrl)
+	invoiceDao.EXPECT().WithTx(gomock.Any()).Return(invoiceDao).AnyTimes()
+	invoiceDao.EXPECT().NextSequence(gomock.Any(), gomock.Any()).Return(1, nil)
+	invoiceDao.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil)
+	return invoiceDao
+}
+
+// newNoInvoiceDao 订单没有发票，退款时不开具贷项通知单
+func newNoInvoiceDao(ctrl *gomock.Controller) *daoMocks.MockInvoiceDao {
+	invoiceDao := daoMocks.NewMockInvoiceDao(ctrl)
+	invoiceDao.EXPECT().WithTx(gomock.Any()).Return(invoiceDao).AnyTimes()
+	invoiceDao.EXPECT().GetByOrderNo(gomock.Any(), gomock.Any(), consts.INVOICE_TYPE_INVOICE).Return(nil, nil)
+	return invoiceDao
+}
+
+// invoiceTestOrder 与 refundTestOrder 相同的商品，按 SG 9% 计税：A 税额 180，B 税额 45
+func invoiceTestOrder(status int) (*model.Order, []*model.OrderProduct) {
+	order, products := refundTestOrder(status)
+	order.ReceiverFirstName, order.ReceiverLastName = "Ada", "Lim"
+	order.ReceiverAddress, order.ReceiverCountry, order.ReceiverZipCode = "10 Clay St", "SG", 123456
+	order.TaxRegion, order.TaxRate, order.BaseCurrency, order.Currency, order.ExchangeRate = "SG", 900, "SGD", "SGD", EXCHANGE_RATE_SCALE
+	products[0].ProductName, products[0].TaxRate, products[0].TaxAmount = "Bowl", 900, 180
+	products[1].ProductName, products[1].TaxRate, products[1].TaxAmount = "Cup", 900, 45
+	return order, products
+}
+
+// invoiceRecord 把发票内容保存为 dao 返回的记录
+func invoiceRecord(t *testing.T, invoice *types.Invoice) *model.Invoice {
+	snapshot, err := utils.JSONEncode(invoice)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	return &model.Invoice{InvoiceNo: invoice.InvoiceNo, Type: invoice.Type, OrderNo: invoice.OrderNo, UserID: invoice.UserID, Snapshot: snapshot}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_IssuesInvoice tests a paid order gets the next invoice number and a snapshot of its lines and tax
+func TestOrderServiceImpl_HandlePaymentResult_IssuesInvoice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, products := invoiceTestOrder(consts.CREATED)
+	series := fmt.Sprintf("INV-%d", time.Now().Year())
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).Return(1, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.invoiceDao.EXPECT().NextSequence(ctx, series).Return(42, nil)
+	m.invoiceDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *model.Invoice) (string, error) {
+			if record.InvoiceNo != series+"-000042" || record.Type != consts.INVOICE_TYPE_INVOICE || record.TotalAmount != 3525 || record.Tax != 225 {
+				t.Errorf("Unexpected invoice record: %+v", record)
+			}
+			invoice := &types.Invoice{}
+			if err := utils.JSONDecode(record.Snapshot, invoice); err != nil {
+				t.Fatalf("Expected snapshot to decode, got: %v", err)
+			}
+			if invoice.BillTo.Name != "Ada Lim" || invoice.PayTransactionID != "PAY001" || invoice.Currency != "SGD" {
+				t.Errorf("Unexpected invoice header: %+v", invoice)
+			}
+			// 两件商品加运费行
+			if len(invoice.Lines) != 3 || invoice.Lines[2].Description != INVOICE_SHIPPING_LINE || invoice.Lines[2].NetAmount != 800 {
+				t.Errorf("Unexpected invoice lines: %+v", invoice.Lines)
+			}
+			if len(invoice.TaxBreakdown) != 1 || invoice.TaxBreakdown[0].TaxableAmount != 2500 || invoice.TaxBreakdown[0].TaxAmount != 225 {
+				t.Errorf("Unexpected tax breakdown: %+v", invoice.TaxBreakdown)
+			}
+			return record.InvoiceNo, nil
+		})
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_InvoiceSequenceFailed tests the payment is rolled back when no invoice number can be allocated
+func TestOrderServiceImpl_HandlePaymentResult_InvoiceSequenceFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, products := invoiceTestOrder(consts.CREATED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).Return(1, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.invoiceDao.EXPECT().NextSequence(ctx, gomock.Any()).Return(0, errors.New("lock wait timeout"))
+	m.invoiceDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+	m.messageWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if order.Status != consts.CREATED {
+		t.Errorf("Expected order status %d, got %d", consts.CREATED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_ApproveRefund_IssuesCreditNote tests a partial refund issues a credit note for the refunded items referencing the invoice
+func TestOrderServiceImpl_ApproveRefund_IssuesCreditNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := invoiceTestOrder(consts.REFUNDING)
+	refund := &model.Refund{RefundNo: "Rf-1", OrderNo: "ORDER001", Amount: 1090, Status: consts.REFUND_PENDING, PrevStatus: consts.DELIVERED, Reason: "chipped"}
+	original := &types.Invoice{InvoiceNo: "INV-2025-000042", Type: consts.INVOICE_TYPE_INVOICE, OrderNo: "ORDER001", UserID: 123,
+		BillTo: &types.InvoiceParty{Name: "Ada Lim"}, TaxRegion: "SG", Currency: "SGD"}
+	series := fmt.Sprintf("CN-%d", time.Now().Year())
+
+	m.refundDao.EXPECT().GetByRefundNo(ctx, "Rf-1").Return(refund, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return([]*model.Refund{refund}, nil)
+	m.refundDao.EXPECT().UpdateStatus(ctx, "Rf-1", consts.REFUND_PENDING, gomock.Any()).Return(1, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.REFUNDING, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.invoiceDao.EXPECT().GetByOrderNo(ctx, "ORDER001", consts.INVOICE_TYPE_INVOICE).Return([]*model.Invoice{invoiceRecord(t, original)}, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return([]*model.RefundItem{
+		{RefundNo: "Rf-0", OrderProductID: 12, Quantity: 1},
+		{RefundNo: "Rf-1", OrderProductID: 11, Quantity: 1},
+	}, nil)
+	m.invoiceDao.EXPECT().NextSequence(ctx, series).Return(7, nil)
+	m.invoiceDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *model.Invoice) (string, error) {
+			if record.InvoiceNo != series+"-000007" || record.Type != consts.INVOICE_TYPE_CREDIT_NOTE ||
+				record.RefundNo != "Rf-1" || record.RelatedInvoiceNo != "INV-2025-000042" || record.TotalAmount != 1090 || record.Tax != 90 {
+				t.Errorf("Unexpected credit note record: %+v", record)
+			}
+			creditNote := &types.Invoice{}
+			if err := utils.JSONDecode(record.Snapshot, creditNote); err != nil {
+				t.Fatalf("Expected snapshot to decode, got: %v", err)
+			}
+			// 只包含本次退款的一个 Bowl：1000 + 税额 90，没有调整行
+			if len(creditNote.Lines) != 1 || creditNote.Lines[0].Quantity != 1 || creditNote.Lines[0].NetAmount != 1000 || creditNote.Lines[0].TaxAmount != 90 {
+				t.Errorf("Unexpected credit note lines: %+v", creditNote.Lines)
+			}
+			if creditNote.BillTo.Name != "Ada Lim" || creditNote.Reason != "chipped" {
+				t.Errorf("Unexpected credit note header: %+v", creditNote)
+			}
+			return record.InvoiceNo, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", "ORDER001", gomock.Any()).Return(nil)
+
+	if err := service.ApproveRefund(ctx, "Rf-1"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestBuildCreditNoteAdjustment tests the final refund's shipping share is recorded as an untaxed adjustment line
+func TestBuildCreditNoteAdjustment(t *testing.T) {
+	order, products := invoiceTestOrder(consts.REFUNDING)
+	creditNote := &types.Invoice{TaxInclusive: false}
+	creditNote.Lines = buildInvoiceLines(order, products, map[int]int{12: 1})
+	if adjustment := 1345 - invoiceLinesTotal(creditNote.Lines, false); adjustment != 800 {
+		t.Errorf("Expected shipping adjustment 800, got %d", adjustment)
+	}
+	creditNote.Lines = append(creditNote.Lines, &types.InvoiceLine{Description: CREDIT_NOTE_ADJUSTMENT, Quantity: 1, UnitPrice: 800, NetAmount: 800})
+	summarizeInvoiceTax(creditNote)
+	if creditNote.Tax != 45 || len(creditNote.TaxBreakdown) != 1 || creditNote.TaxBreakdown[0].TaxableAmount != 500 {
+		t.Errorf("Unexpected tax summary: %d, %+v", creditNote.Tax, creditNote.TaxBreakdown)
+	}
+}
+
+// TestOrderServiceImpl_CustomerGetInvoice tests invoices are only returned for the owner's order
+func TestOrderServiceImpl_CustomerGetInvoice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invoiceDao := daoMocks.NewMockInvoiceDao(ctrl)
+	service := &OrderServiceImpl{invoiceDao: invoiceDao}
+	ctx := context.Background()
+	invoice := &types.Invoice{InvoiceNo: "INV-2025-000001", Type: consts.INVOICE_TYPE_INVOICE, OrderNo: "ORDER001", UserID: 123}
+	creditNote := &types.Invoice{InvoiceNo: "CN-2025-000001", Type: consts.INVOICE_TYPE_CREDIT_NOTE, OrderNo: "ORDER001", UserID: 123}
+
+	invoiceDao.EXPECT().GetByOrderNo(ctx, "ORDER001", consts.INVOICE_TYPE_INVOICE).Return([]*model.Invoice{invoiceRecord(t, invoice)}, nil).Times(2)
+	invoiceDao.EXPECT().GetByInvoiceNo(ctx, "CN-2025-000001").Return(invoiceRecord(t, creditNote), nil).Times(2)
+	invoiceDao.EXPECT().GetByInvoiceNo(ctx, "CN-2025-000009").Return(nil, gorm.ErrRecordNotFound)
+
+	if got, err := service.CustomerGetInvoice(ctx, "ORDER001", "", 123); err != nil || got.InvoiceNo != "INV-2025-000001" {
+		t.Errorf("Expected invoice, got %+v, %v", got, err)
+	}
+	if _, err := service.CustomerGetInvoice(ctx, "ORDER001", "", 456); err == nil {
+		t.Error("Expected error for another user's invoice")
+	}
+	if got, err := service.CustomerGetInvoice(ctx, "ORDER001", "CN-2025-000001", 123); err != nil || got.Type != consts.INVOICE_TYPE_CREDIT_NOTE {
+		t.Errorf("Expected credit note, got %+v, %v", got, err)
+	}
+	// 编号存在但属于其他订单
+	if _, err := service.GetInvoice(ctx, "ORDER002", "CN-2025-000001"); !errors.Is(err, ErrInvoiceNotFound) {
+		t.Errorf("Expected ErrInvoiceNotFound, got: %v", err)
+	}
+	if _, err := service.GetInvoice(ctx, "ORDER001", "CN-2025-000009"); !errors.Is(err, ErrInvoiceNotFound) {
+		t.Errorf("Expected ErrInvoiceNotFound, got: %v", err)
+	}
+}
+
+// TestRenderInvoice tests both formats contain the invoice number and totals and HTML escapes customer input
+func TestRenderInvoice(t *testing.T) {
+	invoice := &types.Invoice{
+		InvoiceNo: "INV-2025-000001", Type: consts.INVOICE_TYPE_INVOICE, TypeName: "Tax Invoice", OrderNo: "ORDER001",
+		IssueTime:    time.Date(2025, 10, 4, 16, 31, 2, 0, time.UTC),
+		Seller:       &types.InvoiceParty{Name: "CeramiCraft Pte. Ltd."},
+		BillTo:       &types.InvoiceParty{Name: "<b>Ada</b>"},
+		Lines:        []*types.InvoiceLine{{ProductID: 1, Description: "Bowl", Quantity: 2, UnitPrice: 1000, NetAmount: 2000, TaxRate: 900, TaxAmount: 180}},
+		TaxBreakdown: []*types.InvoiceTaxLine{{TaxRate: 900, TaxableAmount: 2000, TaxAmount: 180}},
+		Currency:     "SGD", Tax: 180, TotalAmount: 2180,
+	}
+
+	html, contentType, err := RenderInvoice(invoice, INVOICE_FORMAT_HTML)
+	if err != nil || !strings.HasPrefix(contentType, "text/html") {
+		t.Fatalf("Expected html, got %s, %v", contentType, err)
+	}
+	if !bytes.Contains(html, []byte("INV-2025-000001")) || !bytes.Contains(html, []byte("SGD 21.80")) || bytes.Contains(html, []byte("<b>Ada</b>")) {
+		t.Errorf("Unexpected html: %s", html)
+	}
+
+	pdf, contentType, err := RenderInvoice(invoice, INVOICE_FORMAT_PDF)
+	if err != nil || contentType != "application/pdf" {
+		t.Fatalf("Expected pdf, got %s, %v", contentType, err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.Contains(pdf, []byte("INV-2025-000001")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Errorf("Unexpected pdf: %s", pdf)
+	}
+
+	if _, _, err = RenderInvoice(invoice, "docx"); !errors.Is(err, ErrUnsupportedInvoiceFormat) {
+		t.Errorf("Expected ErrUnsupportedInvoiceFormat, got: %v", err)
+	}
+}
@@ -0,0 +1,397 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./order.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	consts "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	types "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// MockOrderService is a mock of OrderService interface.
+type MockOrderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderServiceMockRecorder
+}
+
+// MockOrderServiceMockRecorder is the mock recorder for MockOrderService.
+type MockOrderServiceMockRecorder struct {
+	mock *MockOrderService
+}
+
+// NewMockOrderService creates a new mock instance.
+func NewMockOrderService(ctrl *gomock.Controller) *MockOrderService {
+	mock := &MockOrderService{ctrl: ctrl}
+	mock.recorder = &MockOrderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveRefund mocks base method.
+func (m *MockOrderService) ApproveRefund(ctx context.Context, refundNo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveRefund", ctx, refundNo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveRefund indicates an expected call of ApproveRefund.
+func (mr *MockOrderServiceMockRecorder) ApproveRefund(ctx, refundNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveRefund", reflect.TypeOf((*MockOrderService)(nil).ApproveRefund), ctx, refundNo)
+}
+
+// ApproveReturn mocks base method.
+func (m *MockOrderService) ApproveReturn(ctx context.Context, returnNo string, req types.ApproveReturnRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveReturn", ctx, returnNo, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveReturn indicates an expected call of ApproveReturn.
+func (mr *MockOrderServiceMockRecorder) ApproveReturn(ctx, returnNo, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveReturn", reflect.TypeOf((*MockOrderService)(nil).ApproveReturn), ctx, returnNo, req)
+}
+
+// BatchGetOrders mocks base method.
+func (m *MockOrderService) BatchGetOrders(ctx context.Context, orderNos []string) ([]*types.OrderDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGetOrders", ctx, orderNos)
+	ret0, _ := ret[0].([]*types.OrderDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchGetOrders indicates an expected call of BatchGetOrders.
+func (mr *MockOrderServiceMockRecorder) BatchGetOrders(ctx, orderNos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGetOrders", reflect.TypeOf((*MockOrderService)(nil).BatchGetOrders), ctx, orderNos)
+}
+
+// CreateOrder mocks base method.
+func (m *MockOrderService) CreateOrder(ctx context.Context, orderInfo types.OrderInfo, userID int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, orderInfo, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockOrderServiceMockRecorder) CreateOrder(ctx, orderInfo, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderService)(nil).CreateOrder), ctx, orderInfo, userID)
+}
+
+// CreateOrderIdempotent mocks base method.
+func (m *MockOrderService) CreateOrderIdempotent(ctx context.Context, idempotencyKey string, orderInfo types.OrderInfo, userID int) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrderIdempotent", ctx, idempotencyKey, orderInfo, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateOrderIdempotent indicates an expected call of CreateOrderIdempotent.
+func (mr *MockOrderServiceMockRecorder) CreateOrderIdempotent(ctx, idempotencyKey, orderInfo, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderIdempotent", reflect.TypeOf((*MockOrderService)(nil).CreateOrderIdempotent), ctx, idempotencyKey, orderInfo, userID)
+}
+
+// CustomerGetInvoice mocks base method.
+func (m *MockOrderService) CustomerGetInvoice(ctx context.Context, orderNo, invoiceNo string, userID int) (*types.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CustomerGetInvoice", ctx, orderNo, invoiceNo, userID)
+	ret0, _ := ret[0].(*types.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CustomerGetInvoice indicates an expected call of CustomerGetInvoice.
+func (mr *MockOrderServiceMockRecorder) CustomerGetInvoice(ctx, orderNo, invoiceNo, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomerGetInvoice", reflect.TypeOf((*MockOrderService)(nil).CustomerGetInvoice), ctx, orderNo, invoiceNo, userID)
+}
+
+// CustomerGetOrderDetail mocks base method.
+func (m *MockOrderService) CustomerGetOrderDetail(ctx context.Context, orderNo string, userID int) (*types.OrderDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CustomerGetOrderDetail", ctx, orderNo, userID)
+	ret0, _ := ret[0].(*types.OrderDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CustomerGetOrderDetail indicates an expected call of CustomerGetOrderDetail.
+func (mr *MockOrderServiceMockRecorder) CustomerGetOrderDetail(ctx, orderNo, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomerGetOrderDetail", reflect.TypeOf((*MockOrderService)(nil).CustomerGetOrderDetail), ctx, orderNo, userID)
+}
+
+// CustomerListCreditNotes mocks base method.
+func (m *MockOrderService) CustomerListCreditNotes(ctx context.Context, orderNo string, userID int) ([]*types.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CustomerListCreditNotes", ctx, orderNo, userID)
+	ret0, _ := ret[0].([]*types.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CustomerListCreditNotes indicates an expected call of CustomerListCreditNotes.
+func (mr *MockOrderServiceMockRecorder) CustomerListCreditNotes(ctx, orderNo, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomerListCreditNotes", reflect.TypeOf((*MockOrderService)(nil).CustomerListCreditNotes), ctx, orderNo, userID)
+}
+
+// GetInvoice mocks base method.
+func (m *MockOrderService) GetInvoice(ctx context.Context, orderNo, invoiceNo string) (*types.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvoice", ctx, orderNo, invoiceNo)
+	ret0, _ := ret[0].(*types.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvoice indicates an expected call of GetInvoice.
+func (mr *MockOrderServiceMockRecorder) GetInvoice(ctx, orderNo, invoiceNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvoice", reflect.TypeOf((*MockOrderService)(nil).GetInvoice), ctx, orderNo, invoiceNo)
+}
+
+// GetOrderDetail mocks base method.
+func (m *MockOrderService) GetOrderDetail(ctx context.Context, orderNo string) (*types.OrderDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderDetail", ctx, orderNo)
+	ret0, _ := ret[0].(*types.OrderDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderDetail indicates an expected call of GetOrderDetail.
+func (mr *MockOrderServiceMockRecorder) GetOrderDetail(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderDetail", reflect.TypeOf((*MockOrderService)(nil).GetOrderDetail), ctx, orderNo)
+}
+
+// GetOrderStats mocks base method.
+func (m *MockOrderService) GetOrderStats(ctx context.Context) (types.OrderStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStats", ctx)
+	ret0, _ := ret[0].(types.OrderStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStats indicates an expected call of GetOrderStats.
+func (mr *MockOrderServiceMockRecorder) GetOrderStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStats", reflect.TypeOf((*MockOrderService)(nil).GetOrderStats), ctx)
+}
+
+// HandlePaymentResult mocks base method.
+func (m *MockOrderService) HandlePaymentResult(ctx context.Context, msg *types.PaymentResultMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandlePaymentResult", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandlePaymentResult indicates an expected call of HandlePaymentResult.
+func (mr *MockOrderServiceMockRecorder) HandlePaymentResult(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePaymentResult", reflect.TypeOf((*MockOrderService)(nil).HandlePaymentResult), ctx, msg)
+}
+
+// ListCreditNotes mocks base method.
+func (m *MockOrderService) ListCreditNotes(ctx context.Context, orderNo string) ([]*types.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCreditNotes", ctx, orderNo)
+	ret0, _ := ret[0].([]*types.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCreditNotes indicates an expected call of ListCreditNotes.
+func (mr *MockOrderServiceMockRecorder) ListCreditNotes(ctx, orderNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCreditNotes", reflect.TypeOf((*MockOrderService)(nil).ListCreditNotes), ctx, orderNo)
+}
+
+// ListOrders mocks base method.
+func (m *MockOrderService) ListOrders(ctx context.Context, req types.ListOrderRequest) (*types.ListOrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, req)
+	ret0, _ := ret[0].(*types.ListOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderServiceMockRecorder) ListOrders(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderService)(nil).ListOrders), ctx, req)
+}
+
+// OrderAutoCancelUnpaid mocks base method.
+func (m *MockOrderService) OrderAutoCancelUnpaid(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OrderAutoCancelUnpaid", ctx)
+}
+
+// OrderAutoCancelUnpaid indicates an expected call of OrderAutoCancelUnpaid.
+func (mr *MockOrderServiceMockRecorder) OrderAutoCancelUnpaid(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderAutoCancelUnpaid", reflect.TypeOf((*MockOrderService)(nil).OrderAutoCancelUnpaid), ctx)
+}
+
+// OrderAutoConfirm mocks base method.
+func (m *MockOrderService) OrderAutoConfirm(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OrderAutoConfirm", ctx)
+}
+
+// OrderAutoConfirm indicates an expected call of OrderAutoConfirm.
+func (mr *MockOrderServiceMockRecorder) OrderAutoConfirm(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderAutoConfirm", reflect.TypeOf((*MockOrderService)(nil).OrderAutoConfirm), ctx)
+}
+
+// QuoteOrder mocks base method.
+func (m *MockOrderService) QuoteOrder(ctx context.Context, orderInfo types.OrderInfo, userID int) (*types.OrderQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteOrder", ctx, orderInfo, userID)
+	ret0, _ := ret[0].(*types.OrderQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteOrder indicates an expected call of QuoteOrder.
+func (mr *MockOrderServiceMockRecorder) QuoteOrder(ctx, orderInfo, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteOrder", reflect.TypeOf((*MockOrderService)(nil).QuoteOrder), ctx, orderInfo, userID)
+}
+
+// QuoteShipping mocks base method.
+func (m *MockOrderService) QuoteShipping(ctx context.Context, req types.ShippingQuoteRequest) (*types.ShippingQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteShipping", ctx, req)
+	ret0, _ := ret[0].(*types.ShippingQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteShipping indicates an expected call of QuoteShipping.
+func (mr *MockOrderServiceMockRecorder) QuoteShipping(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteShipping", reflect.TypeOf((*MockOrderService)(nil).QuoteShipping), ctx, req)
+}
+
+// ReceiveReturn mocks base method.
+func (m *MockOrderService) ReceiveReturn(ctx context.Context, returnNo string, restock bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveReturn", ctx, returnNo, restock)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReceiveReturn indicates an expected call of ReceiveReturn.
+func (mr *MockOrderServiceMockRecorder) ReceiveReturn(ctx, returnNo, restock interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveReturn", reflect.TypeOf((*MockOrderService)(nil).ReceiveReturn), ctx, returnNo, restock)
+}
+
+// RecoverOrderSagas mocks base method.
+func (m *MockOrderService) RecoverOrderSagas(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecoverOrderSagas", ctx)
+}
+
+// RecoverOrderSagas indicates an expected call of RecoverOrderSagas.
+func (mr *MockOrderServiceMockRecorder) RecoverOrderSagas(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverOrderSagas", reflect.TypeOf((*MockOrderService)(nil).RecoverOrderSagas), ctx)
+}
+
+// RejectRefund mocks base method.
+func (m *MockOrderService) RejectRefund(ctx context.Context, refundNo, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectRefund", ctx, refundNo, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectRefund indicates an expected call of RejectRefund.
+func (mr *MockOrderServiceMockRecorder) RejectRefund(ctx, refundNo, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectRefund", reflect.TypeOf((*MockOrderService)(nil).RejectRefund), ctx, refundNo, reason)
+}
+
+// RejectReturn mocks base method.
+func (m *MockOrderService) RejectReturn(ctx context.Context, returnNo, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectReturn", ctx, returnNo, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectReturn indicates an expected call of RejectReturn.
+func (mr *MockOrderServiceMockRecorder) RejectReturn(ctx, returnNo, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectReturn", reflect.TypeOf((*MockOrderService)(nil).RejectReturn), ctx, returnNo, reason)
+}
+
+// RequestRefund mocks base method.
+func (m *MockOrderService) RequestRefund(ctx context.Context, orderNo string, userID int, req types.RefundRequest) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRefund", ctx, orderNo, userID, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestRefund indicates an expected call of RequestRefund.
+func (mr *MockOrderServiceMockRecorder) RequestRefund(ctx, orderNo, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRefund", reflect.TypeOf((*MockOrderService)(nil).RequestRefund), ctx, orderNo, userID, req)
+}
+
+// RequestReturn mocks base method.
+func (m *MockOrderService) RequestReturn(ctx context.Context, orderNo string, userID int, req types.CreateReturnRequest) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReturn", ctx, orderNo, userID, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestReturn indicates an expected call of RequestReturn.
+func (mr *MockOrderServiceMockRecorder) RequestReturn(ctx, orderNo, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReturn", reflect.TypeOf((*MockOrderService)(nil).RequestReturn), ctx, orderNo, userID, req)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, orderNo string, newStatus int, in consts.TransitionInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, orderNo, newStatus, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderServiceMockRecorder) UpdateOrderStatus(ctx, orderNo, newStatus, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderService)(nil).UpdateOrderStatus), ctx, orderNo, newStatus, in)
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 	"sync"
 	"time"
 
@@ -14,49 +13,152 @@ import (
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/cache"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
 	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+	"gorm.io/gorm"
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, orderInfo types.OrderInfo, userID int) (orderNo string, err error)
+	CreateOrderIdempotent(ctx context.Context, idempotencyKey string, orderInfo types.OrderInfo, userID int) (orderNo string, replayed bool, err error)
 	ListOrders(ctx context.Context, req types.ListOrderRequest) (resp *types.ListOrderResponse, err error)
 	GetOrderDetail(ctx context.Context, orderNo string) (detail *types.OrderDetail, err error)
+	BatchGetOrders(ctx context.Context, orderNos []string) (details []*types.OrderDetail, err error)
 	CustomerGetOrderDetail(ctx context.Context, orderNo string, userID int) (detail *types.OrderDetail, err error)
-	UpdateOrderStatus(ctx context.Context, orderNo string, newStatus int) (err error)
+	UpdateOrderStatus(ctx context.Context, orderNo string, newStatus int, in consts.TransitionInput) (err error)
 	OrderAutoConfirm(ctx context.Context)
+	OrderAutoCancelUnpaid(ctx context.Context)
+	RecoverOrderSagas(ctx context.Context)
 	GetOrderStats(ctx context.Context) (stats types.OrderStats, err error)
+	RequestRefund(ctx context.Context, orderNo string, userID int, req types.RefundRequest) (refundNo string, err error)
+	ApproveRefund(ctx context.Context, refundNo string) (err error)
+	RejectRefund(ctx context.Context, refundNo string, reason string) (err error)
+	RequestReturn(ctx context.Context, orderNo string, userID int, req types.CreateReturnRequest) (returnNo string, err error)
+	ApproveReturn(ctx context.Context, returnNo string, req types.ApproveReturnRequest) (err error)
+	RejectReturn(ctx context.Context, returnNo string, reason string) (err error)
+	ReceiveReturn(ctx context.Context, returnNo string, restock bool) (err error)
+	HandlePaymentResult(ctx context.Context, msg *types.PaymentResultMessage) (err error)
+	QuoteShipping(ctx context.Context, req types.ShippingQuoteRequest) (quote *types.ShippingQuote, err error)
+	QuoteOrder(ctx context.Context, orderInfo types.OrderInfo, userID int) (quote *types.OrderQuote, err error)
+	GetInvoice(ctx context.Context, orderNo string, invoiceNo string) (invoice *types.Invoice, err error)
+	CustomerGetInvoice(ctx context.Context, orderNo string, invoiceNo string, userID int) (invoice *types.Invoice, err error)
+	ListCreditNotes(ctx context.Context, orderNo string) (creditNotes []*types.Invoice, err error)
+	CustomerListCreditNotes(ctx context.Context, orderNo string, userID int) (creditNotes []*types.Invoice, err error)
 }
 
 type OrderServiceImpl struct {
 	lock                 sync.Mutex
 	orderDao             dao.OrderDao
 	orderStatsCache      cache.IOrderStatsCache
+	idempotencyCache     cache.IIdempotencyCache
 	orderProductDao      dao.OrderProductDao
 	orderLogDao          dao.OrderLogDao
+	orderSagaDao         dao.OrderSagaDao
+	refundDao            dao.RefundDao
+	returnDao            dao.ReturnDao
+	paymentResultDao     dao.PaymentResultDao
+	couponDao            dao.CouponDao
+	orderDiscountDao     dao.OrderDiscountDao
+	invoiceDao           dao.InvoiceDao
+	giftCardDao          dao.GiftCardDao
+	taxCalculator        TaxCalculator
+	shippingCalculator   ShippingCalculator
+	currencyProvider     CurrencyProvider
 	productServiceClient productpb.ProductServiceClient
 	paymentServiceClient paymentpb.PaymentServiceClient
-	messageWriter        utils.Writer
+	messageWriter        utils.TxWriter
+	txBeginner           repository.TxBeginner
 	distributedLocker    utils.Locker
-	syncMode             bool
+	sagaRecoveryLocker   utils.Locker
+	expiryLocker         utils.Locker
+	unpaidOrderTTL       time.Duration
 }
 
 func GetOrderServiceInstance() *OrderServiceImpl {
 	return &OrderServiceImpl{
 		orderDao:             dao.GetOrderDao(),
 		orderStatsCache:      cache.GetOrderStatsCache(),
+		idempotencyCache:     cache.GetIdempotencyCache(),
 		orderProductDao:      dao.GetOrderProductDao(),
 		orderLogDao:          dao.GetOrderLogDao(),
+		orderSagaDao:         dao.GetOrderSagaDao(),
+		refundDao:            dao.GetRefundDao(),
+		returnDao:            dao.GetReturnDao(),
+		paymentResultDao:     dao.GetPaymentResultDao(),
+		couponDao:            dao.GetCouponDao(),
+		orderDiscountDao:     dao.GetOrderDiscountDao(),
+		invoiceDao:           dao.GetInvoiceDao(),
+		giftCardDao:          dao.GetGiftCardDao(),
+		taxCalculator:        getTaxCalculator(),
+		shippingCalculator:   getShippingCalculator(),
+		currencyProvider:     getCurrencyProvider(),
 		productServiceClient: clients.GetProductClient(),
 		paymentServiceClient: clients.GetPaymentClient(),
-		messageWriter:        utils.GetWriter(),
+		messageWriter:        utils.GetOutboxWriter(),
+		txBeginner:           repository.DB,
 		distributedLocker:    utils.GetDistributedLock(AUTO_CONFIRM_LOCK_KEY, uuid.New().String(), LOCK_EXP_TIME),
-		syncMode:             false,
+		sagaRecoveryLocker:   utils.GetDistributedLock(SAGA_RECOVERY_LOCK_KEY, uuid.New().String(), LOCK_EXP_TIME),
+		expiryLocker:         utils.GetDistributedLock(UNPAID_EXPIRY_LOCK_KEY, uuid.New().String(), LOCK_EXP_TIME),
+		unpaidOrderTTL:       getUnpaidOrderTTL(),
 	}
 }
 
+// transaction 在同一数据库事务中执行 fn，fn 中通过 txo 写入的订单数据和消息一起提交或回滚
+func (o *OrderServiceImpl) transaction(fn func(txo *OrderServiceImpl) error) error {
+	return o.txBeginner.Transaction(func(tx *gorm.DB) error {
+		return fn(o.withTx(tx))
+	})
+}
+
+// withTx 返回绑定到事务 tx 的服务副本，只用于写库和写消息，不应在其中调用外部服务
+func (o *OrderServiceImpl) withTx(tx *gorm.DB) *OrderServiceImpl {
+	txo := &OrderServiceImpl{
+		orderDao:             o.orderDao.WithTx(tx),
+		orderStatsCache:      o.orderStatsCache,
+		idempotencyCache:     o.idempotencyCache,
+		orderProductDao:      o.orderProductDao.WithTx(tx),
+		orderLogDao:          o.orderLogDao,
+		orderSagaDao:         o.orderSagaDao,
+		taxCalculator:        o.taxCalculator,
+		shippingCalculator:   o.shippingCalculator,
+		currencyProvider:     o.currencyProvider,
+		productServiceClient: o.productServiceClient,
+		paymentServiceClient: o.paymentServiceClient,
+		messageWriter:        o.messageWriter.WithTx(tx),
+		txBeginner:           tx,
+		distributedLocker:    o.distributedLocker,
+		sagaRecoveryLocker:   o.sagaRecoveryLocker,
+		expiryLocker:         o.expiryLocker,
+		unpaidOrderTTL:       o.unpaidOrderTTL,
+	}
+	// 只在部分流程中使用的 dao 未注入时保持为空
+	if o.refundDao != nil {
+		txo.refundDao = o.refundDao.WithTx(tx)
+	}
+	if o.returnDao != nil {
+		txo.returnDao = o.returnDao.WithTx(tx)
+	}
+	if o.paymentResultDao != nil {
+		txo.paymentResultDao = o.paymentResultDao.WithTx(tx)
+	}
+	if o.couponDao != nil {
+		txo.couponDao = o.couponDao.WithTx(tx)
+	}
+	if o.orderDiscountDao != nil {
+		txo.orderDiscountDao = o.orderDiscountDao.WithTx(tx)
+	}
+	if o.invoiceDao != nil {
+		txo.invoiceDao = o.invoiceDao.WithTx(tx)
+	}
+	if o.giftCardDao != nil {
+		txo.giftCardDao = o.giftCardDao.WithTx(tx)
+	}
+	return txo
+}
+
 const (
 	AUTO_CONFIRM_LOCK_KEY   = "order:auto_confirm:lock"
 	LOCK_EXP_TIME           = 10 * time.Second
@@ -81,25 +183,32 @@ func (o *OrderServiceImpl) OrderAutoConfirm(ctx context.Context) {
 		}
 	}()
 
-	// 2. update by status and shipped time
-	list, err := o.orderDao.AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS)
+	// 2. check transition
+	transition, err := consts.CheckTransition(consts.SHIPPED, consts.DELIVERED, consts.TransitionInput{Actor: consts.ActorSystem})
 	if err != nil {
-		log.Logger.Info("OrderAutoConfirm: failed to update order status, err: %s", err.Error())
+		log.Logger.Errorf("OrderAutoConfirm: invalid transition, err: %s", err.Error())
 		return
 	}
-
-	// 3. send message to mq (insert order logs)
-	for _, order := range list {
-		statusChangeRemark := "Shipped --> AutoConfirmed"
-		oscMsg, err := getOrderStatusChangedMsg(order.OrderNo, order.UserID, statusChangeRemark, consts.DELIVERED)
+	// 3. update by status and shipped time, and write order_status_changed messages in the same transaction
+	err = o.transaction(func(txo *OrderServiceImpl) error {
+		list, err := txo.orderDao.AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, transition.Stamps)
 		if err != nil {
-			log.Logger.Errorf("get order status changed msg failed, err %s", err.Error())
-			continue
+			return err
 		}
-		err = o.messageWriter.SendMsg(ctx, "order_status_changed", order.OrderNo, oscMsg)
-		if err != nil {
-			log.Logger.Errorf("send message failed, err %s", err)
+		for _, order := range list {
+			statusChangeRemark := "Shipped --> AutoConfirmed"
+			oscMsg, err := getOrderStatusChangedMsg(order.OrderNo, order.UserID, statusChangeRemark, consts.DELIVERED)
+			if err != nil {
+				return err
+			}
+			if err = txo.messageWriter.SendMsg(ctx, "order_status_changed", order.OrderNo, oscMsg); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		log.Logger.Errorf("OrderAutoConfirm: failed to update order status, err: %s", err.Error())
 	}
 }
 
@@ -107,51 +216,56 @@ func (o *OrderServiceImpl) CreateOrder(ctx context.Context, orderInfo types.Orde
 	o.lock.Lock()
 	defer o.lock.Unlock()
 
-	orderItemIds := make([]int64, len(orderInfo.OrderItemList))
-	for idx, item := range orderInfo.OrderItemList {
-		orderItemIds[idx] = int64(item.ProductID)
+	_, err = consts.CheckTransition(consts.NONE, consts.CREATED, consts.TransitionInput{
+		Actor:       consts.ActorCustomer,
+		UserID:      userID,
+		OwnerUserID: userID,
+	})
+	if err != nil {
+		log.Logger.Errorf("CreateOrder: invalid transition, err: %s", err.Error())
+		return "", err
 	}
 
-	// log.Logger.Infof("CreateOrder: orderItemIds = %v", orderItemIds)
-
-	// 1. rpc: call product service and check if all the related product's stock is enough
-	productList, err := o.productServiceClient.GetProductList(ctx, &productpb.GetProductListRequest{
-		Ids: orderItemIds,
-	})
+	// 1. rpc: call product service and check if all the related product's stock is enough,
+	// use the price and name from product service, never the client's
+	pricedItems, itemTotalAmount, err := o.getPricedItems(ctx, orderInfo.OrderItemList)
 	if err != nil {
-		log.Logger.Errorf("CreateOrder: get product list failed, err: %s", err.Error())
+		log.Logger.Errorf("CreateOrder: price order items failed, err: %s", err.Error())
 		return "", err
 	}
 
-	productId2StockMap := make(map[int]int)
-	for _, product := range productList.Products {
-		productId2StockMap[int(product.Id)] = int(product.Stock)
+	// 1.1 shipping, coupon and tax, the same calculation as QuoteOrder
+	pricing, err := o.priceOrder(ctx, orderInfo, userID, pricedItems, itemTotalAmount)
+	if err != nil {
+		log.Logger.Errorf("CreateOrder: price order failed, err: %s", err.Error())
+		return "", err
 	}
+	discount, tax, totalAmount := pricing.discount, pricing.tax, pricing.totalAmount
 
-	itemTotalAmount := 0
-	for _, orderItem := range orderInfo.OrderItemList {
-		if orderItem.Quantity > productId2StockMap[orderItem.ProductID] {
-			err = fmt.Errorf("CreateOrder failed, do not have enough stock, product id: %d", orderItem.ProductID)
-			log.Logger.Errorf(err.Error())
-			return "", err
-		}
-		itemTotalAmount += (orderItem.Price * orderItem.Quantity)
+	// 1.2 reject the order if the customer saw a different price
+	if err = checkPriceChanged(orderInfo, pricedItems, totalAmount); err != nil {
+		log.Logger.Errorf("CreateOrder: %s", err.Error())
+		return "", err
 	}
+	orderInfo.OrderItemList = pricedItems
 
-	shippingFee := CalculateShippingFee(itemTotalAmount)
-	tax := CalculateTax(itemTotalAmount)
+	// 1.3 gift cards first, then store credit, the rest is charged by payment service
+	tenders, err := o.planTenders(ctx, orderInfo, userID, totalAmount)
+	if err != nil {
+		log.Logger.Errorf("CreateOrder: plan tenders failed, err: %s", err.Error())
+		return "", err
+	}
 
 	// 2. local func: gen order ID
 	orderId := utils.GenerateOrderID()
 
-	// 3. save order Info to database
-	// 3.1 save order Info
+	// 3. build order Info and order items
 	currentTime := time.Now()
-	_, err = o.orderDao.Create(ctx, &model.Order{
+	orderModel := &model.Order{
 		OrderNo:           orderId,
 		UserID:            userID,
 		Status:            consts.CREATED,
-		TotalAmount:       itemTotalAmount + shippingFee + tax,
+		TotalAmount:       totalAmount,
 		CreateTime:        currentTime,
 		UpdateTime:        currentTime,
 		ReceiverFirstName: orderInfo.ReceiverFirstName,
@@ -161,103 +275,52 @@ func (o *OrderServiceImpl) CreateOrder(ctx context.Context, orderInfo types.Orde
 		ReceiverCountry:   orderInfo.ReceiverCountry,
 		ReceiverZipCode:   orderInfo.ReceiverZipCode,
 		Remark:            orderInfo.Remark,
-		ShippingFee:       shippingFee,
-		Tax:               tax,
-	})
-	if err != nil {
-		log.Logger.Errorf("CreateOrder: insert into db failed, err: %s", err.Error())
-		return "", err
+		ShippingFee:       pricing.shipping.ShippingFee,
+		Tax:               tax.Tax,
+		TaxRegion:         tax.Region,
+		TaxRate:           tax.Rate,
+		TaxInclusive:      tax.Inclusive,
+		Currency:          pricing.currency.Code,
+		BaseCurrency:      pricing.baseCurrency,
+		ExchangeRate:      pricing.currency.ExchangeRate,
+		DiscountAmount:    discount.total(),
+		CouponCode:        discount.code(),
+		TenderAmount:      tenderTotal(tenders),
 	}
 
-	// 3.2 save order items
 	orderProductModelList := make([]model.OrderProduct, len(orderInfo.OrderItemList))
-
-	// build model.orderProduct list
 	for idx, orderItem := range orderInfo.OrderItemList {
 		orderProductModelList[idx] = model.OrderProduct{
-			OrderNo:     orderId,
-			ProductID:   orderItem.ProductID,
-			ProductName: orderItem.ProductName,
-			Price:       orderItem.Price,
-			Quantity:    orderItem.Quantity,
-			TotalPrice:  (orderItem.Price * orderItem.Quantity),
-			CreateTime:  currentTime,
-			UpdateTime:  currentTime,
+			OrderNo:        orderId,
+			ProductID:      orderItem.ProductID,
+			ProductName:    orderItem.ProductName,
+			Price:          orderItem.Price,
+			Quantity:       orderItem.Quantity,
+			TotalPrice:     (orderItem.Price * orderItem.Quantity),
+			DiscountAmount: discount.itemDiscountAt(idx),
+			TaxCategory:    tax.Lines[idx].Category,
+			TaxRate:        tax.Lines[idx].Rate,
+			TaxAmount:      tax.Lines[idx].Amount,
+			CreateTime:     currentTime,
+			UpdateTime:     currentTime,
 		}
 	}
 
-	// save batch
-	_, err = o.orderProductDao.CreateBatch(ctx, orderProductModelList)
-	if err != nil {
-		log.Logger.Errorf("orderProductDao.CreateBatch: add order items failed, err %s", err.Error())
-		return "", err
-	}
-
 	orderMsg, err := getOrderMsg(orderId, orderInfo, userID)
 	if err != nil {
 		log.Logger.Errorf("getOrderMsg: json encode failed, err %s", err.Error())
 		return "", err
 	}
-	// 4. message queue: send msg -- order ID
-	err = o.messageWriter.SendMsg(ctx, "order_created", orderId, orderMsg)
-	if err != nil {
-		log.Logger.Errorf("CreateOrder: send message failed, err %s", err.Error())
-		return "", err
-	}
-
-	oscMsg, err := getOrderStatusChangedMsg(orderId, userID, "Created", 1)
-	if err != nil {
-		log.Logger.Errorf("get order status changed msg failed, err %s", err.Error())
-	}
-	err = o.messageWriter.SendMsg(ctx, "order_status_changed", orderId, oscMsg)
-	if err != nil {
-		log.Logger.Errorf("send message failed, err %s", err)
-	}
-
-	// 5. rpc: call product service and decrease stock
-	for _, orderItem := range orderInfo.OrderItemList {
-		_, _ = o.productServiceClient.UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{
-			Id:   int64(orderItem.ProductID),
-			Deta: int64(-1 * orderItem.Quantity),
-		})
-	}
-
-	// 6. rpc: call payment service and pay
-	// TODO
-	payResp, err := o.paymentServiceClient.PayOrder(ctx, &paymentpb.PayOrderRequest{
-		UserId: int32(userID),
-		Amount: int32(itemTotalAmount + shippingFee + tax),
-		BizId:  orderId,
-	})
-
-	// 6.2 payment failed
-	if err != nil || payResp.Code != 0 {
-		_ = o.messageWriter.SendMsg(ctx, "order_canceled", orderId, orderMsg)
-		if err != nil {
-			log.Logger.Errorf("CreateOrder: payment failed, err: %s", err.Error())
-			return "", err
-		} else {
-			errMsg := payResp.ErrorMsg
-			rpcErr := errors.New(*errMsg)
-			log.Logger.Errorf("CreateOrder: payment failed, err: %s", rpcErr.Error())
-			return "", rpcErr
-		}
-	}
 
-	// 6.1 payment success: update order status
-	err = o.orderDao.UpdateStatusAndPayment(ctx, orderId, consts.PAYED, time.Now())
+	// 4. saga: reserve stock --> persist order --> charge payment --> confirm,
+	// failed steps are compensated in reverse order
+	saga, err := o.startOrderSaga(ctx, orderModel, orderProductModelList, orderMsg, discount, tenders)
 	if err != nil {
-		log.Logger.Errorf("CreateOrder: update status failed, err %s", err.Error())
 		return "", err
 	}
-
-	oscMsg, err = getOrderStatusChangedMsg(orderId, userID, "Created --> Paid", 2)
-	if err != nil {
-		log.Logger.Errorf("get order status changed msg failed, err %s", err.Error())
-	}
-	err = o.messageWriter.SendMsg(ctx, "order_status_changed", orderId, oscMsg)
-	if err != nil {
-		log.Logger.Errorf("send message failed, err %s", err)
+	if err = o.runOrderSaga(ctx, saga); err != nil {
+		log.Logger.Errorf("CreateOrder: saga failed, orderNo: %s, err: %s", orderId, err.Error())
+		return "", err
 	}
 
 	return orderId, nil
@@ -280,6 +343,28 @@ func getOrderMsg(orderId string, orderInfo types.OrderInfo, userId int) (msg str
 	return orderMsgJson, err
 }
 
+// getPersistedOrderMsg 由已保存的订单及订单商品生成与 order_created 相同格式的消息
+func getPersistedOrderMsg(order *model.Order, products []*model.OrderProduct) (msg string, err error) {
+	orderInfo := types.OrderInfo{
+		ReceiverFirstName: order.ReceiverFirstName,
+		ReceiverLastName:  order.ReceiverLastName,
+		ReceiverPhone:     order.ReceiverPhone,
+		ReceiverAddress:   order.ReceiverAddress,
+		ReceiverCountry:   order.ReceiverCountry,
+		ReceiverZipCode:   order.ReceiverZipCode,
+		Remark:            order.Remark,
+	}
+	for _, product := range products {
+		orderInfo.OrderItemList = append(orderInfo.OrderItemList, &types.OrderItemInfo{
+			ProductID:   product.ProductID,
+			ProductName: product.ProductName,
+			Quantity:    product.Quantity,
+			Price:       product.Price,
+		})
+	}
+	return getOrderMsg(order.OrderNo, orderInfo, order.UserID)
+}
+
 func getOrderStatusChangedMsg(orderNo string, userId int, remark string, curStatus int) (msg string, err error) {
 	rawMsg := types.OrderStatusChangedMessage{
 		OrderNo:       orderNo,
@@ -290,20 +375,6 @@ func getOrderStatusChangedMsg(orderNo string, userId int, remark string, curStat
 	return utils.JSONEncode(rawMsg)
 }
 
-func CalculateShippingFee(total int) int {
-	const ShippingFee = 800
-	const TotalThresh = 30000
-	if total >= TotalThresh {
-		return 0
-	}
-	return ShippingFee
-}
-
-// tax = total * 9%
-func CalculateTax(total int) int {
-	return total * 9 / 100
-}
-
 func (o *OrderServiceImpl) ListOrders(ctx context.Context, req types.ListOrderRequest) (resp *types.ListOrderResponse, err error) {
 	// 构建查询条件
 	query := dao.OrderQuery{
@@ -333,7 +404,7 @@ func (o *OrderServiceImpl) ListOrders(ctx context.Context, req types.ListOrderRe
 			ReceiverPhone:     order.ReceiverPhone,
 			CreateTime:        order.CreateTime,
 			TotalAmount:       int(order.TotalAmount),
-			Status:            getOrderStatusName(order.Status),
+			Status:            consts.GetOrderStatusName(order.Status),
 		}
 		orderList[idx] = orderInfo
 	}
@@ -369,42 +440,121 @@ func (o *OrderServiceImpl) GetOrderDetail(ctx context.Context, orderNo string) (
 		return nil, err
 	}
 
-	// 4. 转换订单商品信息
-	orderItems := make([]*types.OrderItemDetail, 0, len(orderProducts))
-	for _, product := range orderProducts {
-		orderItem := &types.OrderItemDetail{
-			ID:          product.ID,
-			ProductID:   product.ProductID,
-			ProductName: product.ProductName,
-			Price:       product.Price,
-			Quantity:    product.Quantity,
-			TotalPrice:  product.TotalPrice,
-			CreateTime:  product.CreateTime,
-			UpdateTime:  product.UpdateTime,
-		}
-		orderItems = append(orderItems, orderItem)
+	// 4. 查询退款记录
+	refunds, refundedAmount, err := o.getRefundDetails(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("GetOrderDetail: get refunds failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return nil, err
 	}
 
-	// 5. 转换订单状态日志
+	// 5. 查询退货记录
+	returns, err := o.getReturnDetails(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("GetOrderDetail: get returns failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return nil, err
+	}
+
+	// 6. 转换订单状态日志
 	statusLogs := make([]*types.OrderStatusLogDetail, 0, len(orderLogs))
 	for _, log := range orderLogs {
 		statusLog := &types.OrderStatusLogDetail{
 			ID:            log.ID,
 			CurrentStatus: log.CurrentStatus,
-			StatusName:    getOrderStatusName(log.CurrentStatus),
+			StatusName:    consts.GetOrderStatusName(log.CurrentStatus),
 			Remark:        log.Remark,
+			OccurredAt:    log.OccurredAt,
 			CreateTime:    log.CreateTime,
 		}
+		// 历史日志没有记录发生时间，以写入时间代替
+		if statusLog.OccurredAt.IsZero() {
+			statusLog.OccurredAt = log.CreateTime
+		}
 		statusLogs = append(statusLogs, statusLog)
 	}
 
-	// 6. 构建订单详情响应
-	detail = &types.OrderDetail{
+	// 7. 查询优惠明细
+	discounts, err := o.getOrderDiscountDetails(ctx, order)
+	if err != nil {
+		log.Logger.Errorf("GetOrderDetail: get discounts failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return nil, err
+	}
+
+	// 8. 查询礼品卡和店铺余额抵扣明细
+	tenders, err := o.getOrderTenderDetails(ctx, order)
+	if err != nil {
+		log.Logger.Errorf("GetOrderDetail: get tenders failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return nil, err
+	}
+
+	// 9. 构建订单详情响应
+	detail = buildOrderDetail(order, orderProducts)
+	detail.Discounts = discounts
+	detail.Tenders = tenders
+	detail.StatusLogs = statusLogs
+	detail.RefundedAmount = refundedAmount
+	detail.Refunds = refunds
+	detail.Returns = returns
+	o.setOrderDetailAmounts(detail)
+
+	return detail, nil
+}
+
+// BatchGetOrders 批量查询订单及订单商品，不包含状态日志、退款和退货记录；不存在的订单号会被忽略
+func (o *OrderServiceImpl) BatchGetOrders(ctx context.Context, orderNos []string) (details []*types.OrderDetail, err error) {
+	if len(orderNos) == 0 {
+		return []*types.OrderDetail{}, nil
+	}
+	orders, err := o.orderDao.GetByOrderNos(ctx, orderNos)
+	if err != nil {
+		log.Logger.Errorf("BatchGetOrders: get orders failed, err: %s", err.Error())
+		return nil, err
+	}
+	orderProducts, err := o.orderProductDao.GetByOrderNos(ctx, orderNos)
+	if err != nil {
+		log.Logger.Errorf("BatchGetOrders: get order products failed, err: %s", err.Error())
+		return nil, err
+	}
+
+	productsByOrderNo := make(map[string][]*model.OrderProduct, len(orders))
+	for _, product := range orderProducts {
+		productsByOrderNo[product.OrderNo] = append(productsByOrderNo[product.OrderNo], product)
+	}
+	details = make([]*types.OrderDetail, 0, len(orders))
+	for _, order := range orders {
+		detail := buildOrderDetail(order, productsByOrderNo[order.OrderNo])
+		o.setOrderDetailAmounts(detail)
+		details = append(details, detail)
+	}
+	return details, nil
+}
+
+// buildOrderDetail 转换订单基本信息及订单商品
+func buildOrderDetail(order *model.Order, orderProducts []*model.OrderProduct) *types.OrderDetail {
+	orderItems := make([]*types.OrderItemDetail, 0, len(orderProducts))
+	for _, product := range orderProducts {
+		orderItem := &types.OrderItemDetail{
+			ID:             product.ID,
+			ProductID:      product.ProductID,
+			ProductName:    product.ProductName,
+			Price:          product.Price,
+			Quantity:       product.Quantity,
+			TotalPrice:     product.TotalPrice,
+			DiscountAmount: product.DiscountAmount,
+			TaxCategory:    product.TaxCategory,
+			TaxRate:        product.TaxRate,
+			TaxAmount:      product.TaxAmount,
+			CreateTime:     product.CreateTime,
+			UpdateTime:     product.UpdateTime,
+		}
+		orderItems = append(orderItems, orderItem)
+	}
+
+	return &types.OrderDetail{
 		// 基本订单信息
 		OrderNo:      order.OrderNo,
 		UserID:       order.UserID,
 		Status:       order.Status,
-		StatusName:   getOrderStatusName(order.Status),
+		StatusName:   consts.GetOrderStatusName(order.Status),
 		TotalAmount:  int(order.TotalAmount),
 		PayAmount:    int(order.PayAmount),
 		ShippingFee:  int(order.ShippingFee),
@@ -414,6 +564,29 @@ func (o *OrderServiceImpl) GetOrderDetail(ctx context.Context, orderNo string) (
 		UpdateTime:   order.UpdateTime,
 		DeliveryTime: order.DeliveryTime,
 		ConfirmTime:  order.ConfirmTime,
+		CancelTime:   order.CancelTime,
+		CancelReason: order.CancelReason,
+
+		// 优惠信息
+		DiscountAmount: order.DiscountAmount,
+		CouponCode:     order.CouponCode,
+		Discounts:      []*types.OrderDiscountDetail{},
+
+		// 税费信息
+		TaxRegion:    order.TaxRegion,
+		TaxRate:      order.TaxRate,
+		TaxInclusive: order.TaxInclusive,
+
+		// 货币信息
+		Currency:     order.Currency,
+		BaseCurrency: order.BaseCurrency,
+		ExchangeRate: order.ExchangeRate,
+
+		// 支付信息
+		PayTransactionID: order.PayTransactionID,
+		PayMethod:        order.PayMethod,
+		TenderAmount:     order.TenderAmount,
+		Tenders:          []*types.OrderTenderDetail{},
 
 		// 收货信息
 		ReceiverFirstName: order.ReceiverFirstName,
@@ -427,29 +600,8 @@ func (o *OrderServiceImpl) GetOrderDetail(ctx context.Context, orderNo string) (
 		Remark:      order.Remark,
 		LogisticsNo: order.LogisticsNo,
 
-		// 关联数据
+		// 订单商品
 		OrderItems: orderItems,
-		StatusLogs: statusLogs,
-	}
-
-	return detail, nil
-}
-
-// 获取订单状态名称
-func getOrderStatusName(status int) string {
-	switch status {
-	case consts.CREATED:
-		return "Created"
-	case consts.PAYED:
-		return "Paid"
-	case consts.SHIPPED:
-		return "Shipped"
-	case consts.DELIVERED:
-		return "Delivered"
-	case consts.CANCELED:
-		return "Canceled"
-	default:
-		return "Unknown"
 	}
 }
 
@@ -467,50 +619,13 @@ func (o *OrderServiceImpl) CustomerGetOrderDetail(ctx context.Context, orderNo s
 	return orderInfo, nil
 }
 
-func (o *OrderServiceImpl) UpdateOrderStatus(ctx context.Context, orderNo string, newStatus int, shippingNo string) (err error) {
-	o.lock.Lock()
-	defer o.lock.Unlock()
-
-	orderInfo, err := o.orderDao.GetByOrderNo(ctx, orderNo)
-	if err != nil {
-		return err
-	}
-
-	oldStatus := orderInfo.Status
-	if oldStatus != newStatus-1 {
-		statusErr := fmt.Errorf("UpdateOrderStatus: Invalid status, cur: %d, next: %d", orderInfo.Status, newStatus)
-		return statusErr
-	}
-
-	switch newStatus {
-	case consts.DELIVERED:
-		err = o.orderDao.UpdateStatusAndConfirmTime(ctx, orderNo, newStatus, time.Now())
-		if err != nil {
-			return err
-		}
-	case consts.SHIPPED:
-		err = o.orderDao.UpdateStatusWithDeliveryInfo(ctx, orderNo, newStatus, time.Now(), shippingNo)
-		if err != nil {
-			return err
-		}
-	default:
-		defaultErr := fmt.Errorf("UpdateOrderStatus: status no support, cur status %d", newStatus)
-		return defaultErr
-	}
-
-	statusChangeRemark := fmt.Sprintf("%s --> %s", getOrderStatusName(oldStatus), getOrderStatusName(newStatus))
-	oscMsg, err := getOrderStatusChangedMsg(orderNo, orderInfo.UserID, statusChangeRemark, newStatus)
-	if err != nil {
-		log.Logger.Errorf("get order status changed msg failed, err %s", err.Error())
-	}
-	err = o.messageWriter.SendMsg(ctx, "order_status_changed", orderNo, oscMsg)
+func (o *OrderServiceImpl) GetOrderStats(ctx context.Context) (stats types.OrderStats, err error) {
+	stats, err = o.orderStatsCache.GetOrderStats()
 	if err != nil {
-		log.Logger.Errorf("send message failed, err %s", err)
+		return stats, err
 	}
-
-	return nil
-}
-
-func (o *OrderServiceImpl) GetOrderStats(ctx context.Context) (stats types.OrderStats, err error) {
-	return o.orderStatsCache.GetOrderStats()
+	baseCurrency := o.getCurrencies().Base().Code
+	stats.BaseCurrency = baseCurrency
+	stats.SalesByCurrency = mergeCurrencySales(stats.SalesByCurrency, baseCurrency)
+	return stats, nil
 }
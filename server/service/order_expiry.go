@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+)
+
+const (
+	UNPAID_EXPIRY_LOCK_KEY        = "order:unpaid_expiry:lock"
+	DEFAULT_UNPAID_EXPIRE_MINUTES = 30
+	UNPAID_EXPIRY_BATCH           = 100
+	UNPAID_EXPIRY_REASON          = "payment timeout"
+)
+
+// getUnpaidOrderTTL 读取未付款订单的超时时间，未配置时使用默认值
+func getUnpaidOrderTTL() time.Duration {
+	minutes := DEFAULT_UNPAID_EXPIRE_MINUTES
+	if config.Config.OrderConfig != nil && config.Config.OrderConfig.UnpaidExpireMinutes > 0 {
+		minutes = config.Config.OrderConfig.UnpaidExpireMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// OrderAutoCancelUnpaid 取消超过 unpaidOrderTTL 仍未付款的订单，回补库存并发送 order_status_changed 和 order_canceled 消息
+func (o *OrderServiceImpl) OrderAutoCancelUnpaid(ctx context.Context) {
+	log.Logger.Infof("Auto Cancel Unpaid Order at: %v", time.Now())
+	// 1. lock
+	lock := o.expiryLocker
+	if err := lock.Lock(ctx); err != nil {
+		// 获取锁失败（其他实例正在处理），直接返回，等下一轮
+		log.Logger.Info("OrderAutoCancelUnpaid: failed to acquire lock, skipping this round")
+		return
+	}
+	defer func() {
+		if unlockErr := lock.Unlock(ctx); unlockErr != nil {
+			log.Logger.Errorf("OrderAutoCancelUnpaid: failed to release lock, err: %s", unlockErr.Error())
+		}
+	}()
+
+	// 2. query expired orders
+	orders, err := o.orderDao.GetExpiredUnpaidOrders(ctx, consts.CREATED, time.Now().Add(-o.unpaidOrderTTL), UNPAID_EXPIRY_BATCH)
+	if err != nil {
+		log.Logger.Errorf("OrderAutoCancelUnpaid: get expired orders failed, err: %s", err.Error())
+		return
+	}
+
+	// 3. cancel one by one, the status update fails if the order was paid in the meantime
+	in := consts.TransitionInput{Actor: consts.ActorSystem, Reason: UNPAID_EXPIRY_REASON}
+	for _, order := range orders {
+		if err = o.changeOrderStatus(ctx, order, consts.CANCELED, in); err != nil {
+			log.Logger.Errorf("OrderAutoCancelUnpaid: cancel order failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		}
+	}
+}
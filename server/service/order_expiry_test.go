@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+// TestOrderServiceImpl_OrderAutoCancelUnpaid_Success tests expired orders are canceled and a concurrently paid order is skipped
+func TestOrderServiceImpl_OrderAutoCancelUnpaid_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Return(nil).Times(1)
+
+	mockOrderDao.EXPECT().
+		GetExpiredUnpaidOrders(ctx, consts.CREATED, gomock.Any(), UNPAID_EXPIRY_BATCH).
+		DoAndReturn(func(_ context.Context, _ int, createdBefore time.Time, _ int) ([]*model.Order, error) {
+			if d := time.Since(createdBefore); d < 30*time.Minute || d > 31*time.Minute {
+				t.Errorf("Expected createdBefore 30 minutes ago, got %v ago", d)
+			}
+			return []*model.Order{
+				{OrderNo: "EXPIRED001", UserID: 101, Status: consts.CREATED},
+				{OrderNo: "EXPIRED002", UserID: 102, Status: consts.CREATED},
+			}, nil
+		})
+
+	// EXPIRED001 is canceled
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, "EXPIRED001", consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["cancel_reason"] != UNPAID_EXPIRY_REASON {
+				t.Errorf("Expected cancel_reason %q, got %v", UNPAID_EXPIRY_REASON, updates["cancel_reason"])
+			}
+			return 1, nil
+		})
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, "EXPIRED001").Return([]*model.OrderProduct{
+		{OrderNo: "EXPIRED001", ProductID: 1, Quantity: 2},
+	}, nil).Times(2)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_canceled", "EXPIRED001", gomock.Any()).Return(nil).Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", "EXPIRED001", gomock.Any()).Return(nil).Times(1)
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
+	// EXPIRED002 was paid in the meantime
+	mockOrderDao.EXPECT().UpdateStatus(ctx, "EXPIRED002", consts.CREATED, gomock.Any()).Return(0, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, gomock.Any(), "EXPIRED002", gomock.Any()).Times(0)
+
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_refund", gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		productServiceClient: mockProductClient,
+		messageWriter:        mockKafkaWriter,
+		expiryLocker:         mockLocker,
+		unpaidOrderTTL:       30 * time.Minute,
+	}
+
+	service.OrderAutoCancelUnpaid(ctx)
+}
+
+// TestOrderServiceImpl_OrderAutoCancelUnpaid_LockFailed tests the job is skipped when another instance holds the lock
+func TestOrderServiceImpl_OrderAutoCancelUnpaid_LockFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(errors.New("lock already held")).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Times(0)
+	mockOrderDao.EXPECT().GetExpiredUnpaidOrders(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		orderDao:     mockOrderDao,
+		expiryLocker: mockLocker,
+	}
+
+	service.OrderAutoCancelUnpaid(ctx)
+}
+
+// TestOrderServiceImpl_OrderAutoCancelUnpaid_DaoError tests a query error is handled gracefully
+func TestOrderServiceImpl_OrderAutoCancelUnpaid_DaoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Return(nil).Times(1)
+	mockOrderDao.EXPECT().
+		GetExpiredUnpaidOrders(ctx, consts.CREATED, gomock.Any(), UNPAID_EXPIRY_BATCH).
+		Return(nil, errors.New("database connection failed"))
+	mockOrderDao.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		orderDao:       mockOrderDao,
+		expiryLocker:   mockLocker,
+		unpaidOrderTTL: 30 * time.Minute,
+	}
+
+	service.OrderAutoCancelUnpaid(ctx)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+// CreateOrderIdempotent 带幂等键的下单：同一用户重放相同的请求返回原订单号，不会重复下单和扣款
+func (o *OrderServiceImpl) CreateOrderIdempotent(ctx context.Context, idempotencyKey string, orderInfo types.OrderInfo, userID int) (orderNo string, replayed bool, err error) {
+	requestHash, err := hashOrderRequest(orderInfo)
+	if err != nil {
+		return "", false, err
+	}
+
+	existing, err := o.idempotencyCache.Reserve(ctx, userID, idempotencyKey, types.IdempotencyRecord{RequestHash: requestHash})
+	if err != nil {
+		log.Logger.Errorf("CreateOrderIdempotent: reserve key failed, userId: %d, key: %s, err: %s", userID, idempotencyKey, err.Error())
+		return "", false, err
+	}
+	if existing != nil {
+		if existing.RequestHash != requestHash {
+			return "", false, ErrIdempotencyKeyReused
+		}
+		if existing.OrderNo == "" {
+			return "", false, ErrIdempotencyKeyInProgress
+		}
+		log.Logger.Infof("CreateOrderIdempotent: replay, userId: %d, key: %s, orderNo: %s", userID, idempotencyKey, existing.OrderNo)
+		return existing.OrderNo, true, nil
+	}
+
+	orderNo, err = o.CreateOrder(ctx, orderInfo, userID)
+	if err != nil {
+		// 下单失败时订单已被补偿，释放幂等键允许客户端重试
+		if releaseErr := o.idempotencyCache.Release(ctx, userID, idempotencyKey); releaseErr != nil {
+			log.Logger.Errorf("CreateOrderIdempotent: release key failed, userId: %d, key: %s, err: %s", userID, idempotencyKey, releaseErr.Error())
+		}
+		return "", false, err
+	}
+
+	err = o.idempotencyCache.Complete(ctx, userID, idempotencyKey, types.IdempotencyRecord{RequestHash: requestHash, OrderNo: orderNo})
+	if err != nil {
+		// 订单已创建成功，只记录日志；处理中的记录过期前重放会得到 409
+		log.Logger.Errorf("CreateOrderIdempotent: complete key failed, userId: %d, key: %s, err: %s", userID, idempotencyKey, err.Error())
+	}
+	return orderNo, false, nil
+}
+
+// hashOrderRequest 计算下单请求的摘要，用于识别同一个幂等键被用于不同的请求
+func hashOrderRequest(orderInfo types.OrderInfo) (string, error) {
+	body, err := utils.JSONEncode(orderInfo)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	cacheMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/cache/mocks"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+)
+
+// TestOrderServiceImpl_CreateOrderIdempotent_FirstRequest tests the order number is saved under the key
+func TestOrderServiceImpl_CreateOrderIdempotent_FirstRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockIdempotencyCache := cacheMocks.NewMockIIdempotencyCache(ctrl)
+
+	ctx := context.TODO()
+	mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	orderInfo := twoItemOrderInfo()
+	requestHash, _ := hashOrderRequest(orderInfo)
+
+	mockIdempotencyCache.EXPECT().
+		Reserve(ctx, 123, "key-1", types.IdempotencyRecord{RequestHash: requestHash}).
+		Return(nil, nil)
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	mockOrderDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+	mockOrderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockPaymentClient.EXPECT().PayOrder(ctx, gomock.Any()).Return(&paymentpb.PayOrderResponse{Code: 0}, nil)
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
+
+	var savedOrderNo string
+	mockIdempotencyCache.EXPECT().
+		Complete(ctx, 123, "key-1", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, _ string, record types.IdempotencyRecord) error {
+			if record.RequestHash != requestHash {
+				t.Errorf("Expected request hash %s, got %s", requestHash, record.RequestHash)
+			}
+			savedOrderNo = record.OrderNo
+			return nil
+		})
+
+	// 付款后开具发票
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
+		productServiceClient: mockProductClient,
+		paymentServiceClient: mockPaymentClient,
+		messageWriter:        mockKafkaWriter,
+		idempotencyCache:     mockIdempotencyCache,
+		invoiceDao:           newIssuingInvoiceDao(ctrl),
+	}
+
+	orderNo, replayed, err := service.CreateOrderIdempotent(ctx, "key-1", orderInfo, 123)
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if replayed {
+		t.Errorf("Expected first request not to be a replay")
+	}
+	if orderNo == "" || orderNo != savedOrderNo {
+		t.Errorf("Expected saved orderNo %s, got %s", savedOrderNo, orderNo)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrderIdempotent_Replay tests a replay returns the original order without creating another
+func TestOrderServiceImpl_CreateOrderIdempotent_Replay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockIdempotencyCache := cacheMocks.NewMockIIdempotencyCache(ctrl)
+
+	ctx := context.TODO()
+	orderInfo := twoItemOrderInfo()
+	requestHash, _ := hashOrderRequest(orderInfo)
+
+	mockIdempotencyCache.EXPECT().
+		Reserve(ctx, 123, "key-1", gomock.Any()).
+		Return(&types.IdempotencyRecord{RequestHash: requestHash, OrderNo: "ORDER001"}, nil)
+	mockProductClient.EXPECT().GetProductList(gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		productServiceClient: mockProductClient,
+		idempotencyCache:     mockIdempotencyCache,
+	}
+
+	orderNo, replayed, err := service.CreateOrderIdempotent(ctx, "key-1", orderInfo, 123)
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if !replayed {
+		t.Errorf("Expected request to be a replay")
+	}
+	if orderNo != "ORDER001" {
+		t.Errorf("Expected orderNo ORDER001, got %s", orderNo)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrderIdempotent_DifferentPayload tests reusing a key for another request is rejected
+func TestOrderServiceImpl_CreateOrderIdempotent_DifferentPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyCache := cacheMocks.NewMockIIdempotencyCache(ctrl)
+
+	ctx := context.TODO()
+
+	mockIdempotencyCache.EXPECT().
+		Reserve(ctx, 123, "key-1", gomock.Any()).
+		Return(&types.IdempotencyRecord{RequestHash: "another-request", OrderNo: "ORDER001"}, nil)
+
+	service := &OrderServiceImpl{
+		idempotencyCache: mockIdempotencyCache,
+	}
+
+	_, _, err := service.CreateOrderIdempotent(ctx, "key-1", twoItemOrderInfo(), 123)
+	if !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrderIdempotent_InProgress tests a concurrent duplicate is rejected while the first request runs
+func TestOrderServiceImpl_CreateOrderIdempotent_InProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIdempotencyCache := cacheMocks.NewMockIIdempotencyCache(ctrl)
+
+	ctx := context.TODO()
+	orderInfo := twoItemOrderInfo()
+	requestHash, _ := hashOrderRequest(orderInfo)
+
+	mockIdempotencyCache.EXPECT().
+		Reserve(ctx, 123, "key-1", gomock.Any()).
+		Return(&types.IdempotencyRecord{RequestHash: requestHash}, nil)
+
+	service := &OrderServiceImpl{
+		idempotencyCache: mockIdempotencyCache,
+	}
+
+	_, _, err := service.CreateOrderIdempotent(ctx, "key-1", orderInfo, 123)
+	if !errors.Is(err, ErrIdempotencyKeyInProgress) {
+		t.Errorf("Expected ErrIdempotencyKeyInProgress, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrderIdempotent_CreateFailed tests the key is released so the client can retry
+func TestOrderServiceImpl_CreateOrderIdempotent_CreateFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockIdempotencyCache := cacheMocks.NewMockIIdempotencyCache(ctrl)
+
+	ctx := context.TODO()
+
+	mockIdempotencyCache.EXPECT().Reserve(ctx, 123, "key-1", gomock.Any()).Return(nil, nil)
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(nil, errors.New("product service unavailable"))
+	mockIdempotencyCache.EXPECT().Release(ctx, 123, "key-1").Return(nil).Times(1)
+	mockIdempotencyCache.EXPECT().Complete(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		productServiceClient: mockProductClient,
+		idempotencyCache:     mockIdempotencyCache,
+	}
+
+	_, _, err := service.CreateOrderIdempotent(ctx, "key-1", twoItemOrderInfo(), 123)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+const LATE_PAYMENT_REFUND_REASON = "payment succeeded after order canceled"
+
+// errDuplicatePaymentResult 支付结果已处理过，回滚本次处理
+var errDuplicatePaymentResult = errors.New("duplicate payment result")
+
+// HandlePaymentResult 处理支付服务的异步支付结果：
+// 成功时未付款订单置为已付款，已取消且未付过款的订单自动退款；失败时取消未付款订单。
+// 同一订单的同一结果只处理一次，重复通知直接忽略
+func (o *OrderServiceImpl) HandlePaymentResult(ctx context.Context, msg *types.PaymentResultMessage) (err error) {
+	if msg.BizID == "" || (msg.Result != consts.PAYMENT_SUCCESS && msg.Result != consts.PAYMENT_FAILED) {
+		log.Logger.Warnf("HandlePaymentResult: invalid message, bizId: %s, result: %s", msg.BizID, msg.Result)
+		return nil
+	}
+
+	order, err := o.orderDao.GetByOrderNo(ctx, msg.BizID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Logger.Warnf("HandlePaymentResult: order not found, orderNo: %s", msg.BizID)
+		return nil
+	}
+	if err != nil {
+		log.Logger.Errorf("HandlePaymentResult: get order failed, orderNo: %s, err: %s", msg.BizID, err.Error())
+		return err
+	}
+
+	record := &model.PaymentResult{
+		OrderNo:    order.OrderNo,
+		Result:     msg.Result,
+		PayOrderID: msg.PayOrderID,
+		UserID:     msg.UserID,
+		Amount:     msg.Amount,
+		ErrorMsg:   msg.ErrorMsg,
+	}
+	if msg.Result == consts.PAYMENT_SUCCESS {
+		err = o.handlePaymentSuccess(ctx, order, record, msg.PayMethod)
+	} else {
+		err = o.handlePaymentFailure(ctx, order, record)
+	}
+	if errors.Is(err, errDuplicatePaymentResult) {
+		log.Logger.Infof("HandlePaymentResult: duplicate notification, orderNo: %s, result: %s", order.OrderNo, msg.Result)
+		return nil
+	}
+	if err != nil {
+		log.Logger.Errorf("HandlePaymentResult: orderNo: %s, result: %s, err: %s", order.OrderNo, msg.Result, err.Error())
+	}
+	return err
+}
+
+func (o *OrderServiceImpl) handlePaymentSuccess(ctx context.Context, order *model.Order, record *model.PaymentResult, payMethod string) error {
+	switch {
+	case order.Status == consts.CREATED:
+		// 下单 saga 仍在执行时由 saga 确认订单，saga 补偿时取消订单并退还这笔扣款
+		inProgress, err := o.orderSagaInProgress(ctx, order.OrderNo)
+		if err != nil {
+			return err
+		}
+		if inProgress {
+			return o.recordSagaPaymentResult(ctx, order, record)
+		}
+		record.Action = consts.PAYMENT_ACTION_PAYED
+		in := consts.TransitionInput{
+			Actor:            consts.ActorSystem,
+			Reason:           fmt.Sprintf("payment %s succeeded", record.PayOrderID),
+			PayAmount:        record.Amount,
+			PayTransactionID: record.PayOrderID,
+			PayMethod:        payMethod,
+		}
+		return o.changeOrderStatusWith(ctx, order, consts.PAYED, in, func(txo *OrderServiceImpl) error {
+			return txo.recordPaymentResult(ctx, record)
+		})
+	case order.Status == consts.CANCELED && order.PayTime.IsZero():
+		// 订单从未付款就已取消，取消时不会退款，这笔扣款需要退回
+		record.Action = consts.PAYMENT_ACTION_REFUNDED
+		return o.transaction(func(txo *OrderServiceImpl) error {
+			if err := txo.recordPaymentResult(ctx, record); err != nil {
+				return err
+			}
+			return txo.requestRefund(ctx, order, "", record.Amount, LATE_PAYMENT_REFUND_REASON)
+		})
+	default:
+		record.Action = consts.PAYMENT_ACTION_IGNORED
+		return o.recordPaymentResult(ctx, record)
+	}
+}
+
+func (o *OrderServiceImpl) handlePaymentFailure(ctx context.Context, order *model.Order, record *model.PaymentResult) error {
+	record.Action = consts.PAYMENT_ACTION_IGNORED
+	if order.Status != consts.CREATED {
+		return o.recordPaymentResult(ctx, record)
+	}
+
+	// 下单 saga 仍在执行时由 saga 自己补偿，避免重复回补库存
+	inProgress, err := o.orderSagaInProgress(ctx, order.OrderNo)
+	if err != nil {
+		return err
+	}
+	if inProgress {
+		return o.recordPaymentResult(ctx, record)
+	}
+
+	record.Action = consts.PAYMENT_ACTION_CANCELED
+	in := consts.TransitionInput{Actor: consts.ActorSystem, Reason: fmt.Sprintf("payment failed: %s", record.ErrorMsg)}
+	return o.changeOrderStatusWith(ctx, order, consts.CANCELED, in, func(txo *OrderServiceImpl) error {
+		return txo.recordPaymentResult(ctx, record)
+	})
+}
+
+// recordSagaPaymentResult 下单 saga 未结束时只记录扣款结果，交给 saga 处理；
+// 先锁住订单再记录，cancelSagaOrder 取消订单时一定能看到该结果并退款
+func (o *OrderServiceImpl) recordSagaPaymentResult(ctx context.Context, order *model.Order, record *model.PaymentResult) error {
+	record.Action = consts.PAYMENT_ACTION_SAGA
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		locked, err := txo.orderDao.GetByOrderNoForUpdate(ctx, order.OrderNo)
+		if err != nil {
+			return err
+		}
+		if locked.Status != consts.CREATED {
+			// saga 已先一步确认或取消了订单，重试时按最新状态处理
+			return fmt.Errorf("order %s changed to %s while recording payment result", order.OrderNo, consts.GetOrderStatusName(locked.Status))
+		}
+		return txo.recordPaymentResult(ctx, record)
+	})
+}
+
+// claimSagaPaymentRefund 将交给 saga 处理的扣款结果标记为已退款并返回该结果，
+// 没有待处理的扣款结果或已被标记时返回 nil，保证同一笔扣款只退一次
+func (o *OrderServiceImpl) claimSagaPaymentRefund(ctx context.Context, orderNo string) (*model.PaymentResult, error) {
+	results, err := o.paymentResultDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("claimSagaPaymentRefund: get payment results failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Result != consts.PAYMENT_SUCCESS || result.Action != consts.PAYMENT_ACTION_SAGA {
+			continue
+		}
+		rows, err := o.paymentResultDao.UpdateAction(ctx, orderNo, consts.PAYMENT_SUCCESS, consts.PAYMENT_ACTION_SAGA, consts.PAYMENT_ACTION_REFUNDED)
+		if err != nil || rows == 0 {
+			return nil, err
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// paidAmount 订单实际支付的金额，包括礼品卡和店铺余额抵扣的部分；记录支付信息之前付款的订单按订单总金额计算
+func paidAmount(order *model.Order) int {
+	if order.PayMethod == "" {
+		return order.TotalAmount
+	}
+	return order.PayAmount + order.TenderAmount
+}
+
+// recordPaymentResult 记录支付结果，已记录过时返回 errDuplicatePaymentResult
+func (o *OrderServiceImpl) recordPaymentResult(ctx context.Context, record *model.PaymentResult) error {
+	created, err := o.paymentResultDao.Create(ctx, record)
+	if err != nil {
+		log.Logger.Errorf("recordPaymentResult: create failed, orderNo: %s, err: %s", record.OrderNo, err.Error())
+		return err
+	}
+	if !created {
+		return errDuplicatePaymentResult
+	}
+	return nil
+}
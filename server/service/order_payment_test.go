@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+type paymentTestMocks struct {
+	refundTestMocks
+	paymentResultDao *daoMocks.MockPaymentResultDao
+	orderSagaDao     *daoMocks.MockOrderSagaDao
+	productClient    *mocks.MockProductServiceClient
+}
+
+func newPaymentTestService(ctrl *gomock.Controller) (*OrderServiceImpl, paymentTestMocks) {
+	service, rm := newRefundTestService(ctrl)
+	m := paymentTestMocks{
+		refundTestMocks:  rm,
+		paymentResultDao: daoMocks.NewMockPaymentResultDao(ctrl),
+		orderSagaDao:     daoMocks.NewMockOrderSagaDao(ctrl),
+		productClient:    mocks.NewMockProductServiceClient(ctrl),
+	}
+	m.paymentResultDao.EXPECT().WithTx(gomock.Any()).Return(m.paymentResultDao).AnyTimes()
+	service.paymentResultDao = m.paymentResultDao
+	service.orderSagaDao = m.orderSagaDao
+	service.productServiceClient = m.productClient
+	return service, m
+}
+
+func paymentResultMsg(result string) *types.PaymentResultMessage {
+	return &types.PaymentResultMessage{PayOrderID: "PAY001", BizID: "ORDER001", UserID: 123, Amount: 3525, Result: result}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_SuccessPaysOrder tests an unpaid order becomes PAYED
+func TestOrderServiceImpl_HandlePaymentResult_SuccessPaysOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.CREATED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.expectInvoiceIssued(ctx, products)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["pay_amount"] != 3525 || updates["pay_transaction_id"] != "PAY001" || updates["pay_method"] != consts.PAY_METHOD_BALANCE {
+				t.Errorf("Unexpected payment fields: %v", updates)
+			}
+			return 1, nil
+		})
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, result *model.PaymentResult) (bool, error) {
+			if result.Action != consts.PAYMENT_ACTION_PAYED || result.Result != consts.PAYMENT_SUCCESS {
+				t.Errorf("Unexpected payment result: %+v", result)
+			}
+			return true, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.PAYED {
+		t.Errorf("Expected order status %d, got %d", consts.PAYED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_Duplicate tests a repeated notification is ignored
+func TestOrderServiceImpl_HandlePaymentResult_Duplicate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.CREATED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).Return(1, nil)
+	m.expectInvoiceIssued(ctx, products)
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(false, nil)
+	m.messageWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	// 事务回滚，订单状态恢复
+	if order.Status != consts.CREATED {
+		t.Errorf("Expected order status %d, got %d", consts.CREATED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_LateSuccessRefunded tests a payment captured after the order was canceled is refunded
+func TestOrderServiceImpl_HandlePaymentResult_LateSuccessRefunded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.CANCELED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, result *model.PaymentResult) (bool, error) {
+			if result.Action != consts.PAYMENT_ACTION_REFUNDED {
+				t.Errorf("Expected action %d, got %d", consts.PAYMENT_ACTION_REFUNDED, result.Action)
+			}
+			return true, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", "ORDER001", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, value string) error {
+			if !strings.Contains(value, `"amount":3525`) || !strings.Contains(value, LATE_PAYMENT_REFUND_REASON) {
+				t.Errorf("Unexpected refund message: %s", value)
+			}
+			return nil
+		})
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_SuccessAfterPaidCancel tests no second refund for an order refunded on cancellation
+func TestOrderServiceImpl_HandlePaymentResult_SuccessAfterPaidCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.CANCELED)
+	order.PayTime = time.Now().Add(-time.Hour)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, result *model.PaymentResult) (bool, error) {
+			if result.Action != consts.PAYMENT_ACTION_IGNORED {
+				t.Errorf("Expected action %d, got %d", consts.PAYMENT_ACTION_IGNORED, result.Action)
+			}
+			return true, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_FailureCancelsOrder tests a failed payment cancels the order and restores stock
+func TestOrderServiceImpl_HandlePaymentResult_FailureCancelsOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.CREATED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).Return(1, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil).Times(2)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_canceled", "ORDER001", gomock.Any()).Return(nil)
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+
+	msg := paymentResultMsg(consts.PAYMENT_FAILED)
+	msg.ErrorMsg = "insufficient balance"
+	if err := service.HandlePaymentResult(ctx, msg); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.CANCELED {
+		t.Errorf("Expected order status %d, got %d", consts.CANCELED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_FailureDuringSaga tests a running saga is left to compensate by itself
+func TestOrderServiceImpl_HandlePaymentResult_FailureDuringSaga(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.CREATED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_RUNNING}, nil)
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	m.orderDao.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_FAILED)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_HandlePaymentResult_OrderNotFound tests notifications for unknown orders are skipped
+func TestOrderServiceImpl_HandlePaymentResult_OrderNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPaymentTestService(ctrl)
+	ctx := context.Background()
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, gorm.ErrRecordNotFound)
+	m.paymentResultDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.HandlePaymentResult(ctx, paymentResultMsg(consts.PAYMENT_SUCCESS)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// ErrInvalidOrderItems 商品不存在、数量不合法或库存不足
+var ErrInvalidOrderItems = errors.New("invalid order items")
+
+// PriceChangedError is returned when the price the customer saw no longer matches
+// the product service. Info carries the re-quoted order for the frontend.
+type PriceChangedError struct {
+	Info types.PriceChangedInfo
+}
+
+func (e *PriceChangedError) Error() string {
+	return fmt.Sprintf("price changed, expected total: %d, current total: %d", e.Info.ExpectedTotalAmount, e.Info.TotalAmount)
+}
+
+// orderPricing 订单金额的计算结果，CreateOrder 和 QuoteOrder 使用相同的计算过程
+type orderPricing struct {
+	items           []*types.OrderItemInfo
+	itemTotalAmount int
+	shipping        *types.ShippingQuote
+	discount        *couponDiscount
+	tax             *TaxResult
+	totalAmount     int
+	currency        *Currency
+	baseCurrency    string
+}
+
+// priceOrder 依次计算运费、优惠和税费，税费按优惠后的商品金额计算；
+// 金额均为基础货币，同时记录订单货币及汇率快照
+func (o *OrderServiceImpl) priceOrder(ctx context.Context, orderInfo types.OrderInfo, userID int, pricedItems []*types.OrderItemInfo, itemTotalAmount int) (*orderPricing, error) {
+	currencies := o.getCurrencies()
+	currency, err := currencies.Resolve(orderInfo.Currency, orderInfo.ReceiverCountry)
+	if err != nil {
+		return nil, err
+	}
+	pricing := &orderPricing{
+		items:           pricedItems,
+		itemTotalAmount: itemTotalAmount,
+		currency:        currency,
+		baseCurrency:    currencies.Base().Code,
+	}
+	pricing.shipping = o.calculateShipping(orderInfo.ReceiverCountry, pricedItems, itemTotalAmount)
+
+	discount, err := o.applyCoupon(ctx, orderInfo.CouponCode, userID, pricedItems, itemTotalAmount, pricing.shipping.ShippingFee)
+	if err != nil {
+		return nil, err
+	}
+	pricing.discount = discount
+	pricing.tax = o.calculateTax(orderInfo.ReceiverCountry, orderInfo.ReceiverZipCode, pricedItems, discount)
+	pricing.totalAmount = orderAmount(itemTotalAmount, pricing.shipping.ShippingFee, pricing.tax, discount)
+	return pricing, nil
+}
+
+// getProducts 查询订单商品在商品服务中的价格和库存
+func (o *OrderServiceImpl) getProducts(ctx context.Context, orderItems []*types.OrderItemInfo) (map[int]*productpb.Product, error) {
+	orderItemIds := make([]int64, len(orderItems))
+	for idx, item := range orderItems {
+		orderItemIds[idx] = int64(item.ProductID)
+	}
+	productList, err := o.productServiceClient.GetProductList(ctx, &productpb.GetProductListRequest{
+		Ids: orderItemIds,
+	})
+	if err != nil {
+		log.Logger.Errorf("getProducts: get product list failed, err: %s", err.Error())
+		return nil, err
+	}
+	productMap := make(map[int]*productpb.Product, len(productList.Products))
+	for _, product := range productList.Products {
+		productMap[int(product.Id)] = product
+	}
+	return productMap, nil
+}
+
+// getPricedItems 查询商品服务并按当前价格生成订单商品列表，商品列表为空、数量不合法或库存不足时返回错误
+func (o *OrderServiceImpl) getPricedItems(ctx context.Context, orderItems []*types.OrderItemInfo) (pricedItems []*types.OrderItemInfo, itemTotalAmount int, err error) {
+	pricedItems, itemTotalAmount, productMap, err := o.lookupPricedItems(ctx, orderItems)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, item := range pricedItems {
+		if item.Quantity > int(productMap[item.ProductID].Stock) {
+			return nil, 0, fmt.Errorf("%w: do not have enough stock, product id: %d", ErrInvalidOrderItems, item.ProductID)
+		}
+	}
+	return pricedItems, itemTotalAmount, nil
+}
+
+// lookupPricedItems 校验订单商品列表后查询商品服务并按当前价格生成订单商品列表，同时返回商品信息用于检查库存；
+// 下单和询价共用，商品列表为空或数量不合法时返回错误
+func (o *OrderServiceImpl) lookupPricedItems(ctx context.Context, orderItems []*types.OrderItemInfo) (pricedItems []*types.OrderItemInfo, itemTotalAmount int, productMap map[int]*productpb.Product, err error) {
+	if len(orderItems) == 0 {
+		return nil, 0, nil, fmt.Errorf("%w: order item list is empty", ErrInvalidOrderItems)
+	}
+	for _, orderItem := range orderItems {
+		if orderItem == nil || orderItem.Quantity <= 0 {
+			return nil, 0, nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidOrderItems)
+		}
+	}
+	productMap, err = o.getProducts(ctx, orderItems)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	pricedItems, itemTotalAmount, err = priceOrderItems(orderItems, productMap)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return pricedItems, itemTotalAmount, productMap, nil
+}
+
+// priceOrderItems 使用商品服务返回的单价和名称重新生成订单商品列表，不校验库存；调用前需先校验商品列表
+func priceOrderItems(orderItems []*types.OrderItemInfo, productMap map[int]*productpb.Product) (pricedItems []*types.OrderItemInfo, itemTotalAmount int, err error) {
+	pricedItems = make([]*types.OrderItemInfo, 0, len(orderItems))
+	for _, orderItem := range orderItems {
+		product, ok := productMap[orderItem.ProductID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: product not found, product id: %d", ErrInvalidOrderItems, orderItem.ProductID)
+		}
+		pricedItems = append(pricedItems, &types.OrderItemInfo{
+			ProductID:   orderItem.ProductID,
+			ProductName: product.Name,
+			Quantity:    orderItem.Quantity,
+			Price:       int(product.Price),
+		})
+		itemTotalAmount += int(product.Price) * orderItem.Quantity
+	}
+	return pricedItems, itemTotalAmount, nil
+}
+
+// checkPriceChanged 比较客户端提交的单价及预期总金额与服务端计算结果，不一致时返回 PriceChangedError
+func checkPriceChanged(orderInfo types.OrderInfo, pricedItems []*types.OrderItemInfo, totalAmount int) error {
+	changedItems := make([]*types.PriceChangedItem, 0)
+	for idx, orderItem := range orderInfo.OrderItemList {
+		if orderItem.Price != pricedItems[idx].Price {
+			changedItems = append(changedItems, &types.PriceChangedItem{
+				ProductID:     orderItem.ProductID,
+				ProductName:   pricedItems[idx].ProductName,
+				ExpectedPrice: orderItem.Price,
+				CurrentPrice:  pricedItems[idx].Price,
+			})
+		}
+	}
+	totalChanged := orderInfo.ExpectedTotalAmount != 0 && orderInfo.ExpectedTotalAmount != totalAmount
+	if len(changedItems) == 0 && !totalChanged {
+		return nil
+	}
+	return &PriceChangedError{
+		Info: types.PriceChangedInfo{
+			Items:               changedItems,
+			ExpectedTotalAmount: orderInfo.ExpectedTotalAmount,
+			TotalAmount:         totalAmount,
+			OrderItemList:       pricedItems,
+		},
+	}
+}
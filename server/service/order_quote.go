@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// QuoteOrder 按与 CreateOrder 相同的计算过程返回价格明细，不保存订单也不调用支付服务。
+// 库存不足的商品标记为不可购买而不返回错误
+func (o *OrderServiceImpl) QuoteOrder(ctx context.Context, orderInfo types.OrderInfo, userID int) (quote *types.OrderQuote, err error) {
+	pricedItems, itemTotalAmount, productMap, err := o.lookupPricedItems(ctx, orderInfo.OrderItemList)
+	if err != nil {
+		log.Logger.Errorf("QuoteOrder: price order items failed, err: %s", err.Error())
+		return nil, err
+	}
+	pricing, err := o.priceOrder(ctx, orderInfo, userID, pricedItems, itemTotalAmount)
+	if err != nil {
+		log.Logger.Errorf("QuoteOrder: price order failed, err: %s", err.Error())
+		return nil, err
+	}
+
+	quote = &types.OrderQuote{
+		Items:           make([]*types.OrderQuoteItem, 0, len(pricedItems)),
+		ItemTotalAmount: itemTotalAmount,
+		ShippingFee:     pricing.shipping.ShippingFee,
+		Shipping:        pricing.shipping,
+		DiscountAmount:  pricing.discount.total(),
+		CouponCode:      pricing.discount.code(),
+		Discounts:       []*types.OrderDiscountDetail{},
+		Tax:             pricing.tax.Tax,
+		TaxRegion:       pricing.tax.Region,
+		TaxRate:         pricing.tax.Rate,
+		TaxInclusive:    pricing.tax.Inclusive,
+		TotalAmount:     pricing.totalAmount,
+		Available:       true,
+		Currency:        pricing.currency.Code,
+		BaseCurrency:    pricing.baseCurrency,
+		ExchangeRate:    pricing.currency.ExchangeRate,
+	}
+	quote.Amounts = formatOrderAmounts(orderAmountValues{
+		itemTotalAmount: itemTotalAmount,
+		shippingFee:     quote.ShippingFee,
+		tax:             quote.Tax,
+		discountAmount:  quote.DiscountAmount,
+		totalAmount:     quote.TotalAmount,
+	}, pricing.currency, pricing.currency.ExchangeRate)
+	if pricing.discount != nil {
+		discount := pricing.discount.orderDiscount("")
+		quote.Discounts = append(quote.Discounts, toOrderDiscountDetail(&discount))
+	}
+	for idx, item := range pricedItems {
+		stock := int(productMap[item.ProductID].Stock)
+		quoteItem := &types.OrderQuoteItem{
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			Price:          item.Price,
+			ExpectedPrice:  orderInfo.OrderItemList[idx].Price,
+			PriceChanged:   orderInfo.OrderItemList[idx].Price != item.Price,
+			Quantity:       item.Quantity,
+			TotalPrice:     item.Price * item.Quantity,
+			DiscountAmount: pricing.discount.itemDiscountAt(idx),
+			TaxRate:        pricing.tax.Lines[idx].Rate,
+			TaxAmount:      pricing.tax.Lines[idx].Amount,
+			Stock:          stock,
+			Available:      item.Quantity <= stock,
+		}
+		quote.Items = append(quote.Items, quoteItem)
+		quote.Available = quote.Available && quoteItem.Available
+	}
+	return quote, nil
+}
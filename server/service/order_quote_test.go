@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+// TestOrderServiceImpl_QuoteOrder tests the quote matches CreateOrder's numbers and reports stock per item without persisting
+func TestOrderServiceImpl_QuoteOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	coupon := &model.Coupon{ID: 7, Code: "SAVE10", Type: consts.COUPON_PERCENTAGE, Value: 10, Status: consts.COUPON_ACTIVE}
+	products := twoProductListResponse()
+	products.Products[1].Stock = 2
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(products, nil)
+	m.couponDao.EXPECT().GetByCode(ctx, "SAVE10").Return(coupon, nil)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.CouponCode = "SAVE10"
+	orderInfo.OrderItemList[1].Price = 400
+	quote, err := service.QuoteOrder(ctx, orderInfo, 123)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// 与 TestOrderServiceImpl_CreateOrder_WithCoupon 相同：商品 2500，减免 250，运费 800，税费 202
+	if quote.ItemTotalAmount != 2500 || quote.DiscountAmount != 250 || quote.ShippingFee != 800 || quote.Tax != 202 || quote.TotalAmount != 3252 {
+		t.Errorf("Unexpected quote amounts: %+v", quote)
+	}
+	if len(quote.Discounts) != 1 || quote.Discounts[0].CouponCode != "SAVE10" || quote.Discounts[0].ItemDiscount != 250 {
+		t.Errorf("Unexpected discounts: %+v", quote.Discounts)
+	}
+	if quote.Available {
+		t.Error("Expected quote to be unavailable when an item is short of stock")
+	}
+	bowl, cup := quote.Items[0], quote.Items[1]
+	if !bowl.Available || bowl.PriceChanged || bowl.DiscountAmount != 100 || bowl.TaxAmount != 80 {
+		t.Errorf("Unexpected bowl: %+v", bowl)
+	}
+	if cup.Available || cup.Stock != 2 || !cup.PriceChanged || cup.Price != 500 || cup.ExpectedPrice != 400 || cup.TaxAmount != 122 {
+		t.Errorf("Unexpected cup: %+v", cup)
+	}
+}
+
+// TestOrderServiceImpl_QuoteOrder_InvalidCoupon tests an unusable coupon fails the quote like it fails the order
+func TestOrderServiceImpl_QuoteOrder_InvalidCoupon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.couponDao.EXPECT().GetByCode(ctx, "NOPE").Return(nil, gorm.ErrRecordNotFound)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.CouponCode = "NOPE"
+	if _, err := service.QuoteOrder(ctx, orderInfo, 123); !errors.Is(err, ErrInvalidCoupon) {
+		t.Errorf("Expected ErrInvalidCoupon, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_QuoteOrder_ProductNotFound tests an unknown product is rejected
+func TestOrderServiceImpl_QuoteOrder_ProductNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(&productpb.GetProductListResponse{}, nil)
+
+	if _, err := service.QuoteOrder(ctx, twoItemOrderInfo(), 123); !errors.Is(err, ErrInvalidOrderItems) {
+		t.Errorf("Expected ErrInvalidOrderItems, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_QuoteOrder_InvalidItems tests an empty list or a nil item is rejected before the product service is called
+func TestOrderServiceImpl_QuoteOrder_InvalidItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	m.productClient.EXPECT().GetProductList(gomock.Any(), gomock.Any()).Times(0)
+
+	t.Run("empty list", func(t *testing.T) {
+		orderInfo := twoItemOrderInfo()
+		orderInfo.OrderItemList = nil
+		if _, err := service.QuoteOrder(ctx, orderInfo, 123); !errors.Is(err, ErrInvalidOrderItems) {
+			t.Errorf("Expected ErrInvalidOrderItems, got: %v", err)
+		}
+	})
+
+	t.Run("nil item", func(t *testing.T) {
+		orderInfo := twoItemOrderInfo()
+		orderInfo.OrderItemList = append(orderInfo.OrderItemList, nil)
+		if _, err := service.QuoteOrder(ctx, orderInfo, 123); !errors.Is(err, ErrInvalidOrderItems) {
+			t.Errorf("Expected ErrInvalidOrderItems, got: %v", err)
+		}
+	})
+}
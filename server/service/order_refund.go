@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+var ErrInvalidRefundItems = errors.New("invalid refund items")
+
+// RequestRefund 用户申请退款，订单进入退款中等待商家审核
+// req.Items 为空时退还订单中尚未退款的全部商品
+func (o *OrderServiceImpl) RequestRefund(ctx context.Context, orderNo string, userID int, req types.RefundRequest) (refundNo string, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	order, err := o.orderDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("RequestRefund: get order failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+	in := consts.TransitionInput{
+		Actor:       consts.ActorCustomer,
+		UserID:      userID,
+		OwnerUserID: order.UserID,
+		Reason:      req.Reason,
+	}
+	if _, err = consts.CheckTransition(order.Status, consts.REFUNDING, in); err != nil {
+		log.Logger.Errorf("RequestRefund: invalid transition, orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+
+	refundNo = utils.GenerateRefundNo()
+	items, amount, err := o.priceRefundItems(ctx, order, req.Items)
+	if err != nil {
+		log.Logger.Errorf("RequestRefund: orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+	for i := range items {
+		items[i].RefundNo = refundNo
+	}
+	refund := &model.Refund{
+		RefundNo:      refundNo,
+		OrderNo:       orderNo,
+		UserID:        userID,
+		Amount:        amount,
+		Status:        consts.REFUND_PENDING,
+		PrevStatus:    order.Status,
+		Reason:        req.Reason,
+		ToStoreCredit: req.ToStoreCredit,
+	}
+
+	err = o.transaction(func(txo *OrderServiceImpl) error {
+		if _, err := txo.refundDao.Create(ctx, refund, items); err != nil {
+			log.Logger.Errorf("RequestRefund: create refund failed, orderNo: %s, err: %s", orderNo, err.Error())
+			return err
+		}
+		oldStatus, err := txo.transitOrderStatus(ctx, order, consts.REFUNDING, in)
+		if err != nil {
+			return err
+		}
+		return txo.sendStatusChangedMsg(ctx, order, oldStatus, in)
+	})
+	if err != nil {
+		return "", err
+	}
+	return refundNo, nil
+}
+
+// ApproveRefund 商家同意退款，退款金额通过 order_refund 消息交给支付服务
+// 订单全部金额退完后为已退款，否则为部分退款
+func (o *OrderServiceImpl) ApproveRefund(ctx context.Context, refundNo string) (err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	refund, order, err := o.getPendingRefund(ctx, refundNo)
+	if err != nil {
+		return err
+	}
+	refundedAmount, err := o.getApprovedRefundAmount(ctx, order.OrderNo)
+	if err != nil {
+		return err
+	}
+	newStatus := consts.PARTIALLY_REFUNDED
+	if refundedAmount+refund.Amount >= paidAmount(order) {
+		newStatus = consts.REFUNDED
+	}
+
+	in := consts.TransitionInput{Actor: consts.ActorMerchant, Reason: refund.Reason}
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		if err := txo.reviewRefund(ctx, refund, consts.REFUND_APPROVED, ""); err != nil {
+			return err
+		}
+		oldStatus, err := txo.transitOrderStatus(ctx, order, newStatus, in)
+		if err != nil {
+			return err
+		}
+		if err = txo.sendStatusChangedMsg(ctx, order, oldStatus, in); err != nil {
+			return err
+		}
+		if err = txo.issueCreditNote(ctx, order, refund.RefundNo, refund.Amount, refund.Reason); err != nil {
+			return err
+		}
+		return txo.settleRefund(ctx, order, refund)
+	})
+}
+
+// settleRefund 退款到店铺余额时先退回原储值卡，剩余部分发放为店铺余额；否则按原支付方式退回，应在退款的事务中调用
+func (o *OrderServiceImpl) settleRefund(ctx context.Context, order *model.Order, refund *model.Refund) error {
+	if !refund.ToStoreCredit {
+		return o.requestRefund(ctx, order, refund.RefundNo, refund.Amount, refund.Reason)
+	}
+	remaining, err := o.restoreOrderTenders(ctx, order, refund.RefundNo, refund.Amount)
+	if err != nil {
+		return err
+	}
+	return o.creditStoreCredit(ctx, order, refund.RefundNo, remaining)
+}
+
+// RejectRefund 商家拒绝退款，订单回到申请退款前的状态
+func (o *OrderServiceImpl) RejectRefund(ctx context.Context, refundNo string, reason string) (err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	refund, order, err := o.getPendingRefund(ctx, refundNo)
+	if err != nil {
+		return err
+	}
+
+	in := consts.TransitionInput{Actor: consts.ActorMerchant, Reason: reason}
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		if err := txo.reviewRefund(ctx, refund, consts.REFUND_REJECTED, reason); err != nil {
+			return err
+		}
+		oldStatus, err := txo.transitOrderStatus(ctx, order, refund.PrevStatus, in)
+		if err != nil {
+			return err
+		}
+		return txo.sendStatusChangedMsg(ctx, order, oldStatus, in)
+	})
+}
+
+// getPendingRefund 查询待审核的退款单及其订单
+func (o *OrderServiceImpl) getPendingRefund(ctx context.Context, refundNo string) (*model.Refund, *model.Order, error) {
+	refund, err := o.refundDao.GetByRefundNo(ctx, refundNo)
+	if err != nil {
+		log.Logger.Errorf("getPendingRefund: get refund failed, refundNo: %s, err: %s", refundNo, err.Error())
+		return nil, nil, err
+	}
+	if refund.Status != consts.REFUND_PENDING {
+		statusErr := fmt.Errorf("refund %s is already %s", refundNo, consts.GetRefundStatusName(refund.Status))
+		log.Logger.Errorf("getPendingRefund: %s", statusErr.Error())
+		return nil, nil, statusErr
+	}
+	order, err := o.orderDao.GetByOrderNo(ctx, refund.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("getPendingRefund: get order failed, orderNo: %s, err: %s", refund.OrderNo, err.Error())
+		return nil, nil, err
+	}
+	return refund, order, nil
+}
+
+// reviewRefund 写入审核结果，只有待审核的退款单才会更新
+func (o *OrderServiceImpl) reviewRefund(ctx context.Context, refund *model.Refund, status int, rejectReason string) error {
+	rows, err := o.refundDao.UpdateStatus(ctx, refund.RefundNo, consts.REFUND_PENDING, map[string]interface{}{
+		"status":        status,
+		"reject_reason": rejectReason,
+		"review_time":   time.Now(),
+	})
+	if err != nil {
+		log.Logger.Errorf("reviewRefund: update refund failed, refundNo: %s, err: %s", refund.RefundNo, err.Error())
+		return err
+	}
+	if rows == 0 {
+		statusErr := fmt.Errorf("reviewRefund: refund reviewed concurrently, refundNo: %s", refund.RefundNo)
+		log.Logger.Errorf(statusErr.Error())
+		return statusErr
+	}
+	refund.Status = status
+	return nil
+}
+
+// priceRefundItems 查询订单商品及已有退款，校验退款数量并计算退款金额
+func (o *OrderServiceImpl) priceRefundItems(ctx context.Context, order *model.Order, reqItems []*types.RefundItemRequest) (items []model.RefundItem, amount int, err error) {
+	orderProducts, err := o.orderProductDao.GetByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		return nil, 0, err
+	}
+	refunds, err := o.refundDao.GetByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		return nil, 0, err
+	}
+	refundItems, err := o.refundDao.GetItemsByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		return nil, 0, err
+	}
+	return buildRefundItems(order, orderProducts, refunds, refundItems, reqItems)
+}
+
+// buildRefundItems 校验退款数量并计算退款金额
+// 商品退款金额为商品小计加按比例分摊的税费；退完全部商品时退还剩余的全部金额（含运费）
+func buildRefundItems(order *model.Order, orderProducts []*model.OrderProduct, refunds []*model.Refund,
+	refundItems []*model.RefundItem, reqItems []*types.RefundItemRequest) (items []model.RefundItem, amount int, err error) {
+	// 已退款或审核中的数量
+	refundedQty := make(map[int]int)
+	refundedAmount := 0
+	activeRefunds := make(map[string]bool)
+	for _, r := range refunds {
+		if r.Status != consts.REFUND_REJECTED {
+			activeRefunds[r.RefundNo] = true
+			refundedAmount += r.Amount
+		}
+	}
+	for _, item := range refundItems {
+		if activeRefunds[item.RefundNo] {
+			refundedQty[item.OrderProductID] += item.Quantity
+		}
+	}
+
+	paid := paidAmount(order)
+	productByID := make(map[int]*model.OrderProduct, len(orderProducts))
+	remaining := make(map[int]int, len(orderProducts))
+	itemTotalAmount := 0
+	for _, product := range orderProducts {
+		productByID[product.ID] = product
+		remaining[product.ID] = product.Quantity - refundedQty[product.ID]
+		itemTotalAmount += product.TotalPrice - product.DiscountAmount
+	}
+
+	if len(reqItems) == 0 {
+		for _, product := range orderProducts {
+			if remaining[product.ID] > 0 {
+				reqItems = append(reqItems, &types.RefundItemRequest{OrderProductID: product.ID, Quantity: remaining[product.ID]})
+			}
+		}
+		if len(reqItems) == 0 {
+			return nil, 0, fmt.Errorf("%w: all items have been refunded", ErrInvalidRefundItems)
+		}
+	}
+
+	for _, reqItem := range reqItems {
+		product, ok := productByID[reqItem.OrderProductID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: order product %d not in order", ErrInvalidRefundItems, reqItem.OrderProductID)
+		}
+		if reqItem.Quantity <= 0 || reqItem.Quantity > remaining[product.ID] {
+			return nil, 0, fmt.Errorf("%w: order product %d has %d refundable, requested %d",
+				ErrInvalidRefundItems, product.ID, remaining[product.ID], reqItem.Quantity)
+		}
+		remaining[product.ID] -= reqItem.Quantity
+
+		// 按优惠后的商品金额退款，税费按商品记录的税额退还，含税价格不另退税费
+		subtotal := (product.TotalPrice - product.DiscountAmount) * reqItem.Quantity / product.Quantity
+		itemAmount := subtotal
+		switch {
+		case order.TaxRegion != "":
+			if !order.TaxInclusive {
+				itemAmount += product.TaxAmount * reqItem.Quantity / product.Quantity
+			}
+		case itemTotalAmount > 0:
+			// 记录商品税额之前的订单按优惠后的金额分摊
+			itemAmount += order.Tax * subtotal / itemTotalAmount
+		}
+		if paid != order.TotalAmount && order.TotalAmount > 0 {
+			// 实际支付金额与订单金额不同时按比例退款
+			itemAmount = itemAmount * paid / order.TotalAmount
+		}
+		items = append(items, model.RefundItem{
+			OrderNo:        order.OrderNo,
+			OrderProductID: product.ID,
+			ProductID:      product.ProductID,
+			Quantity:       reqItem.Quantity,
+			Amount:         itemAmount,
+		})
+		amount += itemAmount
+	}
+
+	allRefunded := true
+	for _, qty := range remaining {
+		if qty > 0 {
+			allRefunded = false
+			break
+		}
+	}
+	if allRefunded {
+		// 最后一次退款退还剩余金额，避免分摊取整造成的误差
+		amount = paid - refundedAmount
+	}
+	return items, amount, nil
+}
+
+// getRefundDetails 转换订单的退款记录，返回已退款金额
+func (o *OrderServiceImpl) getRefundDetails(ctx context.Context, orderNo string) (details []*types.RefundDetail, refundedAmount int, err error) {
+	refunds, err := o.refundDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, 0, err
+	}
+	refundItems, err := o.refundDao.GetItemsByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	itemsByRefundNo := make(map[string][]*types.RefundItemDetail)
+	for _, item := range refundItems {
+		itemsByRefundNo[item.RefundNo] = append(itemsByRefundNo[item.RefundNo], &types.RefundItemDetail{
+			OrderProductID: item.OrderProductID,
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			Amount:         item.Amount,
+		})
+	}
+	details = make([]*types.RefundDetail, 0, len(refunds))
+	for _, refund := range refunds {
+		if refund.Status == consts.REFUND_APPROVED {
+			refundedAmount += refund.Amount
+		}
+		details = append(details, &types.RefundDetail{
+			RefundNo:      refund.RefundNo,
+			Amount:        refund.Amount,
+			Status:        refund.Status,
+			StatusName:    consts.GetRefundStatusName(refund.Status),
+			Reason:        refund.Reason,
+			ToStoreCredit: refund.ToStoreCredit,
+			RejectReason:  refund.RejectReason,
+			ReviewTime:    refund.ReviewTime,
+			CreateTime:    refund.CreateTime,
+			Items:         itemsByRefundNo[refund.RefundNo],
+		})
+	}
+	return details, refundedAmount, nil
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+type refundTestMocks struct {
+	orderDao        *daoMocks.MockOrderDao
+	orderProductDao *daoMocks.MockOrderProductDao
+	refundDao       *daoMocks.MockRefundDao
+	invoiceDao      *daoMocks.MockInvoiceDao
+	messageWriter   *utilMocks.MockTxWriter
+}
+
+func newRefundTestService(ctrl *gomock.Controller) (*OrderServiceImpl, refundTestMocks) {
+	m := refundTestMocks{
+		orderDao:        daoMocks.NewMockOrderDao(ctrl),
+		orderProductDao: daoMocks.NewMockOrderProductDao(ctrl),
+		refundDao:       daoMocks.NewMockRefundDao(ctrl),
+		invoiceDao:      daoMocks.NewMockInvoiceDao(ctrl),
+		messageWriter:   utilMocks.NewMockTxWriter(ctrl),
+	}
+	m.orderDao.EXPECT().WithTx(gomock.Any()).Return(m.orderDao).AnyTimes()
+	m.orderProductDao.EXPECT().WithTx(gomock.Any()).Return(m.orderProductDao).AnyTimes()
+	m.refundDao.EXPECT().WithTx(gomock.Any()).Return(m.refundDao).AnyTimes()
+	m.invoiceDao.EXPECT().WithTx(gomock.Any()).Return(m.invoiceDao).AnyTimes()
+	m.messageWriter.EXPECT().WithTx(gomock.Any()).Return(m.messageWriter).AnyTimes()
+	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		orderDao:        m.orderDao,
+		orderProductDao: m.orderProductDao,
+		refundDao:       m.refundDao,
+		invoiceDao:      m.invoiceDao,
+		messageWriter:   m.messageWriter,
+	}
+	return service, m
+}
+
+// expectInvoiceIssued 订单付款后开具发票
+func (m refundTestMocks) expectInvoiceIssued(ctx context.Context, products []*model.OrderProduct) {
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.invoiceDao.EXPECT().NextSequence(ctx, gomock.Any()).Return(1, nil)
+	m.invoiceDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+}
+
+// expectNoInvoice 订单没有发票，不开具贷项通知单
+func (m refundTestMocks) expectNoInvoice(ctx context.Context, orderNo string) {
+	m.invoiceDao.EXPECT().GetByOrderNo(ctx, orderNo, consts.INVOICE_TYPE_INVOICE).Return(nil, nil)
+}
+
+// refundTestOrder 两件商品 A(1000 x 2) 和 B(500 x 1)，税费 225，运费 800
+func refundTestOrder(status int) (*model.Order, []*model.OrderProduct) {
+	order := &model.Order{OrderNo: "ORDER001", UserID: 123, Status: status, TotalAmount: 3525, ShippingFee: 800, Tax: 225}
+	products := []*model.OrderProduct{
+		{ID: 11, OrderNo: "ORDER001", ProductID: 1, Price: 1000, Quantity: 2, TotalPrice: 2000},
+		{ID: 12, OrderNo: "ORDER001", ProductID: 2, Price: 500, Quantity: 1, TotalPrice: 500},
+	}
+	return order, products
+}
+
+// TestOrderServiceImpl_RequestRefund_PartialItems tests a partial refund is priced with its share of tax
+func TestOrderServiceImpl_RequestRefund_PartialItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.DELIVERED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+			// 1000 + 225 * 1000 / 2500
+			if refund.Amount != 1090 {
+				t.Errorf("Expected refund amount 1090, got %d", refund.Amount)
+			}
+			if refund.PrevStatus != consts.DELIVERED || refund.Status != consts.REFUND_PENDING {
+				t.Errorf("Unexpected refund status %d, prev status %d", refund.Status, refund.PrevStatus)
+			}
+			if len(items) != 1 || items[0].OrderProductID != 11 || items[0].Quantity != 1 || items[0].RefundNo != refund.RefundNo {
+				t.Errorf("Unexpected refund items: %+v", items)
+			}
+			return refund.RefundNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.DELIVERED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	refundNo, err := service.RequestRefund(ctx, "ORDER001", 123, types.RefundRequest{
+		Reason: "broken",
+		Items:  []*types.RefundItemRequest{{OrderProductID: 11, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(refundNo, "Rf-") {
+		t.Errorf("Unexpected refundNo %s", refundNo)
+	}
+	if order.Status != consts.REFUNDING {
+		t.Errorf("Expected order status %d, got %d", consts.REFUNDING, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_RequestRefund_RemainingItems tests refunding the rest of the order returns the remaining amount
+func TestOrderServiceImpl_RequestRefund_RemainingItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.PARTIALLY_REFUNDED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return([]*model.Refund{
+		{RefundNo: "Rf-1", Amount: 1090, Status: consts.REFUND_APPROVED},
+		{RefundNo: "Rf-2", Amount: 545, Status: consts.REFUND_REJECTED},
+	}, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return([]*model.RefundItem{
+		{RefundNo: "Rf-1", OrderProductID: 11, Quantity: 1},
+		{RefundNo: "Rf-2", OrderProductID: 12, Quantity: 1},
+	}, nil)
+	m.refundDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+			if refund.Amount != 3525-1090 {
+				t.Errorf("Expected refund amount %d, got %d", 3525-1090, refund.Amount)
+			}
+			if len(items) != 2 || items[0].Quantity != 1 || items[1].Quantity != 1 {
+				t.Errorf("Unexpected refund items: %+v", items)
+			}
+			return refund.RefundNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.PARTIALLY_REFUNDED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	_, err := service.RequestRefund(ctx, "ORDER001", 123, types.RefundRequest{Reason: "changed my mind"})
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_RequestRefund_DiscountedPayment tests refunds are prorated by the amount actually paid
+func TestOrderServiceImpl_RequestRefund_DiscountedPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.DELIVERED)
+	order.PayAmount = 2820
+	order.PayMethod = consts.PAY_METHOD_BALANCE
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+			// 1090 * 2820 / 3525
+			if refund.Amount != 872 {
+				t.Errorf("Expected refund amount 872, got %d", refund.Amount)
+			}
+			return refund.RefundNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.DELIVERED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	_, err := service.RequestRefund(ctx, "ORDER001", 123, types.RefundRequest{
+		Reason: "broken",
+		Items:  []*types.RefundItemRequest{{OrderProductID: 11, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_RequestRefund_DiscountedItems tests coupon discounts allocated to a product are not refunded
+func TestOrderServiceImpl_RequestRefund_DiscountedItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.DELIVERED)
+	products[0].DiscountAmount = 200
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+			// (2000 - 200) / 2 + 225 * 900 / 2300
+			if refund.Amount != 988 {
+				t.Errorf("Expected refund amount 988, got %d", refund.Amount)
+			}
+			return refund.RefundNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.DELIVERED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	_, err := service.RequestRefund(ctx, "ORDER001", 123, types.RefundRequest{
+		Reason: "broken",
+		Items:  []*types.RefundItemRequest{{OrderProductID: 11, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_RequestRefund_QuantityExceeded tests refunding more than purchased is rejected
+func TestOrderServiceImpl_RequestRefund_QuantityExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.PAYED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.RequestRefund(ctx, "ORDER001", 123, types.RefundRequest{
+		Items: []*types.RefundItemRequest{{OrderProductID: 12, Quantity: 2}},
+	})
+	if !errors.Is(err, ErrInvalidRefundItems) {
+		t.Errorf("Expected ErrInvalidRefundItems, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_RequestRefund_WrongUser tests users cannot refund another user's order
+func TestOrderServiceImpl_RequestRefund_WrongUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.PAYED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.RequestRefund(ctx, "ORDER001", 456, types.RefundRequest{})
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// TestOrderServiceImpl_ApproveRefund_Partial tests approving part of the order requests the money and keeps the order partially refunded
+func TestOrderServiceImpl_ApproveRefund_Partial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.REFUNDING)
+	refund := &model.Refund{RefundNo: "Rf-1", OrderNo: "ORDER001", Amount: 1090, Status: consts.REFUND_PENDING, PrevStatus: consts.DELIVERED}
+
+	m.refundDao.EXPECT().GetByRefundNo(ctx, "Rf-1").Return(refund, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return([]*model.Refund{refund}, nil)
+	m.refundDao.EXPECT().UpdateStatus(ctx, "Rf-1", consts.REFUND_PENDING, gomock.Any()).Return(1, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.REFUNDING, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.expectNoInvoice(ctx, "ORDER001")
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", "ORDER001", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, msg string) error {
+			if !strings.Contains(msg, `"refund_no":"Rf-1"`) || !strings.Contains(msg, `"amount":1090`) {
+				t.Errorf("Unexpected refund message: %s", msg)
+			}
+			return nil
+		})
+
+	if err := service.ApproveRefund(ctx, "Rf-1"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.PARTIALLY_REFUNDED {
+		t.Errorf("Expected order status %d, got %d", consts.PARTIALLY_REFUNDED, order.Status)
+	}
+	if refund.Status != consts.REFUND_APPROVED {
+		t.Errorf("Expected refund status %d, got %d", consts.REFUND_APPROVED, refund.Status)
+	}
+}
+
+// TestOrderServiceImpl_ApproveRefund_Full tests the order is refunded once the whole amount is returned
+func TestOrderServiceImpl_ApproveRefund_Full(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.REFUNDING)
+	refund := &model.Refund{RefundNo: "Rf-2", OrderNo: "ORDER001", Amount: 2435, Status: consts.REFUND_PENDING, PrevStatus: consts.PARTIALLY_REFUNDED}
+
+	m.refundDao.EXPECT().GetByRefundNo(ctx, "Rf-2").Return(refund, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return([]*model.Refund{
+		{RefundNo: "Rf-1", Amount: 1090, Status: consts.REFUND_APPROVED},
+		refund,
+	}, nil)
+	m.refundDao.EXPECT().UpdateStatus(ctx, "Rf-2", consts.REFUND_PENDING, gomock.Any()).Return(1, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.REFUNDING, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.expectNoInvoice(ctx, "ORDER001")
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", "ORDER001", gomock.Any()).Return(nil)
+
+	if err := service.ApproveRefund(ctx, "Rf-2"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.REFUNDED {
+		t.Errorf("Expected order status %d, got %d", consts.REFUNDED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_ApproveRefund_AlreadyReviewed tests a reviewed refund cannot be approved again
+func TestOrderServiceImpl_ApproveRefund_AlreadyReviewed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+
+	m.refundDao.EXPECT().GetByRefundNo(ctx, "Rf-1").Return(&model.Refund{RefundNo: "Rf-1", Status: consts.REFUND_REJECTED}, nil)
+	m.messageWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.ApproveRefund(ctx, "Rf-1"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// TestOrderServiceImpl_RejectRefund tests the order goes back to its status before the refund request
+func TestOrderServiceImpl_RejectRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.REFUNDING)
+	refund := &model.Refund{RefundNo: "Rf-1", OrderNo: "ORDER001", Amount: 1090, Status: consts.REFUND_PENDING, PrevStatus: consts.SHIPPED}
+
+	m.refundDao.EXPECT().GetByRefundNo(ctx, "Rf-1").Return(refund, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.refundDao.EXPECT().UpdateStatus(ctx, "Rf-1", consts.REFUND_PENDING, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["status"] != consts.REFUND_REJECTED || updates["reject_reason"] != "used item" {
+				t.Errorf("Unexpected refund updates: %v", updates)
+			}
+			return 1, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.REFUNDING, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.RejectRefund(ctx, "Rf-1", "used item"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.SHIPPED {
+		t.Errorf("Expected order status %d, got %d", consts.SHIPPED, order.Status)
+	}
+}
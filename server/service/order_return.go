@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+const MAX_RETURN_PHOTOS = 9
+
+var ErrInvalidReturnRequest = errors.New("invalid return request")
+
+// RequestReturn 用户对已收货的订单申请退货
+// 退款金额在申请时计算，商家收到退回的商品后按该金额退款
+func (o *OrderServiceImpl) RequestReturn(ctx context.Context, orderNo string, userID int, req types.CreateReturnRequest) (returnNo string, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if err = validateReturnPhotos(req.PhotoUrls); err != nil {
+		log.Logger.Errorf("RequestReturn: orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+
+	order, err := o.orderDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("RequestReturn: get order failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+	in := consts.TransitionInput{
+		Actor:        consts.ActorCustomer,
+		UserID:       userID,
+		OwnerUserID:  order.UserID,
+		DeliveryTime: order.DeliveryTime,
+		ConfirmTime:  order.ConfirmTime,
+		Reason:       req.Reason,
+	}
+	if _, err = consts.CheckTransition(order.Status, consts.RETURN_REQUESTED, in); err != nil {
+		log.Logger.Errorf("RequestReturn: invalid transition, orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+
+	refundItems, amount, err := o.priceRefundItems(ctx, order, req.Items)
+	if err != nil {
+		log.Logger.Errorf("RequestReturn: orderNo: %s, err: %s", orderNo, err.Error())
+		return "", err
+	}
+	photoUrls, err := utils.JSONEncode(req.PhotoUrls)
+	if err != nil {
+		return "", err
+	}
+
+	returnNo = utils.GenerateReturnNo()
+	items := make([]model.ReturnItem, len(refundItems))
+	for i, item := range refundItems {
+		items[i] = model.ReturnItem{
+			ReturnNo:       returnNo,
+			OrderNo:        orderNo,
+			OrderProductID: item.OrderProductID,
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+		}
+	}
+	ret := &model.ReturnRequest{
+		ReturnNo:      returnNo,
+		OrderNo:       orderNo,
+		UserID:        userID,
+		Status:        consts.RMA_REQUESTED,
+		PrevStatus:    order.Status,
+		Reason:        req.Reason,
+		PhotoUrls:     photoUrls,
+		RefundAmount:  amount,
+		ToStoreCredit: req.ToStoreCredit,
+	}
+
+	err = o.transaction(func(txo *OrderServiceImpl) error {
+		if _, err := txo.returnDao.Create(ctx, ret, items); err != nil {
+			log.Logger.Errorf("RequestReturn: create return failed, orderNo: %s, err: %s", orderNo, err.Error())
+			return err
+		}
+		oldStatus, err := txo.transitOrderStatus(ctx, order, consts.RETURN_REQUESTED, in)
+		if err != nil {
+			return err
+		}
+		return txo.sendStatusChangedMsg(ctx, order, oldStatus, in)
+	})
+	if err != nil {
+		return "", err
+	}
+	return returnNo, nil
+}
+
+// ApproveReturn 商家同意退货并提供退货面单和物流单号
+func (o *OrderServiceImpl) ApproveReturn(ctx context.Context, returnNo string, req types.ApproveReturnRequest) (err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	ret, order, err := o.getReturnInStatus(ctx, returnNo, consts.RMA_REQUESTED)
+	if err != nil {
+		return err
+	}
+
+	in := consts.TransitionInput{
+		Actor:      consts.ActorMerchant,
+		TrackingNo: req.TrackingNo,
+		Reason:     fmt.Sprintf("return %s approved, tracking no: %s", returnNo, req.TrackingNo),
+	}
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		oldStatus, err := txo.transitOrderStatus(ctx, order, consts.RETURNING, in)
+		if err != nil {
+			return err
+		}
+		err = txo.updateReturnStatus(ctx, ret, consts.RMA_APPROVED, map[string]interface{}{
+			"return_label":       req.ReturnLabel,
+			"return_tracking_no": req.TrackingNo,
+			"review_time":        time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return txo.sendStatusChangedMsg(ctx, order, oldStatus, in)
+	})
+}
+
+// RejectReturn 商家拒绝退货，订单回到申请退货前的状态
+func (o *OrderServiceImpl) RejectReturn(ctx context.Context, returnNo string, reason string) (err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	ret, order, err := o.getReturnInStatus(ctx, returnNo, consts.RMA_REQUESTED)
+	if err != nil {
+		return err
+	}
+
+	in := consts.TransitionInput{Actor: consts.ActorMerchant, Reason: reason}
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		err := txo.updateReturnStatus(ctx, ret, consts.RMA_REJECTED, map[string]interface{}{
+			"reject_reason": reason,
+			"review_time":   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		oldStatus, err := txo.transitOrderStatus(ctx, order, returnPrevStatus(ret), in)
+		if err != nil {
+			return err
+		}
+		return txo.sendStatusChangedMsg(ctx, order, oldStatus, in)
+	})
+}
+
+// ReceiveReturn 商家收到退回的商品，生成已同意的退款单并通知支付服务退款
+// restock 为 true 时将退回的商品加回库存
+func (o *OrderServiceImpl) ReceiveReturn(ctx context.Context, returnNo string, restock bool) (err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	ret, order, err := o.getReturnInStatus(ctx, returnNo, consts.RMA_APPROVED)
+	if err != nil {
+		return err
+	}
+	returnItems, err := o.returnDao.GetItemsByReturnNo(ctx, returnNo)
+	if err != nil {
+		log.Logger.Errorf("ReceiveReturn: get return items failed, returnNo: %s, err: %s", returnNo, err.Error())
+		return err
+	}
+	reqItems := make([]*types.RefundItemRequest, len(returnItems))
+	for i, item := range returnItems {
+		reqItems[i] = &types.RefundItemRequest{OrderProductID: item.OrderProductID, Quantity: item.Quantity}
+	}
+	refundItems, amount, err := o.priceRefundItems(ctx, order, reqItems)
+	if err != nil {
+		log.Logger.Errorf("ReceiveReturn: returnNo: %s, err: %s", returnNo, err.Error())
+		return err
+	}
+	if amount != ret.RefundAmount {
+		log.Logger.Warnf("ReceiveReturn: refund amount changed from %d to %d, returnNo: %s", ret.RefundAmount, amount, returnNo)
+	}
+	refundedAmount, err := o.getApprovedRefundAmount(ctx, order.OrderNo)
+	if err != nil {
+		return err
+	}
+	newStatus := consts.PARTIALLY_REFUNDED
+	if refundedAmount+amount >= paidAmount(order) {
+		newStatus = consts.REFUNDED
+	}
+
+	now := time.Now()
+	refundNo := utils.GenerateRefundNo()
+	for i := range refundItems {
+		refundItems[i].RefundNo = refundNo
+	}
+	refund := &model.Refund{
+		RefundNo:      refundNo,
+		OrderNo:       order.OrderNo,
+		UserID:        ret.UserID,
+		Amount:        amount,
+		Status:        consts.REFUND_APPROVED,
+		PrevStatus:    returnPrevStatus(ret),
+		Reason:        fmt.Sprintf("return %s: %s", returnNo, ret.Reason),
+		ReviewTime:    now,
+		ToStoreCredit: ret.ToStoreCredit,
+	}
+
+	in := consts.TransitionInput{Actor: consts.ActorMerchant, Reason: fmt.Sprintf("return %s received", returnNo)}
+	if restock {
+		in.Reason += ", restocked"
+	}
+	err = o.transaction(func(txo *OrderServiceImpl) error {
+		err := txo.updateReturnStatus(ctx, ret, consts.RMA_RECEIVED, map[string]interface{}{
+			"refund_no":    refundNo,
+			"restock":      restock,
+			"receive_time": now,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = txo.refundDao.Create(ctx, refund, refundItems); err != nil {
+			log.Logger.Errorf("ReceiveReturn: create refund failed, returnNo: %s, err: %s", returnNo, err.Error())
+			return err
+		}
+		oldStatus, err := txo.transitOrderStatus(ctx, order, newStatus, in)
+		if err != nil {
+			return err
+		}
+		if err = txo.sendStatusChangedMsg(ctx, order, oldStatus, in); err != nil {
+			return err
+		}
+		if err = txo.issueCreditNote(ctx, order, refundNo, amount, refund.Reason); err != nil {
+			return err
+		}
+		return txo.settleRefund(ctx, order, refund)
+	})
+	if err != nil {
+		return err
+	}
+
+	if restock {
+		o.restockReturnItems(ctx, returnNo, returnItems)
+	}
+	return nil
+}
+
+// returnPrevStatus 申请退货前的订单状态，记录该字段之前创建的退货单都是从已收货申请的
+func returnPrevStatus(ret *model.ReturnRequest) int {
+	if ret.PrevStatus == 0 {
+		return consts.DELIVERED
+	}
+	return ret.PrevStatus
+}
+
+// getReturnInStatus 查询处于 status 状态的退货单及其订单
+func (o *OrderServiceImpl) getReturnInStatus(ctx context.Context, returnNo string, status int) (*model.ReturnRequest, *model.Order, error) {
+	ret, err := o.returnDao.GetByReturnNo(ctx, returnNo)
+	if err != nil {
+		log.Logger.Errorf("getReturnInStatus: get return failed, returnNo: %s, err: %s", returnNo, err.Error())
+		return nil, nil, err
+	}
+	if ret.Status != status {
+		statusErr := fmt.Errorf("return %s is %s", returnNo, consts.GetReturnStatusName(ret.Status))
+		log.Logger.Errorf("getReturnInStatus: %s", statusErr.Error())
+		return nil, nil, statusErr
+	}
+	order, err := o.orderDao.GetByOrderNo(ctx, ret.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("getReturnInStatus: get order failed, orderNo: %s, err: %s", ret.OrderNo, err.Error())
+		return nil, nil, err
+	}
+	return ret, order, nil
+}
+
+// updateReturnStatus 更新退货单状态，只有状态未被并发修改时才会成功
+func (o *OrderServiceImpl) updateReturnStatus(ctx context.Context, ret *model.ReturnRequest, status int, updates map[string]interface{}) error {
+	updates["status"] = status
+	rows, err := o.returnDao.UpdateStatus(ctx, ret.ReturnNo, ret.Status, updates)
+	if err != nil {
+		log.Logger.Errorf("updateReturnStatus: update return failed, returnNo: %s, err: %s", ret.ReturnNo, err.Error())
+		return err
+	}
+	if rows == 0 {
+		statusErr := fmt.Errorf("updateReturnStatus: return status changed concurrently, returnNo: %s", ret.ReturnNo)
+		log.Logger.Errorf(statusErr.Error())
+		return statusErr
+	}
+	ret.Status = status
+	return nil
+}
+
+// getApprovedRefundAmount 订单已同意的退款总额
+func (o *OrderServiceImpl) getApprovedRefundAmount(ctx context.Context, orderNo string) (amount int, err error) {
+	refunds, err := o.refundDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("getApprovedRefundAmount: get refunds failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return 0, err
+	}
+	for _, refund := range refunds {
+		if refund.Status == consts.REFUND_APPROVED {
+			amount += refund.Amount
+		}
+	}
+	return amount, nil
+}
+
+// restockReturnItems 将退回的商品加回库存
+func (o *OrderServiceImpl) restockReturnItems(ctx context.Context, returnNo string, items []*model.ReturnItem) {
+	for _, item := range items {
+		_, err := o.productServiceClient.UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{
+			Id:   int64(item.ProductID),
+			Deta: int64(item.Quantity),
+		})
+		if err != nil {
+			log.Logger.Errorf("restockReturnItems: update stock failed, returnNo: %s, productId: %d, err: %s", returnNo, item.ProductID, err.Error())
+		}
+	}
+}
+
+// validateReturnPhotos 照片数量不超过 MAX_RETURN_PHOTOS，且必须是 http(s) 链接
+func validateReturnPhotos(photoUrls []string) error {
+	if len(photoUrls) > MAX_RETURN_PHOTOS {
+		return fmt.Errorf("%w: at most %d photos", ErrInvalidReturnRequest, MAX_RETURN_PHOTOS)
+	}
+	for _, photoUrl := range photoUrls {
+		u, err := url.ParseRequestURI(photoUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: invalid photo url %q", ErrInvalidReturnRequest, photoUrl)
+		}
+	}
+	return nil
+}
+
+// getReturnDetails 转换订单的退货记录
+func (o *OrderServiceImpl) getReturnDetails(ctx context.Context, orderNo string) (details []*types.ReturnDetail, err error) {
+	returns, err := o.returnDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, err
+	}
+	returnItems, err := o.returnDao.GetItemsByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByReturnNo := make(map[string][]*types.ReturnItemDetail)
+	for _, item := range returnItems {
+		itemsByReturnNo[item.ReturnNo] = append(itemsByReturnNo[item.ReturnNo], &types.ReturnItemDetail{
+			OrderProductID: item.OrderProductID,
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+		})
+	}
+	details = make([]*types.ReturnDetail, 0, len(returns))
+	for _, ret := range returns {
+		var photoUrls []string
+		if ret.PhotoUrls != "" {
+			if err = utils.JSONDecode(ret.PhotoUrls, &photoUrls); err != nil {
+				log.Logger.Errorf("getReturnDetails: decode photo urls failed, returnNo: %s, err: %s", ret.ReturnNo, err.Error())
+			}
+		}
+		details = append(details, &types.ReturnDetail{
+			ReturnNo:         ret.ReturnNo,
+			Status:           ret.Status,
+			StatusName:       consts.GetReturnStatusName(ret.Status),
+			Reason:           ret.Reason,
+			PhotoUrls:        photoUrls,
+			RefundAmount:     ret.RefundAmount,
+			ReturnLabel:      ret.ReturnLabel,
+			ReturnTrackingNo: ret.ReturnTrackingNo,
+			RejectReason:     ret.RejectReason,
+			RefundNo:         ret.RefundNo,
+			Restock:          ret.Restock,
+			ReviewTime:       ret.ReviewTime,
+			ReceiveTime:      ret.ReceiveTime,
+			CreateTime:       ret.CreateTime,
+			Items:            itemsByReturnNo[ret.ReturnNo],
+		})
+	}
+	return details, nil
+}
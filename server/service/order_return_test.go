@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+func newReturnTestService(ctrl *gomock.Controller) (*OrderServiceImpl, refundTestMocks, *daoMocks.MockReturnDao) {
+	service, m := newRefundTestService(ctrl)
+	mockReturnDao := daoMocks.NewMockReturnDao(ctrl)
+	mockReturnDao.EXPECT().WithTx(gomock.Any()).Return(mockReturnDao).AnyTimes()
+	service.returnDao = mockReturnDao
+	return service, m, mockReturnDao
+}
+
+// TestOrderServiceImpl_RequestReturn_Success tests a return is opened on a delivered order
+func TestOrderServiceImpl_RequestReturn_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.DELIVERED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	mockReturnDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ret *model.ReturnRequest, items []model.ReturnItem) (string, error) {
+			if ret.Status != consts.RMA_REQUESTED || ret.PrevStatus != consts.DELIVERED || ret.RefundAmount != 545 {
+				t.Errorf("Unexpected return: %+v", ret)
+			}
+			if ret.PhotoUrls != `["https://img.example.com/1.jpg"]` {
+				t.Errorf("Unexpected photo urls: %s", ret.PhotoUrls)
+			}
+			if len(items) != 1 || items[0].OrderProductID != 12 || items[0].ReturnNo != ret.ReturnNo {
+				t.Errorf("Unexpected return items: %+v", items)
+			}
+			return ret.ReturnNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.DELIVERED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	returnNo, err := service.RequestReturn(ctx, "ORDER001", 123, types.CreateReturnRequest{
+		Reason:    "arrived broken",
+		PhotoUrls: []string{"https://img.example.com/1.jpg"},
+		Items:     []*types.RefundItemRequest{{OrderProductID: 12, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(returnNo, "Rt-") {
+		t.Errorf("Unexpected returnNo %s", returnNo)
+	}
+	if order.Status != consts.RETURN_REQUESTED {
+		t.Errorf("Expected order status %d, got %d", consts.RETURN_REQUESTED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_RequestReturn_InvalidPhoto tests photo urls must be http(s) links
+func TestOrderServiceImpl_RequestReturn_InvalidPhoto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, _ := newReturnTestService(ctrl)
+	m.orderDao.EXPECT().GetByOrderNo(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.RequestReturn(context.Background(), "ORDER001", 123, types.CreateReturnRequest{
+		PhotoUrls: []string{"javascript:alert(1)"},
+	})
+	if !errors.Is(err, ErrInvalidReturnRequest) {
+		t.Errorf("Expected ErrInvalidReturnRequest, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_RequestReturn_NotDelivered tests returns can only be opened after delivery
+func TestOrderServiceImpl_RequestReturn_NotDelivered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.SHIPPED)
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	mockReturnDao.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := service.RequestReturn(ctx, "ORDER001", 123, types.CreateReturnRequest{Reason: "arrived broken"})
+	if !errors.Is(err, consts.ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_ApproveReturn tests approving a return saves the label and tracking number
+func TestOrderServiceImpl_ApproveReturn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.RETURN_REQUESTED)
+	ret := &model.ReturnRequest{ReturnNo: "Rt-1", OrderNo: "ORDER001", Status: consts.RMA_REQUESTED}
+
+	mockReturnDao.EXPECT().GetByReturnNo(ctx, "Rt-1").Return(ret, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.RETURN_REQUESTED, gomock.Any()).Return(1, nil)
+	mockReturnDao.EXPECT().UpdateStatus(ctx, "Rt-1", consts.RMA_REQUESTED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["status"] != consts.RMA_APPROVED || updates["return_tracking_no"] != "SF100" || updates["return_label"] != "https://label" {
+				t.Errorf("Unexpected return updates: %v", updates)
+			}
+			return 1, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, msg string) error {
+			if !strings.Contains(msg, "SF100") {
+				t.Errorf("Expected tracking no in status log remark, got: %s", msg)
+			}
+			return nil
+		})
+
+	err := service.ApproveReturn(ctx, "Rt-1", types.ApproveReturnRequest{ReturnLabel: "https://label", TrackingNo: "SF100"})
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.RETURNING {
+		t.Errorf("Expected order status %d, got %d", consts.RETURNING, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_RejectReturn tests a rejected return sends the order back to delivered
+func TestOrderServiceImpl_RejectReturn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.RETURN_REQUESTED)
+
+	mockReturnDao.EXPECT().GetByReturnNo(ctx, "Rt-1").Return(&model.ReturnRequest{ReturnNo: "Rt-1", OrderNo: "ORDER001", Status: consts.RMA_REQUESTED}, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	mockReturnDao.EXPECT().UpdateStatus(ctx, "Rt-1", consts.RMA_REQUESTED, gomock.Any()).Return(1, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.RETURN_REQUESTED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	if err := service.RejectReturn(ctx, "Rt-1", "no damage visible"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.DELIVERED {
+		t.Errorf("Expected order status %d, got %d", consts.DELIVERED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_RejectReturn_PartiallyRefunded tests a rejected return of a partially refunded order sends it back to partially refunded
+func TestOrderServiceImpl_RejectReturn_PartiallyRefunded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.RETURN_REQUESTED)
+
+	mockReturnDao.EXPECT().GetByReturnNo(ctx, "Rt-1").Return(&model.ReturnRequest{
+		ReturnNo: "Rt-1", OrderNo: "ORDER001", Status: consts.RMA_REQUESTED, PrevStatus: consts.PARTIALLY_REFUNDED,
+	}, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	mockReturnDao.EXPECT().UpdateStatus(ctx, "Rt-1", consts.RMA_REQUESTED, gomock.Any()).Return(1, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.RETURN_REQUESTED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	if err := service.RejectReturn(ctx, "Rt-1", "no damage visible"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.PARTIALLY_REFUNDED {
+		t.Errorf("Expected order status %d, got %d", consts.PARTIALLY_REFUNDED, order.Status)
+	}
+}
+
+// TestOrderServiceImpl_ReceiveReturn_Restock tests receiving the goods refunds the order and restocks the items
+func TestOrderServiceImpl_ReceiveReturn_Restock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	service.productServiceClient = mockProductClient
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.RETURNING)
+	ret := &model.ReturnRequest{ReturnNo: "Rt-1", OrderNo: "ORDER001", UserID: 123, Status: consts.RMA_APPROVED, RefundAmount: 545, Reason: "arrived broken"}
+
+	mockReturnDao.EXPECT().GetByReturnNo(ctx, "Rt-1").Return(ret, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	mockReturnDao.EXPECT().GetItemsByReturnNo(ctx, "Rt-1").Return([]*model.ReturnItem{
+		{ReturnNo: "Rt-1", OrderProductID: 12, ProductID: 2, Quantity: 1},
+	}, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil).Times(2)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	mockReturnDao.EXPECT().UpdateStatus(ctx, "Rt-1", consts.RMA_APPROVED, gomock.Any()).Return(1, nil)
+	m.refundDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+			// 记录 PrevStatus 之前创建的退货单按已收货处理
+			if refund.Status != consts.REFUND_APPROVED || refund.Amount != 545 || refund.PrevStatus != consts.DELIVERED {
+				t.Errorf("Unexpected refund: %+v", refund)
+			}
+			if len(items) != 1 || items[0].RefundNo != refund.RefundNo {
+				t.Errorf("Unexpected refund items: %+v", items)
+			}
+			return refund.RefundNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.RETURNING, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.expectNoInvoice(ctx, "ORDER001")
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", "ORDER001", gomock.Any()).Return(nil)
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 2, Deta: 1}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(1)
+
+	if err := service.ReceiveReturn(ctx, "Rt-1", true); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if order.Status != consts.PARTIALLY_REFUNDED {
+		t.Errorf("Expected order status %d, got %d", consts.PARTIALLY_REFUNDED, order.Status)
+	}
+	if ret.Status != consts.RMA_RECEIVED {
+		t.Errorf("Expected return status %d, got %d", consts.RMA_RECEIVED, ret.Status)
+	}
+}
+
+// TestOrderServiceImpl_ReceiveReturn_NotApproved tests goods cannot be received before the return is approved
+func TestOrderServiceImpl_ReceiveReturn_NotApproved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m, mockReturnDao := newReturnTestService(ctrl)
+	ctx := context.Background()
+
+	mockReturnDao.EXPECT().GetByReturnNo(ctx, "Rt-1").Return(&model.ReturnRequest{ReturnNo: "Rt-1", Status: consts.RMA_REQUESTED}, nil)
+	m.messageWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.ReceiveReturn(ctx, "Rt-1", false); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
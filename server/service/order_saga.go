@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+	"gorm.io/gorm"
+)
+
+const (
+	SAGA_RECOVERY_LOCK_KEY = "order:saga_recovery:lock"
+	SAGA_RECOVER_AFTER     = 2 * time.Minute
+	SAGA_RECOVER_BATCH     = 100
+)
+
+// orderSaga 下单 saga 的运行时状态，record 为持久化的步骤状态
+type orderSaga struct {
+	record   *model.OrderSaga
+	items    []types.SagaItem
+	reserved []types.SagaItem
+	order    *model.Order
+	products []model.OrderProduct
+	orderMsg string
+	payment  *paymentpb.PayOrderInfo // 扣款得到的支付单，恢复执行时可能为空
+	discount *couponDiscount         // 使用的优惠券，随订单一起保存
+	tenders  []model.OrderTender     // 使用的礼品卡和店铺余额，随订单一起冻结
+}
+
+// sagaStep 下单 saga 的一个步骤，action 成功后 saga 推进到 done
+type sagaStep struct {
+	name       string
+	done       int
+	action     func(o *OrderServiceImpl, ctx context.Context, saga *orderSaga) error
+	compensate func(o *OrderServiceImpl, ctx context.Context, saga *orderSaga) error
+	// partial 表示 action 失败时可能已产生部分副作用，需要对该步骤本身也执行补偿
+	partial bool
+}
+
+// orderSagaSteps reserve stock --> persist order --> charge payment --> confirm
+var orderSagaSteps = []sagaStep{
+	{
+		name:       "reserve stock",
+		done:       consts.SAGA_STEP_STOCK_RESERVED,
+		action:     (*OrderServiceImpl).reserveStock,
+		compensate: (*OrderServiceImpl).releaseStock,
+		partial:    true,
+	},
+	{
+		name:       "persist order",
+		done:       consts.SAGA_STEP_ORDER_PERSISTED,
+		action:     (*OrderServiceImpl).persistOrder,
+		compensate: (*OrderServiceImpl).cancelSagaOrder,
+		partial:    true,
+	},
+	{
+		name:       "charge payment",
+		done:       consts.SAGA_STEP_PAYMENT_CHARGED,
+		action:     (*OrderServiceImpl).chargePayment,
+		compensate: (*OrderServiceImpl).refundSagaPayment,
+	},
+	{
+		name:   "confirm order",
+		done:   consts.SAGA_STEP_CONFIRMED,
+		action: (*OrderServiceImpl).confirmSagaOrder,
+	},
+}
+
+// startOrderSaga 持久化 saga 记录，之后的每一步都会更新该记录
+func (o *OrderServiceImpl) startOrderSaga(ctx context.Context, order *model.Order, products []model.OrderProduct, orderMsg string, discount *couponDiscount, tenders []model.OrderTender) (*orderSaga, error) {
+	items := make([]types.SagaItem, len(products))
+	for idx, product := range products {
+		items[idx] = types.SagaItem{ProductID: product.ProductID, Quantity: product.Quantity}
+	}
+	itemsJson, err := utils.JSONEncode(items)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.OrderSaga{
+		OrderNo: order.OrderNo,
+		UserID:  order.UserID,
+		Amount:  order.TotalAmount - order.TenderAmount,
+		Step:    consts.SAGA_STEP_STARTED,
+		Status:  consts.SAGA_RUNNING,
+		Items:   itemsJson,
+	}
+	if _, err = o.orderSagaDao.Create(ctx, record); err != nil {
+		log.Logger.Errorf("startOrderSaga: create saga failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return nil, err
+	}
+	return &orderSaga{
+		record:   record,
+		items:    items,
+		order:    order,
+		products: products,
+		orderMsg: orderMsg,
+		discount: discount,
+		tenders:  tenders,
+	}, nil
+}
+
+// runOrderSaga 从 saga 当前步骤开始向前执行，任意一步失败则补偿已完成的步骤
+func (o *OrderServiceImpl) runOrderSaga(ctx context.Context, saga *orderSaga) error {
+	for _, step := range orderSagaSteps {
+		if saga.record.Step >= step.done {
+			continue
+		}
+		if err := step.action(o, ctx, saga); err != nil {
+			log.Logger.Errorf("runOrderSaga: %s failed, orderNo: %s, err: %s", step.name, saga.record.OrderNo, err.Error())
+			saga.record.LastError = fmt.Sprintf("%s: %s", step.name, err.Error())
+			o.compensateOrderSaga(ctx, saga)
+			return err
+		}
+		saga.record.Step = step.done
+		o.saveOrderSaga(ctx, saga)
+	}
+	saga.record.Status = consts.SAGA_COMPLETED
+	o.saveOrderSaga(ctx, saga)
+	return nil
+}
+
+// compensateOrderSaga 按相反顺序补偿已完成的步骤，补偿失败时保留补偿中状态等待恢复任务重试
+func (o *OrderServiceImpl) compensateOrderSaga(ctx context.Context, saga *orderSaga) {
+	saga.record.Status = consts.SAGA_COMPENSATING
+	o.saveOrderSaga(ctx, saga)
+
+	for idx := len(orderSagaSteps) - 1; idx >= 0; idx-- {
+		step := orderSagaSteps[idx]
+		reached := saga.record.Step >= step.done
+		failedHere := step.partial && saga.record.Step == step.done-1
+		if step.compensate == nil || (!reached && !failedHere) {
+			continue
+		}
+		if err := step.compensate(o, ctx, saga); err != nil {
+			log.Logger.Errorf("compensateOrderSaga: compensate %s failed, orderNo: %s, err: %s", step.name, saga.record.OrderNo, err.Error())
+			saga.record.LastError = fmt.Sprintf("compensate %s: %s", step.name, err.Error())
+			o.saveOrderSaga(ctx, saga)
+			return
+		}
+		if reached {
+			saga.record.Step = step.done - 1
+			o.saveOrderSaga(ctx, saga)
+		}
+	}
+
+	saga.record.Status = consts.SAGA_COMPENSATED
+	o.saveOrderSaga(ctx, saga)
+}
+
+func (o *OrderServiceImpl) saveOrderSaga(ctx context.Context, saga *orderSaga) {
+	reservedJson, err := utils.JSONEncode(saga.reserved)
+	if err != nil {
+		log.Logger.Errorf("saveOrderSaga: json encode failed, err %s", err.Error())
+		return
+	}
+	saga.record.ReservedItems = reservedJson
+	if err = o.orderSagaDao.UpdateProgress(ctx, saga.record); err != nil {
+		log.Logger.Errorf("saveOrderSaga: update saga failed, orderNo: %s, err: %s", saga.record.OrderNo, err.Error())
+	}
+}
+
+// reserveStock 逐个扣减库存，每扣减成功一个商品就持久化，保证补偿时只回补已扣减的部分
+func (o *OrderServiceImpl) reserveStock(ctx context.Context, saga *orderSaga) error {
+	for _, item := range saga.items {
+		resp, err := o.productServiceClient.UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{
+			Id:   int64(item.ProductID),
+			Deta: int64(-1 * item.Quantity),
+		})
+		if err != nil {
+			return err
+		}
+		if resp.GetBase().GetCode() != 0 {
+			return fmt.Errorf("update stock failed, product id: %d, msg: %s", item.ProductID, resp.GetBase().GetMsg())
+		}
+		saga.reserved = append(saga.reserved, item)
+		o.saveOrderSaga(ctx, saga)
+	}
+	return nil
+}
+
+// releaseStock 回补已扣减的库存
+func (o *OrderServiceImpl) releaseStock(ctx context.Context, saga *orderSaga) error {
+	for len(saga.reserved) > 0 {
+		item := saga.reserved[len(saga.reserved)-1]
+		resp, err := o.productServiceClient.UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{
+			Id:   int64(item.ProductID),
+			Deta: int64(item.Quantity),
+		})
+		if err != nil {
+			return err
+		}
+		if resp.GetBase().GetCode() != 0 {
+			return fmt.Errorf("release stock failed, product id: %d, msg: %s", item.ProductID, resp.GetBase().GetMsg())
+		}
+		saga.reserved = saga.reserved[:len(saga.reserved)-1]
+		o.saveOrderSaga(ctx, saga)
+	}
+	return nil
+}
+
+// persistOrder 在同一事务中保存订单、订单商品、优惠券使用、储值卡冻结及 order_created 和 order_status_changed 消息
+func (o *OrderServiceImpl) persistOrder(ctx context.Context, saga *orderSaga) error {
+	orderNo := saga.order.OrderNo
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		if _, err := txo.orderDao.Create(ctx, saga.order); err != nil {
+			return err
+		}
+		if _, err := txo.orderProductDao.CreateBatch(ctx, saga.products); err != nil {
+			return err
+		}
+		if saga.discount != nil {
+			if err := txo.redeemCoupon(ctx, saga.order, saga.discount); err != nil {
+				return err
+			}
+		}
+		if err := txo.holdTenders(ctx, saga.order, saga.tenders); err != nil {
+			return err
+		}
+		if err := txo.messageWriter.SendMsg(ctx, "order_created", orderNo, saga.orderMsg); err != nil {
+			return err
+		}
+
+		oscMsg, err := getOrderStatusChangedMsg(orderNo, saga.order.UserID, consts.GetOrderStatusName(consts.CREATED), consts.CREATED)
+		if err != nil {
+			return err
+		}
+		return txo.messageWriter.SendMsg(ctx, "order_status_changed", orderNo, oscMsg)
+	})
+}
+
+// cancelSagaOrder 取消 saga 创建的订单，退回优惠券并解冻储值卡；库存由 releaseStock 回补，因此不执行其他取消的副作用。
+// saga 没有拿到但 payment_result 通知记录下的扣款在这里退还；已付款的订单退还付款金额和储值卡，
+// 已进入后续履约状态的订单不能取消，保留补偿中状态等待人工处理
+func (o *OrderServiceImpl) cancelSagaOrder(ctx context.Context, saga *orderSaga) error {
+	order, err := o.orderDao.GetByOrderNo(ctx, saga.record.OrderNo)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if order.Status == consts.CANCELED {
+		return nil
+	}
+	if order.Status != consts.CREATED && order.Status != consts.PAYED {
+		return fmt.Errorf("order %s is already %s, can not be canceled", order.OrderNo, consts.GetOrderStatusName(order.Status))
+	}
+
+	in := consts.TransitionInput{Actor: consts.ActorSystem, Reason: saga.record.LastError}
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		oldStatus, err := txo.transitOrderStatus(ctx, order, consts.CANCELED, in)
+		if err != nil {
+			return err
+		}
+		if err = txo.releaseOrderCoupon(ctx, order); err != nil {
+			return err
+		}
+		// 订单保存成功后才会发起扣款
+		charging := saga.record.Amount > 0 && saga.record.Step >= consts.SAGA_STEP_ORDER_PERSISTED
+		if err = txo.refundSagaOrder(ctx, order, oldStatus, charging, in.Reason); err != nil {
+			return err
+		}
+		if err = txo.sendStatusChangedMsg(ctx, order, oldStatus, in); err != nil {
+			return err
+		}
+		return txo.messageWriter.SendMsg(ctx, "order_canceled", order.OrderNo, saga.orderMsg)
+	})
+}
+
+// refundSagaOrder 退还取消的 saga 订单的付款：未付款的订单解冻储值卡，发起过扣款时退还交给 saga 处理的扣款；
+// 已付款的订单储值卡已扣减，退还全部付款金额，储值卡部分加回余额
+func (o *OrderServiceImpl) refundSagaOrder(ctx context.Context, order *model.Order, oldStatus int, charging bool, reason string) error {
+	if oldStatus == consts.PAYED {
+		return o.requestRefund(ctx, order, "", paidAmount(order), reason)
+	}
+	if err := o.releaseOrderTenders(ctx, order); err != nil || !charging {
+		return err
+	}
+	payment, err := o.claimSagaPaymentRefund(ctx, order.OrderNo)
+	if err != nil || payment == nil {
+		return err
+	}
+	return o.requestRefund(ctx, order, "", payment.Amount, reason)
+}
+
+// chargePayment 调用支付服务扣款；rpc 出错时结果未知，先查询支付单确认是否已扣款
+// 订单金额已被储值卡全额抵扣时不需要扣款
+func (o *OrderServiceImpl) chargePayment(ctx context.Context, saga *orderSaga) error {
+	if saga.record.Amount == 0 {
+		return nil
+	}
+	payResp, err := o.paymentServiceClient.PayOrder(ctx, &paymentpb.PayOrderRequest{
+		UserId: int32(saga.record.UserID),
+		Amount: int32(saga.record.Amount),
+		BizId:  saga.record.OrderNo,
+	})
+	if err != nil {
+		if saga.payment = o.paymentCaptured(ctx, saga.record); saga.payment != nil {
+			o.recordSagaPayment(ctx, saga)
+			return nil
+		}
+		return err
+	}
+	if payResp.Code != 0 {
+		return errors.New(payResp.GetErrorMsg())
+	}
+	saga.payment = payResp.GetPayOrderInfo()
+	if saga.payment == nil {
+		saga.payment = &paymentpb.PayOrderInfo{Amount: int32(saga.record.Amount)}
+	}
+	o.recordSagaPayment(ctx, saga)
+	return nil
+}
+
+// recordSagaPayment 记录 saga 已拿到的扣款结果，之后到达的 payment_result 通知不再重复处理
+func (o *OrderServiceImpl) recordSagaPayment(ctx context.Context, saga *orderSaga) {
+	record := saga.record
+	_, err := o.paymentResultDao.Create(ctx, &model.PaymentResult{
+		OrderNo:    record.OrderNo,
+		Result:     consts.PAYMENT_SUCCESS,
+		PayOrderID: saga.payment.GetPayOrderId(),
+		UserID:     record.UserID,
+		Amount:     sagaPaidAmount(saga),
+		Action:     consts.PAYMENT_ACTION_SAGA,
+	})
+	if err != nil {
+		log.Logger.Errorf("recordSagaPayment: create payment result failed, orderNo: %s, err: %s", record.OrderNo, err.Error())
+	}
+}
+
+// sagaPaidAmount 支付服务实际扣款的金额，拿不到支付单时按 saga 发起扣款的金额计算
+func sagaPaidAmount(saga *orderSaga) int {
+	if saga.payment != nil && saga.payment.Amount > 0 {
+		return int(saga.payment.Amount)
+	}
+	return saga.record.Amount
+}
+
+// paymentCaptured 查询支付服务中该订单的支付单，未扣款时返回 nil
+func (o *OrderServiceImpl) paymentCaptured(ctx context.Context, record *model.OrderSaga) *paymentpb.PayOrderInfo {
+	bizId := record.OrderNo
+	querySize := int32(1)
+	resp, err := o.paymentServiceClient.QueryPayOrder(ctx, &paymentpb.PayOrderQueryRequest{
+		UserId:    int32(record.UserID),
+		BizId:     &bizId,
+		QuerySize: &querySize,
+	})
+	if err != nil {
+		log.Logger.Errorf("paymentCaptured: query pay order failed, orderNo: %s, err: %s", bizId, err.Error())
+		return nil
+	}
+	if resp.Code != 0 || len(resp.PayOrderInfos) == 0 {
+		return nil
+	}
+	return resp.PayOrderInfos[0]
+}
+
+// refundSagaPayment 退还已扣款的金额；储值卡此时仍为冻结状态，由 cancelSagaOrder 解冻。
+// 同时将记录的扣款结果标记为已退款，cancelSagaOrder 不会再次退还
+func (o *OrderServiceImpl) refundSagaPayment(ctx context.Context, saga *orderSaga) error {
+	if saga.record.Amount == 0 {
+		return nil
+	}
+	order := saga.order
+	if order == nil {
+		order = &model.Order{OrderNo: saga.record.OrderNo, UserID: saga.record.UserID}
+	}
+	return o.transaction(func(txo *OrderServiceImpl) error {
+		if _, err := txo.claimSagaPaymentRefund(ctx, order.OrderNo); err != nil {
+			return err
+		}
+		return txo.requestRefund(ctx, order, "", saga.record.Amount, saga.record.LastError)
+	})
+}
+
+// confirmSagaOrder 扣款成功后将订单置为已付款
+func (o *OrderServiceImpl) confirmSagaOrder(ctx context.Context, saga *orderSaga) error {
+	if saga.order == nil {
+		return fmt.Errorf("order not found, orderNo: %s", saga.record.OrderNo)
+	}
+	if saga.payment == nil && saga.record.Amount > 0 {
+		// 从扣款之后的步骤恢复时没有支付单，重新查询
+		saga.payment = o.paymentCaptured(ctx, saga.record)
+	}
+	err := o.changeOrderStatus(ctx, saga.order, consts.PAYED, consts.TransitionInput{
+		Actor:            consts.ActorSystem,
+		PayAmount:        sagaPaidAmount(saga),
+		PayTransactionID: saga.payment.GetPayOrderId(),
+		PayMethod:        sagaPayMethod(saga),
+	})
+	if err == nil {
+		return nil
+	}
+	// payment_result 通知可能已先一步将订单置为已付款
+	order, getErr := o.orderDao.GetByOrderNo(ctx, saga.record.OrderNo)
+	if getErr == nil && order.Status == consts.PAYED {
+		saga.order = order
+		return nil
+	}
+	return err
+}
+
+// sagaPayMethod 没有使用储值卡时为余额支付，全额抵扣时为礼品卡支付，否则为组合支付
+func sagaPayMethod(saga *orderSaga) string {
+	if saga.order.TenderAmount == 0 {
+		return consts.PAY_METHOD_BALANCE
+	}
+	if saga.record.Amount == 0 {
+		return consts.PAY_METHOD_GIFT_CARD
+	}
+	return consts.PAY_METHOD_SPLIT
+}
+
+// RecoverOrderSagas 恢复因实例宕机而中断的下单 saga：已扣款的继续确认，其余的补偿
+func (o *OrderServiceImpl) RecoverOrderSagas(ctx context.Context) {
+	lock := o.sagaRecoveryLocker
+	if err := lock.Lock(ctx); err != nil {
+		log.Logger.Info("RecoverOrderSagas: failed to acquire lock, skipping this round")
+		return
+	}
+	defer func() {
+		if unlockErr := lock.Unlock(ctx); unlockErr != nil {
+			log.Logger.Errorf("RecoverOrderSagas: failed to release lock, err: %s", unlockErr.Error())
+		}
+	}()
+
+	records, err := o.orderSagaDao.GetUnfinished(ctx, time.Now().Add(-SAGA_RECOVER_AFTER), SAGA_RECOVER_BATCH)
+	if err != nil {
+		log.Logger.Errorf("RecoverOrderSagas: get unfinished sagas failed, err: %s", err.Error())
+		return
+	}
+	for _, record := range records {
+		// 锁可能在处理一批 saga 的过程中过期，逐个认领后再处理，避免其他实例重复退款或回补库存；
+		// 每一步都会刷新更新时间，处理中的 saga 不会被再次认领
+		claimed, err := o.orderSagaDao.Claim(ctx, record.ID, time.Now().Add(-SAGA_RECOVER_AFTER))
+		if err != nil {
+			log.Logger.Errorf("RecoverOrderSagas: claim saga failed, orderNo: %s, err: %s", record.OrderNo, err.Error())
+			continue
+		}
+		if !claimed {
+			log.Logger.Infof("RecoverOrderSagas: saga claimed by another instance, orderNo: %s", record.OrderNo)
+			continue
+		}
+		o.resumeOrderSaga(ctx, record)
+	}
+}
+
+func (o *OrderServiceImpl) resumeOrderSaga(ctx context.Context, record *model.OrderSaga) {
+	saga, err := o.loadOrderSaga(ctx, record)
+	if err != nil {
+		log.Logger.Errorf("resumeOrderSaga: load saga failed, orderNo: %s, err: %s", record.OrderNo, err.Error())
+		return
+	}
+	log.Logger.Infof("resumeOrderSaga: orderNo: %s, step: %d, status: %d", record.OrderNo, record.Step, record.Status)
+
+	if record.Status == consts.SAGA_RUNNING {
+		if record.Step == consts.SAGA_STEP_ORDER_PERSISTED {
+			// 储值卡全额抵扣的订单不需要扣款，可以直接确认
+			if record.Amount == 0 {
+				record.Step = consts.SAGA_STEP_PAYMENT_CHARGED
+			} else if saga.payment = o.paymentCaptured(ctx, record); saga.payment != nil {
+				record.Step = consts.SAGA_STEP_PAYMENT_CHARGED
+			}
+		}
+		if record.Step >= consts.SAGA_STEP_PAYMENT_CHARGED {
+			_ = o.runOrderSaga(ctx, saga)
+			return
+		}
+		record.LastError = "abandoned by crashed instance"
+	}
+	o.compensateOrderSaga(ctx, saga)
+}
+
+// loadOrderSaga 从持久化记录和数据库中的订单重建 saga 运行时状态
+func (o *OrderServiceImpl) loadOrderSaga(ctx context.Context, record *model.OrderSaga) (*orderSaga, error) {
+	saga := &orderSaga{record: record}
+	if err := utils.JSONDecode(record.Items, &saga.items); err != nil {
+		return nil, err
+	}
+	if record.ReservedItems != "" {
+		if err := utils.JSONDecode(record.ReservedItems, &saga.reserved); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := o.orderDao.GetByOrderNo(ctx, record.OrderNo)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return saga, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	saga.order = order
+
+	products, err := o.orderProductDao.GetByOrderNo(ctx, record.OrderNo)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		saga.products = append(saga.products, *product)
+	}
+	saga.orderMsg, err = getPersistedOrderMsg(order, products)
+	if err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
@@ -0,0 +1,569 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+	"gorm.io/gorm"
+)
+
+func twoItemOrderInfo() types.OrderInfo {
+	return types.OrderInfo{
+		ReceiverCountry: "SG",
+		OrderItemList: []*types.OrderItemInfo{
+			{ProductID: 1, Quantity: 1, Price: 1000},
+			{ProductID: 2, Quantity: 3, Price: 500},
+		},
+	}
+}
+
+func twoProductListResponse() *productpb.GetProductListResponse {
+	return &productpb.GetProductListResponse{
+		Products: []*productpb.Product{
+			{Id: 1, Name: "Bowl", Price: 1000, Stock: 10},
+			{Id: 2, Name: "Cup", Price: 500, Stock: 10},
+		},
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_SagaReserveStockFailed tests only the already reserved stock is released
+func TestOrderServiceImpl_CreateOrder_SagaReserveStockFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+
+	ctx := context.TODO()
+
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	gomock.InOrder(
+		mockProductClient.EXPECT().
+			UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: -1}).
+			Return(&productpb.UpdateStockWithCASResponse{}, nil),
+		mockProductClient.EXPECT().
+			UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 2, Deta: -3}).
+			Return(&productpb.UpdateStockWithCASResponse{
+				Base: &productpb.BaseResponse{Code: int32(productpb.ResponseCode_INSUFFICIENT_STOCK), Msg: "insufficient stock"},
+			}, nil),
+		mockProductClient.EXPECT().
+			UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 1}).
+			Return(&productpb.UpdateStockWithCASResponse{}, nil),
+	)
+	// the order was never persisted
+	mockOrderDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderProductDao:      mockOrderProductDao,
+		messageWriter:        mockMessageWriter,
+		orderDao:             mockOrderDao,
+		orderSagaDao:         mockOrderSagaDao,
+		productServiceClient: mockProductClient,
+	}
+
+	orderNo, err := service.CreateOrder(ctx, twoItemOrderInfo(), 123)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if orderNo != "" {
+		t.Errorf("Expected empty orderNo, got: %s", orderNo)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_SagaConfirmFailed tests a charged order is refunded, canceled and its stock released
+func TestOrderServiceImpl_CreateOrder_SagaConfirmFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	ctx := context.TODO()
+	mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(4)
+	mockOrderDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+	mockOrderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_created", gomock.Any(), gomock.Any()).Return(nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockPaymentClient.EXPECT().PayOrder(ctx, gomock.Any()).Return(&paymentpb.PayOrderResponse{Code: 0}, nil)
+
+	// confirm fails
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).Return(0, errors.New("database error"))
+
+	// compensation: refund --> cancel --> release; the order is read once more to check whether it was paid by a payment_result notification
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_refund", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	// the refund marks the recorded charge, so canceling the order does not refund it again
+	gomock.InOrder(
+		mockPaymentResultDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).
+			Return([]*model.PaymentResult{{Result: consts.PAYMENT_SUCCESS, Action: consts.PAYMENT_ACTION_SAGA, Amount: 3175}}, nil),
+		mockPaymentResultDao.EXPECT().UpdateAction(ctx, gomock.Any(), consts.PAYMENT_SUCCESS, consts.PAYMENT_ACTION_SAGA, consts.PAYMENT_ACTION_REFUNDED).Return(1, nil),
+		mockPaymentResultDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).
+			Return([]*model.PaymentResult{{Result: consts.PAYMENT_SUCCESS, Action: consts.PAYMENT_ACTION_REFUNDED, Amount: 3175}}, nil),
+	)
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(&model.Order{UserID: 123, Status: consts.CREATED}, nil).Times(2)
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).Return(1, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_canceled", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
+		productServiceClient: mockProductClient,
+		paymentServiceClient: mockPaymentClient,
+		messageWriter:        mockKafkaWriter,
+	}
+
+	_, err := service.CreateOrder(ctx, twoItemOrderInfo(), 123)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_SagaPaymentTimeoutButCaptured tests an ambiguous payment error is resolved by querying the payment
+func TestOrderServiceImpl_CreateOrder_SagaPaymentTimeoutButCaptured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	ctx := context.TODO()
+	mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	mockOrderDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+	mockOrderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockPaymentClient.EXPECT().PayOrder(ctx, gomock.Any()).Return(nil, errors.New("deadline exceeded"))
+	mockPaymentClient.EXPECT().QueryPayOrder(ctx, gomock.Any()).Return(&paymentpb.PayOrderQueryResponse{
+		Code:          0,
+		PayOrderInfos: []*paymentpb.PayOrderInfo{{PayOrderId: "PAY001", Amount: 3175}},
+	}, nil)
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			// 查询到的支付单信息写入订单
+			if updates["pay_amount"] != 3175 || updates["pay_transaction_id"] != "PAY001" {
+				t.Errorf("Unexpected payment fields: %v", updates)
+			}
+			return 1, nil
+		})
+
+	// 付款后开具发票
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
+		productServiceClient: mockProductClient,
+		paymentServiceClient: mockPaymentClient,
+		messageWriter:        mockKafkaWriter,
+		invoiceDao:           newIssuingInvoiceDao(ctrl),
+	}
+
+	orderNo, err := service.CreateOrder(ctx, twoItemOrderInfo(), 123)
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if orderNo == "" {
+		t.Errorf("Expected orderNo to be not empty")
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_SagaCancelDuringCharge tests a cancel arriving on another instance while the payment is charged is rejected,
+// so the stock is only restored by the saga
+func TestOrderServiceImpl_CreateOrder_SagaCancelDuringCharge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	ctx := context.TODO()
+	newService := func(invoiceDao dao.InvoiceDao) *OrderServiceImpl {
+		return &OrderServiceImpl{
+			txBeginner:           testTxBeginner{},
+			orderDao:             mockOrderDao,
+			orderProductDao:      mockOrderProductDao,
+			orderSagaDao:         mockOrderSagaDao,
+			paymentResultDao:     mockPaymentResultDao,
+			productServiceClient: mockProductClient,
+			paymentServiceClient: mockPaymentClient,
+			messageWriter:        mockKafkaWriter,
+			invoiceDao:           invoiceDao,
+		}
+	}
+	// another instance, not sharing the in-process lock
+	otherService := newService(nil)
+
+	var persisted *model.Order
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+	// only the reservation, the cancel must not restore the stock
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	mockOrderDao.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+		persisted = order
+		return order.OrderNo, nil
+	})
+	mockOrderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockPaymentClient.EXPECT().PayOrder(ctx, gomock.Any()).DoAndReturn(
+		func(context.Context, *paymentpb.PayOrderRequest, ...interface{}) (*paymentpb.PayOrderResponse, error) {
+			order := *persisted
+			mockOrderDao.EXPECT().GetByOrderNo(ctx, order.OrderNo).Return(&order, nil)
+			mockOrderSagaDao.EXPECT().GetByOrderNo(ctx, order.OrderNo).Return(&model.OrderSaga{OrderNo: order.OrderNo, Status: consts.SAGA_RUNNING}, nil)
+			err := otherService.UpdateOrderStatus(ctx, order.OrderNo, consts.CANCELED, consts.TransitionInput{Actor: consts.ActorCustomer, UserID: 123})
+			if !errors.Is(err, ErrOrderSagaInProgress) {
+				t.Errorf("Expected ErrOrderSagaInProgress, got: %v", err)
+			}
+			return &paymentpb.PayOrderResponse{Code: 0, PayOrderInfo: &paymentpb.PayOrderInfo{PayOrderId: "PAY001", Amount: 3175}}, nil
+		})
+	mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	// the saga confirms the order, it is never canceled
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["status"] != consts.PAYED {
+				t.Errorf("Expected the order to be payed, got updates: %v", updates)
+			}
+			return 1, nil
+		})
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+
+	if _, err := newService(newIssuingInvoiceDao(ctrl)).CreateOrder(ctx, twoItemOrderInfo(), 123); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_SagaCompensatingPaymentSucceeded tests a success notification arriving while the saga compensates a timed out charge
+// leaves the order to the saga, which cancels it and refunds the charge exactly once
+func TestOrderServiceImpl_CreateOrder_SagaCompensatingPaymentSucceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	ctx := context.TODO()
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
+		productServiceClient: mockProductClient,
+		paymentServiceClient: mockPaymentClient,
+		messageWriter:        mockKafkaWriter,
+	}
+	// the consumer on another instance
+	otherService := &OrderServiceImpl{
+		txBeginner:       testTxBeginner{},
+		orderDao:         mockOrderDao,
+		orderProductDao:  mockOrderProductDao,
+		orderSagaDao:     mockOrderSagaDao,
+		paymentResultDao: mockPaymentResultDao,
+		messageWriter:    mockKafkaWriter,
+	}
+
+	var persisted *model.Order
+	var recorded *model.PaymentResult
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	// reserve twice, release twice
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(4)
+	mockOrderDao.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+		copied := *order
+		persisted = &copied
+		return order.OrderNo, nil
+	})
+	mockOrderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_created", gomock.Any(), gomock.Any()).Return(nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	// the charge times out and the payment is not visible yet
+	mockPaymentClient.EXPECT().PayOrder(ctx, gomock.Any()).Return(nil, errors.New("deadline exceeded"))
+	mockPaymentClient.EXPECT().QueryPayOrder(ctx, gomock.Any()).Return(&paymentpb.PayOrderQueryResponse{Code: 0}, nil)
+
+	// the success notification arrives once the saga starts compensating
+	notified := false
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, record *model.OrderSaga) error {
+		if record.Status != consts.SAGA_COMPENSATING || notified {
+			return nil
+		}
+		notified = true
+		order := *persisted
+		mockOrderDao.EXPECT().GetByOrderNo(ctx, order.OrderNo).Return(&order, nil)
+		mockOrderSagaDao.EXPECT().GetByOrderNo(ctx, order.OrderNo).Return(&model.OrderSaga{OrderNo: order.OrderNo, Status: consts.SAGA_COMPENSATING}, nil)
+		mockOrderDao.EXPECT().GetByOrderNoForUpdate(ctx, order.OrderNo).Return(&order, nil)
+		mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, result *model.PaymentResult) (bool, error) {
+			recorded = result
+			return true, nil
+		})
+		err := otherService.HandlePaymentResult(ctx, &types.PaymentResultMessage{
+			PayOrderID: "PAY001", BizID: order.OrderNo, UserID: 123, Amount: 3175, Result: consts.PAYMENT_SUCCESS,
+		})
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+		if recorded == nil || recorded.Action != consts.PAYMENT_ACTION_SAGA {
+			t.Errorf("Expected the result to be left to the saga, got: %+v", recorded)
+		}
+		return nil
+	}).AnyTimes()
+
+	// compensation cancels the order, never pays it, and refunds the recorded charge
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, _ string) (*model.Order, error) {
+		return persisted, nil
+	})
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["status"] != consts.CANCELED {
+				t.Errorf("Expected the order to be canceled, got updates: %v", updates)
+			}
+			return 1, nil
+		})
+	mockPaymentResultDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).DoAndReturn(func(context.Context, string) ([]*model.PaymentResult, error) {
+		return []*model.PaymentResult{recorded}, nil
+	})
+	mockPaymentResultDao.EXPECT().UpdateAction(ctx, gomock.Any(), consts.PAYMENT_SUCCESS, consts.PAYMENT_ACTION_SAGA, consts.PAYMENT_ACTION_REFUNDED).Return(1, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_refund", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, value string) error {
+			if !strings.Contains(value, `"amount":3175`) {
+				t.Errorf("Unexpected refund message: %s", value)
+			}
+			return nil
+		})
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_canceled", gomock.Any(), gomock.Any()).Return(nil)
+
+	if _, err := service.CreateOrder(ctx, twoItemOrderInfo(), 123); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if !notified {
+		t.Error("Expected the notification to arrive during compensation")
+	}
+}
+
+// TestOrderServiceImpl_CancelSagaOrder_Shipped tests an order that has moved past payment is not canceled by compensation
+func TestOrderServiceImpl_CancelSagaOrder_Shipped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	ctx := context.TODO()
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.Order{OrderNo: "ORDER001", Status: consts.SHIPPED}, nil)
+	mockOrderDao.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		txBeginner: testTxBeginner{},
+		orderDao:   mockOrderDao,
+	}
+	saga := &orderSaga{record: &model.OrderSaga{OrderNo: "ORDER001", Step: consts.SAGA_STEP_PAYMENT_CHARGED, Amount: 3175}}
+	if err := service.cancelSagaOrder(ctx, saga); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+// TestOrderServiceImpl_RecoverOrderSagas tests abandoned sagas are compensated, charged sagas are confirmed and sagas claimed by another instance are skipped
+func TestOrderServiceImpl_RecoverOrderSagas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Return(nil).Times(1)
+
+	abandoned := &model.OrderSaga{
+		ID:            1,
+		OrderNo:       "SAGA001",
+		UserID:        101,
+		Step:          consts.SAGA_STEP_STARTED,
+		Status:        consts.SAGA_RUNNING,
+		Items:         `[{"product_id":1,"quantity":1},{"product_id":2,"quantity":3}]`,
+		ReservedItems: `[{"product_id":1,"quantity":1}]`,
+	}
+	charged := &model.OrderSaga{
+		ID:            2,
+		OrderNo:       "SAGA002",
+		UserID:        102,
+		Amount:        1890,
+		Step:          consts.SAGA_STEP_ORDER_PERSISTED,
+		Status:        consts.SAGA_RUNNING,
+		Items:         `[{"product_id":1,"quantity":1}]`,
+		ReservedItems: `[{"product_id":1,"quantity":1}]`,
+	}
+	// taken over by another instance after the lock expired, left alone
+	claimedElsewhere := &model.OrderSaga{
+		ID:            3,
+		OrderNo:       "SAGA003",
+		UserID:        103,
+		Step:          consts.SAGA_STEP_STARTED,
+		Status:        consts.SAGA_COMPENSATING,
+		Items:         `[{"product_id":1,"quantity":1}]`,
+		ReservedItems: `[{"product_id":1,"quantity":1}]`,
+	}
+	mockOrderSagaDao.EXPECT().
+		GetUnfinished(ctx, gomock.Any(), SAGA_RECOVER_BATCH).
+		Return([]*model.OrderSaga{abandoned, charged, claimedElsewhere}, nil)
+	mockOrderSagaDao.EXPECT().Claim(ctx, 1, gomock.Any()).Return(true, nil)
+	mockOrderSagaDao.EXPECT().Claim(ctx, 2, gomock.Any()).Return(true, nil)
+	mockOrderSagaDao.EXPECT().Claim(ctx, 3, gomock.Any()).Return(false, nil)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, "SAGA003").Times(0)
+
+	// abandoned saga: order never persisted, release the reserved product 1
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, "SAGA001").Return(nil, gorm.ErrRecordNotFound).Times(1)
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 1}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
+	// charged saga: payment found, confirm the order
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, "SAGA002").Return(&model.Order{OrderNo: "SAGA002", UserID: 102, Status: consts.CREATED}, nil)
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, "SAGA002").Return([]*model.OrderProduct{{OrderNo: "SAGA002", ProductID: 1, Quantity: 1}}, nil)
+	mockPaymentClient.EXPECT().QueryPayOrder(ctx, gomock.Any()).Return(&paymentpb.PayOrderQueryResponse{
+		PayOrderInfos: []*paymentpb.PayOrderInfo{{PayOrderId: "PAY002", Amount: 1890}},
+	}, nil)
+	mockOrderDao.EXPECT().UpdateStatus(ctx, "SAGA002", consts.CREATED, gomock.Any()).Return(1, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", "SAGA002", gomock.Any()).Return(nil)
+
+	// 付款后开具发票
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		productServiceClient: mockProductClient,
+		paymentServiceClient: mockPaymentClient,
+		messageWriter:        mockKafkaWriter,
+		sagaRecoveryLocker:   mockLocker,
+		invoiceDao:           newIssuingInvoiceDao(ctrl),
+	}
+
+	service.RecoverOrderSagas(ctx)
+
+	if abandoned.Status != consts.SAGA_COMPENSATED || abandoned.Step != consts.SAGA_STEP_STARTED {
+		t.Errorf("Expected abandoned saga to be compensated, got status %d step %d", abandoned.Status, abandoned.Step)
+	}
+	if charged.Status != consts.SAGA_COMPLETED || charged.Step != consts.SAGA_STEP_CONFIRMED {
+		t.Errorf("Expected charged saga to be completed, got status %d step %d", charged.Status, charged.Step)
+	}
+	if claimedElsewhere.Status != consts.SAGA_COMPENSATING {
+		t.Errorf("Expected saga claimed by another instance to be left alone, got status %d", claimedElsewhere.Status)
+	}
+}
+
+// TestOrderServiceImpl_RecoverOrderSagas_LockFailed tests recovery is skipped when another instance holds the lock
+func TestOrderServiceImpl_RecoverOrderSagas_LockFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(errors.New("lock already held")).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Times(0)
+	mockOrderSagaDao.EXPECT().GetUnfinished(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		txBeginner:         testTxBeginner{},
+		orderDao:           mockOrderDao,
+		orderProductDao:    mockOrderProductDao,
+		messageWriter:      mockMessageWriter,
+		orderSagaDao:       mockOrderSagaDao,
+		sagaRecoveryLocker: mockLocker,
+	}
+
+	service.RecoverOrderSagas(ctx)
+}
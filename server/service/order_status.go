@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+// statusEnteredHook 订单进入某状态后需要执行的副作用
+type statusEnteredHook struct {
+	// inTx 与状态变更在同一事务中执行，只能写库和写消息
+	inTx func(o *OrderServiceImpl, ctx context.Context, order *model.Order, oldStatus int, in consts.TransitionInput) error
+	// afterCommit 状态变更提交后执行，用于调用外部服务
+	afterCommit func(o *OrderServiceImpl, ctx context.Context, order *model.Order, oldStatus int, in consts.TransitionInput)
+}
+
+var statusEnteredHooks = map[int]statusEnteredHook{
+	consts.PAYED: {
+		inTx: (*OrderServiceImpl).handleOrderPayed,
+	},
+	consts.CANCELED: {
+		inTx:        (*OrderServiceImpl).handleOrderCanceled,
+		afterCommit: (*OrderServiceImpl).restoreCanceledOrderStock,
+	},
+}
+
+// ErrOrderSagaInProgress 下单 saga 仍在扣款或补偿中，订单暂时不能取消
+var ErrOrderSagaInProgress = errors.New("order is still being placed, try again later")
+
+// UpdateOrderStatus 按状态机校验后变更订单状态
+func (o *OrderServiceImpl) UpdateOrderStatus(ctx context.Context, orderNo string, newStatus int, in consts.TransitionInput) (err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	orderInfo, err := o.orderDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("UpdateOrderStatus: get order failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return err
+	}
+	// 退款、退货相关的状态需要与退款单、退货单一起变更，只能通过退款、退货接口触发
+	if transition, findErr := consts.FindTransition(orderInfo.Status, newStatus); findErr == nil && transition.Internal {
+		log.Logger.Errorf("UpdateOrderStatus: internal transition, orderNo: %s, %d --> %d", orderNo, orderInfo.Status, newStatus)
+		return fmt.Errorf("%w: %s --> %s", consts.ErrInternalTransition, consts.GetOrderStatusName(orderInfo.Status), consts.GetOrderStatusName(newStatus))
+	}
+	// 下单 saga 未结束时由 saga 决定确认还是补偿，否则取消回补的库存会被补偿再回补一次
+	if orderInfo.Status == consts.CREATED && newStatus == consts.CANCELED {
+		inProgress, err := o.orderSagaInProgress(ctx, orderNo)
+		if err != nil {
+			return err
+		}
+		if inProgress {
+			log.Logger.Errorf("UpdateOrderStatus: saga in progress, orderNo: %s", orderNo)
+			return ErrOrderSagaInProgress
+		}
+	}
+	return o.changeOrderStatus(ctx, orderInfo, newStatus, in)
+}
+
+// orderSagaInProgress 订单的下单 saga 是否仍在执行或补偿中，没有 saga 记录的订单返回 false
+func (o *OrderServiceImpl) orderSagaInProgress(ctx context.Context, orderNo string) (bool, error) {
+	saga, err := o.orderSagaDao.GetByOrderNo(ctx, orderNo)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		log.Logger.Errorf("orderSagaInProgress: get saga failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return false, err
+	}
+	return saga.Status == consts.SAGA_RUNNING || saga.Status == consts.SAGA_COMPENSATING, nil
+}
+
+// changeOrderStatus 校验状态机，在同一事务中写入新状态及时间戳、状态日志消息和副作用消息，提交后调用外部服务
+func (o *OrderServiceImpl) changeOrderStatus(ctx context.Context, orderInfo *model.Order, newStatus int, in consts.TransitionInput) error {
+	return o.changeOrderStatusWith(ctx, orderInfo, newStatus, in, nil)
+}
+
+// changeOrderStatusWith 同 changeOrderStatus，extra 不为空时与状态变更在同一事务中执行
+func (o *OrderServiceImpl) changeOrderStatusWith(ctx context.Context, orderInfo *model.Order, newStatus int, in consts.TransitionInput, extra func(txo *OrderServiceImpl) error) error {
+	hook := statusEnteredHooks[newStatus]
+	var oldStatus int
+	err := o.transaction(func(txo *OrderServiceImpl) (err error) {
+		oldStatus, err = txo.transitOrderStatus(ctx, orderInfo, newStatus, in)
+		if err != nil {
+			return err
+		}
+		if hook.inTx != nil {
+			if err = hook.inTx(txo, ctx, orderInfo, oldStatus, in); err != nil {
+				return err
+			}
+		}
+		if extra != nil {
+			if err = extra(txo); err != nil {
+				return err
+			}
+		}
+		return txo.sendStatusChangedMsg(ctx, orderInfo, oldStatus, in)
+	})
+	if err != nil {
+		// 事务回滚，恢复内存中的订单状态
+		orderInfo.Status = oldStatus
+		return err
+	}
+
+	if hook.afterCommit != nil {
+		hook.afterCommit(o, ctx, orderInfo, oldStatus, in)
+	}
+	return nil
+}
+
+// transitOrderStatus 校验状态机并写入新状态及时间戳，不执行副作用
+func (o *OrderServiceImpl) transitOrderStatus(ctx context.Context, orderInfo *model.Order, newStatus int, in consts.TransitionInput) (oldStatus int, err error) {
+	orderNo := orderInfo.OrderNo
+	oldStatus = orderInfo.Status
+	in.OwnerUserID = orderInfo.UserID
+	in.DeliveryTime = orderInfo.DeliveryTime
+	in.ConfirmTime = orderInfo.ConfirmTime
+
+	transition, err := consts.CheckTransition(oldStatus, newStatus, in)
+	if err != nil {
+		log.Logger.Errorf("transitOrderStatus: orderNo: %s, err: %s", orderNo, err.Error())
+		return oldStatus, err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": newStatus}
+	for _, stamp := range transition.Stamps {
+		updates[stamp] = now
+	}
+	if transition.Fields != nil {
+		for field, value := range transition.Fields(in) {
+			updates[field] = value
+		}
+	}
+
+	// 只有状态未被并发修改时才会成功
+	rows, err := o.orderDao.UpdateStatus(ctx, orderNo, oldStatus, updates)
+	if err != nil {
+		log.Logger.Errorf("transitOrderStatus: update status failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return oldStatus, err
+	}
+	if rows == 0 {
+		statusErr := fmt.Errorf("transitOrderStatus: order status changed concurrently, orderNo: %s", orderNo)
+		log.Logger.Errorf(statusErr.Error())
+		return oldStatus, statusErr
+	}
+	orderInfo.Status = newStatus
+	return oldStatus, nil
+}
+
+// sendStatusChangedMsg 写入 order_status_changed 消息，由消费者写入状态日志
+func (o *OrderServiceImpl) sendStatusChangedMsg(ctx context.Context, orderInfo *model.Order, oldStatus int, in consts.TransitionInput) error {
+	statusChangeRemark := fmt.Sprintf("%s --> %s by %s", consts.GetOrderStatusName(oldStatus), consts.GetOrderStatusName(orderInfo.Status), in.Actor)
+	if in.Reason != "" {
+		statusChangeRemark = fmt.Sprintf("%s, reason: %s", statusChangeRemark, in.Reason)
+	}
+	oscMsg, err := getOrderStatusChangedMsg(orderInfo.OrderNo, orderInfo.UserID, statusChangeRemark, orderInfo.Status)
+	if err != nil {
+		log.Logger.Errorf("get order status changed msg failed, err %s", err.Error())
+		return err
+	}
+	err = o.messageWriter.SendMsg(ctx, "order_status_changed", orderInfo.OrderNo, oscMsg)
+	if err != nil {
+		log.Logger.Errorf("send message failed, err %s", err)
+	}
+	return err
+}
+
+// handleOrderPayed 扣除冻结的储值卡余额并开具发票
+func (o *OrderServiceImpl) handleOrderPayed(ctx context.Context, orderInfo *model.Order, oldStatus int, in consts.TransitionInput) error {
+	if err := o.captureOrderTenders(ctx, orderInfo, oldStatus); err != nil {
+		return err
+	}
+	return o.issueOrderInvoice(ctx, orderInfo, oldStatus, in)
+}
+
+// handleOrderCanceled 退回订单使用的优惠券，解冻未付款订单的储值卡并通知订单取消
+func (o *OrderServiceImpl) handleOrderCanceled(ctx context.Context, orderInfo *model.Order, oldStatus int, in consts.TransitionInput) error {
+	if err := o.releaseOrderCoupon(ctx, orderInfo); err != nil {
+		return err
+	}
+	if err := o.releaseOrderTenders(ctx, orderInfo); err != nil {
+		return err
+	}
+	return o.notifyOrderCanceled(ctx, orderInfo, oldStatus, in)
+}
+
+// notifyOrderCanceled 写入 order_canceled 消息；已付款的订单同时发起退款
+func (o *OrderServiceImpl) notifyOrderCanceled(ctx context.Context, orderInfo *model.Order, oldStatus int, in consts.TransitionInput) error {
+	orderProducts, err := o.orderProductDao.GetByOrderNo(ctx, orderInfo.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("notifyOrderCanceled: get order products failed, orderNo: %s, err: %s", orderInfo.OrderNo, err.Error())
+		return err
+	}
+	orderMsg, err := getPersistedOrderMsg(orderInfo, orderProducts)
+	if err != nil {
+		return err
+	}
+	if err = o.messageWriter.SendMsg(ctx, "order_canceled", orderInfo.OrderNo, orderMsg); err != nil {
+		log.Logger.Errorf("notifyOrderCanceled: send message failed, err %s", err)
+		return err
+	}
+
+	if oldStatus != consts.PAYED {
+		return nil
+	}
+	if err = o.issueCreditNote(ctx, orderInfo, "", paidAmount(orderInfo), in.Reason); err != nil {
+		return err
+	}
+	return o.requestRefund(ctx, orderInfo, "", paidAmount(orderInfo), in.Reason)
+}
+
+// restoreCanceledOrderStock 订单取消后回补库存
+func (o *OrderServiceImpl) restoreCanceledOrderStock(ctx context.Context, orderInfo *model.Order, oldStatus int, in consts.TransitionInput) {
+	o.restoreStock(ctx, orderInfo.OrderNo)
+}
+
+// restoreStock 将订单中的商品数量加回库存
+func (o *OrderServiceImpl) restoreStock(ctx context.Context, orderNo string) {
+	orderProducts, err := o.orderProductDao.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		log.Logger.Errorf("restoreStock: get order products failed, orderNo: %s, err: %s", orderNo, err.Error())
+		return
+	}
+	for _, product := range orderProducts {
+		_, err = o.productServiceClient.UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{
+			Id:   int64(product.ProductID),
+			Deta: int64(product.Quantity),
+		})
+		if err != nil {
+			log.Logger.Errorf("restoreStock: update stock failed, orderNo: %s, productId: %d, err: %s", orderNo, product.ProductID, err.Error())
+		}
+	}
+}
+
+// requestRefund 优先退回订单使用的礼品卡和店铺余额，剩余部分通知支付服务退款；
+// 支付服务没有退款 rpc，通过 order_refund 消息异步完成
+// refundNo 为退款单号，取消订单的整单退款没有退款单，传空
+func (o *OrderServiceImpl) requestRefund(ctx context.Context, orderInfo *model.Order, refundNo string, amount int, reason string) error {
+	amount, err := o.restoreOrderTenders(ctx, orderInfo, refundNo, amount)
+	if err != nil || amount == 0 {
+		return err
+	}
+	refundMsg, err := utils.JSONEncode(types.RefundMessage{
+		OrderNo:  orderInfo.OrderNo,
+		RefundNo: refundNo,
+		UserID:   orderInfo.UserID,
+		Amount:   amount,
+		Reason:   reason,
+	})
+	if err != nil {
+		log.Logger.Errorf("requestRefund: json encode failed, err %s", err.Error())
+		return err
+	}
+	err = o.messageWriter.SendMsg(ctx, "order_refund", orderInfo.OrderNo, refundMsg)
+	if err != nil {
+		log.Logger.Errorf("requestRefund: send message failed, err %s", err)
+	}
+	return err
+}
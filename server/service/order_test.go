@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"reflect"
 	"time"
 
 	// "errors"
@@ -14,14 +16,15 @@ import (
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
 
-	// "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
 	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
 	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
 	cacheMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/cache/mocks"
 	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
 	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
 	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	// "github.com/stretchr/testify/assert"
 )
 
@@ -31,19 +34,33 @@ func init() {
 	log.Logger = logger.Sugar()
 }
 
+// testTxBeginner 直接执行事务函数，配合 mock dao 和 writer 的 WithTx 使用
+type testTxBeginner struct{}
+
+func (testTxBeginner) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	return fc(nil)
+}
+
 func TestOrderServiceImpl_CreateOrder_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	// Create all mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
 
 	// Setup test data
 	ctx := context.TODO()
+	mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
 	orderInfo := types.OrderInfo{
 		ReceiverFirstName: "John",
 		ReceiverLastName:  "Doe",
@@ -71,6 +88,8 @@ func TestOrderServiceImpl_CreateOrder_Success(t *testing.T) {
 			Products: []*productpb.Product{
 				{
 					Id:    1,
+					Name:  "Test Product",
+					Price: 1000,
 					Stock: 10, // Sufficient stock
 				},
 			},
@@ -119,8 +138,8 @@ func TestOrderServiceImpl_CreateOrder_Success(t *testing.T) {
 
 	// Mock order status update after payment
 	mockOrderDao.EXPECT().
-		UpdateStatusAndPayment(ctx, gomock.Any(), consts.PAYED, gomock.Any()).
-		Return(nil).
+		UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		Return(1, nil).
 		Times(1)
 
 	mockKafkaWriter.EXPECT().
@@ -128,14 +147,24 @@ func TestOrderServiceImpl_CreateOrder_Success(t *testing.T) {
 		Return(nil).
 		AnyTimes()
 
+	// Mock saga progress
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil).Times(1)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
 	// Create service instance with all mocks
+	// 付款后开具发票
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
+		invoiceDao:           newIssuingInvoiceDao(ctrl),
 	}
 
 	// Now we can actually test the CreateOrder method
@@ -154,10 +183,13 @@ func TestOrderServiceImpl_CreateOrder_GetProductListError(t *testing.T) {
 
 	// Create mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	// Setup test data
 	ctx := context.TODO()
@@ -189,12 +221,12 @@ func TestOrderServiceImpl_CreateOrder_GetProductListError(t *testing.T) {
 
 	// Create service instance with mocks
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
 	}
 
 	// Test the CreateOrder method
@@ -213,10 +245,13 @@ func TestOrderServiceImpl_CreateOrder_InsufficientStock(t *testing.T) {
 
 	// Create mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	// Setup test data with high quantity
 	ctx := context.TODO()
@@ -247,6 +282,8 @@ func TestOrderServiceImpl_CreateOrder_InsufficientStock(t *testing.T) {
 			Products: []*productpb.Product{
 				{
 					Id:    1,
+					Name:  "Test Product",
+					Price: 1000,
 					Stock: 5, // Insufficient stock
 				},
 			},
@@ -255,12 +292,12 @@ func TestOrderServiceImpl_CreateOrder_InsufficientStock(t *testing.T) {
 
 	// Create service instance with mocks
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
 	}
 
 	// Test the CreateOrder method
@@ -273,16 +310,52 @@ func TestOrderServiceImpl_CreateOrder_InsufficientStock(t *testing.T) {
 	}
 }
 
+// TestOrderServiceImpl_CreateOrder_InvalidItems tests empty item lists and non-positive quantities are rejected before calling product service
+func TestOrderServiceImpl_CreateOrder_InvalidItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// product service must not be called
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		productServiceClient: mocks.NewMockProductServiceClient(ctrl),
+	}
+
+	tests := []struct {
+		name  string
+		items []*types.OrderItemInfo
+	}{
+		{name: "empty", items: nil},
+		{name: "zero quantity", items: []*types.OrderItemInfo{{ProductID: 1, Quantity: 0, Price: 1000}}},
+		{name: "negative quantity", items: []*types.OrderItemInfo{{ProductID: 1, Quantity: 2, Price: 1000}, {ProductID: 2, Quantity: -1, Price: 1000}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderNo, err := service.CreateOrder(context.TODO(), types.OrderInfo{OrderItemList: tt.items}, 123)
+			if !errors.Is(err, ErrInvalidOrderItems) {
+				t.Errorf("Expected ErrInvalidOrderItems, got: %v", err)
+			}
+			if orderNo != "" {
+				t.Errorf("Expected empty orderNo, got: %s", orderNo)
+			}
+		})
+	}
+}
+
 func TestOrderServiceImpl_CreateOrder_OrderDaoCreateError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	// Create mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
 
 	// Setup test data
 	ctx := context.TODO()
@@ -313,6 +386,8 @@ func TestOrderServiceImpl_CreateOrder_OrderDaoCreateError(t *testing.T) {
 			Products: []*productpb.Product{
 				{
 					Id:    1,
+					Name:  "Test Product",
+					Price: 1000,
 					Stock: 10,
 				},
 			},
@@ -325,14 +400,35 @@ func TestOrderServiceImpl_CreateOrder_OrderDaoCreateError(t *testing.T) {
 		Return("", errors.New("database connection failed")).
 		Times(1)
 
+	// Mock saga progress
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil).Times(1)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	// Mock saga reserving stock before the order is persisted
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: -2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
+	// Mock saga compensation - nothing persisted, only release the reserved stock
+	mockOrderDao.EXPECT().
+		GetByOrderNo(ctx, gomock.Any()).
+		Return(nil, gorm.ErrRecordNotFound).
+		Times(1)
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
 	// Create service instance with mocks
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
 	}
 
 	// Test the CreateOrder method
@@ -349,8 +445,13 @@ func TestOrderServiceImpl_ListOrders_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -382,10 +483,11 @@ func TestOrderServiceImpl_ListOrders_Success(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderQuery(ctx, gomock.Any()).Return(orders, nil)
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	resp, err := service.ListOrders(ctx, req)
 	if err != nil {
@@ -403,8 +505,13 @@ func TestOrderServiceImpl_ListOrders_DaoError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -412,10 +519,11 @@ func TestOrderServiceImpl_ListOrders_DaoError(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderQuery(ctx, gomock.Any()).Return(nil, errors.New("db error"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	resp, err := service.ListOrders(ctx, req)
 	if err == nil {
@@ -430,9 +538,16 @@ func TestOrderServiceImpl_GetOrderDetail_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
+	mockRefundDao := daoMocks.NewMockRefundDao(ctrl)
+	mockReturnDao := daoMocks.NewMockReturnDao(ctrl)
 
 	ctx := context.Background()
 	orderNo := "order1"
@@ -468,12 +583,19 @@ func TestOrderServiceImpl_GetOrderDetail_Success(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(order, nil)
 	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(products, nil)
 	mockOrderLogDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(logs, nil)
+	mockRefundDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockRefundDao.EXPECT().GetItemsByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockReturnDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockReturnDao.EXPECT().GetItemsByOrderNo(ctx, orderNo).Return(nil, nil)
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
+		refundDao:       mockRefundDao,
+		returnDao:       mockReturnDao,
 	}
 	detail, err := service.GetOrderDetail(ctx, orderNo)
 	if err != nil {
@@ -488,14 +610,23 @@ func TestOrderServiceImpl_GetOrderDetail_Success(t *testing.T) {
 	if len(detail.StatusLogs) != 1 || detail.StatusLogs[0].Remark != "created" {
 		t.Errorf("StatusLogs mismatch: %v", detail.StatusLogs)
 	}
+	// 历史日志没有发生时间时以写入时间代替
+	if !detail.StatusLogs[0].OccurredAt.Equal(logs[0].CreateTime) {
+		t.Errorf("StatusLogs OccurredAt mismatch: %v", detail.StatusLogs[0].OccurredAt)
+	}
 }
 
 func TestOrderServiceImpl_GetOrderDetail_OrderNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -503,10 +634,11 @@ func TestOrderServiceImpl_GetOrderDetail_OrderNotFound(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("not found"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	detail, err := service.GetOrderDetail(ctx, orderNo)
 	if err == nil {
@@ -521,8 +653,13 @@ func TestOrderServiceImpl_GetOrderDetail_ProductError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -532,10 +669,11 @@ func TestOrderServiceImpl_GetOrderDetail_ProductError(t *testing.T) {
 	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("product error"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	detail, err := service.GetOrderDetail(ctx, orderNo)
 	if err == nil {
@@ -550,8 +688,13 @@ func TestOrderServiceImpl_GetOrderDetail_LogError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -563,10 +706,11 @@ func TestOrderServiceImpl_GetOrderDetail_LogError(t *testing.T) {
 	mockOrderLogDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("log error"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	detail, err := service.GetOrderDetail(ctx, orderNo)
 	if err == nil {
@@ -583,10 +727,14 @@ func TestOrderServiceImpl_CreateOrder_OrderProductDaoCreateError(t *testing.T) {
 
 	// Create mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
 
 	// Setup test data
 	ctx := context.TODO()
@@ -617,6 +765,8 @@ func TestOrderServiceImpl_CreateOrder_OrderProductDaoCreateError(t *testing.T) {
 			Products: []*productpb.Product{
 				{
 					Id:    1,
+					Name:  "Test Product",
+					Price: 1000,
 					Stock: 10,
 				},
 			},
@@ -635,14 +785,47 @@ func TestOrderServiceImpl_CreateOrder_OrderProductDaoCreateError(t *testing.T) {
 		Return(0, errors.New("failed to create order product")).
 		Times(1)
 
+	// Mock saga progress
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil).Times(1)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	// Mock saga reserving stock before the order is persisted
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: -2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
+	// Mock saga compensation - cancel the persisted order and release the reserved stock
+	mockOrderDao.EXPECT().
+		GetByOrderNo(ctx, gomock.Any()).
+		Return(&model.Order{UserID: 123, Status: consts.CREATED}, nil).
+		Times(1)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		Return(1, nil).
+		Times(1)
+	mockKafkaWriter.EXPECT().
+		SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	mockKafkaWriter.EXPECT().
+		SendMsg(ctx, "order_canceled", gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(1)
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
 	// Create service instance with mocks
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
 	}
 
 	// Test the CreateOrder method
@@ -661,10 +844,14 @@ func TestOrderServiceImpl_CreateOrder_KafkaOrderCreatedError(t *testing.T) {
 
 	// Create mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
 
 	// Setup test data
 	ctx := context.TODO()
@@ -695,6 +882,8 @@ func TestOrderServiceImpl_CreateOrder_KafkaOrderCreatedError(t *testing.T) {
 			Products: []*productpb.Product{
 				{
 					Id:    1,
+					Name:  "Test Product",
+					Price: 1000,
 					Stock: 10,
 				},
 			},
@@ -719,14 +908,47 @@ func TestOrderServiceImpl_CreateOrder_KafkaOrderCreatedError(t *testing.T) {
 		Return(errors.New("kafka connection failed")).
 		Times(1)
 
+	// Mock saga progress
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil).Times(1)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	// Mock saga reserving stock before the order is persisted
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: -2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
+	// Mock saga compensation - cancel the persisted order and release the reserved stock
+	mockOrderDao.EXPECT().
+		GetByOrderNo(ctx, gomock.Any()).
+		Return(&model.Order{UserID: 123, Status: consts.CREATED}, nil).
+		Times(1)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		Return(1, nil).
+		Times(1)
+	mockKafkaWriter.EXPECT().
+		SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	mockKafkaWriter.EXPECT().
+		SendMsg(ctx, "order_canceled", gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(1)
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+
 	// Create service instance with mocks
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
 	}
 
 	// Test the CreateOrder method
@@ -745,10 +967,16 @@ func TestOrderServiceImpl_CreateOrder_PaymentFailed(t *testing.T) {
 
 	// Create mocks
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
 	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
 
 	// Setup test data
 	ctx := context.TODO()
@@ -777,6 +1005,8 @@ func TestOrderServiceImpl_CreateOrder_PaymentFailed(t *testing.T) {
 			Products: []*productpb.Product{
 				{
 					Id:    1,
+					Name:  "Test Product",
+					Price: 1000,
 					Stock: 10,
 				},
 			},
@@ -805,11 +1035,11 @@ func TestOrderServiceImpl_CreateOrder_PaymentFailed(t *testing.T) {
 		Return(nil).
 		AnyTimes()
 
-	// Mock product stock update
+	// Mock product stock reserve and release
 	mockProductClient.EXPECT().
 		UpdateStockWithCAS(ctx, gomock.Any()).
 		Return(&productpb.UpdateStockWithCASResponse{}, nil).
-		Times(1)
+		Times(2)
 
 	// Mock payment service - payment failed with error message
 	errorMsg := "Insufficient balance"
@@ -827,14 +1057,32 @@ func TestOrderServiceImpl_CreateOrder_PaymentFailed(t *testing.T) {
 		Return(nil).
 		Times(1)
 
+	// Mock saga progress
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil).Times(1)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	// Mock saga compensation - cancel the persisted order
+	mockOrderDao.EXPECT().
+		GetByOrderNo(ctx, gomock.Any()).
+		Return(&model.Order{UserID: 123, Status: consts.CREATED}, nil).
+		Times(1)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		Return(1, nil).
+		Times(1)
+	// no payment_result notification arrived, nothing to refund
+	mockPaymentResultDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil).Times(1)
+
 	// Create service instance with mocks
 	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
 		orderDao:             mockOrderDao,
 		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
 		productServiceClient: mockProductClient,
 		paymentServiceClient: mockPaymentClient,
 		messageWriter:        mockKafkaWriter,
-		syncMode:             true,
 	}
 
 	// Test the CreateOrder method
@@ -851,9 +1099,16 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
+	mockRefundDao := daoMocks.NewMockRefundDao(ctrl)
+	mockReturnDao := daoMocks.NewMockReturnDao(ctrl)
 
 	ctx := context.Background()
 	orderNo := "order1"
@@ -900,12 +1155,19 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_Success(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(order, nil)
 	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(products, nil)
 	mockOrderLogDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(logs, nil)
+	mockRefundDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockRefundDao.EXPECT().GetItemsByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockReturnDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockReturnDao.EXPECT().GetItemsByOrderNo(ctx, orderNo).Return(nil, nil)
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
+		refundDao:       mockRefundDao,
+		returnDao:       mockReturnDao,
 	}
 	detail, err := service.CustomerGetOrderDetail(ctx, orderNo, userID)
 	if err != nil {
@@ -929,9 +1191,16 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_WrongUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
+	mockRefundDao := daoMocks.NewMockRefundDao(ctrl)
+	mockReturnDao := daoMocks.NewMockReturnDao(ctrl)
 
 	ctx := context.Background()
 	orderNo := "order1"
@@ -981,12 +1250,19 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_WrongUser(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(order, nil)
 	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(products, nil)
 	mockOrderLogDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(logs, nil)
+	mockRefundDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockRefundDao.EXPECT().GetItemsByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockReturnDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, nil)
+	mockReturnDao.EXPECT().GetItemsByOrderNo(ctx, orderNo).Return(nil, nil)
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
+		refundDao:       mockRefundDao,
+		returnDao:       mockReturnDao,
 	}
 
 	// 用户456尝试访问用户123的订单
@@ -1006,8 +1282,13 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_OrderNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -1016,10 +1297,11 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_OrderNotFound(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("not found"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	detail, err := service.CustomerGetOrderDetail(ctx, orderNo, userID)
 	if err == nil {
@@ -1034,8 +1316,13 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_ProductError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -1046,10 +1333,11 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_ProductError(t *testing.T) {
 	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("product error"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	detail, err := service.CustomerGetOrderDetail(ctx, orderNo, userID)
 	if err == nil {
@@ -1064,8 +1352,13 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_LogError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
 	mockOrderLogDao := daoMocks.NewMockOrderLogDao(ctrl)
 
 	ctx := context.Background()
@@ -1078,10 +1371,11 @@ func TestOrderServiceImpl_CustomerGetOrderDetail_LogError(t *testing.T) {
 	mockOrderLogDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("log error"))
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		messageWriter:   mockMessageWriter,
 		orderDao:        mockOrderDao,
 		orderProductDao: mockOrderProductDao,
 		orderLogDao:     mockOrderLogDao,
-		syncMode:        true,
 	}
 	detail, err := service.CustomerGetOrderDetail(ctx, orderNo, userID)
 	if err == nil {
@@ -1097,8 +1391,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_ToShipped_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
-	mockMessageWriter := utilMocks.NewMockWriter(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
 
 	ctx := context.Background()
 	orderNo := "TEST001"
@@ -1110,18 +1409,22 @@ func TestOrderServiceImpl_UpdateOrderStatus_ToShipped_Success(t *testing.T) {
 		OrderNo: orderNo,
 		Status:  int(consts.PAYED),
 	}, nil)
-	mockOrderDao.EXPECT().UpdateStatusWithDeliveryInfo(ctx, orderNo, newStatus, gomock.Any(), logisticsInfo).Return(nil)
+	mockOrderDao.EXPECT().UpdateStatus(ctx, orderNo, consts.PAYED, gomock.Any()).Return(1, nil)
 
 	// Mock successful Kafka message
 	mockMessageWriter.EXPECT().SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).Return(nil)
 
 	service := &OrderServiceImpl{
-		orderDao:      mockOrderDao,
-		messageWriter: mockMessageWriter,
-		syncMode:      true,
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		orderDao:        mockOrderDao,
+		messageWriter:   mockMessageWriter,
 	}
 
-	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, logisticsInfo)
+	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, consts.TransitionInput{
+		Actor:      consts.ActorMerchant,
+		TrackingNo: logisticsInfo,
+	})
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -1132,8 +1435,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_ToDelivered_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
-	mockMessageWriter := utilMocks.NewMockWriter(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
 
 	ctx := context.Background()
 	orderNo := "TEST002"
@@ -1144,18 +1452,19 @@ func TestOrderServiceImpl_UpdateOrderStatus_ToDelivered_Success(t *testing.T) {
 		OrderNo: orderNo,
 		Status:  int(consts.SHIPPED),
 	}, nil)
-	mockOrderDao.EXPECT().UpdateStatusAndConfirmTime(ctx, orderNo, newStatus, gomock.Any()).Return(nil)
+	mockOrderDao.EXPECT().UpdateStatus(ctx, orderNo, consts.SHIPPED, gomock.Any()).Return(1, nil)
 
 	// Mock successful Kafka message
 	mockMessageWriter.EXPECT().SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).Return(nil)
 
 	service := &OrderServiceImpl{
-		orderDao:      mockOrderDao,
-		messageWriter: mockMessageWriter,
-		syncMode:      true,
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		orderDao:        mockOrderDao,
+		messageWriter:   mockMessageWriter,
 	}
 
-	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, "")
+	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, consts.TransitionInput{Actor: consts.ActorCustomer})
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -1166,7 +1475,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_OrderNotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 
 	ctx := context.Background()
 	orderNo := "NOTFOUND"
@@ -1175,11 +1490,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_OrderNotFound(t *testing.T) {
 	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(nil, errors.New("order not found"))
 
 	service := &OrderServiceImpl{
-		orderDao: mockOrderDao,
-		syncMode: true,
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
 	}
 
-	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, "")
+	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, consts.TransitionInput{Actor: consts.ActorCustomer})
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
@@ -1190,7 +1507,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_InvalidStatusTransition(t *testing.T
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 
 	ctx := context.Background()
 	orderNo := "TEST003"
@@ -1203,22 +1526,57 @@ func TestOrderServiceImpl_UpdateOrderStatus_InvalidStatusTransition(t *testing.T
 	}, nil)
 
 	service := &OrderServiceImpl{
-		orderDao: mockOrderDao,
-		syncMode: true,
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
 	}
 
-	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, "")
+	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, consts.TransitionInput{Actor: consts.ActorCustomer})
 	if err == nil {
 		t.Errorf("Expected error for invalid status transition, got nil")
 	}
 }
 
+// TestOrderServiceImpl_UpdateOrderStatus_InternalTransition tests refund transitions cannot bypass the refund flow
+func TestOrderServiceImpl_UpdateOrderStatus_InternalTransition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+
+	ctx := context.Background()
+	orderNo := "TEST003"
+
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.Order{
+		OrderNo: orderNo,
+		Status:  consts.REFUNDING,
+	}, nil)
+	mockOrderDao.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		txBeginner: testTxBeginner{},
+		orderDao:   mockOrderDao,
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.REFUNDED, consts.TransitionInput{Actor: consts.ActorMerchant})
+	if !errors.Is(err, consts.ErrInternalTransition) {
+		t.Errorf("Expected ErrInternalTransition, got %v", err)
+	}
+}
+
 // TestOrderServiceImpl_UpdateOrderStatus_UnsupportedStatus tests unsupported status update
 func TestOrderServiceImpl_UpdateOrderStatus_UnsupportedStatus(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 
 	ctx := context.Background()
 	orderNo := "TEST004"
@@ -1230,11 +1588,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_UnsupportedStatus(t *testing.T) {
 	}, nil)
 
 	service := &OrderServiceImpl{
-		orderDao: mockOrderDao,
-		syncMode: true,
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
 	}
 
-	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, "")
+	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, consts.TransitionInput{Actor: consts.ActorCustomer})
 	if err == nil {
 		t.Errorf("Expected error for unsupported status, got nil")
 	}
@@ -1245,7 +1605,13 @@ func TestOrderServiceImpl_UpdateOrderStatus_DaoUpdateError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 
 	ctx := context.Background()
 	orderNo := "TEST005"
@@ -1255,14 +1621,16 @@ func TestOrderServiceImpl_UpdateOrderStatus_DaoUpdateError(t *testing.T) {
 		OrderNo: orderNo,
 		Status:  int(consts.SHIPPED),
 	}, nil)
-	mockOrderDao.EXPECT().UpdateStatusAndConfirmTime(ctx, orderNo, newStatus, gomock.Any()).Return(errors.New("database error"))
+	mockOrderDao.EXPECT().UpdateStatus(ctx, orderNo, consts.SHIPPED, gomock.Any()).Return(0, errors.New("database error"))
 
 	service := &OrderServiceImpl{
-		orderDao: mockOrderDao,
-		syncMode: true,
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
 	}
 
-	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, "")
+	err := service.UpdateOrderStatus(ctx, orderNo, newStatus, consts.TransitionInput{Actor: consts.ActorCustomer})
 	if err == nil {
 		t.Errorf("Expected error from DAO update, got nil")
 	}
@@ -1273,9 +1641,14 @@ func TestOrderServiceImpl_OrderAutoConfirm_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	ctx := context.Background()
 
@@ -1289,7 +1662,7 @@ func TestOrderServiceImpl_OrderAutoConfirm_Success(t *testing.T) {
 		{OrderNo: "ORDER002", UserID: 102},
 	}
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return(autoConfirmedOrders, nil).
 		Times(1)
 
@@ -1304,10 +1677,11 @@ func TestOrderServiceImpl_OrderAutoConfirm_Success(t *testing.T) {
 		Times(1)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
 		orderDao:          mockOrderDao,
 		messageWriter:     mockKafkaWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
 	// Execute
@@ -1319,6 +1693,13 @@ func TestOrderServiceImpl_OrderAutoConfirm_LockFailed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockLocker := utilMocks.NewMockLocker(ctrl)
 
 	ctx := context.Background()
@@ -1329,8 +1710,11 @@ func TestOrderServiceImpl_OrderAutoConfirm_LockFailed(t *testing.T) {
 	mockLocker.EXPECT().Unlock(ctx).Times(0)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderDao:          mockOrderDao,
+		orderProductDao:   mockOrderProductDao,
+		messageWriter:     mockMessageWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
 	// Execute - should return gracefully without error
@@ -1342,7 +1726,13 @@ func TestOrderServiceImpl_OrderAutoConfirm_DaoError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
 
 	ctx := context.Background()
@@ -1353,14 +1743,16 @@ func TestOrderServiceImpl_OrderAutoConfirm_DaoError(t *testing.T) {
 
 	// Mock DAO returns error
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return(nil, errors.New("database connection failed")).
 		Times(1)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
+		messageWriter:     mockMessageWriter,
 		orderDao:          mockOrderDao,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
 	// Execute - should handle error gracefully
@@ -1372,9 +1764,14 @@ func TestOrderServiceImpl_OrderAutoConfirm_NoOrders(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	ctx := context.Background()
 
@@ -1384,7 +1781,7 @@ func TestOrderServiceImpl_OrderAutoConfirm_NoOrders(t *testing.T) {
 
 	// Mock DAO returns empty list
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return([]types.OrderNoAndUserId{}, nil).
 		Times(1)
 
@@ -1392,24 +1789,30 @@ func TestOrderServiceImpl_OrderAutoConfirm_NoOrders(t *testing.T) {
 	mockKafkaWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
 		orderDao:          mockOrderDao,
 		messageWriter:     mockKafkaWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
 	// Execute
 	service.OrderAutoConfirm(ctx)
 }
 
-// TestOrderServiceImpl_OrderAutoConfirm_MessageSendError tests Kafka message send failure
+// TestOrderServiceImpl_OrderAutoConfirm_MessageSendError tests outbox write failure rolls back the auto-confirm batch
 func TestOrderServiceImpl_OrderAutoConfirm_MessageSendError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	ctx := context.Background()
 
@@ -1422,24 +1825,25 @@ func TestOrderServiceImpl_OrderAutoConfirm_MessageSendError(t *testing.T) {
 		{OrderNo: "ORDER001", UserID: 101},
 	}
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return(autoConfirmedOrders, nil).
 		Times(1)
 
-	// Mock Kafka message send fails
+	// Mock outbox write fails
 	mockKafkaWriter.EXPECT().
 		SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).
-		Return(errors.New("kafka connection failed")).
+		Return(errors.New("outbox insert failed")).
 		Times(1)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
 		orderDao:          mockOrderDao,
 		messageWriter:     mockKafkaWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
-	// Execute - should log error but continue
+	// Execute - should log error and roll back
 	service.OrderAutoConfirm(ctx)
 }
 
@@ -1448,9 +1852,14 @@ func TestOrderServiceImpl_OrderAutoConfirm_UnlockError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	ctx := context.Background()
 
@@ -1464,7 +1873,7 @@ func TestOrderServiceImpl_OrderAutoConfirm_UnlockError(t *testing.T) {
 		{OrderNo: "ORDER001", UserID: 101},
 	}
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return(autoConfirmedOrders, nil).
 		Times(1)
 
@@ -1475,10 +1884,11 @@ func TestOrderServiceImpl_OrderAutoConfirm_UnlockError(t *testing.T) {
 		Times(1)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
 		orderDao:          mockOrderDao,
 		messageWriter:     mockKafkaWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
 	// Execute - should log error but not panic
@@ -1490,9 +1900,14 @@ func TestOrderServiceImpl_OrderAutoConfirm_MultipleOrders(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	ctx := context.Background()
 
@@ -1509,7 +1924,7 @@ func TestOrderServiceImpl_OrderAutoConfirm_MultipleOrders(t *testing.T) {
 		{OrderNo: "ORDER005", UserID: 105},
 	}
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return(autoConfirmedOrders, nil).
 		Times(1)
 
@@ -1522,10 +1937,11 @@ func TestOrderServiceImpl_OrderAutoConfirm_MultipleOrders(t *testing.T) {
 	}
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
 		orderDao:          mockOrderDao,
 		messageWriter:     mockKafkaWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
 	// Execute
@@ -1537,9 +1953,14 @@ func TestOrderServiceImpl_OrderAutoConfirm_PartialMessageFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+
 	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
 	mockLocker := utilMocks.NewMockLocker(ctrl)
-	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
 
 	ctx := context.Background()
 
@@ -1554,7 +1975,7 @@ func TestOrderServiceImpl_OrderAutoConfirm_PartialMessageFailure(t *testing.T) {
 		{OrderNo: "ORDER003", UserID: 103},
 	}
 	mockOrderDao.EXPECT().
-		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS).
+		AutoConfirmShippedOrders(ctx, consts.SHIPPED, consts.DELIVERED, AUTO_CONFIRM_AFTER_DAYS, []string{"confirm_time"}).
 		Return(autoConfirmedOrders, nil).
 		Times(1)
 
@@ -1567,28 +1988,35 @@ func TestOrderServiceImpl_OrderAutoConfirm_PartialMessageFailure(t *testing.T) {
 	// Second message fails
 	mockKafkaWriter.EXPECT().
 		SendMsg(ctx, "order_status_changed", "ORDER002", gomock.Any()).
-		Return(errors.New("kafka timeout")).
+		Return(errors.New("outbox insert failed")).
 		Times(1)
 
-	// Third message succeeds
+	// The transaction is rolled back, so the third message is never written
 	mockKafkaWriter.EXPECT().
 		SendMsg(ctx, "order_status_changed", "ORDER003", gomock.Any()).
-		Return(nil).
-		Times(1)
+		Times(0)
 
 	service := &OrderServiceImpl{
+		txBeginner:        testTxBeginner{},
+		orderProductDao:   mockOrderProductDao,
 		orderDao:          mockOrderDao,
 		messageWriter:     mockKafkaWriter,
 		distributedLocker: mockLocker,
-		syncMode:          true,
 	}
 
-	// Execute - should continue processing all orders despite one failure
+	// Execute - should roll back the whole batch, the orders are confirmed again next round
 	service.OrderAutoConfirm(ctx)
 }
 func TestOrderServiceImpl_GetOrderStats_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
 	orderStatsCacheMock := cacheMocks.NewMockIOrderStatsCache(ctrl)
 
 	ctx := context.TODO()
@@ -1600,8 +2028,14 @@ func TestOrderServiceImpl_GetOrderStats_Success(t *testing.T) {
 	}
 
 	orderStatsCacheMock.EXPECT().GetOrderStats().Return(expectedStats, nil)
+	expectedStats.BaseCurrency = DEFAULT_BASE_CURRENCY
+	expectedStats.SalesByCurrency = []*types.CurrencySales{}
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		orderDao:        mockOrderDao,
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
 		orderStatsCache: orderStatsCacheMock,
 	}
 
@@ -1609,7 +2043,7 @@ func TestOrderServiceImpl_GetOrderStats_Success(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected no error, got: %s", err.Error())
 	}
-	if stats != expectedStats {
+	if !reflect.DeepEqual(stats, expectedStats) {
 		t.Errorf("Expected stats to be %v, got: %v", expectedStats, stats)
 	}
 }
@@ -1618,6 +2052,13 @@ func TestOrderServiceImpl_GetOrderStats_Error(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
 	orderStatsCacheMock := cacheMocks.NewMockIOrderStatsCache(ctrl)
 
 	ctx := context.TODO()
@@ -1626,6 +2067,10 @@ func TestOrderServiceImpl_GetOrderStats_Error(t *testing.T) {
 	orderStatsCacheMock.EXPECT().GetOrderStats().Return(types.OrderStats{}, expectedError)
 
 	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		orderDao:        mockOrderDao,
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
 		orderStatsCache: orderStatsCacheMock,
 	}
 
@@ -1633,7 +2078,548 @@ func TestOrderServiceImpl_GetOrderStats_Error(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
-	if stats != (types.OrderStats{}) {
+	if !reflect.DeepEqual(stats, types.OrderStats{}) {
 		t.Errorf("Expected empty stats, got: %v", stats)
 	}
 }
+
+// TestOrderServiceImpl_UpdateOrderStatus_CustomerCancelPaidSuccess tests cancelling a paid order restores stock and refunds
+func TestOrderServiceImpl_UpdateOrderStatus_CustomerCancelPaidSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	ctx := context.Background()
+	orderNo := "CANCEL001"
+
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.Order{
+		OrderNo:     orderNo,
+		UserID:      123,
+		Status:      consts.PAYED,
+		TotalAmount: 2980,
+	}, nil)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, orderNo, consts.PAYED, gomock.Any()).
+		Return(1, nil)
+	// read once for the order_canceled message and once for restoring stock
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return([]*model.OrderProduct{
+		{OrderNo: orderNo, ProductID: 1, Quantity: 2},
+	}, nil).Times(2)
+	mockProductClient.EXPECT().
+		UpdateStockWithCAS(ctx, &productpb.UpdateStockWithCASRequest{Id: 1, Deta: 2}).
+		Return(&productpb.UpdateStockWithCASResponse{}, nil).
+		Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_canceled", orderNo, gomock.Any()).Return(nil).Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_refund", orderNo, gomock.Any()).Return(nil).Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", orderNo, gomock.Any()).Return(nil).Times(1)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		productServiceClient: mockProductClient,
+		messageWriter:        mockKafkaWriter,
+		invoiceDao:           newNoInvoiceDao(ctrl),
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorCustomer,
+		UserID: 123,
+		Reason: "changed my mind",
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_MerchantCancelCreatedNoRefund tests cancelling an unpaid order does not refund
+func TestOrderServiceImpl_UpdateOrderStatus_MerchantCancelCreatedNoRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+
+	ctx := context.Background()
+	orderNo := "CANCEL002"
+
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.Order{
+		OrderNo: orderNo,
+		UserID:  123,
+		Status:  consts.CREATED,
+	}, nil)
+	mockOrderSagaDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.OrderSaga{OrderNo: orderNo, Status: consts.SAGA_COMPLETED}, nil)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, orderNo, consts.CREATED, gomock.Any()).
+		Return(1, nil)
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return([]*model.OrderProduct{
+		{OrderNo: orderNo, ProductID: 1, Quantity: 1},
+		{OrderNo: orderNo, ProductID: 2, Quantity: 3},
+	}, nil).Times(2)
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_canceled", orderNo, gomock.Any()).Return(nil).Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_refund", gomock.Any(), gomock.Any()).Times(0)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", orderNo, gomock.Any()).Return(nil).Times(1)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		productServiceClient: mockProductClient,
+		messageWriter:        mockKafkaWriter,
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorMerchant,
+		Reason: "out of stock",
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_CustomerCancelWrongUser tests a customer can not cancel another user's order
+func TestOrderServiceImpl_UpdateOrderStatus_CustomerCancelWrongUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+
+	ctx := context.Background()
+	orderNo := "CANCEL003"
+
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.Order{
+		OrderNo: orderNo,
+		UserID:  123,
+		Status:  consts.PAYED,
+	}, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorCustomer,
+		UserID: 456,
+	})
+	if err == nil {
+		t.Errorf("Expected error for wrong user, got nil")
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_CustomerCancelAlreadyShipped tests shipped orders can not be canceled
+func TestOrderServiceImpl_UpdateOrderStatus_CustomerCancelAlreadyShipped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+
+	ctx := context.Background()
+	orderNo := "CANCEL004"
+
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.Order{
+		OrderNo: orderNo,
+		UserID:  123,
+		Status:  consts.SHIPPED,
+	}, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorCustomer,
+		UserID: 123,
+	})
+	if err == nil {
+		t.Errorf("Expected error for shipped order, got nil")
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_MerchantCancelConcurrentUpdate tests cancel fails when the status changed concurrently
+func TestOrderServiceImpl_UpdateOrderStatus_MerchantCancelConcurrentUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+
+	ctx := context.Background()
+	orderNo := "CANCEL005"
+
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(&model.Order{
+		OrderNo: orderNo,
+		UserID:  123,
+		Status:  consts.PAYED,
+	}, nil)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, orderNo, consts.PAYED, gomock.Any()).
+		Return(0, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:      testTxBeginner{},
+		orderProductDao: mockOrderProductDao,
+		messageWriter:   mockMessageWriter,
+		orderDao:        mockOrderDao,
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{Actor: consts.ActorMerchant})
+	if err == nil {
+		t.Errorf("Expected error for concurrent update, got nil")
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_PriceChanged tests the order is rejected when the client price is stale
+func TestOrderServiceImpl_CreateOrder_PriceChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+
+	ctx := context.TODO()
+	orderInfo := types.OrderInfo{
+		ReceiverCountry: "SG",
+		OrderItemList: []*types.OrderItemInfo{
+			{ProductID: 1, ProductName: "Vase", Quantity: 2, Price: 1},
+		},
+	}
+
+	mockProductClient.EXPECT().
+		GetProductList(ctx, gomock.Any()).
+		Return(&productpb.GetProductListResponse{
+			Products: []*productpb.Product{
+				{Id: 1, Name: "Vase", Price: 1000, Stock: 10},
+			},
+		}, nil).
+		Times(1)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		messageWriter:        mockMessageWriter,
+		productServiceClient: mockProductClient,
+	}
+
+	orderNo, err := service.CreateOrder(ctx, orderInfo, 123)
+	var priceErr *PriceChangedError
+	if !errors.As(err, &priceErr) {
+		t.Fatalf("Expected PriceChangedError, got: %v", err)
+	}
+	if orderNo != "" {
+		t.Errorf("Expected empty orderNo, got: %s", orderNo)
+	}
+	if len(priceErr.Info.Items) != 1 || priceErr.Info.Items[0].CurrentPrice != 1000 || priceErr.Info.Items[0].ExpectedPrice != 1 {
+		t.Errorf("Changed items mismatch: %+v", priceErr.Info.Items)
+	}
+	// 2000 + 800 shipping + 180 tax
+	if priceErr.Info.TotalAmount != 2980 {
+		t.Errorf("Expected re-quoted total 2980, got: %d", priceErr.Info.TotalAmount)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_ExpectedTotalMismatch tests the order is rejected when the expected total differs
+func TestOrderServiceImpl_CreateOrder_ExpectedTotalMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+
+	ctx := context.TODO()
+	orderInfo := types.OrderInfo{
+		OrderItemList: []*types.OrderItemInfo{
+			{ProductID: 1, ProductName: "Vase", Quantity: 2, Price: 1000},
+		},
+		ExpectedTotalAmount: 2000,
+	}
+
+	mockProductClient.EXPECT().
+		GetProductList(ctx, gomock.Any()).
+		Return(&productpb.GetProductListResponse{
+			Products: []*productpb.Product{
+				{Id: 1, Name: "Vase", Price: 1000, Stock: 10},
+			},
+		}, nil).
+		Times(1)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		messageWriter:        mockMessageWriter,
+		productServiceClient: mockProductClient,
+	}
+
+	_, err := service.CreateOrder(ctx, orderInfo, 123)
+	var priceErr *PriceChangedError
+	if !errors.As(err, &priceErr) {
+		t.Fatalf("Expected PriceChangedError, got: %v", err)
+	}
+	if len(priceErr.Info.Items) != 0 {
+		t.Errorf("Expected no changed items, got: %+v", priceErr.Info.Items)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_ProductNotFound tests ordering a product unknown to the product service
+func TestOrderServiceImpl_CreateOrder_ProductNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockMessageWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockMessageWriter.EXPECT().WithTx(gomock.Any()).Return(mockMessageWriter).AnyTimes()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+
+	ctx := context.TODO()
+	orderInfo := types.OrderInfo{
+		OrderItemList: []*types.OrderItemInfo{
+			{ProductID: 99, Quantity: 1, Price: 1000},
+		},
+	}
+
+	mockProductClient.EXPECT().
+		GetProductList(ctx, gomock.Any()).
+		Return(&productpb.GetProductListResponse{}, nil).
+		Times(1)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		messageWriter:        mockMessageWriter,
+		productServiceClient: mockProductClient,
+	}
+
+	_, err := service.CreateOrder(ctx, orderInfo, 123)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_UsesServerPriceAndName tests persisted rows and payment use the product service data
+func TestOrderServiceImpl_CreateOrder_UsesServerPriceAndName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockPaymentClient := mocks.NewMockPaymentServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+	mockOrderSagaDao := daoMocks.NewMockOrderSagaDao(ctrl)
+	mockPaymentResultDao := daoMocks.NewMockPaymentResultDao(ctrl)
+	mockPaymentResultDao.EXPECT().WithTx(gomock.Any()).Return(mockPaymentResultDao).AnyTimes()
+
+	ctx := context.TODO()
+	mockPaymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	orderInfo := types.OrderInfo{
+		OrderItemList: []*types.OrderItemInfo{
+			{ProductID: 1, ProductName: "Renamed by client", Quantity: 2, Price: 1000},
+		},
+		ExpectedTotalAmount: 2980,
+	}
+
+	mockProductClient.EXPECT().
+		GetProductList(ctx, gomock.Any()).
+		Return(&productpb.GetProductListResponse{
+			Products: []*productpb.Product{
+				{Id: 1, Name: "Celadon Vase", Price: 1000, Stock: 10},
+			},
+		}, nil).
+		Times(1)
+	mockOrderDao.EXPECT().
+		Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+			if order.TotalAmount != 2980 {
+				t.Errorf("Expected total amount 2980, got: %d", order.TotalAmount)
+			}
+			return order.OrderNo, nil
+		})
+	mockOrderProductDao.EXPECT().
+		CreateBatch(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, products []model.OrderProduct) (int, error) {
+			if products[0].ProductName != "Celadon Vase" || products[0].Price != 1000 || products[0].TotalPrice != 2000 {
+				t.Errorf("Order product mismatch: %+v", products[0])
+			}
+			return len(products), nil
+		})
+	mockKafkaWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockProductClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil)
+	mockPaymentClient.EXPECT().
+		PayOrder(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *paymentpb.PayOrderRequest, _ ...interface{}) (*paymentpb.PayOrderResponse, error) {
+			if req.Amount != 2980 {
+				t.Errorf("Expected pay amount 2980, got: %d", req.Amount)
+			}
+			return &paymentpb.PayOrderResponse{Code: 0}, nil
+		})
+	mockOrderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).Return(1, nil)
+
+	// Mock saga progress
+	mockOrderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil).Times(1)
+	mockOrderSagaDao.EXPECT().UpdateProgress(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	// 付款后开具发票
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		orderSagaDao:         mockOrderSagaDao,
+		paymentResultDao:     mockPaymentResultDao,
+		productServiceClient: mockProductClient,
+		paymentServiceClient: mockPaymentClient,
+		messageWriter:        mockKafkaWriter,
+		invoiceDao:           newIssuingInvoiceDao(ctrl),
+	}
+
+	orderNo, err := service.CreateOrder(ctx, orderInfo, 123)
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if orderNo == "" {
+		t.Errorf("Expected orderNo to be not empty")
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_CancelMessageWriteFailed tests the status change is rolled back and stock is not restored when the outbox write fails
+func TestOrderServiceImpl_UpdateOrderStatus_CancelMessageWriteFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderDao := daoMocks.NewMockOrderDao(ctrl)
+	mockOrderDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderDao).AnyTimes()
+	mockOrderProductDao := daoMocks.NewMockOrderProductDao(ctrl)
+	mockOrderProductDao.EXPECT().WithTx(gomock.Any()).Return(mockOrderProductDao).AnyTimes()
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	mockKafkaWriter := utilMocks.NewMockTxWriter(ctrl)
+	mockKafkaWriter.EXPECT().WithTx(gomock.Any()).Return(mockKafkaWriter).AnyTimes()
+
+	ctx := context.Background()
+	orderNo := "CANCEL006"
+
+	order := &model.Order{
+		OrderNo:     orderNo,
+		UserID:      123,
+		Status:      consts.PAYED,
+		TotalAmount: 2980,
+	}
+	mockOrderDao.EXPECT().GetByOrderNo(ctx, orderNo).Return(order, nil)
+	mockOrderDao.EXPECT().
+		UpdateStatus(ctx, orderNo, consts.PAYED, gomock.Any()).
+		Return(1, nil)
+	mockOrderProductDao.EXPECT().GetByOrderNo(ctx, orderNo).Return([]*model.OrderProduct{
+		{OrderNo: orderNo, ProductID: 1, Quantity: 2},
+	}, nil).Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_canceled", orderNo, gomock.Any()).Return(nil).Times(1)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_refund", orderNo, gomock.Any()).Return(nil).Times(1)
+	mockKafkaWriter.EXPECT().
+		SendMsg(ctx, "order_status_changed", orderNo, gomock.Any()).
+		Return(errors.New("outbox insert failed")).
+		Times(1)
+	// stock is only restored after the transaction commits
+	mockProductClient.EXPECT().UpdateStockWithCAS(gomock.Any(), gomock.Any()).Times(0)
+
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             mockOrderDao,
+		orderProductDao:      mockOrderProductDao,
+		productServiceClient: mockProductClient,
+		messageWriter:        mockKafkaWriter,
+		invoiceDao:           newNoInvoiceDao(ctrl),
+	}
+
+	err := service.UpdateOrderStatus(ctx, orderNo, consts.CANCELED, consts.TransitionInput{
+		Actor:  consts.ActorMerchant,
+		Reason: "out of stock",
+	})
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if order.Status != consts.PAYED {
+		t.Errorf("Expected status to stay %d, got %d", consts.PAYED, order.Status)
+	}
+}
+
+// TestOrderMessages_MatchEventContracts tests the published payloads stay within the event contracts in common/proto/events.proto
+func TestOrderMessages_MatchEventContracts(t *testing.T) {
+	order := &model.Order{
+		OrderNo: "ORDER001", UserID: 123, ReceiverFirstName: "F", ReceiverLastName: "L", ReceiverPhone: "123",
+		ReceiverAddress: "addr", ReceiverCountry: "CN", ReceiverZipCode: 10000, Remark: "remark",
+	}
+	products := []*model.OrderProduct{{ProductID: 2, ProductName: "P1", Price: 10, Quantity: 1}}
+	orderMsg, err := getPersistedOrderMsg(order, products)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	oscMsg, err := getOrderStatusChangedMsg(order.OrderNo, order.UserID, "Created --> Payed by customer", consts.PAYED)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for topic, msg := range map[string]string{"order_created": orderMsg, "order_canceled": orderMsg, "order_status_changed": oscMsg} {
+		if _, err = utils.WrapEvent(topic, order.OrderNo, msg); err != nil {
+			t.Errorf("%s payload breaks its event contract: %v", topic, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/metrics"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+const (
+	OUTBOX_RELAY_LOCK_KEY  = "order:outbox_relay:lock"
+	OUTBOX_RELAY_LOCK_TIME = 30 * time.Second
+	OUTBOX_RELAY_BATCH     = 200
+	OUTBOX_MAX_ATTEMPTS    = 10
+	OUTBOX_RETRY_BASE      = time.Second
+	OUTBOX_RETRY_MAX       = 5 * time.Minute
+)
+
+// OutboxRelay 将 outbox 中的消息投递到 kafka
+type OutboxRelay struct {
+	outboxDao   dao.OutboxDao
+	kafkaWriter utils.Writer
+	locker      utils.Locker
+}
+
+func GetOutboxRelayInstance() *OutboxRelay {
+	return &OutboxRelay{
+		outboxDao:   dao.GetOutboxDao(),
+		kafkaWriter: utils.GetWriter(),
+		locker:      utils.GetDistributedLock(OUTBOX_RELAY_LOCK_KEY, uuid.New().String(), OUTBOX_RELAY_LOCK_TIME),
+	}
+}
+
+// Relay 按 id 顺序投递待发送的消息；同一 key 的前一条消息未投递成功时，后续消息本轮不投递，保证同一订单的消息有序
+func (r *OutboxRelay) Relay(ctx context.Context) {
+	// 只有一个实例投递，避免并发投递打乱顺序
+	if err := r.locker.Lock(ctx); err != nil {
+		log.Logger.Info("Relay: failed to acquire lock, skipping this round")
+		return
+	}
+	defer func() {
+		if unlockErr := r.locker.Unlock(ctx); unlockErr != nil {
+			log.Logger.Errorf("Relay: failed to release lock, err: %s", unlockErr.Error())
+		}
+	}()
+	defer r.reportBacklog(ctx)
+
+	msgList, err := r.outboxDao.GetPending(ctx, OUTBOX_RELAY_BATCH)
+	if err != nil {
+		log.Logger.Errorf("Relay: get pending messages failed, err: %s", err.Error())
+		return
+	}
+
+	now := time.Now()
+	blockedKeys := make(map[string]bool)
+	for _, msg := range msgList {
+		if blockedKeys[msg.MsgKey] {
+			continue
+		}
+		if msg.NextRetryTime.After(now) {
+			blockedKeys[msg.MsgKey] = true
+			continue
+		}
+		// 一批消息的投递可能超过锁的过期时间，每条消息投递前续期；锁已被其他实例取得时停止，避免重复投递和打乱顺序
+		if err = r.locker.Renew(ctx); err != nil {
+			log.Logger.Errorf("Relay: lock lost, stop this round, err: %s", err.Error())
+			return
+		}
+		if !r.publish(ctx, msg) {
+			blockedKeys[msg.MsgKey] = true
+		}
+	}
+}
+
+// publish 投递一条消息并保存结果，返回该 key 的后续消息是否可以继续投递
+func (r *OutboxRelay) publish(ctx context.Context, msg *model.Outbox) bool {
+	err := r.kafkaWriter.SendMsg(ctx, msg.Topic, msg.MsgKey, msg.Payload)
+	if err == nil {
+		metrics.OutboxPublishTotal.WithLabelValues(msg.Topic, "sent").Inc()
+		// 标记失败时消息会被重复投递，消费者需要幂等
+		if markErr := r.outboxDao.MarkSent(ctx, msg.ID); markErr != nil {
+			log.Logger.Errorf("publish: mark sent failed, id: %d, err: %s", msg.ID, markErr.Error())
+			return false
+		}
+		return true
+	}
+
+	msg.Attempts++
+	msg.LastError = err.Error()
+	if len(msg.LastError) > 512 {
+		msg.LastError = msg.LastError[:512]
+	}
+	msg.NextRetryTime = time.Now().Add(outboxRetryDelay(msg.Attempts))
+	if msg.Attempts >= OUTBOX_MAX_ATTEMPTS {
+		// 重试耗尽的消息不再阻塞后续消息，需要人工处理
+		msg.Status = consts.OUTBOX_FAILED
+		metrics.OutboxPublishTotal.WithLabelValues(msg.Topic, "failed").Inc()
+		log.Logger.Errorf("publish: retries exhausted, id: %d, topic: %s, key: %s, err: %s", msg.ID, msg.Topic, msg.MsgKey, err.Error())
+	} else {
+		metrics.OutboxPublishTotal.WithLabelValues(msg.Topic, "retry").Inc()
+		log.Logger.Errorf("publish: failed, id: %d, attempts: %d, err: %s", msg.ID, msg.Attempts, err.Error())
+	}
+	if updateErr := r.outboxDao.UpdateDelivery(ctx, msg); updateErr != nil {
+		log.Logger.Errorf("publish: update delivery failed, id: %d, err: %s", msg.ID, updateErr.Error())
+	}
+	return msg.Status == consts.OUTBOX_FAILED
+}
+
+func (r *OutboxRelay) reportBacklog(ctx context.Context) {
+	count, oldest, err := r.outboxDao.GetPendingStats(ctx)
+	if err != nil {
+		log.Logger.Errorf("reportBacklog: get pending stats failed, err: %s", err.Error())
+		return
+	}
+	metrics.OutboxPendingMessages.Set(float64(count))
+	if oldest.IsZero() {
+		metrics.OutboxOldestPendingSeconds.Set(0)
+		return
+	}
+	metrics.OutboxOldestPendingSeconds.Set(time.Since(oldest).Seconds())
+}
+
+// outboxRetryDelay 指数退避：1s, 2s, 4s ... 最长 5 分钟
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := OUTBOX_RETRY_BASE
+	for i := 1; i < attempts && delay < OUTBOX_RETRY_MAX; i++ {
+		delay *= 2
+	}
+	if delay > OUTBOX_RETRY_MAX {
+		delay = OUTBOX_RETRY_MAX
+	}
+	return delay
+}
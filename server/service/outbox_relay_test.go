@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+)
+
+// TestOutboxRelay_Relay_KeepsOrderPerKey tests a failed or backing-off message blocks the later messages with the same key
+func TestOutboxRelay_Relay_KeepsOrderPerKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutboxDao := daoMocks.NewMockOutboxDao(ctrl)
+	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Renew(ctx).Return(nil).Times(2)
+
+	failed := &model.Outbox{ID: 1, Topic: "order_created", MsgKey: "ORDER001", Payload: "a1", Status: consts.OUTBOX_PENDING}
+	msgList := []*model.Outbox{
+		failed,
+		{ID: 2, Topic: "order_created", MsgKey: "ORDER002", Payload: "b1", Status: consts.OUTBOX_PENDING},
+		{ID: 3, Topic: "order_status_changed", MsgKey: "ORDER001", Payload: "a2", Status: consts.OUTBOX_PENDING},
+		{ID: 4, Topic: "order_created", MsgKey: "ORDER003", Payload: "c1", Status: consts.OUTBOX_PENDING, Attempts: 1, NextRetryTime: time.Now().Add(time.Minute)},
+		{ID: 5, Topic: "order_status_changed", MsgKey: "ORDER003", Payload: "c2", Status: consts.OUTBOX_PENDING},
+	}
+	mockOutboxDao.EXPECT().GetPending(ctx, OUTBOX_RELAY_BATCH).Return(msgList, nil)
+
+	gomock.InOrder(
+		mockKafkaWriter.EXPECT().SendMsg(ctx, "order_created", "ORDER001", "a1").Return(errors.New("broker not available")),
+		mockKafkaWriter.EXPECT().SendMsg(ctx, "order_created", "ORDER002", "b1").Return(nil),
+	)
+	mockOutboxDao.EXPECT().UpdateDelivery(ctx, failed).Return(nil).Times(1)
+	mockOutboxDao.EXPECT().MarkSent(ctx, 2).Return(nil).Times(1)
+	mockOutboxDao.EXPECT().GetPendingStats(ctx).Return(int64(4), time.Now().Add(-time.Minute), nil)
+
+	relay := &OutboxRelay{
+		outboxDao:   mockOutboxDao,
+		kafkaWriter: mockKafkaWriter,
+		locker:      mockLocker,
+	}
+
+	relay.Relay(ctx)
+
+	if failed.Status != consts.OUTBOX_PENDING || failed.Attempts != 1 {
+		t.Errorf("Expected message to stay pending with 1 attempt, got status %d attempts %d", failed.Status, failed.Attempts)
+	}
+	if !failed.NextRetryTime.After(time.Now()) {
+		t.Errorf("Expected next retry time in the future, got %v", failed.NextRetryTime)
+	}
+}
+
+// TestOutboxRelay_Relay_RetriesExhausted tests a message is given up after the last attempt and stops blocking its key
+func TestOutboxRelay_Relay_RetriesExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutboxDao := daoMocks.NewMockOutboxDao(ctrl)
+	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Renew(ctx).Return(nil).Times(2)
+
+	exhausted := &model.Outbox{ID: 1, Topic: "order_created", MsgKey: "ORDER001", Payload: "a1", Status: consts.OUTBOX_PENDING, Attempts: OUTBOX_MAX_ATTEMPTS - 1}
+	next := &model.Outbox{ID: 2, Topic: "order_status_changed", MsgKey: "ORDER001", Payload: "a2", Status: consts.OUTBOX_PENDING}
+	mockOutboxDao.EXPECT().GetPending(ctx, OUTBOX_RELAY_BATCH).Return([]*model.Outbox{exhausted, next}, nil)
+
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_created", "ORDER001", "a1").Return(errors.New("message too large"))
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", "a2").Return(nil)
+	mockOutboxDao.EXPECT().UpdateDelivery(ctx, exhausted).Return(nil).Times(1)
+	mockOutboxDao.EXPECT().MarkSent(ctx, 2).Return(nil).Times(1)
+	mockOutboxDao.EXPECT().GetPendingStats(ctx).Return(int64(0), time.Time{}, nil)
+
+	relay := &OutboxRelay{
+		outboxDao:   mockOutboxDao,
+		kafkaWriter: mockKafkaWriter,
+		locker:      mockLocker,
+	}
+
+	relay.Relay(ctx)
+
+	if exhausted.Status != consts.OUTBOX_FAILED {
+		t.Errorf("Expected status %d, got %d", consts.OUTBOX_FAILED, exhausted.Status)
+	}
+}
+
+// TestOutboxRelay_Relay_LockLost tests the relay stops publishing once another instance has taken over the expired lock
+func TestOutboxRelay_Relay_LockLost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutboxDao := daoMocks.NewMockOutboxDao(ctrl)
+	mockKafkaWriter := utilMocks.NewMockWriter(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(nil).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Return(utils.ErrLockNotHeld).Times(1)
+	gomock.InOrder(
+		mockLocker.EXPECT().Renew(ctx).Return(nil),
+		mockLocker.EXPECT().Renew(ctx).Return(utils.ErrLockNotHeld),
+	)
+
+	mockOutboxDao.EXPECT().GetPending(ctx, OUTBOX_RELAY_BATCH).Return([]*model.Outbox{
+		{ID: 1, Topic: "order_created", MsgKey: "ORDER001", Payload: "a1", Status: consts.OUTBOX_PENDING},
+		{ID: 2, Topic: "order_status_changed", MsgKey: "ORDER001", Payload: "a2", Status: consts.OUTBOX_PENDING},
+	}, nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_created", "ORDER001", "a1").Return(nil)
+	mockKafkaWriter.EXPECT().SendMsg(ctx, "order_status_changed", gomock.Any(), gomock.Any()).Times(0)
+	mockOutboxDao.EXPECT().MarkSent(ctx, 1).Return(nil).Times(1)
+	mockOutboxDao.EXPECT().GetPendingStats(ctx).Return(int64(1), time.Now(), nil)
+
+	relay := &OutboxRelay{
+		outboxDao:   mockOutboxDao,
+		kafkaWriter: mockKafkaWriter,
+		locker:      mockLocker,
+	}
+
+	relay.Relay(ctx)
+}
+
+// TestOutboxRelay_Relay_LockFailed tests relay is skipped when another instance holds the lock
+func TestOutboxRelay_Relay_LockFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutboxDao := daoMocks.NewMockOutboxDao(ctrl)
+	mockLocker := utilMocks.NewMockLocker(ctrl)
+
+	ctx := context.Background()
+
+	mockLocker.EXPECT().Lock(ctx).Return(errors.New("lock already held")).Times(1)
+	mockLocker.EXPECT().Unlock(ctx).Times(0)
+	mockOutboxDao.EXPECT().GetPending(gomock.Any(), gomock.Any()).Times(0)
+
+	relay := &OutboxRelay{
+		outboxDao: mockOutboxDao,
+		locker:    mockLocker,
+	}
+
+	relay.Relay(ctx)
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		{10, OUTBOX_RETRY_MAX},
+		{30, OUTBOX_RETRY_MAX},
+	}
+	for _, tt := range tests {
+		if got := outboxRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("outboxRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCoupon = errors.New("invalid coupon")
+
+// couponDiscount 优惠券在一个订单上的减免，itemDiscounts 与订单商品一一对应
+type couponDiscount struct {
+	coupon           *model.Coupon
+	itemDiscounts    []int
+	shippingDiscount int
+}
+
+// itemDiscount 商品金额减免合计，未使用优惠券时为 0
+func (d *couponDiscount) itemDiscount() int {
+	if d == nil {
+		return 0
+	}
+	total := 0
+	for _, discount := range d.itemDiscounts {
+		total += discount
+	}
+	return total
+}
+
+// itemDiscountAt 分摊到第 idx 个订单商品的减免
+func (d *couponDiscount) itemDiscountAt(idx int) int {
+	if d == nil {
+		return 0
+	}
+	return d.itemDiscounts[idx]
+}
+
+// total 商品和运费减免合计
+func (d *couponDiscount) total() int {
+	if d == nil {
+		return 0
+	}
+	return d.itemDiscount() + d.shippingDiscount
+}
+
+func (d *couponDiscount) code() string {
+	if d == nil {
+		return ""
+	}
+	return d.coupon.Code
+}
+
+func (d *couponDiscount) orderDiscount(orderNo string) model.OrderDiscount {
+	return model.OrderDiscount{
+		OrderNo:          orderNo,
+		CouponID:         d.coupon.ID,
+		CouponCode:       d.coupon.Code,
+		Type:             d.coupon.Type,
+		ItemDiscount:     d.itemDiscount(),
+		ShippingDiscount: d.shippingDiscount,
+		Description:      describeCoupon(d.coupon),
+	}
+}
+
+// applyCoupon 校验优惠码并计算减免，code 为空时返回 nil。
+// 总使用次数在保存订单时才真正占用，这里只做预检查
+func (o *OrderServiceImpl) applyCoupon(ctx context.Context, code string, userID int, items []*types.OrderItemInfo, itemTotalAmount int, shippingFee int) (*couponDiscount, error) {
+	if code == "" {
+		return nil, nil
+	}
+	coupon, err := o.couponDao.GetByCode(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: coupon %s not found", ErrInvalidCoupon, code)
+	}
+	if err != nil {
+		log.Logger.Errorf("applyCoupon: get coupon failed, code: %s, err: %s", code, err.Error())
+		return nil, err
+	}
+	if err = checkCouponUsable(coupon, itemTotalAmount, time.Now()); err != nil {
+		return nil, err
+	}
+	if err = o.checkCouponUserLimit(ctx, coupon, userID); err != nil {
+		return nil, err
+	}
+
+	discount := calculateCouponDiscount(coupon, items, shippingFee)
+	if discount.total() == 0 {
+		return nil, fmt.Errorf("%w: coupon %s does not apply to this order", ErrInvalidCoupon, code)
+	}
+	return discount, nil
+}
+
+// checkCouponUsable 校验优惠券状态、有效期、总使用次数和最低消费
+func checkCouponUsable(coupon *model.Coupon, itemTotalAmount int, now time.Time) error {
+	switch {
+	case coupon.Status != consts.COUPON_ACTIVE:
+		return fmt.Errorf("%w: coupon %s is disabled", ErrInvalidCoupon, coupon.Code)
+	case !coupon.StartTime.IsZero() && now.Before(coupon.StartTime):
+		return fmt.Errorf("%w: coupon %s is not yet valid", ErrInvalidCoupon, coupon.Code)
+	case !coupon.EndTime.IsZero() && !now.Before(coupon.EndTime):
+		return fmt.Errorf("%w: coupon %s has expired", ErrInvalidCoupon, coupon.Code)
+	case coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit:
+		return fmt.Errorf("%w: coupon %s usage limit reached", ErrInvalidCoupon, coupon.Code)
+	case itemTotalAmount < coupon.MinSpend:
+		return fmt.Errorf("%w: coupon %s requires a minimum spend of %d", ErrInvalidCoupon, coupon.Code, coupon.MinSpend)
+	}
+	return nil
+}
+
+// checkCouponUserLimit 校验用户的使用次数
+func (o *OrderServiceImpl) checkCouponUserLimit(ctx context.Context, coupon *model.Coupon, userID int) error {
+	if coupon.PerUserLimit <= 0 {
+		return nil
+	}
+	used, err := o.couponDao.CountUserRedemptions(ctx, coupon.ID, userID)
+	if err != nil {
+		log.Logger.Errorf("checkCouponUserLimit: count redemptions failed, code: %s, err: %s", coupon.Code, err.Error())
+		return err
+	}
+	if used >= coupon.PerUserLimit {
+		return fmt.Errorf("%w: coupon %s usage limit per user reached", ErrInvalidCoupon, coupon.Code)
+	}
+	return nil
+}
+
+// calculateCouponDiscount 计算优惠券对订单商品和运费的减免
+func calculateCouponDiscount(coupon *model.Coupon, items []*types.OrderItemInfo, shippingFee int) *couponDiscount {
+	discount := &couponDiscount{coupon: coupon, itemDiscounts: make([]int, len(items))}
+	itemTotalAmount := 0
+	for _, item := range items {
+		itemTotalAmount += item.Price * item.Quantity
+	}
+
+	switch coupon.Type {
+	case consts.COUPON_PERCENTAGE:
+		amount := itemTotalAmount * coupon.Value / 100
+		if coupon.MaxDiscount > 0 && amount > coupon.MaxDiscount {
+			amount = coupon.MaxDiscount
+		}
+		discount.itemDiscounts = allocateDiscount(items, amount)
+	case consts.COUPON_FIXED:
+		discount.itemDiscounts = allocateDiscount(items, min(coupon.Value, itemTotalAmount))
+	case consts.COUPON_FREE_SHIPPING:
+		discount.shippingDiscount = shippingFee
+	case consts.COUPON_BUY_X_GET_Y:
+		discount.itemDiscounts = buyXGetYDiscounts(coupon, items)
+	}
+	return discount
+}
+
+// allocateDiscount 按商品金额比例分摊减免，取整的余数计入最后一个商品
+func allocateDiscount(items []*types.OrderItemInfo, amount int) []int {
+	discounts := make([]int, len(items))
+	itemTotalAmount, last := 0, -1
+	for idx, item := range items {
+		if subtotal := item.Price * item.Quantity; subtotal > 0 {
+			itemTotalAmount += subtotal
+			last = idx
+		}
+	}
+	if amount <= 0 || itemTotalAmount == 0 {
+		return discounts
+	}
+
+	allocated := 0
+	for idx, item := range items {
+		if idx == last {
+			discounts[idx] = amount - allocated
+			break
+		}
+		discounts[idx] = amount * item.Price * item.Quantity / itemTotalAmount
+		allocated += discounts[idx]
+	}
+	return discounts
+}
+
+// buyXGetYDiscounts 参与活动的商品每 X + Y 件中赠送 Y 件，按单价从低到高减免
+func buyXGetYDiscounts(coupon *model.Coupon, items []*types.OrderItemInfo) []int {
+	discounts := make([]int, len(items))
+	if coupon.BuyQuantity <= 0 || coupon.GetQuantity <= 0 {
+		return discounts
+	}
+
+	eligible := make([]int, 0, len(items))
+	units := 0
+	for idx, item := range items {
+		if coupon.ProductID == 0 || item.ProductID == coupon.ProductID {
+			eligible = append(eligible, idx)
+			units += item.Quantity
+		}
+	}
+	free := units / (coupon.BuyQuantity + coupon.GetQuantity) * coupon.GetQuantity
+	sort.SliceStable(eligible, func(i, j int) bool {
+		return items[eligible[i]].Price < items[eligible[j]].Price
+	})
+	for _, idx := range eligible {
+		if free == 0 {
+			break
+		}
+		quantity := min(free, items[idx].Quantity)
+		discounts[idx] = quantity * items[idx].Price
+		free -= quantity
+	}
+	return discounts
+}
+
+// describeCoupon 生成展示给用户的优惠说明
+func describeCoupon(coupon *model.Coupon) string {
+	switch coupon.Type {
+	case consts.COUPON_PERCENTAGE:
+		if coupon.MaxDiscount > 0 {
+			return fmt.Sprintf("%d%% off, up to %d", coupon.Value, coupon.MaxDiscount)
+		}
+		return fmt.Sprintf("%d%% off", coupon.Value)
+	case consts.COUPON_FIXED:
+		return fmt.Sprintf("%d off", coupon.Value)
+	case consts.COUPON_FREE_SHIPPING:
+		return "free shipping"
+	case consts.COUPON_BUY_X_GET_Y:
+		return fmt.Sprintf("buy %d get %d free", coupon.BuyQuantity, coupon.GetQuantity)
+	}
+	return coupon.Name
+}
+
+// redeemCoupon 占用优惠券的使用次数并保存优惠明细，与订单在同一事务中提交；
+// 总使用次数和用户的使用次数都在 Redeem 中锁住优惠券后校验，并发下单不会超出上限
+func (o *OrderServiceImpl) redeemCoupon(ctx context.Context, order *model.Order, discount *couponDiscount) error {
+	coupon := discount.coupon
+	redeemed, err := o.couponDao.Redeem(ctx, &model.CouponRedemption{
+		CouponID: coupon.ID,
+		OrderNo:  order.OrderNo,
+		UserID:   order.UserID,
+		Code:     coupon.Code,
+		Discount: discount.total(),
+	})
+	if err != nil {
+		log.Logger.Errorf("redeemCoupon: redeem failed, orderNo: %s, code: %s, err: %s", order.OrderNo, coupon.Code, err.Error())
+		return err
+	}
+	if !redeemed {
+		return fmt.Errorf("%w: coupon %s usage limit reached", ErrInvalidCoupon, coupon.Code)
+	}
+	_, err = o.orderDiscountDao.CreateBatch(ctx, []model.OrderDiscount{discount.orderDiscount(order.OrderNo)})
+	return err
+}
+
+// releaseOrderCoupon 订单取消时退回使用的优惠券，应在取消订单的事务中调用
+func (o *OrderServiceImpl) releaseOrderCoupon(ctx context.Context, order *model.Order) error {
+	if order.CouponCode == "" {
+		return nil
+	}
+	released, err := o.couponDao.Release(ctx, order.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("releaseOrderCoupon: release failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return err
+	}
+	log.Logger.Infof("releaseOrderCoupon: orderNo: %s, code: %s, released: %d", order.OrderNo, order.CouponCode, released)
+	return nil
+}
+
+// getOrderDiscountDetails 转换订单的优惠明细，未使用优惠的订单不查询
+func (o *OrderServiceImpl) getOrderDiscountDetails(ctx context.Context, order *model.Order) ([]*types.OrderDiscountDetail, error) {
+	details := make([]*types.OrderDiscountDetail, 0)
+	if order.DiscountAmount == 0 {
+		return details, nil
+	}
+	discounts, err := o.orderDiscountDao.GetByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		return nil, err
+	}
+	for _, discount := range discounts {
+		details = append(details, toOrderDiscountDetail(discount))
+	}
+	return details, nil
+}
+
+func toOrderDiscountDetail(discount *model.OrderDiscount) *types.OrderDiscountDetail {
+	return &types.OrderDiscountDetail{
+		CouponCode:       discount.CouponCode,
+		Type:             discount.Type,
+		TypeName:         consts.GetCouponTypeName(discount.Type),
+		ItemDiscount:     discount.ItemDiscount,
+		ShippingDiscount: discount.ShippingDiscount,
+		Description:      discount.Description,
+	}
+}
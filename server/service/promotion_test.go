@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+	"gorm.io/gorm"
+)
+
+type promotionTestMocks struct {
+	orderDao         *daoMocks.MockOrderDao
+	orderProductDao  *daoMocks.MockOrderProductDao
+	orderSagaDao     *daoMocks.MockOrderSagaDao
+	paymentResultDao *daoMocks.MockPaymentResultDao
+	couponDao        *daoMocks.MockCouponDao
+	orderDiscountDao *daoMocks.MockOrderDiscountDao
+	invoiceDao       *daoMocks.MockInvoiceDao
+	giftCardDao      *daoMocks.MockGiftCardDao
+	productClient    *mocks.MockProductServiceClient
+	paymentClient    *mocks.MockPaymentServiceClient
+	messageWriter    *utilMocks.MockTxWriter
+}
+
+func newPromotionTestService(ctrl *gomock.Controller) (*OrderServiceImpl, promotionTestMocks) {
+	m := promotionTestMocks{
+		orderDao:         daoMocks.NewMockOrderDao(ctrl),
+		orderProductDao:  daoMocks.NewMockOrderProductDao(ctrl),
+		orderSagaDao:     daoMocks.NewMockOrderSagaDao(ctrl),
+		paymentResultDao: daoMocks.NewMockPaymentResultDao(ctrl),
+		couponDao:        daoMocks.NewMockCouponDao(ctrl),
+		orderDiscountDao: daoMocks.NewMockOrderDiscountDao(ctrl),
+		invoiceDao:       daoMocks.NewMockInvoiceDao(ctrl),
+		giftCardDao:      daoMocks.NewMockGiftCardDao(ctrl),
+		productClient:    mocks.NewMockProductServiceClient(ctrl),
+		paymentClient:    mocks.NewMockPaymentServiceClient(ctrl),
+		messageWriter:    utilMocks.NewMockTxWriter(ctrl),
+	}
+	m.orderDao.EXPECT().WithTx(gomock.Any()).Return(m.orderDao).AnyTimes()
+	m.orderProductDao.EXPECT().WithTx(gomock.Any()).Return(m.orderProductDao).AnyTimes()
+	m.paymentResultDao.EXPECT().WithTx(gomock.Any()).Return(m.paymentResultDao).AnyTimes()
+	m.couponDao.EXPECT().WithTx(gomock.Any()).Return(m.couponDao).AnyTimes()
+	m.orderDiscountDao.EXPECT().WithTx(gomock.Any()).Return(m.orderDiscountDao).AnyTimes()
+	m.invoiceDao.EXPECT().WithTx(gomock.Any()).Return(m.invoiceDao).AnyTimes()
+	m.giftCardDao.EXPECT().WithTx(gomock.Any()).Return(m.giftCardDao).AnyTimes()
+	m.messageWriter.EXPECT().WithTx(gomock.Any()).Return(m.messageWriter).AnyTimes()
+	m.orderSagaDao.EXPECT().UpdateProgress(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	service := &OrderServiceImpl{
+		txBeginner:           testTxBeginner{},
+		orderDao:             m.orderDao,
+		orderProductDao:      m.orderProductDao,
+		orderSagaDao:         m.orderSagaDao,
+		paymentResultDao:     m.paymentResultDao,
+		couponDao:            m.couponDao,
+		orderDiscountDao:     m.orderDiscountDao,
+		invoiceDao:           m.invoiceDao,
+		giftCardDao:          m.giftCardDao,
+		productServiceClient: m.productClient,
+		paymentServiceClient: m.paymentClient,
+		messageWriter:        m.messageWriter,
+	}
+	return service, m
+}
+
+// expectInvoiceIssued 下单付款成功后开具发票
+func (m promotionTestMocks) expectInvoiceIssued(ctx context.Context) {
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+	m.invoiceDao.EXPECT().NextSequence(ctx, gomock.Any()).Return(1, nil)
+	m.invoiceDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+}
+
+// TestCalculateCouponDiscount tests each coupon type against Bowl(1000 x 1) and Cup(500 x 3)
+func TestCalculateCouponDiscount(t *testing.T) {
+	tests := []struct {
+		name             string
+		coupon           *model.Coupon
+		itemDiscounts    []int
+		shippingDiscount int
+	}{
+		{
+			name:          "percentage split by amount",
+			coupon:        &model.Coupon{Type: consts.COUPON_PERCENTAGE, Value: 10},
+			itemDiscounts: []int{100, 150},
+		},
+		{
+			name:          "percentage capped",
+			coupon:        &model.Coupon{Type: consts.COUPON_PERCENTAGE, Value: 50, MaxDiscount: 300},
+			itemDiscounts: []int{120, 180},
+		},
+		{
+			name:          "fixed with remainder on last item",
+			coupon:        &model.Coupon{Type: consts.COUPON_FIXED, Value: 1001},
+			itemDiscounts: []int{400, 601},
+		},
+		{
+			name:          "fixed no more than item total",
+			coupon:        &model.Coupon{Type: consts.COUPON_FIXED, Value: 9999},
+			itemDiscounts: []int{1000, 1500},
+		},
+		{
+			name:             "free shipping",
+			coupon:           &model.Coupon{Type: consts.COUPON_FREE_SHIPPING},
+			itemDiscounts:    []int{0, 0},
+			shippingDiscount: 800,
+		},
+		{
+			name:          "buy 1 get 1 on cheapest items",
+			coupon:        &model.Coupon{Type: consts.COUPON_BUY_X_GET_Y, BuyQuantity: 1, GetQuantity: 1},
+			itemDiscounts: []int{0, 1000},
+		},
+		{
+			name:          "buy 2 get 1 limited to product",
+			coupon:        &model.Coupon{Type: consts.COUPON_BUY_X_GET_Y, BuyQuantity: 2, GetQuantity: 1, ProductID: 2},
+			itemDiscounts: []int{0, 500},
+		},
+		{
+			name:          "buy 2 get 1 not enough items",
+			coupon:        &model.Coupon{Type: consts.COUPON_BUY_X_GET_Y, BuyQuantity: 2, GetQuantity: 1, ProductID: 1},
+			itemDiscounts: []int{0, 0},
+		},
+	}
+
+	items := twoItemOrderInfo().OrderItemList
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount := calculateCouponDiscount(tt.coupon, items, 800)
+			for idx, expected := range tt.itemDiscounts {
+				if discount.itemDiscounts[idx] != expected {
+					t.Errorf("Expected item discounts %v, got %v", tt.itemDiscounts, discount.itemDiscounts)
+					break
+				}
+			}
+			if discount.shippingDiscount != tt.shippingDiscount {
+				t.Errorf("Expected shipping discount %d, got %d", tt.shippingDiscount, discount.shippingDiscount)
+			}
+		})
+	}
+}
+
+// TestOrderServiceImpl_ApplyCoupon_Rejected tests unusable coupons are rejected with ErrInvalidCoupon
+func TestOrderServiceImpl_ApplyCoupon_Rejected(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		coupon     *model.Coupon
+		userUsed   int
+		errMessage string
+	}{
+		{
+			name:       "disabled",
+			coupon:     &model.Coupon{Status: consts.COUPON_DISABLED, Type: consts.COUPON_FREE_SHIPPING},
+			errMessage: "disabled",
+		},
+		{
+			name:       "not started",
+			coupon:     &model.Coupon{Status: consts.COUPON_ACTIVE, Type: consts.COUPON_FREE_SHIPPING, StartTime: now.Add(time.Hour)},
+			errMessage: "not yet valid",
+		},
+		{
+			name:       "expired",
+			coupon:     &model.Coupon{Status: consts.COUPON_ACTIVE, Type: consts.COUPON_FREE_SHIPPING, EndTime: now.Add(-time.Hour)},
+			errMessage: "expired",
+		},
+		{
+			name:       "global limit reached",
+			coupon:     &model.Coupon{Status: consts.COUPON_ACTIVE, Type: consts.COUPON_FREE_SHIPPING, UsageLimit: 5, UsedCount: 5},
+			errMessage: "usage limit reached",
+		},
+		{
+			name:       "minimum spend",
+			coupon:     &model.Coupon{Status: consts.COUPON_ACTIVE, Type: consts.COUPON_FREE_SHIPPING, MinSpend: 3000},
+			errMessage: "minimum spend",
+		},
+		{
+			name:       "per user limit reached",
+			coupon:     &model.Coupon{Status: consts.COUPON_ACTIVE, Type: consts.COUPON_FREE_SHIPPING, PerUserLimit: 1},
+			userUsed:   1,
+			errMessage: "per user",
+		},
+		{
+			name:       "no discount",
+			coupon:     &model.Coupon{Status: consts.COUPON_ACTIVE, Type: consts.COUPON_BUY_X_GET_Y, BuyQuantity: 5, GetQuantity: 1},
+			errMessage: "does not apply",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, m := newPromotionTestService(ctrl)
+			ctx := context.Background()
+			tt.coupon.ID, tt.coupon.Code = 1, "SAVE"
+			m.couponDao.EXPECT().GetByCode(ctx, "SAVE").Return(tt.coupon, nil)
+			m.couponDao.EXPECT().CountUserRedemptions(ctx, 1, 123).Return(tt.userUsed, nil).AnyTimes()
+
+			_, err := service.applyCoupon(ctx, "SAVE", 123, twoItemOrderInfo().OrderItemList, 2500, 800)
+			if !errors.Is(err, ErrInvalidCoupon) {
+				t.Fatalf("Expected ErrInvalidCoupon, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.errMessage) {
+				t.Errorf("Expected error to mention %q, got: %v", tt.errMessage, err)
+			}
+		})
+	}
+}
+
+// TestOrderServiceImpl_ApplyCoupon_NotFound tests an unknown code is rejected
+func TestOrderServiceImpl_ApplyCoupon_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	m.couponDao.EXPECT().GetByCode(ctx, "NOPE").Return(nil, gorm.ErrRecordNotFound)
+
+	if _, err := service.applyCoupon(ctx, "NOPE", 123, twoItemOrderInfo().OrderItemList, 2500, 800); !errors.Is(err, ErrInvalidCoupon) {
+		t.Errorf("Expected ErrInvalidCoupon, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_WithCoupon tests the discount is reflected in the total, products and discount lines
+func TestOrderServiceImpl_CreateOrder_WithCoupon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	coupon := &model.Coupon{ID: 7, Code: "SAVE10", Type: consts.COUPON_PERCENTAGE, Value: 10, Status: consts.COUPON_ACTIVE}
+	// 商品 2500，减免 250，运费 800，税费 (2500 - 250) * 9% = 202
+	expectedTotal := 2500 - 250 + 800 + 202
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.couponDao.EXPECT().GetByCode(ctx, "SAVE10").Return(coupon, nil)
+	m.orderSagaDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *model.OrderSaga) (int, error) {
+			if record.Amount != expectedTotal {
+				t.Errorf("Expected saga amount %d, got %d", expectedTotal, record.Amount)
+			}
+			return 1, nil
+		})
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	m.orderDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+			if order.TotalAmount != expectedTotal || order.DiscountAmount != 250 || order.Tax != 202 || order.CouponCode != "SAVE10" {
+				t.Errorf("Unexpected order amounts: %+v", order)
+			}
+			return order.OrderNo, nil
+		})
+	m.orderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, products []model.OrderProduct) (int, error) {
+			if products[0].DiscountAmount != 100 || products[1].DiscountAmount != 150 {
+				t.Errorf("Unexpected product discounts: %d, %d", products[0].DiscountAmount, products[1].DiscountAmount)
+			}
+			return len(products), nil
+		})
+	m.couponDao.EXPECT().Redeem(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, redemption *model.CouponRedemption) (bool, error) {
+			if redemption.CouponID != 7 || redemption.UserID != 123 || redemption.Discount != 250 {
+				t.Errorf("Unexpected redemption: %+v", redemption)
+			}
+			return true, nil
+		})
+	m.orderDiscountDao.EXPECT().CreateBatch(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, discounts []model.OrderDiscount) (int, error) {
+			if len(discounts) != 1 || discounts[0].ItemDiscount != 250 || discounts[0].CouponCode != "SAVE10" {
+				t.Errorf("Unexpected discount lines: %+v", discounts)
+			}
+			return 1, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	m.paymentClient.EXPECT().PayOrder(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *paymentpb.PayOrderRequest, _ ...interface{}) (*paymentpb.PayOrderResponse, error) {
+			if int(req.Amount) != expectedTotal {
+				t.Errorf("Expected charge %d, got %d", expectedTotal, req.Amount)
+			}
+			return &paymentpb.PayOrderResponse{}, nil
+		})
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).Return(1, nil)
+	m.expectInvoiceIssued(ctx)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.CouponCode = "SAVE10"
+	if _, err := service.CreateOrder(ctx, orderInfo, 123); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_CouponUsedUp tests the order is compensated when the last use is taken concurrently
+func TestOrderServiceImpl_CreateOrder_CouponUsedUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	coupon := &model.Coupon{ID: 7, Code: "ONCE", Type: consts.COUPON_FIXED, Value: 500, Status: consts.COUPON_ACTIVE, UsageLimit: 1}
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.couponDao.EXPECT().GetByCode(ctx, "ONCE").Return(coupon, nil)
+	m.orderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	// 扣减两次，回补两次
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(4)
+	m.orderDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+	m.orderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	m.couponDao.EXPECT().Redeem(ctx, gomock.Any()).Return(false, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+	m.paymentClient.EXPECT().PayOrder(gomock.Any(), gomock.Any()).Times(0)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.CouponCode = "ONCE"
+	if _, err := service.CreateOrder(ctx, orderInfo, 123); !errors.Is(err, ErrInvalidCoupon) {
+		t.Errorf("Expected ErrInvalidCoupon, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_CancelReleasesCoupon tests the coupon is returned in the cancel transaction
+func TestOrderServiceImpl_UpdateOrderStatus_CancelReleasesCoupon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.CREATED)
+	order.CouponCode = "SAVE10"
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).Return(1, nil)
+	m.couponDao.EXPECT().Release(ctx, "ORDER001").Return(1, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil).Times(2)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_canceled", "ORDER001", gomock.Any()).Return(nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+
+	err := service.UpdateOrderStatus(ctx, "ORDER001", consts.CANCELED, consts.TransitionInput{Actor: consts.ActorMerchant, Reason: "out of stock"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_CancelReleaseFailed tests the cancellation is rolled back when the coupon can not be returned
+func TestOrderServiceImpl_UpdateOrderStatus_CancelReleaseFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.CREATED)
+	order.CouponCode = "SAVE10"
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderSagaDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(&model.OrderSaga{Status: consts.SAGA_COMPLETED}, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.CREATED, gomock.Any()).Return(1, nil)
+	m.couponDao.EXPECT().Release(ctx, "ORDER001").Return(0, errors.New("db error"))
+	m.messageWriter.EXPECT().SendMsg(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	m.productClient.EXPECT().UpdateStockWithCAS(gomock.Any(), gomock.Any()).Times(0)
+
+	err := service.UpdateOrderStatus(ctx, "ORDER001", consts.CANCELED, consts.TransitionInput{Actor: consts.ActorMerchant})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if order.Status != consts.CREATED {
+		t.Errorf("Expected order status %d, got %d", consts.CREATED, order.Status)
+	}
+}
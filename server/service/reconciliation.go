@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+)
+
+const (
+	RECONCILE_LOCK_KEY   = "order:reconciliation:lock"
+	RECONCILE_LOCK_TIME  = 30 * time.Minute
+	RECONCILE_INTERVAL   = time.Hour
+	RECONCILE_DELAY      = time.Hour     // 只核对一小时前创建的订单，等待异步支付结果和超时取消完成
+	RECONCILE_WINDOW     = 2 * time.Hour // 大于执行间隔，错过一轮时下一轮仍能覆盖
+	RECONCILE_PAGE_SIZE  = 100
+	RECONCILE_QUERY_SIZE = 10 // 每个订单最多查询的支付单数
+)
+
+// Reconciler 核对订单与支付服务中的支付单，将差异记录到 reconciliation_discrepancies
+type Reconciler struct {
+	orderDao             dao.OrderDao
+	paymentResultDao     dao.PaymentResultDao
+	reconciliationDao    dao.ReconciliationDao
+	paymentServiceClient paymentpb.PaymentServiceClient
+	locker               utils.Locker
+}
+
+func GetReconcilerInstance() *Reconciler {
+	return &Reconciler{
+		orderDao:             dao.GetOrderDao(),
+		paymentResultDao:     dao.GetPaymentResultDao(),
+		reconciliationDao:    dao.GetReconciliationDao(),
+		paymentServiceClient: clients.GetPaymentClient(),
+		locker:               utils.GetDistributedLock(RECONCILE_LOCK_KEY, uuid.New().String(), RECONCILE_LOCK_TIME),
+	}
+}
+
+// Reconcile 核对 [now - RECONCILE_DELAY - RECONCILE_WINDOW, now - RECONCILE_DELAY) 之间创建的订单
+func (r *Reconciler) Reconcile(ctx context.Context) {
+	if err := r.locker.Lock(ctx); err != nil {
+		log.Logger.Info("Reconcile: failed to acquire lock, skipping this round")
+		return
+	}
+	defer func() {
+		if unlockErr := r.locker.Unlock(ctx); unlockErr != nil {
+			log.Logger.Errorf("Reconcile: failed to release lock, err: %s", unlockErr.Error())
+		}
+	}()
+
+	end := time.Now().Add(-RECONCILE_DELAY)
+	r.reconcileRange(ctx, end.Add(-RECONCILE_WINDOW), end)
+}
+
+// reconcileRange 按 id 分页核对时间窗口内的订单，单个订单核对失败不影响其他订单
+func (r *Reconciler) reconcileRange(ctx context.Context, start, end time.Time) {
+	log.Logger.Infof("reconcileRange: start: %v, end: %v", start, end)
+	checked, found, afterID := 0, 0, 0
+	for {
+		orders, err := r.orderDao.GetByCreateTimeRange(ctx, start, end, afterID, RECONCILE_PAGE_SIZE)
+		if err != nil {
+			log.Logger.Errorf("reconcileRange: get orders failed, afterId: %d, err: %s", afterID, err.Error())
+			return
+		}
+		for _, order := range orders {
+			created, err := r.reconcileOrder(ctx, order)
+			if err != nil {
+				log.Logger.Errorf("reconcileRange: reconcile order failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+				continue
+			}
+			checked++
+			if created {
+				found++
+			}
+		}
+		if len(orders) < RECONCILE_PAGE_SIZE {
+			break
+		}
+		afterID = orders[len(orders)-1].ID
+	}
+	log.Logger.Infof("reconcileRange: checked %d orders, found %d new discrepancies", checked, found)
+}
+
+// reconcileOrder 核对一个订单，返回是否记录了新的差异
+func (r *Reconciler) reconcileOrder(ctx context.Context, order *model.Order) (bool, error) {
+	bizId := order.OrderNo
+	querySize := int32(RECONCILE_QUERY_SIZE)
+	resp, err := r.paymentServiceClient.QueryPayOrder(ctx, &paymentpb.PayOrderQueryRequest{
+		UserId:    int32(order.UserID),
+		BizId:     &bizId,
+		QuerySize: &querySize,
+	})
+	if err != nil {
+		return false, err
+	}
+	if resp.Code != 0 {
+		return false, fmt.Errorf("query pay order failed: %s", resp.GetErrorMsg())
+	}
+
+	capturedAmount := 0
+	payOrderIDs := make([]string, 0, len(resp.PayOrderInfos))
+	for _, info := range resp.PayOrderInfos {
+		capturedAmount += int(info.Amount)
+		payOrderIDs = append(payOrderIDs, info.PayOrderId)
+	}
+
+	// 礼品卡和店铺余额抵扣的部分不经过支付服务，只核对支付服务扣款的金额
+	var discrepancyType int
+	paid := !order.PayTime.IsZero()
+	chargedAmount := paidAmount(order) - order.TenderAmount
+	switch {
+	case paid && chargedAmount > 0 && len(payOrderIDs) == 0:
+		discrepancyType = consts.RECON_MISSING_PAYMENT
+	case paid && capturedAmount != chargedAmount:
+		discrepancyType = consts.RECON_AMOUNT_MISMATCH
+	case !paid && len(payOrderIDs) > 0:
+		refunded, err := r.paymentRefunded(ctx, order)
+		if err != nil {
+			return false, err
+		}
+		if refunded {
+			return false, nil
+		}
+		discrepancyType = consts.RECON_UNMATCHED_PAYMENT
+	default:
+		return false, nil
+	}
+
+	log.Logger.Warnf("reconcileOrder: discrepancy %s, orderNo: %s, paidAmount: %d, capturedAmount: %d",
+		consts.GetDiscrepancyTypeName(discrepancyType), order.OrderNo, chargedAmount, capturedAmount)
+	return r.reconciliationDao.Create(ctx, &model.ReconciliationDiscrepancy{
+		OrderNo:     order.OrderNo,
+		Type:        discrepancyType,
+		UserID:      order.UserID,
+		OrderStatus: order.Status,
+		OrderAmount: chargedAmount,
+		PaidAmount:  capturedAmount,
+		PayOrderIDs: strings.Join(payOrderIDs, ","),
+		OrderTime:   order.CreateTime,
+	})
+}
+
+// paymentRefunded 未付款就取消的订单，扣款是否已由 payment_result 消费者或下单 saga 自动退回
+func (r *Reconciler) paymentRefunded(ctx context.Context, order *model.Order) (bool, error) {
+	if order.Status != consts.CANCELED {
+		return false, nil
+	}
+	results, err := r.paymentResultDao.GetByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		return false, err
+	}
+	for _, result := range results {
+		if result.Result != consts.PAYMENT_SUCCESS {
+			continue
+		}
+		if result.Action == consts.PAYMENT_ACTION_REFUNDED || result.Action == consts.PAYMENT_ACTION_SAGA {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListDiscrepancies 分页查询对账差异
+func (r *Reconciler) ListDiscrepancies(ctx context.Context, req types.ListDiscrepancyRequest) (resp *types.ListDiscrepancyResponse, err error) {
+	dList, total, err := r.reconciliationDao.List(ctx, dao.DiscrepancyQuery{
+		Type:      req.Type,
+		OrderNo:   req.OrderNo,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	})
+	if err != nil {
+		log.Logger.Errorf("ListDiscrepancies: list failed, err: %s", err.Error())
+		return nil, err
+	}
+
+	resp = &types.ListDiscrepancyResponse{
+		Discrepancies: make([]*types.DiscrepancyInfo, 0, len(dList)),
+		Total:         total,
+	}
+	for _, d := range dList {
+		payOrderIDs := []string{}
+		if d.PayOrderIDs != "" {
+			payOrderIDs = strings.Split(d.PayOrderIDs, ",")
+		}
+		resp.Discrepancies = append(resp.Discrepancies, &types.DiscrepancyInfo{
+			ID:              d.ID,
+			OrderNo:         d.OrderNo,
+			Type:            d.Type,
+			TypeName:        consts.GetDiscrepancyTypeName(d.Type),
+			UserID:          d.UserID,
+			OrderStatus:     d.OrderStatus,
+			OrderStatusName: consts.GetOrderStatusName(d.OrderStatus),
+			OrderAmount:     d.OrderAmount,
+			PaidAmount:      d.PaidAmount,
+			PayOrderIDs:     payOrderIDs,
+			OrderTime:       d.OrderTime,
+			CreateTime:      d.CreateTime,
+		})
+	}
+	return resp, nil
+}
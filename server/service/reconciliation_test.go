@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	utilMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/utils/mocks"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+)
+
+type reconcilerTestMocks struct {
+	orderDao          *daoMocks.MockOrderDao
+	paymentResultDao  *daoMocks.MockPaymentResultDao
+	reconciliationDao *daoMocks.MockReconciliationDao
+	paymentClient     *mocks.MockPaymentServiceClient
+	locker            *utilMocks.MockLocker
+}
+
+func newTestReconciler(ctrl *gomock.Controller) (*Reconciler, reconcilerTestMocks) {
+	m := reconcilerTestMocks{
+		orderDao:          daoMocks.NewMockOrderDao(ctrl),
+		paymentResultDao:  daoMocks.NewMockPaymentResultDao(ctrl),
+		reconciliationDao: daoMocks.NewMockReconciliationDao(ctrl),
+		paymentClient:     mocks.NewMockPaymentServiceClient(ctrl),
+		locker:            utilMocks.NewMockLocker(ctrl),
+	}
+	reconciler := &Reconciler{
+		orderDao:             m.orderDao,
+		paymentResultDao:     m.paymentResultDao,
+		reconciliationDao:    m.reconciliationDao,
+		paymentServiceClient: m.paymentClient,
+		locker:               m.locker,
+	}
+	return reconciler, m
+}
+
+func payOrderQueryResponse(amounts ...int32) *paymentpb.PayOrderQueryResponse {
+	resp := &paymentpb.PayOrderQueryResponse{}
+	for idx, amount := range amounts {
+		resp.PayOrderInfos = append(resp.PayOrderInfos, &paymentpb.PayOrderInfo{PayOrderId: fmt.Sprintf("PAY%03d", idx+1), Amount: amount})
+	}
+	return resp
+}
+
+// TestReconciler_Reconcile tests every kind of discrepancy is detected and consistent orders are skipped
+func TestReconciler_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconciler, m := newTestReconciler(ctrl)
+	ctx := context.Background()
+	payTime := time.Now().Add(-2 * time.Hour)
+
+	orders := []*model.Order{
+		{ID: 1, OrderNo: "OK001", UserID: 1, Status: consts.PAYED, TotalAmount: 1000, PayTime: payTime},
+		{ID: 2, OrderNo: "MISSING001", UserID: 1, Status: consts.SHIPPED, TotalAmount: 1000, PayTime: payTime},
+		{ID: 3, OrderNo: "MISMATCH001", UserID: 1, Status: consts.DELIVERED, TotalAmount: 1000, PayTime: payTime},
+		{ID: 4, OrderNo: "UNMATCHED001", UserID: 1, Status: consts.CREATED, TotalAmount: 1000},
+		{ID: 5, OrderNo: "REFUNDED001", UserID: 1, Status: consts.CANCELED, TotalAmount: 1000},
+		{ID: 6, OrderNo: "ERROR001", UserID: 1, Status: consts.PAYED, TotalAmount: 1000, PayTime: payTime},
+	}
+	payments := map[string]*paymentpb.PayOrderQueryResponse{
+		"OK001":        payOrderQueryResponse(1000),
+		"MISSING001":   payOrderQueryResponse(),
+		"MISMATCH001":  payOrderQueryResponse(1000, 1000),
+		"UNMATCHED001": payOrderQueryResponse(1000),
+		"REFUNDED001":  payOrderQueryResponse(1000),
+	}
+
+	m.locker.EXPECT().Lock(ctx).Return(nil)
+	m.locker.EXPECT().Unlock(ctx).Return(nil)
+	m.orderDao.EXPECT().GetByCreateTimeRange(ctx, gomock.Any(), gomock.Any(), 0, RECONCILE_PAGE_SIZE).Return(orders, nil)
+	m.paymentClient.EXPECT().QueryPayOrder(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *paymentpb.PayOrderQueryRequest, _ ...interface{}) (*paymentpb.PayOrderQueryResponse, error) {
+			if resp, ok := payments[req.GetBizId()]; ok {
+				return resp, nil
+			}
+			return nil, errors.New("payment service unavailable")
+		}).Times(len(orders))
+	m.paymentResultDao.EXPECT().GetByOrderNo(ctx, "REFUNDED001").Return([]*model.PaymentResult{
+		{OrderNo: "REFUNDED001", Result: consts.PAYMENT_SUCCESS, Action: consts.PAYMENT_ACTION_REFUNDED},
+	}, nil)
+
+	found := map[string]int{}
+	m.reconciliationDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, d *model.ReconciliationDiscrepancy) (bool, error) {
+			found[d.OrderNo] = d.Type
+			return true, nil
+		}).Times(3)
+
+	reconciler.Reconcile(ctx)
+
+	expected := map[string]int{
+		"MISSING001":   consts.RECON_MISSING_PAYMENT,
+		"MISMATCH001":  consts.RECON_AMOUNT_MISMATCH,
+		"UNMATCHED001": consts.RECON_UNMATCHED_PAYMENT,
+	}
+	for orderNo, discrepancyType := range expected {
+		if found[orderNo] != discrepancyType {
+			t.Errorf("Expected %s to have discrepancy %d, got %d", orderNo, discrepancyType, found[orderNo])
+		}
+	}
+}
+
+// TestReconciler_Reconcile_Paging tests orders are paged by id
+func TestReconciler_Reconcile_Paging(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconciler, m := newTestReconciler(ctrl)
+	ctx := context.Background()
+
+	page := make([]*model.Order, RECONCILE_PAGE_SIZE)
+	for idx := range page {
+		page[idx] = &model.Order{ID: idx + 1, Status: consts.CREATED}
+	}
+
+	m.locker.EXPECT().Lock(ctx).Return(nil)
+	m.locker.EXPECT().Unlock(ctx).Return(nil)
+	gomock.InOrder(
+		m.orderDao.EXPECT().GetByCreateTimeRange(ctx, gomock.Any(), gomock.Any(), 0, RECONCILE_PAGE_SIZE).Return(page, nil),
+		m.orderDao.EXPECT().GetByCreateTimeRange(ctx, gomock.Any(), gomock.Any(), RECONCILE_PAGE_SIZE, RECONCILE_PAGE_SIZE).Return(nil, nil),
+	)
+	m.paymentClient.EXPECT().QueryPayOrder(ctx, gomock.Any()).Return(payOrderQueryResponse(), nil).Times(RECONCILE_PAGE_SIZE)
+
+	reconciler.Reconcile(ctx)
+}
+
+// TestReconciler_Reconcile_LockFailed tests the job is skipped when another instance holds the lock
+func TestReconciler_Reconcile_LockFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconciler, m := newTestReconciler(ctrl)
+	ctx := context.Background()
+
+	m.locker.EXPECT().Lock(ctx).Return(errors.New("lock held"))
+	m.orderDao.EXPECT().GetByCreateTimeRange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	reconciler.Reconcile(ctx)
+}
+
+// TestReconciler_ListDiscrepancies tests pay order ids are split and names are filled
+func TestReconciler_ListDiscrepancies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconciler, m := newTestReconciler(ctrl)
+	ctx := context.Background()
+
+	m.reconciliationDao.EXPECT().List(ctx, gomock.Any()).Return([]*model.ReconciliationDiscrepancy{
+		{ID: 1, OrderNo: "ORDER001", Type: consts.RECON_AMOUNT_MISMATCH, OrderStatus: consts.PAYED, PayOrderIDs: "PAY001,PAY002"},
+	}, 1, nil)
+
+	resp, err := reconciler.ListDiscrepancies(ctx, types.ListDiscrepancyRequest{Limit: 20})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Total != 1 || len(resp.Discrepancies) != 1 {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+	d := resp.Discrepancies[0]
+	if d.TypeName != "AmountMismatch" || len(d.PayOrderIDs) != 2 || d.OrderStatusName != consts.GetOrderStatusName(consts.PAYED) {
+		t.Errorf("Unexpected discrepancy: %+v", d)
+	}
+}
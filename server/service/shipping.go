@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+const (
+	// 未配置运费规则时运费 800，商品金额满 30000 免运费
+	DEFAULT_SHIPPING_FEE       = 800
+	DEFAULT_FREE_SHIPPING_FROM = 30000
+	DEFAULT_SHIPPING_ZONE      = "default"
+)
+
+// ShippingCalculator 按收货国家、商品重量和尺寸计算运费
+type ShippingCalculator interface {
+	Calculate(country string, items []*types.OrderItemInfo, itemTotalAmount int) *types.ShippingQuote
+}
+
+type configShippingCalculator struct {
+	defaultZone   *config.ShippingZone
+	defaultWeight int
+	zones         []*config.ShippingZone
+	products      map[int]*config.ProductShipping
+}
+
+// NewShippingCalculator 由配置生成 ShippingCalculator，cfg 为空时使用固定运费
+func NewShippingCalculator(cfg *config.ShippingConfig) ShippingCalculator {
+	calculator := &configShippingCalculator{
+		defaultZone: &config.ShippingZone{
+			Name:          DEFAULT_SHIPPING_ZONE,
+			BaseFee:       DEFAULT_SHIPPING_FEE,
+			FreeThreshold: DEFAULT_FREE_SHIPPING_FROM,
+		},
+		products: make(map[int]*config.ProductShipping),
+	}
+	if cfg == nil {
+		return calculator
+	}
+	calculator.defaultWeight = cfg.DefaultWeight
+	calculator.zones = cfg.Zones
+	for _, zone := range cfg.Zones {
+		if strings.EqualFold(zone.Name, cfg.DefaultZone) {
+			calculator.defaultZone = zone
+		}
+	}
+	for _, product := range cfg.Products {
+		calculator.products[product.ProductID] = product
+	}
+	return calculator
+}
+
+func getShippingCalculator() ShippingCalculator {
+	return NewShippingCalculator(config.Config.ShippingConfig)
+}
+
+// Calculate 计费重量取实际重量和体积重中较大者，超出首重部分按公斤向上取整计费；
+// 达到免运费门槛时只收取易碎商品附加费
+func (c *configShippingCalculator) Calculate(country string, items []*types.OrderItemInfo, itemTotalAmount int) *types.ShippingQuote {
+	zone := c.matchZone(country)
+	quote := &types.ShippingQuote{Zone: zone.Name}
+
+	volume, fragileUnits := 0, 0
+	for _, item := range items {
+		weight := c.defaultWeight
+		if product, ok := c.products[item.ProductID]; ok {
+			if product.Weight > 0 {
+				weight = product.Weight
+			}
+			volume += product.Length * product.Width * product.Height * item.Quantity
+			if product.Fragile {
+				fragileUnits += item.Quantity
+			}
+		}
+		quote.Weight += weight * item.Quantity
+	}
+
+	quote.ChargeableWeight = quote.Weight
+	if zone.VolumetricDivisor > 0 {
+		// 体积(立方厘米) / 系数 得到公斤，换算为克
+		quote.ChargeableWeight = max(quote.ChargeableWeight, volume*1000/zone.VolumetricDivisor)
+	}
+	quote.BaseFee = zone.BaseFee
+	if extra := quote.ChargeableWeight - zone.BaseWeight; extra > 0 && zone.PerKgFee > 0 {
+		quote.WeightFee = (extra + 999) / 1000 * zone.PerKgFee
+	}
+	quote.FragileSurcharge = fragileUnits * zone.FragileSurcharge
+	quote.FreeShipping = zone.FreeThreshold > 0 && itemTotalAmount >= zone.FreeThreshold
+
+	quote.ShippingFee = quote.FragileSurcharge
+	if !quote.FreeShipping {
+		quote.ShippingFee += quote.BaseFee + quote.WeightFee
+	}
+	return quote
+}
+
+func (c *configShippingCalculator) matchZone(country string) *config.ShippingZone {
+	for _, zone := range c.zones {
+		for _, zoneCountry := range zone.Countries {
+			if strings.EqualFold(zoneCountry, country) {
+				return zone
+			}
+		}
+	}
+	return c.defaultZone
+}
+
+// calculateShipping 未注入 shippingCalculator 时使用固定运费
+func (o *OrderServiceImpl) calculateShipping(country string, items []*types.OrderItemInfo, itemTotalAmount int) *types.ShippingQuote {
+	calculator := o.shippingCalculator
+	if calculator == nil {
+		calculator = NewShippingCalculator(nil)
+	}
+	return calculator.Calculate(country, items, itemTotalAmount)
+}
+
+// QuoteShipping 按商品服务的当前价格计算运费，不创建订单
+func (o *OrderServiceImpl) QuoteShipping(ctx context.Context, req types.ShippingQuoteRequest) (quote *types.ShippingQuote, err error) {
+	pricedItems, itemTotalAmount, err := o.getPricedItems(ctx, req.OrderItemList)
+	if err != nil {
+		log.Logger.Errorf("QuoteShipping: price order items failed, err: %s", err.Error())
+		return nil, err
+	}
+	return o.calculateShipping(req.ReceiverCountry, pricedItems, itemTotalAmount), nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/clients/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+// testShippingConfig Bowl(1200g, 20x20x10, 易碎)，Cup(300g)，Vase(800g, 50x40x40)
+func testShippingConfig() *config.ShippingConfig {
+	return &config.ShippingConfig{
+		DefaultZone:   "international",
+		DefaultWeight: 500,
+		Zones: []*config.ShippingZone{
+			{Name: "domestic", Countries: []string{"SG"}, BaseFee: 800, BaseWeight: 2000, PerKgFee: 200,
+				VolumetricDivisor: 5000, FragileSurcharge: 300, FreeThreshold: 30000},
+			{Name: "international", BaseFee: 2500, BaseWeight: 1000, PerKgFee: 1200,
+				VolumetricDivisor: 5000, FragileSurcharge: 800},
+		},
+		Products: []*config.ProductShipping{
+			{ProductID: 1, Weight: 1200, Length: 20, Width: 20, Height: 10, Fragile: true},
+			{ProductID: 2, Weight: 300},
+			{ProductID: 3, Weight: 800, Length: 50, Width: 40, Height: 40},
+		},
+	}
+}
+
+// TestShippingCalculator_Calculate tests zone matching, weight steps, volumetric weight and fragile surcharges
+func TestShippingCalculator_Calculate(t *testing.T) {
+	bowlAndCups := twoItemOrderInfo().OrderItemList
+	tests := []struct {
+		name             string
+		cfg              *config.ShippingConfig
+		country          string
+		items            []*types.OrderItemInfo
+		itemTotalAmount  int
+		zone             string
+		chargeableWeight int
+		shippingFee      int
+	}{
+		{
+			name:            "no config charges flat fee",
+			country:         "SG",
+			items:           bowlAndCups,
+			itemTotalAmount: 2500,
+			zone:            DEFAULT_SHIPPING_ZONE,
+			shippingFee:     800,
+		},
+		{
+			name:            "no config free above threshold",
+			country:         "SG",
+			items:           bowlAndCups,
+			itemTotalAmount: 30000,
+			zone:            DEFAULT_SHIPPING_ZONE,
+			shippingFee:     0,
+		},
+		{
+			name:             "domestic weight step and fragile surcharge",
+			cfg:              testShippingConfig(),
+			country:          "sg",
+			items:            bowlAndCups,
+			itemTotalAmount:  2500,
+			zone:             "domestic",
+			chargeableWeight: 2100,
+			shippingFee:      800 + 200 + 300,
+		},
+		{
+			name:             "free shipping keeps fragile surcharge",
+			cfg:              testShippingConfig(),
+			country:          "SG",
+			items:            bowlAndCups,
+			itemTotalAmount:  30000,
+			zone:             "domestic",
+			chargeableWeight: 2100,
+			shippingFee:      300,
+		},
+		{
+			name:             "unknown country uses default zone",
+			cfg:              testShippingConfig(),
+			country:          "JP",
+			items:            bowlAndCups,
+			itemTotalAmount:  2500,
+			zone:             "international",
+			chargeableWeight: 2100,
+			shippingFee:      2500 + 2*1200 + 800,
+		},
+		{
+			name:             "volumetric weight of a large vase",
+			cfg:              testShippingConfig(),
+			country:          "SG",
+			items:            []*types.OrderItemInfo{{ProductID: 3, Quantity: 1, Price: 5000}},
+			itemTotalAmount:  5000,
+			zone:             "domestic",
+			chargeableWeight: 16000,
+			shippingFee:      800 + 14*200,
+		},
+		{
+			name:             "unconfigured product uses default weight",
+			cfg:              testShippingConfig(),
+			country:          "SG",
+			items:            []*types.OrderItemInfo{{ProductID: 9, Quantity: 2, Price: 1000}},
+			itemTotalAmount:  2000,
+			zone:             "domestic",
+			chargeableWeight: 1000,
+			shippingFee:      800,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := NewShippingCalculator(tt.cfg).Calculate(tt.country, tt.items, tt.itemTotalAmount)
+			if quote.Zone != tt.zone || quote.ChargeableWeight != tt.chargeableWeight || quote.ShippingFee != tt.shippingFee {
+				t.Errorf("Expected zone %s weight %d fee %d, got %+v", tt.zone, tt.chargeableWeight, tt.shippingFee, quote)
+			}
+		})
+	}
+}
+
+// TestOrderServiceImpl_QuoteShipping tests the quote uses server-side prices for the free shipping threshold
+func TestOrderServiceImpl_QuoteShipping(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	service := &OrderServiceImpl{
+		productServiceClient: mockProductClient,
+		shippingCalculator:   NewShippingCalculator(testShippingConfig()),
+	}
+	ctx := context.Background()
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(&productpb.GetProductListResponse{
+		Products: []*productpb.Product{{Id: 1, Name: "Bowl", Price: 30000, Stock: 10}},
+	}, nil)
+
+	// 客户端提交的价格低于门槛，按商品服务的价格免运费
+	quote, err := service.QuoteShipping(ctx, types.ShippingQuoteRequest{
+		ReceiverCountry: "SG",
+		OrderItemList:   []*types.OrderItemInfo{{ProductID: 1, Quantity: 1, Price: 100}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !quote.FreeShipping || quote.ShippingFee != 300 {
+		t.Errorf("Expected free shipping with fragile surcharge 300, got %+v", quote)
+	}
+}
+
+// TestOrderServiceImpl_QuoteShipping_InvalidItems tests an unknown product is reported as invalid items
+func TestOrderServiceImpl_QuoteShipping_InvalidItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductClient := mocks.NewMockProductServiceClient(ctrl)
+	service := &OrderServiceImpl{productServiceClient: mockProductClient}
+	ctx := context.Background()
+	mockProductClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(&productpb.GetProductListResponse{}, nil)
+
+	_, err := service.QuoteShipping(ctx, types.ShippingQuoteRequest{
+		ReceiverCountry: "SG",
+		OrderItemList:   []*types.OrderItemInfo{{ProductID: 1, Quantity: 1}},
+	})
+	if !errors.Is(err, ErrInvalidOrderItems) {
+		t.Errorf("Expected ErrInvalidOrderItems, got: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+)
+
+const (
+	// DEFAULT_TAX_RATE 未配置税率时使用新加坡 GST 9%
+	DEFAULT_TAX_RATE    = 900
+	DEFAULT_TAX_COUNTRY = "SG"
+	TAX_RATE_BASE       = 10000
+)
+
+// TaxLine 一个订单商品的计税金额，Amount 为优惠后的商品金额
+type TaxLine struct {
+	ProductID int
+	Amount    int
+}
+
+// TaxLineResult 一个订单商品适用的税率和税额
+type TaxLineResult struct {
+	Category string // 税务类别，标准税率时为空
+	Rate     int
+	Amount   int
+}
+
+// TaxResult 订单的计税结果，Lines 与 TaxLine 一一对应
+type TaxResult struct {
+	Region    string // 匹配到的税率规则，如 SG、US-94
+	Rate      int    // 标准税率
+	Inclusive bool   // 为 true 时商品价格已含税，税额不再计入订单总金额
+	Tax       int
+	Lines     []TaxLineResult
+}
+
+// TaxCalculator 按收货地址计算订单税费
+type TaxCalculator interface {
+	Calculate(country string, zipCode int, lines []TaxLine) *TaxResult
+}
+
+type configTaxCalculator struct {
+	defaultCountry string
+	regions        []*config.TaxRegion
+	categories     map[int]string
+}
+
+// NewTaxCalculator 由配置生成 TaxCalculator，cfg 为空时所有订单使用默认税率
+func NewTaxCalculator(cfg *config.TaxConfig) TaxCalculator {
+	calculator := &configTaxCalculator{
+		defaultCountry: DEFAULT_TAX_COUNTRY,
+		categories:     make(map[int]string),
+	}
+	if cfg == nil {
+		return calculator
+	}
+	if cfg.DefaultCountry != "" {
+		calculator.defaultCountry = cfg.DefaultCountry
+	}
+	calculator.regions = cfg.Regions
+	for category, productIDs := range cfg.ProductCategories {
+		for _, productID := range productIDs {
+			// viper 读取的 map key 为小写，统一按小写比较
+			calculator.categories[productID] = strings.ToLower(category)
+		}
+	}
+	return calculator
+}
+
+func getTaxCalculator() TaxCalculator {
+	return NewTaxCalculator(config.Config.TaxConfig)
+}
+
+// Calculate 先匹配税率规则，再按税率分组计税，组内税额按金额比例分摊到商品，余数计入最后一个商品
+func (c *configTaxCalculator) Calculate(country string, zipCode int, lines []TaxLine) *TaxResult {
+	region := c.matchRegion(country, zipCode)
+	if region == nil {
+		region = c.matchRegion(c.defaultCountry, 0)
+	}
+	result := &TaxResult{
+		Region: DEFAULT_TAX_COUNTRY,
+		Rate:   DEFAULT_TAX_RATE,
+		Lines:  make([]TaxLineResult, len(lines)),
+	}
+	if region != nil {
+		result.Region = regionName(region)
+		result.Rate = region.Rate
+		result.Inclusive = region.Inclusive
+	}
+
+	groupAmount := make(map[int]int)
+	groupLast := make(map[int]int)
+	for idx, line := range lines {
+		category := c.categories[line.ProductID]
+		rate := result.Rate
+		if region != nil && category != "" {
+			if categoryRate, ok := lowerKeyLookup(region.CategoryRates, category); ok {
+				rate = categoryRate
+			}
+		}
+		result.Lines[idx] = TaxLineResult{Category: category, Rate: rate}
+		if line.Amount > 0 {
+			groupAmount[rate] += line.Amount
+			groupLast[rate] = idx
+		}
+	}
+
+	groupTax := make(map[int]int, len(groupAmount))
+	for rate, amount := range groupAmount {
+		groupTax[rate] = taxOf(amount, rate, result.Inclusive)
+		result.Tax += groupTax[rate]
+	}
+	groupAllocated := make(map[int]int, len(groupAmount))
+	for idx, line := range lines {
+		rate := result.Lines[idx].Rate
+		if line.Amount <= 0 {
+			continue
+		}
+		if idx == groupLast[rate] {
+			result.Lines[idx].Amount = groupTax[rate] - groupAllocated[rate]
+			continue
+		}
+		result.Lines[idx].Amount = groupTax[rate] * line.Amount / groupAmount[rate]
+		groupAllocated[rate] += result.Lines[idx].Amount
+	}
+	return result
+}
+
+// matchRegion 在国家的规则中选择邮编前缀最长的一条
+func (c *configTaxCalculator) matchRegion(country string, zipCode int) *config.TaxRegion {
+	zip := strconv.Itoa(zipCode)
+	var matched *config.TaxRegion
+	for _, region := range c.regions {
+		if !strings.EqualFold(region.Country, country) {
+			continue
+		}
+		if region.ZipPrefix != "" && (zipCode == 0 || !strings.HasPrefix(zip, region.ZipPrefix)) {
+			continue
+		}
+		if matched == nil || len(region.ZipPrefix) > len(matched.ZipPrefix) {
+			matched = region
+		}
+	}
+	return matched
+}
+
+func regionName(region *config.TaxRegion) string {
+	name := strings.ToUpper(region.Country)
+	if region.ZipPrefix != "" {
+		name += "-" + region.ZipPrefix
+	}
+	return name
+}
+
+func lowerKeyLookup(m map[string]int, key string) (int, bool) {
+	for k, v := range m {
+		if strings.ToLower(k) == key {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// taxOf 含税价格中包含的税额为 amount * rate / (1 + rate)
+func taxOf(amount int, rate int, inclusive bool) int {
+	if inclusive {
+		return amount * rate / (TAX_RATE_BASE + rate)
+	}
+	return amount * rate / TAX_RATE_BASE
+}
+
+// calculateTax 按优惠后的商品金额计算订单税费，未注入 taxCalculator 时使用默认税率
+func (o *OrderServiceImpl) calculateTax(country string, zipCode int, items []*types.OrderItemInfo, discount *couponDiscount) *TaxResult {
+	calculator := o.taxCalculator
+	if calculator == nil {
+		calculator = NewTaxCalculator(nil)
+	}
+	lines := make([]TaxLine, len(items))
+	for idx, item := range items {
+		lines[idx] = TaxLine{
+			ProductID: item.ProductID,
+			Amount:    item.Price*item.Quantity - discount.itemDiscountAt(idx),
+		}
+	}
+	return calculator.Calculate(country, zipCode, lines)
+}
+
+// orderAmount 订单总金额，含税价格的税额已包含在商品金额中
+func orderAmount(itemTotalAmount int, shippingFee int, tax *TaxResult, discount *couponDiscount) int {
+	total := itemTotalAmount + shippingFee - discount.total()
+	if !tax.Inclusive {
+		total += tax.Tax
+	}
+	return total
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/config"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+)
+
+// testTaxConfig SG 9%，美国按邮编前缀区分税率，德国含税价格且书籍(商品 2)为 7%
+func testTaxConfig() *config.TaxConfig {
+	return &config.TaxConfig{
+		DefaultCountry: "SG",
+		Regions: []*config.TaxRegion{
+			{Country: "SG", Rate: 900},
+			{Country: "US", Rate: 400},
+			{Country: "US", ZipPrefix: "94", Rate: 725},
+			{Country: "DE", Rate: 1900, Inclusive: true, CategoryRates: map[string]int{"books": 700}},
+		},
+		ProductCategories: map[string][]int{"books": {2}},
+	}
+}
+
+// TestTaxCalculator_Calculate tests region matching and tax split for Bowl(1000) and Book(1500)
+func TestTaxCalculator_Calculate(t *testing.T) {
+	lines := []TaxLine{{ProductID: 1, Amount: 1000}, {ProductID: 2, Amount: 1500}}
+	tests := []struct {
+		name       string
+		cfg        *config.TaxConfig
+		country    string
+		zipCode    int
+		region     string
+		inclusive  bool
+		tax        int
+		lineTaxes  []int
+		categories []string
+	}{
+		{
+			name:       "no config uses 9%",
+			country:    "USA",
+			region:     "SG",
+			tax:        225,
+			lineTaxes:  []int{90, 135},
+			categories: []string{"", ""},
+		},
+		{
+			name:       "country is case insensitive",
+			cfg:        testTaxConfig(),
+			country:    "sg",
+			region:     "SG",
+			tax:        225,
+			lineTaxes:  []int{90, 135},
+			categories: []string{"", "books"},
+		},
+		{
+			name:       "longest zip prefix wins",
+			cfg:        testTaxConfig(),
+			country:    "US",
+			zipCode:    94105,
+			region:     "US-94",
+			tax:        181, // 2500 * 7.25%
+			lineTaxes:  []int{72, 109},
+			categories: []string{"", "books"},
+		},
+		{
+			name:       "country rate without matching prefix",
+			cfg:        testTaxConfig(),
+			country:    "US",
+			zipCode:    10001,
+			region:     "US",
+			tax:        100,
+			lineTaxes:  []int{40, 60},
+			categories: []string{"", "books"},
+		},
+		{
+			name:       "unknown country uses default country",
+			cfg:        testTaxConfig(),
+			country:    "FR",
+			region:     "SG",
+			tax:        225,
+			lineTaxes:  []int{90, 135},
+			categories: []string{"", "books"},
+		},
+		{
+			name:       "inclusive price with category rate",
+			cfg:        testTaxConfig(),
+			country:    "DE",
+			region:     "DE",
+			inclusive:  true,
+			tax:        257, // 1000 * 19 / 119 + 1500 * 7 / 107
+			lineTaxes:  []int{159, 98},
+			categories: []string{"", "books"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewTaxCalculator(tt.cfg).Calculate(tt.country, tt.zipCode, lines)
+			if result.Region != tt.region || result.Inclusive != tt.inclusive || result.Tax != tt.tax {
+				t.Errorf("Expected region %s inclusive %v tax %d, got %+v", tt.region, tt.inclusive, tt.tax, result)
+			}
+			for idx, line := range result.Lines {
+				if line.Amount != tt.lineTaxes[idx] || line.Category != tt.categories[idx] {
+					t.Errorf("Line %d: expected tax %d category %q, got %+v", idx, tt.lineTaxes[idx], tt.categories[idx], line)
+				}
+			}
+		})
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_TaxInclusive tests the applied rate and item taxes are stored and not added to the total
+func TestOrderServiceImpl_CreateOrder_TaxInclusive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	service.taxCalculator = NewTaxCalculator(testTaxConfig())
+	ctx := context.Background()
+	// 商品 2500 已含税，运费 800
+	expectedTotal := 2500 + 800
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.orderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	m.orderDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+			if order.TotalAmount != expectedTotal || order.Tax != 257 || order.TaxRegion != "DE" || order.TaxRate != 1900 || !order.TaxInclusive {
+				t.Errorf("Unexpected order tax: %+v", order)
+			}
+			return order.OrderNo, nil
+		})
+	m.orderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, products []model.OrderProduct) (int, error) {
+			if products[0].TaxRate != 1900 || products[0].TaxAmount != 159 ||
+				products[1].TaxRate != 700 || products[1].TaxAmount != 98 || products[1].TaxCategory != "books" {
+				t.Errorf("Unexpected product taxes: %+v", products)
+			}
+			return len(products), nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	m.paymentClient.EXPECT().PayOrder(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *paymentpb.PayOrderRequest, _ ...interface{}) (*paymentpb.PayOrderResponse, error) {
+			if int(req.Amount) != expectedTotal {
+				t.Errorf("Expected charge %d, got %d", expectedTotal, req.Amount)
+			}
+			return &paymentpb.PayOrderResponse{}, nil
+		})
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).Return(1, nil)
+	m.expectInvoiceIssued(ctx)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.ReceiverCountry = "DE"
+	if _, err := service.CreateOrder(ctx, orderInfo, 123); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_RequestRefund_StoredItemTax tests a refund uses the tax stored on the item instead of a proportional share
+func TestOrderServiceImpl_RequestRefund_StoredItemTax(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.DELIVERED)
+	// 商品 B 为免税类别
+	order.TaxRegion, order.TaxRate, order.Tax, order.TotalAmount = "SG", 900, 180, 3480
+	products[0].TaxRate, products[0].TaxAmount = 900, 180
+	products[1].TaxCategory = "exempt"
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().GetItemsByOrderNo(ctx, "ORDER001").Return(nil, nil)
+	m.refundDao.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, refund *model.Refund, items []model.RefundItem) (string, error) {
+			if refund.Amount != 500 {
+				t.Errorf("Expected refund amount 500, got %d", refund.Amount)
+			}
+			return refund.RefundNo, nil
+		})
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.DELIVERED, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+
+	_, err := service.RequestRefund(ctx, "ORDER001", 123, types.RefundRequest{
+		Reason: "broken",
+		Items:  []*types.RefundItemRequest{{OrderProductID: 12, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/log"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"gorm.io/gorm"
+)
+
+// planTenders 按顺序使用礼品卡，再使用店铺余额抵扣订单金额，返回每张卡的抵扣金额；
+// 这里只做校验和试算，实际扣减在 holdTenders 中与订单一起提交
+func (o *OrderServiceImpl) planTenders(ctx context.Context, orderInfo types.OrderInfo, userID int, amount int) ([]model.OrderTender, error) {
+	if len(orderInfo.GiftCardCodes) == 0 && !orderInfo.UseStoreCredit {
+		return nil, nil
+	}
+
+	now := time.Now()
+	remaining := amount
+	tenders := make([]model.OrderTender, 0, len(orderInfo.GiftCardCodes)+1)
+	seen := make(map[string]bool, len(orderInfo.GiftCardCodes))
+	use := func(card *model.GiftCard) {
+		take := min(card.Balance, remaining)
+		if take <= 0 {
+			return
+		}
+		tenders = append(tenders, model.OrderTender{
+			GiftCardID: card.ID,
+			Code:       card.Code,
+			Type:       card.Type,
+			Amount:     take,
+			Status:     consts.TENDER_HELD,
+		})
+		remaining -= take
+	}
+
+	for _, code := range orderInfo.GiftCardCodes {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		card, err := o.giftCardDao.GetByCode(ctx, code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s not found", ErrInvalidGiftCard, code)
+		}
+		if err != nil {
+			log.Logger.Errorf("planTenders: get gift card failed, code: %s, err: %s", code, err.Error())
+			return nil, err
+		}
+		// 店铺余额只能通过 use_store_credit 使用
+		if card.Type != consts.GIFT_CARD_TYPE_GIFT_CARD {
+			return nil, fmt.Errorf("%w: %s not found", ErrInvalidGiftCard, code)
+		}
+		if err = checkGiftCardUsable(card, now); err != nil {
+			return nil, err
+		}
+		use(card)
+	}
+
+	if orderInfo.UseStoreCredit {
+		card, err := o.giftCardDao.GetByCode(ctx, storeCreditCode(userID))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Logger.Errorf("planTenders: get store credit failed, userID: %d, err: %s", userID, err.Error())
+			return nil, err
+		}
+		// 没有店铺余额时直接使用其他支付方式
+		if err == nil && card.Status == consts.GIFT_CARD_ACTIVE {
+			use(card)
+		}
+	}
+	return tenders, nil
+}
+
+func checkGiftCardUsable(card *model.GiftCard, now time.Time) error {
+	if card.Status != consts.GIFT_CARD_ACTIVE {
+		return fmt.Errorf("%w: %s is disabled", ErrInvalidGiftCard, card.Code)
+	}
+	if !card.ExpireTime.IsZero() && !card.ExpireTime.After(now) {
+		return fmt.Errorf("%w: %s has expired", ErrInvalidGiftCard, card.Code)
+	}
+	if card.Balance <= 0 {
+		return fmt.Errorf("%w: %s has no balance", ErrInvalidGiftCard, card.Code)
+	}
+	return nil
+}
+
+func tenderTotal(tenders []model.OrderTender) int {
+	total := 0
+	for _, tender := range tenders {
+		total += tender.Amount
+	}
+	return total
+}
+
+// holdTenders 冻结订单使用的储值卡余额，余额已被其他订单使用时整单失败，应在创建订单的事务中调用
+func (o *OrderServiceImpl) holdTenders(ctx context.Context, order *model.Order, tenders []model.OrderTender) error {
+	if len(tenders) == 0 {
+		return nil
+	}
+	for idx := range tenders {
+		tenders[idx].OrderNo = order.OrderNo
+	}
+	if _, err := o.giftCardDao.CreateTenders(ctx, tenders); err != nil {
+		log.Logger.Errorf("holdTenders: create tenders failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return err
+	}
+	for _, tender := range tenders {
+		held, err := o.giftCardDao.AdjustBalance(ctx, &model.GiftCardTransaction{
+			GiftCardID: tender.GiftCardID,
+			OrderNo:    order.OrderNo,
+			Type:       consts.GIFT_CARD_TXN_HOLD,
+			Amount:     -tender.Amount,
+		})
+		if err != nil {
+			log.Logger.Errorf("holdTenders: hold failed, orderNo: %s, code: %s, err: %s", order.OrderNo, tender.Code, err.Error())
+			return err
+		}
+		if !held {
+			return fmt.Errorf("%w: %s has insufficient balance", ErrInvalidGiftCard, tender.Code)
+		}
+	}
+	return nil
+}
+
+// captureOrderTenders 订单付款后扣除冻结的余额，应在付款的事务中调用
+func (o *OrderServiceImpl) captureOrderTenders(ctx context.Context, order *model.Order, oldStatus int) error {
+	if oldStatus != consts.CREATED || order.TenderAmount == 0 {
+		return nil
+	}
+	return o.updateOrderTenders(ctx, order, consts.TENDER_HELD, consts.TENDER_CAPTURED, 0)
+}
+
+// releaseOrderTenders 付款失败或未付款订单取消时解冻余额，应在取消订单的事务中调用
+func (o *OrderServiceImpl) releaseOrderTenders(ctx context.Context, order *model.Order) error {
+	if order.TenderAmount == 0 {
+		return nil
+	}
+	return o.updateOrderTenders(ctx, order, consts.TENDER_HELD, consts.TENDER_RELEASED, consts.GIFT_CARD_TXN_RELEASE)
+}
+
+// updateOrderTenders 修改订单储值卡的状态，txnType 不为 0 时把冻结的金额加回余额
+func (o *OrderServiceImpl) updateOrderTenders(ctx context.Context, order *model.Order, oldStatus, newStatus, txnType int) error {
+	tenders, err := o.giftCardDao.GetTendersByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("updateOrderTenders: get tenders failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return err
+	}
+	for _, tender := range tenders {
+		rows, err := o.giftCardDao.UpdateTenderStatus(ctx, tender.ID, oldStatus, newStatus)
+		if err != nil {
+			log.Logger.Errorf("updateOrderTenders: update failed, orderNo: %s, code: %s, err: %s", order.OrderNo, tender.Code, err.Error())
+			return err
+		}
+		// 已经处理过的储值卡不重复加回余额
+		if rows == 0 || txnType == 0 {
+			continue
+		}
+		if _, err = o.giftCardDao.AdjustBalance(ctx, &model.GiftCardTransaction{
+			GiftCardID: tender.GiftCardID,
+			OrderNo:    order.OrderNo,
+			Type:       txnType,
+			Amount:     tender.Amount,
+		}); err != nil {
+			log.Logger.Errorf("updateOrderTenders: adjust balance failed, orderNo: %s, code: %s, err: %s", order.OrderNo, tender.Code, err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreOrderTenders 退款时优先退回原礼品卡或店铺余额，返回还需要通过支付服务退款的金额，应在退款的事务中调用
+func (o *OrderServiceImpl) restoreOrderTenders(ctx context.Context, order *model.Order, refundNo string, amount int) (remaining int, err error) {
+	if order.TenderAmount == 0 || o.giftCardDao == nil {
+		return amount, nil
+	}
+	tenders, err := o.giftCardDao.GetTendersByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		log.Logger.Errorf("restoreOrderTenders: get tenders failed, orderNo: %s, err: %s", order.OrderNo, err.Error())
+		return 0, err
+	}
+	remaining = amount
+	for _, tender := range tenders {
+		if remaining == 0 {
+			break
+		}
+		take := min(tender.Amount-tender.RefundedAmount, remaining)
+		if tender.Status != consts.TENDER_CAPTURED || take <= 0 {
+			continue
+		}
+		rows, err := o.giftCardDao.AddTenderRefund(ctx, tender.ID, take)
+		if err != nil {
+			log.Logger.Errorf("restoreOrderTenders: add refund failed, orderNo: %s, code: %s, err: %s", order.OrderNo, tender.Code, err.Error())
+			return 0, err
+		}
+		if rows == 0 {
+			continue
+		}
+		if _, err = o.giftCardDao.AdjustBalance(ctx, &model.GiftCardTransaction{
+			GiftCardID: tender.GiftCardID,
+			OrderNo:    order.OrderNo,
+			RefundNo:   refundNo,
+			Type:       consts.GIFT_CARD_TXN_RESTORE,
+			Amount:     take,
+		}); err != nil {
+			log.Logger.Errorf("restoreOrderTenders: adjust balance failed, orderNo: %s, code: %s, err: %s", order.OrderNo, tender.Code, err.Error())
+			return 0, err
+		}
+		remaining -= take
+	}
+	return remaining, nil
+}
+
+// creditStoreCredit 退款发放为用户的店铺余额，首次发放时创建账户，应在退款的事务中调用
+func (o *OrderServiceImpl) creditStoreCredit(ctx context.Context, order *model.Order, refundNo string, amount int) error {
+	if amount <= 0 {
+		return nil
+	}
+	code := storeCreditCode(order.UserID)
+	if _, err := o.giftCardDao.Create(ctx, &model.GiftCard{
+		Code:   code,
+		Type:   consts.GIFT_CARD_TYPE_STORE_CREDIT,
+		UserID: order.UserID,
+		Status: consts.GIFT_CARD_ACTIVE,
+	}); err != nil {
+		log.Logger.Errorf("creditStoreCredit: create account failed, userID: %d, err: %s", order.UserID, err.Error())
+		return err
+	}
+	card, err := o.giftCardDao.GetByCode(ctx, code)
+	if err != nil {
+		log.Logger.Errorf("creditStoreCredit: get account failed, userID: %d, err: %s", order.UserID, err.Error())
+		return err
+	}
+	if _, err = o.giftCardDao.AdjustBalance(ctx, &model.GiftCardTransaction{
+		GiftCardID: card.ID,
+		OrderNo:    order.OrderNo,
+		RefundNo:   refundNo,
+		Type:       consts.GIFT_CARD_TXN_CREDIT,
+		Amount:     amount,
+	}); err != nil {
+		log.Logger.Errorf("creditStoreCredit: credit failed, orderNo: %s, refundNo: %s, err: %s", order.OrderNo, refundNo, err.Error())
+		return err
+	}
+	return nil
+}
+
+// getOrderTenderDetails 转换订单使用的储值卡，未使用储值卡的订单不查询
+func (o *OrderServiceImpl) getOrderTenderDetails(ctx context.Context, order *model.Order) ([]*types.OrderTenderDetail, error) {
+	details := make([]*types.OrderTenderDetail, 0)
+	if order.TenderAmount == 0 {
+		return details, nil
+	}
+	tenders, err := o.giftCardDao.GetTendersByOrderNo(ctx, order.OrderNo)
+	if err != nil {
+		return nil, err
+	}
+	for _, tender := range tenders {
+		details = append(details, &types.OrderTenderDetail{
+			Code:           maskGiftCardCode(tender.Code),
+			Type:           tender.Type,
+			TypeName:       consts.GetGiftCardTypeName(tender.Type),
+			Amount:         tender.Amount,
+			RefundedAmount: tender.RefundedAmount,
+			Status:         tender.Status,
+			StatusName:     consts.GetTenderStatusName(tender.Status),
+		})
+	}
+	return details, nil
+}
+
+// maskGiftCardCode 订单详情中只显示卡号后 4 位
+func maskGiftCardCode(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+	return strings.Repeat("*", len(code)-4) + code[len(code)-4:]
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sw5005-sus/ceramicraft-commodity-mservice/common/productpb"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/consts"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/pkg/types"
+	daoMocks "github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/dao/mocks"
+	"github.com/sw5005-sus/ceramicraft-order-mservice/server/repository/model"
+	"github.com/sw5005-sus/ceramicraft-payment-mservice/common/paymentpb"
+	"gorm.io/gorm"
+)
+
+// 商品 2500，运费 800，税费 2500 * 9% = 225
+const tenderTestOrderTotal = 3525
+
+func activeGiftCard(id int, code string, balance int) *model.GiftCard {
+	return &model.GiftCard{ID: id, Code: code, Type: consts.GIFT_CARD_TYPE_GIFT_CARD, Balance: balance, Status: consts.GIFT_CARD_ACTIVE}
+}
+
+// expectTendersHeld 下单时按 amounts 的顺序冻结储值卡余额
+func expectTendersHeld(t *testing.T, ctx context.Context, giftCardDao *daoMocks.MockGiftCardDao, amounts ...int) {
+	giftCardDao.EXPECT().CreateTenders(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, tenders []model.OrderTender) (int, error) {
+			if len(tenders) != len(amounts) {
+				t.Fatalf("Expected %d tenders, got %+v", len(amounts), tenders)
+			}
+			for idx, tender := range tenders {
+				if tender.Amount != amounts[idx] || tender.Status != consts.TENDER_HELD || tender.OrderNo == "" {
+					t.Errorf("Unexpected tender %d: %+v", idx, tender)
+				}
+			}
+			return len(tenders), nil
+		})
+	for _, amount := range amounts {
+		giftCardDao.EXPECT().AdjustBalance(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, txn *model.GiftCardTransaction) (bool, error) {
+				if txn.Type != consts.GIFT_CARD_TXN_HOLD || txn.Amount != -amount {
+					t.Errorf("Expected hold of %d, got %+v", amount, txn)
+				}
+				return true, nil
+			})
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_SplitTender tests gift card then store credit are held and only the rest is charged
+func TestOrderServiceImpl_CreateOrder_SplitTender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	storeCredit := &model.GiftCard{ID: 2, Code: "SC-123", Type: consts.GIFT_CARD_TYPE_STORE_CREDIT, UserID: 123, Balance: 500, Status: consts.GIFT_CARD_ACTIVE}
+	charged := tenderTestOrderTotal - 1000 - 500
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.giftCardDao.EXPECT().GetByCode(ctx, "GC-1").Return(activeGiftCard(1, "GC-1", 1000), nil)
+	m.giftCardDao.EXPECT().GetByCode(ctx, "SC-123").Return(storeCredit, nil)
+	m.orderSagaDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *model.OrderSaga) (int, error) {
+			if record.Amount != charged {
+				t.Errorf("Expected saga amount %d, got %d", charged, record.Amount)
+			}
+			return 1, nil
+		})
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	m.orderDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+			if order.TotalAmount != tenderTestOrderTotal || order.TenderAmount != 1500 {
+				t.Errorf("Unexpected order amounts: %+v", order)
+			}
+			return order.OrderNo, nil
+		})
+	m.orderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	expectTendersHeld(t, ctx, m.giftCardDao, 1000, 500)
+	m.messageWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	m.paymentClient.EXPECT().PayOrder(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *paymentpb.PayOrderRequest, _ ...interface{}) (*paymentpb.PayOrderResponse, error) {
+			if int(req.Amount) != charged {
+				t.Errorf("Expected charge %d, got %d", charged, req.Amount)
+			}
+			return &paymentpb.PayOrderResponse{}, nil
+		})
+	m.paymentResultDao.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["pay_method"] != consts.PAY_METHOD_SPLIT || updates["pay_amount"] != charged {
+				t.Errorf("Unexpected payment updates: %v", updates)
+			}
+			return 1, nil
+		})
+	// 付款后扣除冻结的余额
+	m.giftCardDao.EXPECT().GetTendersByOrderNo(ctx, gomock.Any()).Return([]*model.OrderTender{
+		{ID: 1, GiftCardID: 1, Amount: 1000, Status: consts.TENDER_HELD},
+		{ID: 2, GiftCardID: 2, Amount: 500, Status: consts.TENDER_HELD},
+	}, nil)
+	m.giftCardDao.EXPECT().UpdateTenderStatus(ctx, gomock.Any(), consts.TENDER_HELD, consts.TENDER_CAPTURED).Return(1, nil).Times(2)
+	m.expectInvoiceIssued(ctx)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.GiftCardCodes = []string{"GC-1", "GC-1"}
+	orderInfo.UseStoreCredit = true
+	if _, err := service.CreateOrder(ctx, orderInfo, 123); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_GiftCardCoversTotal tests a fully covered order is confirmed without calling the payment service
+func TestOrderServiceImpl_CreateOrder_GiftCardCoversTotal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.giftCardDao.EXPECT().GetByCode(ctx, "GC-1").Return(activeGiftCard(1, "GC-1", 5000), nil)
+	m.orderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+	m.orderDao.EXPECT().Create(ctx, gomock.Any()).Return("", nil)
+	m.orderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	expectTendersHeld(t, ctx, m.giftCardDao, tenderTestOrderTotal)
+	m.messageWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	m.paymentClient.EXPECT().PayOrder(gomock.Any(), gomock.Any()).Times(0)
+	m.paymentClient.EXPECT().QueryPayOrder(gomock.Any(), gomock.Any()).Times(0)
+	m.paymentResultDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+	m.orderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ int, updates map[string]interface{}) (int, error) {
+			if updates["pay_method"] != consts.PAY_METHOD_GIFT_CARD || updates["pay_amount"] != 0 {
+				t.Errorf("Unexpected payment updates: %v", updates)
+			}
+			return 1, nil
+		})
+	m.giftCardDao.EXPECT().GetTendersByOrderNo(ctx, gomock.Any()).Return([]*model.OrderTender{
+		{ID: 1, GiftCardID: 1, Amount: tenderTestOrderTotal, Status: consts.TENDER_HELD},
+	}, nil)
+	m.giftCardDao.EXPECT().UpdateTenderStatus(ctx, 1, consts.TENDER_HELD, consts.TENDER_CAPTURED).Return(1, nil)
+	m.expectInvoiceIssued(ctx)
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.GiftCardCodes = []string{"GC-1"}
+	if _, err := service.CreateOrder(ctx, orderInfo, 123); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_PaymentFailedReleasesTenders tests the held balance goes back to the card when the charge fails
+func TestOrderServiceImpl_CreateOrder_PaymentFailedReleasesTenders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	var persisted *model.Order
+
+	m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+	m.giftCardDao.EXPECT().GetByCode(ctx, "GC-1").Return(activeGiftCard(1, "GC-1", 1000), nil)
+	m.orderSagaDao.EXPECT().Create(ctx, gomock.Any()).Return(1, nil)
+	// 扣减两次，回补两次
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(4)
+	m.orderDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *model.Order) (string, error) {
+			copied := *order
+			persisted = &copied
+			return order.OrderNo, nil
+		})
+	m.orderProductDao.EXPECT().CreateBatch(ctx, gomock.Any()).Return(2, nil)
+	expectTendersHeld(t, ctx, m.giftCardDao, 1000)
+	m.messageWriter.EXPECT().SendMsg(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	m.paymentClient.EXPECT().PayOrder(ctx, gomock.Any()).Return(&paymentpb.PayOrderResponse{Code: 1}, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string) (*model.Order, error) { return persisted, nil })
+	m.paymentResultDao.EXPECT().GetByOrderNo(ctx, gomock.Any()).Return(nil, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, gomock.Any(), consts.CREATED, gomock.Any()).Return(1, nil)
+	m.giftCardDao.EXPECT().GetTendersByOrderNo(ctx, gomock.Any()).Return([]*model.OrderTender{
+		{ID: 1, GiftCardID: 1, Amount: 1000, Status: consts.TENDER_HELD},
+	}, nil)
+	m.giftCardDao.EXPECT().UpdateTenderStatus(ctx, 1, consts.TENDER_HELD, consts.TENDER_RELEASED).Return(1, nil)
+	m.giftCardDao.EXPECT().AdjustBalance(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, txn *model.GiftCardTransaction) (bool, error) {
+			if txn.Type != consts.GIFT_CARD_TXN_RELEASE || txn.Amount != 1000 || txn.GiftCardID != 1 {
+				t.Errorf("Unexpected release: %+v", txn)
+			}
+			return true, nil
+		})
+
+	orderInfo := twoItemOrderInfo()
+	orderInfo.GiftCardCodes = []string{"GC-1"}
+	if _, err := service.CreateOrder(ctx, orderInfo, 123); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+// TestOrderServiceImpl_CreateOrder_GiftCardRejected tests unusable cards fail the order before stock is reserved
+func TestOrderServiceImpl_CreateOrder_GiftCardRejected(t *testing.T) {
+	expired := activeGiftCard(1, "GC-1", 1000)
+	expired.ExpireTime = time.Now().Add(-time.Hour)
+	disabled := activeGiftCard(1, "GC-1", 1000)
+	disabled.Status = consts.GIFT_CARD_DISABLED
+	storeCredit := &model.GiftCard{ID: 1, Code: "GC-1", Type: consts.GIFT_CARD_TYPE_STORE_CREDIT, UserID: 456, Balance: 1000, Status: consts.GIFT_CARD_ACTIVE}
+
+	tests := []struct {
+		name string
+		card *model.GiftCard
+		err  error
+	}{
+		{name: "not found", err: gorm.ErrRecordNotFound},
+		{name: "expired", card: expired},
+		{name: "disabled", card: disabled},
+		{name: "empty", card: activeGiftCard(1, "GC-1", 0)},
+		{name: "store credit by code", card: storeCredit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service, m := newPromotionTestService(ctrl)
+			ctx := context.Background()
+			m.productClient.EXPECT().GetProductList(ctx, gomock.Any()).Return(twoProductListResponse(), nil)
+			m.giftCardDao.EXPECT().GetByCode(ctx, "GC-1").Return(tt.card, tt.err)
+			m.orderSagaDao.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+			orderInfo := twoItemOrderInfo()
+			orderInfo.GiftCardCodes = []string{"GC-1"}
+			if _, err := service.CreateOrder(ctx, orderInfo, 123); !errors.Is(err, ErrInvalidGiftCard) {
+				t.Errorf("Expected ErrInvalidGiftCard, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestOrderServiceImpl_UpdateOrderStatus_CancelPaidRestoresTenders tests a paid order is refunded to its cards first and the rest through payment
+func TestOrderServiceImpl_UpdateOrderStatus_CancelPaidRestoresTenders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	service.invoiceDao = newNoInvoiceDao(ctrl)
+	ctx := context.Background()
+	order, products := refundTestOrder(consts.PAYED)
+	order.PayMethod, order.PayAmount, order.TenderAmount = consts.PAY_METHOD_SPLIT, 2525, 1000
+	tenders := []*model.OrderTender{{ID: 1, GiftCardID: 1, Amount: 1000, Status: consts.TENDER_CAPTURED}}
+
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.PAYED, gomock.Any()).Return(1, nil)
+	m.orderProductDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(products, nil).Times(2)
+	// 已扣除的储值卡不解冻，而是退回
+	m.giftCardDao.EXPECT().GetTendersByOrderNo(ctx, "ORDER001").Return(tenders, nil).Times(2)
+	m.giftCardDao.EXPECT().UpdateTenderStatus(ctx, 1, consts.TENDER_HELD, consts.TENDER_RELEASED).Return(0, nil)
+	m.giftCardDao.EXPECT().AddTenderRefund(ctx, 1, 1000).Return(1, nil)
+	m.giftCardDao.EXPECT().AdjustBalance(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, txn *model.GiftCardTransaction) (bool, error) {
+			if txn.Type != consts.GIFT_CARD_TXN_RESTORE || txn.Amount != 1000 {
+				t.Errorf("Unexpected restore: %+v", txn)
+			}
+			return true, nil
+		})
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_canceled", "ORDER001", gomock.Any()).Return(nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", "ORDER001", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, msg string) error {
+			if !strings.Contains(msg, `"amount":2525`) {
+				t.Errorf("Unexpected refund message: %s", msg)
+			}
+			return nil
+		})
+	m.productClient.EXPECT().UpdateStockWithCAS(ctx, gomock.Any()).Return(&productpb.UpdateStockWithCASResponse{}, nil).Times(2)
+
+	err := service.UpdateOrderStatus(ctx, "ORDER001", consts.CANCELED, consts.TransitionInput{Actor: consts.ActorMerchant, Reason: "out of stock"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+// TestOrderServiceImpl_ApproveRefund_ToStoreCredit tests a store credit refund returns the card share first and credits the rest
+func TestOrderServiceImpl_ApproveRefund_ToStoreCredit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newRefundTestService(ctrl)
+	giftCardDao := daoMocks.NewMockGiftCardDao(ctrl)
+	giftCardDao.EXPECT().WithTx(gomock.Any()).Return(giftCardDao).AnyTimes()
+	service.giftCardDao = giftCardDao
+	ctx := context.Background()
+	order, _ := refundTestOrder(consts.REFUNDING)
+	order.PayMethod, order.PayAmount, order.TenderAmount = consts.PAY_METHOD_SPLIT, 3225, 300
+	refund := &model.Refund{RefundNo: "Rf-1", OrderNo: "ORDER001", Amount: 1090, Status: consts.REFUND_PENDING, PrevStatus: consts.DELIVERED, ToStoreCredit: true}
+
+	m.refundDao.EXPECT().GetByRefundNo(ctx, "Rf-1").Return(refund, nil)
+	m.orderDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return(order, nil)
+	m.refundDao.EXPECT().GetByOrderNo(ctx, "ORDER001").Return([]*model.Refund{refund}, nil)
+	m.refundDao.EXPECT().UpdateStatus(ctx, "Rf-1", consts.REFUND_PENDING, gomock.Any()).Return(1, nil)
+	m.orderDao.EXPECT().UpdateStatus(ctx, "ORDER001", consts.REFUNDING, gomock.Any()).Return(1, nil)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_status_changed", "ORDER001", gomock.Any()).Return(nil)
+	m.expectNoInvoice(ctx, "ORDER001")
+	giftCardDao.EXPECT().GetTendersByOrderNo(ctx, "ORDER001").Return([]*model.OrderTender{
+		{ID: 1, GiftCardID: 1, Amount: 300, Status: consts.TENDER_CAPTURED},
+	}, nil)
+	giftCardDao.EXPECT().AddTenderRefund(ctx, 1, 300).Return(1, nil)
+	giftCardDao.EXPECT().Create(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, card *model.GiftCard) (bool, error) {
+			if card.Code != "SC-123" || card.Type != consts.GIFT_CARD_TYPE_STORE_CREDIT || card.UserID != 123 {
+				t.Errorf("Unexpected store credit account: %+v", card)
+			}
+			return false, nil
+		})
+	giftCardDao.EXPECT().GetByCode(ctx, "SC-123").Return(&model.GiftCard{ID: 9, Code: "SC-123"}, nil)
+	gomock.InOrder(
+		giftCardDao.EXPECT().AdjustBalance(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, txn *model.GiftCardTransaction) (bool, error) {
+				if txn.GiftCardID != 1 || txn.Type != consts.GIFT_CARD_TXN_RESTORE || txn.Amount != 300 || txn.RefundNo != "Rf-1" {
+					t.Errorf("Unexpected restore: %+v", txn)
+				}
+				return true, nil
+			}),
+		giftCardDao.EXPECT().AdjustBalance(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, txn *model.GiftCardTransaction) (bool, error) {
+				if txn.GiftCardID != 9 || txn.Type != consts.GIFT_CARD_TXN_CREDIT || txn.Amount != 790 {
+					t.Errorf("Unexpected credit: %+v", txn)
+				}
+				return true, nil
+			}),
+	)
+	m.messageWriter.EXPECT().SendMsg(ctx, "order_refund", gomock.Any(), gomock.Any()).Times(0)
+
+	if err := service.ApproveRefund(ctx, "Rf-1"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// TestPlanTenders_StoreCreditOnly tests store credit is skipped when the user has none and capped at the order amount
+func TestPlanTenders_StoreCreditOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newPromotionTestService(ctrl)
+	ctx := context.Background()
+	orderInfo := types.OrderInfo{UseStoreCredit: true}
+
+	m.giftCardDao.EXPECT().GetByCode(ctx, "SC-123").Return(nil, gorm.ErrRecordNotFound)
+	tenders, err := service.planTenders(ctx, orderInfo, 123, 1000)
+	if err != nil || len(tenders) != 0 {
+		t.Errorf("Expected no tenders, got: %+v, err: %v", tenders, err)
+	}
+
+	m.giftCardDao.EXPECT().GetByCode(ctx, "SC-123").Return(&model.GiftCard{ID: 2, Code: "SC-123", Type: consts.GIFT_CARD_TYPE_STORE_CREDIT, Balance: 5000, Status: consts.GIFT_CARD_ACTIVE}, nil)
+	tenders, err = service.planTenders(ctx, orderInfo, 123, 1000)
+	if err != nil || len(tenders) != 1 || tenders[0].Amount != 1000 {
+		t.Errorf("Expected store credit of 1000, got: %+v, err: %v", tenders, err)
+	}
+}